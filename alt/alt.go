@@ -62,7 +62,7 @@ func Decompose(v any, options ...*ojg.Options) any {
 	if opt.Converter != nil {
 		v = opt.Converter.Convert(v)
 	}
-	return decompose(v, opt)
+	return decompose(v, opt, NewCycles(opt))
 }
 
 // Alter the data into all simple types converting non simple to simple types
@@ -77,7 +77,7 @@ func Alter(v any, options ...*ojg.Options) any {
 	if opt.Converter != nil {
 		v = opt.Converter.Convert(v)
 	}
-	return alter(v, opt)
+	return alter(v, opt, NewCycles(opt))
 }
 
 // Recompose simple data into more complex go types.
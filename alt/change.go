@@ -0,0 +1,492 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package alt
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+	"unsafe"
+
+	"github.com/ohler55/ojg/gen"
+)
+
+// ChangeKind identifies the kind of a Change.
+type ChangeKind byte
+
+const (
+	// Added indicates a value is in the second value but not the first.
+	Added ChangeKind = 'a'
+	// Removed indicates a value is in the first value but not the second.
+	Removed ChangeKind = 'r'
+	// Changed indicates a value is different in the second value.
+	Changed ChangeKind = 'c'
+	// Moved indicates an array element is at a different index in the
+	// second value.
+	Moved ChangeKind = 'm'
+)
+
+// String returns the name of the kind.
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Changed:
+		return "changed"
+	case Moved:
+		return "moved"
+	}
+	return fmt.Sprintf("ChangeKind(%d)", k)
+}
+
+// Change is a record of one difference found by DiffChanges.
+type Change struct {
+	// Kind of change.
+	Kind ChangeKind
+
+	// Path to the value. For an Added, Changed, or Moved change array
+	// indexes are indexes in the second value. For a Removed change they
+	// are indexes in the first value.
+	Path Path
+
+	// From is the path in the first value to an array element that was
+	// moved. It is nil for all other kinds of change.
+	From Path
+
+	// Old value or nil if the value was added.
+	Old any
+
+	// New value or nil if the value was removed.
+	New any
+}
+
+// String returns a description of the change.
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("added %v: %v", c.Path, c.New)
+	case Removed:
+		return fmt.Sprintf("removed %v: %v", c.Path, c.Old)
+	case Moved:
+		return fmt.Sprintf("moved %v to %v", c.From, c.Path)
+	}
+	return fmt.Sprintf("changed %v: %v to %v", c.Path, c.Old, c.New)
+}
+
+// ArrayMatch identifies how array elements are paired up when comparing
+// arrays.
+type ArrayMatch int
+
+const (
+	// MatchIndex compares the array elements at the same index.
+	MatchIndex ArrayMatch = iota
+	// MatchLCS pairs up equal elements using a longest common subsequence
+	// so that an insertion or removal is reported as a single change. Equal
+	// elements that are not part of the subsequence are reported as moved.
+	MatchLCS
+	// MatchKey pairs up elements that have the same value at the
+	// DiffOptions Key path. Paired elements are compared and reported as
+	// moved if their order changed.
+	MatchKey
+)
+
+// DiffOptions are the options for DiffChanges.
+type DiffOptions struct {
+	// ArrayMatch is the method used to pair up array elements.
+	ArrayMatch ArrayMatch
+
+	// Key is the path from an array element to the value that identifies it
+	// when ArrayMatch is MatchKey. Elements without a value at the key path
+	// or with the same key as an earlier element are matched by value.
+	Key Path
+
+	// FloatTolerance is the largest difference between two numbers that are
+	// considered equal when either of them is a float.
+	FloatTolerance float64
+
+	// TimeTolerance is the tolerance when comparing time elements. If zero
+	// the package TimeTolerance is used.
+	TimeTolerance time.Duration
+
+	// Ignores are paths to ignore in the comparison. A nil path element
+	// matches any key or index.
+	Ignores []Path
+}
+
+type differ struct {
+	DiffOptions
+	changes []Change
+}
+
+// DiffChanges returns a record of each difference between two values. Map
+// members are compared in key order and *gen.Ordered members in member
+// order. The values can be simple data, gen.Node data, or types that are
+// decomposed for the comparison. A gen.Node is compared and reported as
+// simple data.
+func DiffChanges(v0, v1 any, options ...*DiffOptions) []Change {
+	d := differ{}
+	if 0 < len(options) && options[0] != nil {
+		d.DiffOptions = *options[0]
+	}
+	if d.TimeTolerance == 0 {
+		d.TimeTolerance = TimeTolerance
+	}
+	if n, ok := v0.(gen.Node); ok {
+		v0 = n.Simplify()
+	}
+	if n, ok := v1.(gen.Node); ok {
+		v1 = n.Simplify()
+	}
+	d.compare(Path{}, v0, v1, d.Ignores)
+
+	return d.changes
+}
+
+func (d *differ) add(c Change) {
+	c.Path = append(Path{}, c.Path...)
+	if c.From != nil {
+		c.From = append(Path{}, c.From...)
+	}
+	d.changes = append(d.changes, c)
+}
+
+func (d *differ) compare(path Path, v0, v1 any, ignores []Path) {
+	switch t0 := v0.(type) {
+	case nil:
+		if v1 != nil {
+			d.add(Change{Kind: Changed, Path: path, New: v1})
+		}
+	case bool:
+		if t1, ok := v1.(bool); !ok || t0 != t1 {
+			d.add(Change{Kind: Changed, Path: path, Old: v0, New: v1})
+		}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		if !d.sameNumber(v0, v1) {
+			d.add(Change{Kind: Changed, Path: path, Old: v0, New: v1})
+		}
+	case string:
+		if t1, ok := v1.(string); !ok || t0 != t1 {
+			d.add(Change{Kind: Changed, Path: path, Old: v0, New: v1})
+		}
+	case time.Time:
+		if t1, ok := v1.(time.Time); !ok || !t0.Round(d.TimeTolerance).Equal(t1.Round(d.TimeTolerance)) {
+			d.add(Change{Kind: Changed, Path: path, Old: v0, New: v1})
+		}
+	case []any:
+		if t1, ok := v1.([]any); ok {
+			d.compareArrays(path, t0, t1, ignores)
+		} else {
+			d.add(Change{Kind: Changed, Path: path, Old: v0, New: v1})
+		}
+	case map[string]any:
+		switch t1 := v1.(type) {
+		case map[string]any:
+			d.compareObjects(path, mapKeys(t0, t1), t0, t1, ignores)
+		case *gen.Ordered:
+			d.compareObjects(path, mapKeys(t0, orderedAsMap(t1).(map[string]any)), t0, t1, ignores)
+		default:
+			d.add(Change{Kind: Changed, Path: path, Old: v0, New: v1})
+		}
+	case *gen.Ordered:
+		switch t1 := v1.(type) {
+		case map[string]any:
+			d.compareObjects(path, orderedKeys(t0, t1), t0, t1, ignores)
+		case *gen.Ordered:
+			d.compareObjects(path, orderedKeys(t0, orderedAsMap(t1).(map[string]any)), t0, t1, ignores)
+		default:
+			d.add(Change{Kind: Changed, Path: path, Old: v0, New: v1})
+		}
+	default:
+		vt0 := (*[2]uintptr)(unsafe.Pointer(&v0))[0]
+		vt1 := (*[2]uintptr)(unsafe.Pointer(&v1))[0]
+		if vt0 == vt1 {
+			if s0, _ := v0.(Simplifier); s0 != nil {
+				if s1, _ := v1.(Simplifier); s1 != nil {
+					d.compare(path, s0.Simplify(), s1.Simplify(), ignores)
+					return
+				}
+			}
+			opt := &Options{}
+			r0 := reflectValue(reflect.ValueOf(v0), v0, opt, nil)
+			r1 := reflectValue(reflect.ValueOf(v1), v1, opt, nil)
+			if r0 != nil && r1 != nil {
+				d.compare(path, r0, r1, ignores)
+				return
+			}
+			if reflect.DeepEqual(v0, v1) {
+				return
+			}
+		}
+		d.add(Change{Kind: Changed, Path: path, Old: v0, New: v1})
+	}
+}
+
+func (d *differ) sameNumber(v0, v1 any) bool {
+	if i0, ok := asInt(v0); ok {
+		if i1, ok := asInt(v1); ok {
+			return i0 == i1
+		}
+	}
+	f0, _ := asFloat(v0)
+	f1, ok := asFloat(v1)
+
+	return ok && math.Abs(f0-f1) <= d.FloatTolerance
+}
+
+// same returns true if there are no differences between the two values.
+func (d *differ) same(v0, v1 any, ignores []Path) bool {
+	sub := differ{DiffOptions: d.DiffOptions}
+	sub.compare(Path{}, v0, v1, ignores)
+
+	return len(sub.changes) == 0
+}
+
+func (d *differ) compareObjects(path Path, keys []string, v0, v1 any, ignores []Path) {
+	childIgnores := childIgnores(ignores, false)
+	path = append(path, nil)
+	for _, k := range keys {
+		if ignoreKey(k, ignores) {
+			continue
+		}
+		path[len(path)-1] = k
+		m0, has0 := memberValue(v0, k)
+		m1, has1 := memberValue(v1, k)
+		switch {
+		case !has0:
+			d.add(Change{Kind: Added, Path: path, New: m1})
+		case !has1:
+			d.add(Change{Kind: Removed, Path: path, Old: m0})
+		default:
+			d.compare(path, m0, m1, childIgnores)
+		}
+	}
+}
+
+func (d *differ) compareArrays(path Path, a0, a1 []any, ignores []Path) {
+	childIgnores := childIgnores(ignores, true)
+	path = append(path, nil)
+	switch d.ArrayMatch {
+	case MatchLCS:
+		d.matchArrays(path, a0, a1, ignores, childIgnores, func(i, j int) bool {
+			return d.same(a0[i], a1[j], childIgnores)
+		})
+	case MatchKey:
+		k0 := d.elementKeys(a0)
+		k1 := d.elementKeys(a1)
+		d.matchArrays(path, a0, a1, ignores, childIgnores, func(i, j int) bool {
+			if k0[i] == nil || k1[j] == nil {
+				return k0[i] == nil && k1[j] == nil && d.same(a0[i], a1[j], childIgnores)
+			}
+			return d.same(k0[i], k1[j], nil)
+		})
+	default:
+		for j, m1 := range a1 {
+			if ignoreIndex(j, ignores) {
+				continue
+			}
+			path[len(path)-1] = j
+			if len(a0) <= j {
+				d.add(Change{Kind: Added, Path: path, New: m1})
+				continue
+			}
+			d.compare(path, a0[j], m1, childIgnores)
+		}
+		for i := len(a1); i < len(a0); i++ {
+			if ignoreIndex(i, ignores) {
+				continue
+			}
+			path[len(path)-1] = i
+			d.add(Change{Kind: Removed, Path: path, Old: a0[i]})
+		}
+	}
+}
+
+// matchArrays pairs up the elements that match using a longest common
+// subsequence. Elements that match but are not in the subsequence are
+// reported as moved. Unmatched elements that are between the same pair of
+// subsequence elements are compared by position and the rest are added or
+// removed.
+func (d *differ) matchArrays(path Path, a0, a1 []any, ignores, childIgnores []Path, match func(i, j int) bool) {
+	n0 := len(a0)
+	n1 := len(a1)
+	eq := make([][]bool, n0)
+	lens := make([][]int, n0+1)
+	for i := range lens {
+		lens[i] = make([]int, n1+1)
+	}
+	for i := n0 - 1; 0 <= i; i-- {
+		eq[i] = make([]bool, n1)
+		for j := n1 - 1; 0 <= j; j-- {
+			eq[i][j] = match(i, j)
+			switch {
+			case eq[i][j]:
+				lens[i][j] = lens[i+1][j+1] + 1
+			case lens[i][j+1] < lens[i+1][j]:
+				lens[i][j] = lens[i+1][j]
+			default:
+				lens[i][j] = lens[i][j+1]
+			}
+		}
+	}
+	// pairs maps indexes in a1 to the matching index in a0 or -1.
+	pairs := make([]int, n1)
+	for j := range pairs {
+		pairs[j] = -1
+	}
+	used := make([]bool, n0)
+	moved := make([]bool, n1)
+	// Unmatched elements between the same pair of subsequence elements
+	// share a gap number so they can be paired up by position.
+	gap0 := make([]int, n0)
+	gap1 := make([]int, n1)
+	gap := 0
+	for i, j := 0, 0; i < n0 || j < n1; {
+		switch {
+		case i < n0 && j < n1 && eq[i][j]:
+			pairs[j] = i
+			used[i] = true
+			gap++
+			i++
+			j++
+		case n1 <= j || (i < n0 && lens[i][j+1] <= lens[i+1][j]):
+			gap0[i] = gap
+			i++
+		default:
+			gap1[j] = gap
+			j++
+		}
+	}
+	// Matching elements outside of the subsequence have moved.
+	for j := 0; j < n1; j++ {
+		if 0 <= pairs[j] {
+			continue
+		}
+		for i := 0; i < n0; i++ {
+			if !used[i] && eq[i][j] {
+				pairs[j] = i
+				used[i] = true
+				moved[j] = true
+				break
+			}
+		}
+	}
+	// Pair up the remaining elements in the same gap by position unless
+	// matching by key in which case elements with different keys are
+	// always added or removed.
+	for j := 0; j < n1 && d.ArrayMatch != MatchKey; j++ {
+		if 0 <= pairs[j] {
+			continue
+		}
+		for i := 0; i < n0; i++ {
+			if !used[i] && gap0[i] == gap1[j] {
+				pairs[j] = i
+				used[i] = true
+				break
+			}
+		}
+	}
+	for j, i := range pairs {
+		if ignoreIndex(j, ignores) {
+			continue
+		}
+		path[len(path)-1] = j
+		switch {
+		case i < 0:
+			d.add(Change{Kind: Added, Path: path, New: a1[j]})
+		case moved[j]:
+			d.add(Change{Kind: Moved, Path: path, From: append(append(Path{}, path[:len(path)-1]...), i), Old: a0[i], New: a1[j]})
+			d.compare(path, a0[i], a1[j], childIgnores)
+		default:
+			d.compare(path, a0[i], a1[j], childIgnores)
+		}
+	}
+	for i, u := range used {
+		if !u && !ignoreIndex(i, ignores) {
+			path[len(path)-1] = i
+			d.add(Change{Kind: Removed, Path: path, Old: a0[i]})
+		}
+	}
+}
+
+// elementKeys returns the identity key of each element or nil if the
+// element has no key or the key is a duplicate.
+func (d *differ) elementKeys(a []any) []any {
+	keys := make([]any, len(a))
+	for i, v := range a {
+		k, has := pathValue(v, d.Key)
+		if !has || k == nil {
+			continue
+		}
+		keys[i] = k
+		for _, prev := range keys[:i] {
+			if prev != nil && d.same(prev, k, nil) {
+				keys[i] = nil
+				break
+			}
+		}
+	}
+	return keys
+}
+
+func childIgnores(ignores []Path, array bool) (children []Path) {
+	for _, ign := range ignores {
+		if 1 < len(ign) {
+			switch ign[0].(type) {
+			case nil:
+				children = append(children, ign[1:])
+			case int:
+				if array {
+					children = append(children, ign[1:])
+				}
+			case string:
+				if !array {
+					children = append(children, ign[1:])
+				}
+			}
+		}
+	}
+	return
+}
+
+func memberValue(obj any, key string) (v any, has bool) {
+	switch to := obj.(type) {
+	case map[string]any:
+		v, has = to[key]
+	case *gen.Ordered:
+		v, has = to.Get(key)
+	}
+	return
+}
+
+func mapKeys(m0, m1 map[string]any) []string {
+	keys := make([]string, 0, len(m0)+len(m1))
+	for k := range m0 {
+		keys = append(keys, k)
+	}
+	for k := range m1 {
+		if _, has := m0[k]; !has {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func orderedKeys(o *gen.Ordered, m1 map[string]any) []string {
+	keys := o.Keys()
+	var extra []string
+	for k := range m1 {
+		if _, has := o.Get(k); !has {
+			extra = append(extra, k)
+		}
+	}
+	sort.Strings(extra)
+
+	return append(keys, extra...)
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package alt_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/sen"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestDiffChanges(t *testing.T) {
+	for i, d := range []struct {
+		v0     string
+		v1     string
+		opt    alt.DiffOptions
+		expect string
+	}{
+		{v0: "{a:1 b:2}", v1: "{a:1 b:2}", expect: "[]"},
+		{v0: "{a:1 b:2 c:[1 2]}", v1: "{a:3 d:4 c:[1]}", expect: "[changed [a]: 1 to 3 removed [b]: 2 removed [c 1]: 2 added [d]: 4]"},
+		{v0: "[1 2 3]", v1: "[0 1 2 3]", expect: "[changed [0]: 1 to 0 changed [1]: 2 to 1 changed [2]: 3 to 2 added [3]: 3]"},
+		{v0: "[1 2 3]", v1: "[0 1 2 3]", opt: alt.DiffOptions{ArrayMatch: alt.MatchLCS}, expect: "[added [0]: 0]"},
+		{v0: "[1 2 3 4]", v1: "[1 5 3]", opt: alt.DiffOptions{ArrayMatch: alt.MatchLCS}, expect: "[changed [1]: 2 to 5 removed [3]: 4]"},
+		{v0: "[a b c]", v1: "[b c a]", opt: alt.DiffOptions{ArrayMatch: alt.MatchLCS}, expect: "[moved [0] to [2]]"},
+		{v0: "[[1 2] x]", v1: "[x [1 2]]", opt: alt.DiffOptions{ArrayMatch: alt.MatchLCS}, expect: "[moved [0] to [1]]"},
+		{
+			v0:     "[{id:1 v:a} {id:2 v:b} {id:3 v:c}]",
+			v1:     "[{id:0 v:z} {id:2 v:B} {id:1 v:a}]",
+			opt:    alt.DiffOptions{ArrayMatch: alt.MatchKey, Key: alt.Path{"id"}},
+			expect: "[added [0]: map[id:0 v:z] changed [1 v]: b to B moved [0] to [2] removed [2]: map[id:3 v:c]]",
+		},
+		{
+			v0:     "[{id:1 v:a} {v:x}]",
+			v1:     "[{v:x} {id:1 v:b}]",
+			opt:    alt.DiffOptions{ArrayMatch: alt.MatchKey, Key: alt.Path{"id"}},
+			expect: "[moved [0] to [1] changed [1 v]: a to b]",
+		},
+		{v0: "{x:1.0 y:[2.5]}", v1: "{x:1.05 y:[2.45]}", opt: alt.DiffOptions{FloatTolerance: 0.1}, expect: "[]"},
+		{v0: "{x:1.0 y:3}", v1: "{x:1.2 y:3.05}", opt: alt.DiffOptions{FloatTolerance: 0.1}, expect: "[changed [x]: 1 to 1.2]"},
+		{v0: "{a:1 b:{c:2 d:3}}", v1: "{a:2 b:{c:3 d:4}}", opt: alt.DiffOptions{Ignores: []alt.Path{{"a"}, {"b", "c"}}}, expect: "[changed [b d]: 3 to 4]"},
+		{v0: "[{a:1} {a:2}]", v1: "[{a:3} {a:4}]", opt: alt.DiffOptions{Ignores: []alt.Path{{nil, "a"}}}, expect: "[]"},
+		{v0: "{a:null}", v1: "{a:[1]}", expect: "[changed [a]: <nil> to [1]]"},
+	} {
+		changes := alt.DiffChanges(sen.MustParse([]byte(d.v0)), sen.MustParse([]byte(d.v1)), &d.opt)
+		tt.Equal(t, d.expect, fmt.Sprint(changes), i, ": ", d.v0, " ", d.v1)
+	}
+}
+
+func TestDiffChangesRecord(t *testing.T) {
+	changes := alt.DiffChanges(
+		[]any{"a", "b", map[string]any{"x": 1}},
+		[]any{map[string]any{"x": 1}, "a", "b"},
+		&alt.DiffOptions{ArrayMatch: alt.MatchLCS},
+	)
+	tt.Equal(t, 1, len(changes))
+	tt.Equal(t, alt.Moved, changes[0].Kind)
+	tt.Equal(t, "moved", changes[0].Kind.String())
+	tt.Equal(t, alt.Path{0}, changes[0].Path)
+	tt.Equal(t, alt.Path{2}, changes[0].From)
+	tt.Equal(t, map[string]any{"x": 1}, changes[0].Old)
+	tt.Equal(t, map[string]any{"x": 1}, changes[0].New)
+	tt.Equal(t, "added removed changed", fmt.Sprint(alt.Added, " ", alt.Removed, " ", alt.Changed))
+}
+
+func TestDiffChangesTypes(t *testing.T) {
+	tm := time.Date(2023, time.May, 1, 2, 3, 4, 0, time.UTC)
+	changes := alt.DiffChanges(
+		gen.Object{"t": gen.Time(tm), "n": gen.Int(3)},
+		&gen.Ordered{Members: []gen.Member{{Key: "t", Value: tm.Add(time.Microsecond)}, {Key: "n", Value: 3.0}}},
+	)
+	tt.Equal(t, 0, len(changes))
+
+	changes = alt.DiffChanges(
+		map[string]any{"t": tm},
+		map[string]any{"t": tm.Add(time.Second)},
+		&alt.DiffOptions{TimeTolerance: time.Minute},
+	)
+	tt.Equal(t, 0, len(changes))
+
+	changes = alt.DiffChanges(&Dummy{Val: 1}, &Dummy{Val: 2})
+	tt.Equal(t, "[changed [val]: 1 to 2]", fmt.Sprint(changes))
+
+	changes = alt.DiffChanges(map[string]any{"a": 1}, []any{1})
+	tt.Equal(t, "[changed []: map[a:1] to [1]]", fmt.Sprint(changes))
+}
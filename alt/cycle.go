@@ -0,0 +1,172 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package alt
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/ohler55/ojg"
+)
+
+// Same as the jp tokenMap where a '.' indicates a key must be bracketed.
+const pathTokenMap = "" +
+	"................................" + // 0x00
+	"...o.o..........oooooooooooo...o" + // 0x20
+	".oooooooooooooooooooooooooo...oo" + // 0x40
+	".oooooooooooooooooooooooooooooo." + // 0x60
+	"oooooooooooooooooooooooooooooooo" + // 0x80
+	"oooooooooooooooooooooooooooooooo" + // 0xa0
+	"oooooooooooooooooooooooooooooooo" + // 0xc0
+	"oooooooooooooooooooooooooooooooo" //   0xe0
+
+type pathFrag struct {
+	key   string
+	index int // -1 for a key
+}
+
+// pathStack tracks the location in the data being walked so that a JSONPath
+// can be built when needed.
+type pathStack []pathFrag
+
+func (ps *pathStack) key(k string) {
+	*ps = append(*ps, pathFrag{key: k, index: -1})
+}
+
+func (ps *pathStack) index(i int) {
+	*ps = append(*ps, pathFrag{index: i})
+}
+
+func (ps *pathStack) pop() {
+	*ps = (*ps)[:len(*ps)-1]
+}
+
+// String returns the first n fragments as a JSONPath in the same format as
+// jp.Expr.String().
+func (ps pathStack) String(n int) string {
+	buf := []byte{'$'}
+	for _, f := range ps[:n] {
+		if 0 <= f.index {
+			buf = append(buf, '[')
+			buf = strconv.AppendInt(buf, int64(f.index), 10)
+			buf = append(buf, ']')
+			continue
+		}
+		bracket := len(f.key) == 0
+		for _, b := range []byte(f.key) {
+			if pathTokenMap[b] == '.' {
+				bracket = true
+				break
+			}
+		}
+		if bracket {
+			buf = append(buf, "['"...)
+			buf = append(buf, f.key...)
+			buf = append(buf, "']"...)
+		} else {
+			buf = append(buf, '.')
+			buf = append(buf, f.key...)
+		}
+	}
+	return string(buf)
+}
+
+type cycleEntry struct {
+	ptr   uintptr
+	size  int
+	rt    reflect.Type
+	depth int
+}
+
+// Cycles tracks the pointers, maps, and slices that are being walked along
+// with the path to the current value so that a value that contains itself
+// can be handled according to the OnCycle option. Decompose and the oj, sen,
+// and pretty writers use it as they walk data. A nil *Cycles does not track
+// anything so the methods can be called unconditionally.
+type Cycles struct {
+	path   pathStack
+	active []cycleEntry
+	policy int
+	refKey string
+}
+
+// NewCycles returns a new Cycles for the OnCycle and RefKey options or nil
+// if OnCycle is CycleIgnore.
+func NewCycles(opt *Options) *Cycles {
+	if opt.OnCycle == ojg.CycleIgnore {
+		return nil
+	}
+	c := Cycles{policy: opt.OnCycle, refKey: opt.RefKey}
+	if len(c.refKey) == 0 {
+		c.refKey = ojg.DefaultRefKey
+	}
+	return &c
+}
+
+// Key adds an object key to the current path.
+func (c *Cycles) Key(k string) {
+	if c != nil {
+		c.path.key(k)
+	}
+}
+
+// Index adds an array index to the current path.
+func (c *Cycles) Index(i int) {
+	if c != nil {
+		c.path.index(i)
+	}
+}
+
+// Pop removes the last key or index from the current path.
+func (c *Cycles) Pop() {
+	if c != nil {
+		c.path.pop()
+	}
+}
+
+// Enter adds rv, a pointer, map, or slice, to the values being walked. If rv
+// is already being walked then the value to use in its place according to
+// the OnCycle option is returned along with true and Exit should not be
+// called. A *ojg.CycleError panic is raised instead if OnCycle is
+// CycleFail. If false is returned then Exit must be called once rv has been
+// walked.
+func (c *Cycles) Enter(rv reflect.Value) (any, bool) {
+	if c == nil {
+		return nil, false
+	}
+	e := cycleEntry{ptr: rv.Pointer(), rt: rv.Type(), depth: len(c.path)}
+	if rv.Kind() == reflect.Slice {
+		e.size = rv.Len()
+	}
+	for _, a := range c.active {
+		if a.ptr == e.ptr && a.size == e.size && a.rt == e.rt {
+			switch c.policy {
+			case ojg.CycleNull:
+				return nil, true
+			case ojg.CycleRef:
+				return map[string]any{c.refKey: c.path.String(a.depth)}, true
+			}
+			panic(&ojg.CycleError{Path: c.path.String(len(c.path)), Ref: c.path.String(a.depth)})
+		}
+	}
+	c.active = append(c.active, e)
+
+	return nil, false
+}
+
+// Exit removes the value most recently added with Enter.
+func (c *Cycles) Exit() {
+	if c != nil {
+		c.active = c.active[:len(c.active)-1]
+	}
+}
+
+// Decompose is the same as the Decompose function except the pointers, maps,
+// and slices already being walked and the current path are taken into
+// account when checking for cycles.
+func (c *Cycles) Decompose(v any, opt *Options) any {
+	if opt.Converter != nil {
+		v = opt.Converter.Convert(v)
+	}
+	return decompose(v, opt, c)
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package alt_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+type cyNode struct {
+	Name     string
+	Parent   *cyNode
+	Children []*cyNode
+}
+
+func cyTree() *cyNode {
+	root := &cyNode{Name: "root"}
+	root.Children = []*cyNode{{Name: "kid", Parent: root}}
+	return root
+}
+
+func TestCycles(t *testing.T) {
+	var c *alt.Cycles
+	tt.Nil(t, alt.NewCycles(&ojg.Options{}))
+	m := map[string]any{}
+	rv := reflect.ValueOf(m)
+	_, seen := c.Enter(rv)
+	tt.Equal(t, false, seen)
+	c.Key("a")
+	c.Pop()
+	c.Exit()
+
+	c = alt.NewCycles(&ojg.Options{OnCycle: ojg.CycleRef})
+	_, seen = c.Enter(rv)
+	tt.Equal(t, false, seen)
+	c.Key("a")
+	c.Index(1)
+	_, seen = c.Enter(reflect.ValueOf(map[string]any{}))
+	tt.Equal(t, false, seen)
+	c.Exit()
+	v, seen := c.Enter(rv)
+	tt.Equal(t, true, seen)
+	tt.Equal(t, map[string]any{"$ref": "$"}, v)
+
+	m["b"] = []any{m}
+	tt.Equal(t, []any{map[string]any{"$ref": "$"}}, c.Decompose(m["b"], &ojg.Options{}))
+	tt.Equal(t, map[string]any{"$ref": "$"}, c.Decompose(m, &ojg.Options{}))
+	c.Pop()
+	c.Pop()
+	c.Exit()
+
+	c = alt.NewCycles(&ojg.Options{OnCycle: ojg.CycleFail})
+	c.Enter(rv)
+	c.Key("x y")
+	tt.Panic(t, func() { c.Enter(rv) })
+}
+
+func TestDecomposeCycle(t *testing.T) {
+	v := alt.Decompose(cyTree(), &ojg.Options{OnCycle: ojg.CycleRef})
+	tt.Equal(t,
+		`{"children":[{"children":[],"name":"kid","parent":{"$ref":"$"}}],"name":"root","parent":null}`,
+		oj.JSON(v, &ojg.Options{Sort: true}))
+
+	v = alt.Decompose(cyTree(), &ojg.Options{OnCycle: ojg.CycleRef, RefKey: "@"})
+	tt.Equal(t, map[string]any{"@": "$"}, cyParent(v))
+
+	v = alt.Decompose(cyTree(), &ojg.Options{OnCycle: ojg.CycleNull})
+	tt.Nil(t, cyParent(v))
+
+	shared := &cyNode{Name: "shared"}
+	v = alt.Decompose([]any{shared, shared}, &ojg.Options{OnCycle: ojg.CycleFail})
+	tt.Equal(t, `[{"children":[],"name":"shared","parent":null},{"children":[],"name":"shared","parent":null}]`,
+		oj.JSON(v, &ojg.Options{Sort: true}))
+
+	m := map[string]any{}
+	m["a b"] = []any{1, m}
+	v = alt.Decompose(m, &ojg.Options{OnCycle: ojg.CycleRef})
+	tt.Equal(t, `{"a b":[1,{"$ref":"$"}]}`, oj.JSON(v))
+
+	var err error
+	func() {
+		defer func() {
+			err, _ = recover().(error)
+		}()
+		alt.Decompose(m, &ojg.Options{OnCycle: ojg.CycleFail})
+	}()
+	var ce *ojg.CycleError
+	tt.Equal(t, true, errors.As(err, &ce))
+	tt.Equal(t, "$['a b'][1]", ce.Path)
+	tt.Equal(t, "$", ce.Ref)
+	tt.Equal(t, "cycle at $['a b'][1] refers back to $", err.Error())
+}
+
+func cyParent(v any) any {
+	return v.(map[string]any)["children"].([]any)[0].(map[string]any)["parent"]
+}
+
+func TestRecomposeRef(t *testing.T) {
+	v := alt.Decompose(cyTree(), &ojg.Options{OnCycle: ojg.CycleRef})
+	r := alt.MustNewRecomposer("", nil)
+	r.RefKey = "$ref"
+
+	var out cyNode
+	_, err := r.Recompose(v, &out)
+	tt.Nil(t, err)
+	tt.Equal(t, "kid", out.Children[0].Name)
+	tt.Equal(t, true, out.Children[0].Parent == &out)
+
+	src := map[string]any{"a": []any{1, map[string]any{"$ref": "$.a"}}}
+	v, err = r.Recompose(src)
+	tt.Nil(t, err)
+	a := v.(map[string]any)["a"].([]any)
+	a[0] = 2
+	tt.Equal(t, 2, a[1].([]any)[0])
+
+	_, err = r.Recompose(map[string]any{"a": map[string]any{"$ref": "$.z"}})
+	tt.NotNil(t, err)
+	tt.Equal(t, "reference to $.z at $.a can not be resolved", err.Error())
+
+	// Without RefKey reference objects are left as is.
+	out2, err := alt.MustNewRecomposer("", nil).Recompose(src)
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"$ref": "$.a"}, out2.(map[string]any)["a"].([]any)[1])
+}
@@ -5,21 +5,32 @@ package alt
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"math"
+	"math/big"
 	"reflect"
 	"time"
 
 	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/gen"
 )
 
 // 23 for fraction in IEEE 754 which amounts to 7 significant digits. Use base
 // 10 so that numbers look correct when displayed in base 10.
 const fracMax = 10000000.0
 
-func decompose(v any, opt *Options) any {
+func decompose(v any, opt *Options, c *Cycles) any {
 	switch tv := v.(type) {
-	case nil, bool, int64, float64, string:
+	case nil, bool, int64, float64, string, json.Number:
+	case *big.Int:
+		if tv != nil {
+			v = new(big.Int).Set(tv)
+		}
+	case *big.Float:
+		if tv != nil {
+			v = new(big.Float).Copy(tv)
+		}
 	case int:
 		v = int64(tv)
 	case int8:
@@ -45,17 +56,49 @@ func decompose(v any, opt *Options) any {
 		f = float64(int64(f*fracMax)) / fracMax
 		v = math.Ldexp(f, i)
 	case []any:
+		if c != nil && 0 < len(tv) {
+			if cv, seen := c.Enter(reflect.ValueOf(tv)); seen {
+				return cv
+			}
+			defer c.Exit()
+		}
 		a := make([]any, len(tv))
 		for i, m := range tv {
-			a[i] = decompose(m, opt)
+			c.Index(i)
+			a[i] = decompose(m, opt, c)
+			c.Pop()
 		}
 		v = a
 	case map[string]any:
+		if c != nil && 0 < len(tv) {
+			if cv, seen := c.Enter(reflect.ValueOf(tv)); seen {
+				return cv
+			}
+			defer c.Exit()
+		}
 		o := map[string]any{}
 		for k, m := range tv {
-			condMapSet(o, k, decompose(m, opt), opt)
+			c.Key(k)
+			condMapSet(o, k, decompose(m, opt, c), opt)
+			c.Pop()
 		}
 		v = o
+	case *gen.Ordered:
+		if c != nil && tv != nil {
+			if cv, seen := c.Enter(reflect.ValueOf(tv)); seen {
+				return cv
+			}
+			defer c.Exit()
+		}
+		o := gen.Ordered{Members: make([]gen.Member, 0, len(tv.Members))}
+		for _, m := range tv.Members {
+			c.Key(m.Key)
+			if mv := decompose(m.Value, opt, c); !omitValue(mv, opt) {
+				o.Members = append(o.Members, gen.Member{Key: m.Key, Value: mv})
+			}
+			c.Pop()
+		}
+		v = &o
 	case []byte:
 		switch opt.BytesAs {
 		case ojg.BytesAsBase64:
@@ -63,7 +106,7 @@ func decompose(v any, opt *Options) any {
 		case ojg.BytesAsArray:
 			a := make([]any, len(tv))
 			for i, m := range tv {
-				a[i] = decompose(m, opt)
+				a[i] = decompose(m, opt, c)
 			}
 			v = a
 		default:
@@ -73,16 +116,16 @@ func decompose(v any, opt *Options) any {
 		v = opt.DecomposeTime(tv)
 	default:
 		if simp, _ := v.(Simplifier); simp != nil {
-			return decompose(simp.Simplify(), opt)
+			return decompose(simp.Simplify(), opt, c)
 		}
-		return reflectValue(reflect.ValueOf(v), v, opt)
+		return reflectValue(reflect.ValueOf(v), v, opt, c)
 	}
 	return v
 }
 
-func alter(v any, opt *Options) any {
+func alter(v any, opt *Options, c *Cycles) any {
 	switch tv := v.(type) {
-	case bool, nil, int64, float64, string, time.Time:
+	case bool, nil, int64, float64, string, time.Time, json.Number, *big.Int, *big.Float:
 	case int:
 		v = int64(tv)
 	case int8:
@@ -108,12 +151,28 @@ func alter(v any, opt *Options) any {
 		f = float64(int64(f*fracMax)) / fracMax
 		v = math.Ldexp(f, i)
 	case []any:
+		if c != nil && 0 < len(tv) {
+			if cv, seen := c.Enter(reflect.ValueOf(tv)); seen {
+				return cv
+			}
+			defer c.Exit()
+		}
 		for i, m := range tv {
-			tv[i] = alter(m, opt)
+			c.Index(i)
+			tv[i] = alter(m, opt, c)
+			c.Pop()
 		}
 	case map[string]any:
+		if c != nil && 0 < len(tv) {
+			if cv, seen := c.Enter(reflect.ValueOf(tv)); seen {
+				return cv
+			}
+			defer c.Exit()
+		}
 		for k, m := range tv {
-			mv := alter(m, opt)
+			c.Key(k)
+			mv := alter(m, opt, c)
+			c.Pop()
 			switch tmv := mv.(type) {
 			case nil:
 				if opt.OmitNil || opt.OmitEmpty {
@@ -148,6 +207,22 @@ func alter(v any, opt *Options) any {
 			}
 			tv[k] = mv
 		}
+	case *gen.Ordered:
+		if c != nil && tv != nil {
+			if cv, seen := c.Enter(reflect.ValueOf(tv)); seen {
+				return cv
+			}
+			defer c.Exit()
+		}
+		members := tv.Members[:0]
+		for _, m := range tv.Members {
+			c.Key(m.Key)
+			if m.Value = alter(m.Value, opt, c); !omitValue(m.Value, opt) {
+				members = append(members, m)
+			}
+			c.Pop()
+		}
+		tv.Members = members
 	case []byte:
 		switch opt.BytesAs {
 		case ojg.BytesAsBase64:
@@ -155,7 +230,7 @@ func alter(v any, opt *Options) any {
 		case ojg.BytesAsArray:
 			a := make([]any, len(tv))
 			for i, m := range tv {
-				a[i] = decompose(m, opt)
+				a[i] = decompose(m, opt, c)
 			}
 			v = a
 		default:
@@ -163,32 +238,50 @@ func alter(v any, opt *Options) any {
 		}
 	default:
 		if simp, _ := v.(Simplifier); simp != nil {
-			return alter(simp.Simplify(), opt)
+			return alter(simp.Simplify(), opt, c)
 		}
-		return reflectValue(reflect.ValueOf(v), v, opt)
+		return reflectValue(reflect.ValueOf(v), v, opt, c)
 	}
 	return v
 }
 
-func reflectValue(rv reflect.Value, val any, opt *Options) (v any) {
+func reflectValue(rv reflect.Value, val any, opt *Options, c *Cycles) (v any) {
 	switch rv.Kind() {
 	case reflect.Invalid, reflect.Uintptr, reflect.UnsafePointer, reflect.Chan, reflect.Func, reflect.Interface:
 		v = nil
 	case reflect.Complex64, reflect.Complex128:
 		v = reflectComplex(rv, opt)
 	case reflect.Map:
-		v = reflectMap(rv, opt)
+		if c != nil && 0 < rv.Len() {
+			if cv, seen := c.Enter(rv); seen {
+				return cv
+			}
+			defer c.Exit()
+		}
+		v = reflectMap(rv, opt, c)
 	case reflect.Ptr:
 		elem := rv.Elem()
 		if elem.IsValid() && elem.CanInterface() {
-			v = reflectValue(elem, elem.Interface(), opt)
+			if c != nil {
+				if cv, seen := c.Enter(rv); seen {
+					return cv
+				}
+				defer c.Exit()
+			}
+			v = reflectValue(elem, elem.Interface(), opt, c)
 		} else {
 			v = nil
 		}
 	case reflect.Slice, reflect.Array:
-		v = reflectArray(rv, opt)
+		if c != nil && rv.Kind() == reflect.Slice && 0 < rv.Len() {
+			if cv, seen := c.Enter(rv); seen {
+				return cv
+			}
+			defer c.Exit()
+		}
+		v = reflectArray(rv, opt, c)
 	case reflect.Struct:
-		v = reflectStruct(rv, val, opt)
+		v = reflectStruct(rv, val, opt, c)
 	case reflect.String:
 		v = rv.String()
 	case reflect.Bool:
@@ -203,9 +296,9 @@ func reflectValue(rv reflect.Value, val any, opt *Options) (v any) {
 	return
 }
 
-func reflectStruct(rv reflect.Value, val any, opt *Options) any {
+func reflectStruct(rv reflect.Value, val any, opt *Options, c *Cycles) any {
 	if !rv.CanAddr() {
-		return reflectEmbed(rv, val, opt)
+		return reflectEmbed(rv, val, opt, c)
 	}
 	obj := map[string]any{}
 	si := getSinfo(val, opt.OmitEmpty)
@@ -222,11 +315,13 @@ func reflectStruct(rv reflect.Value, val any, opt *Options) any {
 	for _, fi := range fields {
 		if v, fv, omit := fi.value(fi, rv, addr); !omit {
 			if fv.IsValid() {
+				c.Key(fi.key)
 				if opt.NestEmbed && fv.Kind() == reflect.Struct {
-					v = reflectEmbed(fv, v, opt)
+					v = reflectEmbed(fv, v, opt, c)
 				} else {
-					v = decompose(v, opt)
+					v = decompose(v, opt, c)
 				}
+				c.Pop()
 			}
 			condMapSet(obj, fi.key, v, opt)
 		}
@@ -234,7 +329,7 @@ func reflectStruct(rv reflect.Value, val any, opt *Options) any {
 	return obj
 }
 
-func reflectEmbed(rv reflect.Value, val any, opt *Options) any {
+func reflectEmbed(rv reflect.Value, val any, opt *Options, c *Cycles) any {
 	obj := map[string]any{}
 	si := getSinfo(val, opt.OmitEmpty)
 	t := si.rt
@@ -249,11 +344,13 @@ func reflectEmbed(rv reflect.Value, val any, opt *Options) any {
 	for _, fi := range fields {
 		if v, fv, omit := fi.ivalue(fi, rv, 0); !omit {
 			if fv.IsValid() {
+				c.Key(fi.key)
 				if opt.NestEmbed && fv.Kind() == reflect.Struct {
-					v = reflectEmbed(fv, v, opt)
+					v = reflectEmbed(fv, v, opt, c)
 				} else {
-					v = decompose(v, opt)
+					v = decompose(v, opt, c)
 				}
+				c.Pop()
 			}
 			condMapSet(obj, fi.key, v, opt)
 		}
@@ -273,16 +370,11 @@ func reflectComplex(rv reflect.Value, opt *Options) any {
 	return obj
 }
 
-func reflectMap(rv reflect.Value, opt *Options) any {
+func reflectMap(rv reflect.Value, opt *Options, c *Cycles) any {
 	obj := map[string]any{}
 	it := rv.MapRange()
 	for it.Next() {
 		k := it.Key().Interface()
-		var g any
-		vv := it.Value()
-		if !isNil(vv) {
-			g = decompose(vv.Interface(), opt)
-		}
 		var (
 			ks string
 			ok bool
@@ -290,16 +382,25 @@ func reflectMap(rv reflect.Value, opt *Options) any {
 		if ks, ok = k.(string); !ok {
 			ks = fmt.Sprint(k)
 		}
+		var g any
+		vv := it.Value()
+		if !isNil(vv) {
+			c.Key(ks)
+			g = decompose(vv.Interface(), opt, c)
+			c.Pop()
+		}
 		condMapSet(obj, ks, g, opt)
 	}
 	return obj
 }
 
-func reflectArray(rv reflect.Value, opt *Options) any {
+func reflectArray(rv reflect.Value, opt *Options, c *Cycles) any {
 	size := rv.Len()
 	a := make([]any, size)
 	for i := size - 1; 0 <= i; i-- {
-		a[i] = decompose(rv.Index(i).Interface(), opt)
+		c.Index(i)
+		a[i] = decompose(rv.Index(i).Interface(), opt, c)
+		c.Pop()
 	}
 	return a
 }
@@ -313,31 +414,29 @@ func isNil(rv reflect.Value) bool {
 }
 
 func condMapSet(m map[string]any, key string, value any, opt *Options) {
+	if !omitValue(value, opt) {
+		m[key] = value
+	}
+}
+
+// omitValue returns true if the value should be omitted from an object
+// according to the OmitNil and OmitEmpty options.
+func omitValue(value any, opt *Options) bool {
 	switch tv := value.(type) {
 	case nil:
-		if opt.OmitNil || opt.OmitEmpty {
-			return
-		}
+		return opt.OmitNil || opt.OmitEmpty
 	case string:
-		if opt.OmitEmpty && len(tv) == 0 {
-			return
-		}
+		return opt.OmitEmpty && len(tv) == 0
 	case []any:
-		if opt.OmitEmpty && len(tv) == 0 {
-			return
-		}
+		return opt.OmitEmpty && len(tv) == 0
 	case map[string]any:
-		if opt.OmitEmpty && len(tv) == 0 {
-			return
-		}
+		return opt.OmitEmpty && len(tv) == 0
+	case *gen.Ordered:
+		return opt.OmitEmpty && len(tv.Members) == 0
 	case bool:
-		if opt.OmitEmpty && !tv {
-			return
-		}
+		return opt.OmitEmpty && !tv
 	case int64:
-		if opt.OmitEmpty && tv == 0 {
-			return
-		}
+		return opt.OmitEmpty && tv == 0
 	}
-	m[key] = value
-}
\ No newline at end of file
+	return false
+}
@@ -4,12 +4,15 @@
 package alt_test
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"testing"
 	"time"
 
 	"github.com/ohler55/ojg"
 	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/gen"
 	"github.com/ohler55/ojg/tt"
 )
 
@@ -56,6 +59,16 @@ func TestDecomposeNumbers(t *testing.T) {
 	tt.Equal(t, []any{-8, -16, -32, 0, 8, 16, 32, 64, 3.2}, v)
 }
 
+func TestDecomposeBigNumbers(t *testing.T) {
+	bi := big.NewInt(123)
+	bf := big.NewFloat(1.5)
+	v := alt.Decompose([]any{json.Number("0.10"), bi, bf}).([]any)
+	tt.Equal(t, json.Number("0.10"), v[0])
+	tt.Equal(t, "123 1.5", fmt.Sprint(v[1], " ", v[2]))
+	bi.SetInt64(7)
+	tt.Equal(t, "123", fmt.Sprint(v[1]))
+}
+
 func TestDecomposeStruct(t *testing.T) {
 	d := Dummy{Val: 3, Nest: &Dummy{Val: 2}, hidden: 3}
 	v := alt.Decompose(&d)
@@ -373,4 +386,24 @@ func BenchmarkDecompose(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_ = alt.Decompose(&a, &alt.Options{UseTags: true})
 	}
-}
\ No newline at end of file
+}
+
+func TestDecomposeOrdered(t *testing.T) {
+	o := &gen.Ordered{Members: []gen.Member{
+		{Key: "b", Value: gen.Int(2)},
+		{Key: "a", Value: &gen.Ordered{Members: []gen.Member{{Key: "x", Value: 3}}}},
+		{Key: "n", Value: nil},
+	}}
+	dup := alt.Dup(o, &ojg.Options{OmitNil: true}).(*gen.Ordered)
+	tt.Equal(t, []string{"b", "a"}, dup.Keys())
+	tt.Equal(t, int64(2), dup.Members[0].Value)
+	tt.Equal(t, int64(3), dup.Members[1].Value.(*gen.Ordered).Members[0].Value)
+	tt.Equal(t, 3, o.Members[1].Value.(*gen.Ordered).Members[0].Value)
+
+	g := alt.Generify(dup).(*gen.Ordered)
+	tt.Equal(t, gen.Int(3), g.Members[1].Value.(*gen.Ordered).Members[0].Value)
+
+	altered := alt.Alter(o, &ojg.Options{OmitNil: true}).(*gen.Ordered)
+	tt.Equal(t, []string{"b", "a"}, altered.Keys())
+	tt.Equal(t, int64(2), altered.Members[0].Value)
+}
@@ -22,13 +22,13 @@ type Path []any
 // Diff returns the paths to the differences between two values. Any ignore
 // paths are ignored in the comparison.
 func Diff(v0, v1 any, ignores ...Path) (diffs []Path) {
-	return diff(v0, v1, false, ignores...)
+	return diff(v0, v1, false, false, ignores...)
 }
 
 // Compare returns a path to the first difference encountered between two
 // values. Any ignore paths are ignored in the comparison.
 func Compare(v0, v1 any, ignores ...Path) Path {
-	if diffs := diff(v0, v1, true, ignores...); 0 < len(diffs) {
+	if diffs := diff(v0, v1, true, false, ignores...); 0 < len(diffs) {
 		return diffs[0]
 	}
 	return nil
@@ -39,6 +39,8 @@ func Compare(v0, v1 any, ignores ...Path) Path {
 // explicit nil in the fingerprint will match either a nil in the target or a
 // missing value in the target.
 func Match(fingerprint, target any) bool {
+	fingerprint = orderedAsMap(fingerprint)
+	target = orderedAsMap(target)
 	switch fp := fingerprint.(type) {
 	case nil:
 		if target != nil {
@@ -96,8 +98,8 @@ func Match(fingerprint, target any) bool {
 				}
 			}
 			opt := &Options{}
-			fingerprint = reflectValue(reflect.ValueOf(fingerprint), fingerprint, opt)
-			target = reflectValue(reflect.ValueOf(target), target, opt)
+			fingerprint = reflectValue(reflect.ValueOf(fingerprint), fingerprint, opt, nil)
+			target = reflectValue(reflect.ValueOf(target), target, opt, nil)
 			if fingerprint != nil && target != nil {
 				return Match(fingerprint, target)
 			}
@@ -107,7 +109,14 @@ func Match(fingerprint, target any) bool {
 	return true
 }
 
-func diff(v0, v1 any, one bool, ignores ...Path) (diffs []Path) {
+// diff returns the paths to differences. If one is true only the first
+// difference is returned. If exact is true a missing map member is
+// considered different from a member with a nil value.
+func diff(v0, v1 any, one, exact bool, ignores ...Path) (diffs []Path) {
+	// Member order is not significant when comparing so an Ordered is
+	// compared as a map.
+	v0 = orderedAsMap(v0)
+	v1 = orderedAsMap(v1)
 	switch t0 := v0.(type) {
 	case nil:
 		if v1 != nil {
@@ -158,7 +167,7 @@ func diff(v0, v1 any, one bool, ignores ...Path) (diffs []Path) {
 				diffs = append(diffs, Path{i})
 				return
 			}
-			ds := diff(m1, t1[i], one, childIgnores...)
+			ds := diff(m1, t1[i], one, exact, childIgnores...)
 			for _, d := range ds {
 				if len(d) == 1 && d[0] == nil {
 					d[0] = i
@@ -200,7 +209,18 @@ func diff(v0, v1 any, one bool, ignores ...Path) (diffs []Path) {
 			if ignoreKey(k, ignores) {
 				continue
 			}
-			ds := diff(t0[k], t1[k], one, childIgnores...)
+			if exact {
+				_, has0 := t0[k]
+				_, has1 := t1[k]
+				if has0 != has1 {
+					diffs = append(diffs, Path{k})
+					if one {
+						return
+					}
+					continue
+				}
+			}
+			ds := diff(t0[k], t1[k], one, exact, childIgnores...)
 			for _, d := range ds {
 				if len(d) == 1 && d[0] == nil {
 					d[0] = k
@@ -219,15 +239,15 @@ func diff(v0, v1 any, one bool, ignores ...Path) (diffs []Path) {
 		if vt0 == vt1 {
 			if s0, _ := v0.(Simplifier); s0 != nil {
 				if s1, _ := v1.(Simplifier); s1 != nil {
-					return diff(s0.Simplify(), s1.Simplify(), one, ignores...)
+					return diff(s0.Simplify(), s1.Simplify(), one, exact, ignores...)
 				}
 			}
 			opt := &Options{}
 			// TBD optimize by a more direct compare of fields
-			v0 = reflectValue(reflect.ValueOf(v0), v0, opt)
-			v1 = reflectValue(reflect.ValueOf(v1), v1, opt)
+			v0 = reflectValue(reflect.ValueOf(v0), v0, opt, nil)
+			v1 = reflectValue(reflect.ValueOf(v1), v1, opt, nil)
 			if v0 != nil && v1 != nil {
-				return diff(v0, v1, one, ignores...)
+				return diff(v0, v1, one, exact, ignores...)
 			}
 		}
 		diffs = append(diffs, Path{nil})
@@ -236,6 +256,17 @@ func diff(v0, v1 any, one bool, ignores ...Path) (diffs []Path) {
 	return
 }
 
+func orderedAsMap(v any) any {
+	if o, ok := v.(*gen.Ordered); ok {
+		m := make(map[string]any, len(o.Members))
+		for _, mem := range o.Members {
+			m[mem.Key] = mem.Value
+		}
+		return m
+	}
+	return v
+}
+
 func asInt(v any) (i int64, ok bool) {
 	ok = true
 	switch tv := v.(type) {
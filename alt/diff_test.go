@@ -197,3 +197,11 @@ func TestDiffReflect(t *testing.T) {
 	)
 	tt.Equal(t, 0, len(diffs))
 }
+
+func TestDiffOrdered(t *testing.T) {
+	o0 := &gen.Ordered{Members: []gen.Member{{Key: "b", Value: int64(2)}, {Key: "a", Value: int64(1)}}}
+	o1 := &gen.Ordered{Members: []gen.Member{{Key: "a", Value: int64(1)}, {Key: "b", Value: int64(3)}}}
+	tt.Equal(t, []alt.Path{{"b"}}, alt.Diff(o0, o1))
+	tt.Equal(t, 0, len(alt.Diff(o0, map[string]any{"a": 1, "b": 2})))
+	tt.Equal(t, true, alt.Match(map[string]any{"a": 1}, o0))
+}
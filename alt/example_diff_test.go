@@ -43,3 +43,18 @@ func ExampleMatch() {
 
 	// Output: match: true
 }
+
+func ExampleDiffChanges() {
+	changes := alt.DiffChanges(
+		[]any{map[string]any{"id": 1, "qty": 2}, map[string]any{"id": 2, "qty": 1}},
+		[]any{map[string]any{"id": 3, "qty": 5}, map[string]any{"id": 2, "qty": 1}, map[string]any{"id": 1, "qty": 4}},
+		&alt.DiffOptions{ArrayMatch: alt.MatchKey, Key: alt.Path{"id"}},
+	)
+	for _, c := range changes {
+		fmt.Println(c)
+	}
+	// Output:
+	// added [0]: map[id:3 qty:5]
+	// moved [0] to [2]
+	// changed [2 qty]: 2 to 4
+}
@@ -102,7 +102,7 @@ top:
 		data = tv.Simplify()
 		goto top
 	default:
-		data = reflectValue(reflect.ValueOf(tv), tv, &Options{})
+		data = reflectValue(reflect.ValueOf(tv), tv, &Options{}, nil)
 		goto top
 	}
 	return
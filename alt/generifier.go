@@ -4,7 +4,9 @@
 package alt
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"reflect"
 	"time"
 	"unsafe"
@@ -69,6 +71,16 @@ func Generify(v any, options ...*Options) (n gen.Node) {
 			n = tv
 		case time.Time:
 			n = gen.Time(tv)
+		case json.Number:
+			n = gen.Big(tv)
+		case *big.Int:
+			if tv != nil {
+				n = gen.Big(tv.String())
+			}
+		case *big.Float:
+			if tv != nil {
+				n = gen.Big(tv.Text('g', -1))
+			}
 		case gen.Time:
 			n = tv
 		case []any:
@@ -87,6 +99,15 @@ func Generify(v any, options ...*Options) (n gen.Node) {
 				}
 			}
 			n = o
+		case *gen.Ordered:
+			o := gen.Ordered{Members: make([]gen.Member, 0, len(tv.Members))}
+			for _, m := range tv.Members {
+				g := Generify(m.Value, opt)
+				if g != nil || !opt.OmitNil {
+					o.Members = append(o.Members, gen.Member{Key: m.Key, Value: g})
+				}
+			}
+			n = &o
 		default:
 			var ok bool
 			if n, ok = v.(gen.Node); ok {
@@ -152,6 +173,16 @@ func GenAlter(v any, options ...*Options) (n gen.Node) {
 			n = tv
 		case time.Time:
 			n = gen.Time(tv)
+		case json.Number:
+			n = gen.Big(tv)
+		case *big.Int:
+			if tv != nil {
+				n = gen.Big(tv.String())
+			}
+		case *big.Float:
+			if tv != nil {
+				n = gen.Big(tv.Text('g', -1))
+			}
 		case []any:
 			a := *(*gen.Array)(unsafe.Pointer(&tv))
 			for i, m := range tv {
@@ -175,6 +206,15 @@ func GenAlter(v any, options ...*Options) (n gen.Node) {
 				delete(o, k)
 			}
 			n = o
+		case *gen.Ordered:
+			members := tv.Members[:0]
+			for _, m := range tv.Members {
+				if m.Value = GenAlter(m.Value, opt); m.Value != nil || !opt.OmitNil {
+					members = append(members, m)
+				}
+			}
+			tv.Members = members
+			n = tv
 		default:
 			var ok bool
 			if n, ok = v.(gen.Node); ok {
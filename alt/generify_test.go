@@ -4,6 +4,8 @@
 package alt_test
 
 import (
+	"encoding/json"
+	"math/big"
 	"testing"
 	"time"
 
@@ -34,6 +36,9 @@ func TestGenerifyBase(t *testing.T) {
 		gen.String("string"),
 		tm,
 		gen.Time(tm),
+		json.Number("0.10"),
+		big.NewInt(-12),
+		big.NewFloat(2.5),
 	}
 	v := alt.Generify(a)
 	tt.Equal(t, gen.Array{
@@ -56,6 +61,9 @@ func TestGenerifyBase(t *testing.T) {
 		gen.String("string"),
 		gen.Time(tm),
 		gen.Time(tm),
+		gen.Big("0.10"),
+		gen.Big("-12"),
+		gen.Big("2.5"),
 	}, v)
 }
 
@@ -0,0 +1,316 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package alt
+
+import (
+	"sort"
+
+	"github.com/ohler55/ojg/gen"
+)
+
+// Conflict is a value that was changed differently in ours and theirs when
+// merging with Merge3. A value that is missing is nil and the In flag for
+// it is false.
+type Conflict struct {
+	// Path to the value. Array indexes are indexes in ours except for an
+	// element only in theirs which has its index in the merged array.
+	// Convert the path with jp.PathExpr() to use it as a jp.Expr.
+	Path Path
+
+	Base   any
+	Ours   any
+	Theirs any
+
+	InBase   bool
+	InOurs   bool
+	InTheirs bool
+}
+
+// MergeResolver returns the value to use for a conflict. If ok is false the
+// value is left out of the merged result.
+type MergeResolver func(c *Conflict) (value any, ok bool)
+
+// ResolveOurs is a MergeResolver that uses the ours value.
+func ResolveOurs(c *Conflict) (any, bool) {
+	return c.Ours, c.InOurs
+}
+
+// ResolveTheirs is a MergeResolver that uses the theirs value.
+func ResolveTheirs(c *Conflict) (any, bool) {
+	return c.Theirs, c.InTheirs
+}
+
+// ArrayKey identifies the arrays at a path that are merged by matching
+// elements with the same value at the Key path instead of by index. A nil
+// element in the Path matches any key or index. Elements without a key or
+// with a duplicate key are taken from ours.
+type ArrayKey struct {
+	Path Path
+	Key  Path
+}
+
+// MergeOptions are the options for Merge3.
+type MergeOptions struct {
+	// DiffOptions are used when comparing values. The ArrayMatch, Key,
+	// and Ignores are not used.
+	DiffOptions
+
+	// Resolver is called for each conflict. If nil ResolveOurs is used.
+	Resolver MergeResolver
+
+	// ArrayKeys are the arrays that are merged by an identity key. All
+	// other arrays are merged by index.
+	ArrayKeys []ArrayKey
+}
+
+type merger struct {
+	MergeOptions
+	d         differ
+	conflicts []Conflict
+}
+
+// Merge3 merges the changes made in ours and theirs to a common base and
+// returns the merged value along with the conflicts found. Changes made in
+// only one of ours or theirs are included in the result. Objects are merged
+// member by member and arrays element by element. When a value is changed
+// differently in each, the conflict is recorded and the MergeResolver
+// decides the value used. A gen.Node is merged as simple data. Values in
+// the result are not copied and may be shared with the arguments.
+func Merge3(base, ours, theirs any, options ...*MergeOptions) (merged any, conflicts []Conflict) {
+	m := merger{}
+	if 0 < len(options) && options[0] != nil {
+		m.MergeOptions = *options[0]
+	}
+	if m.Resolver == nil {
+		m.Resolver = ResolveOurs
+	}
+	m.d.DiffOptions = m.DiffOptions
+	m.d.Ignores = nil
+	if m.d.TimeTolerance == 0 {
+		m.d.TimeTolerance = TimeTolerance
+	}
+	var has bool
+	merged, has = m.merge(Path{}, simplifyNode(base), simplifyNode(ours), simplifyNode(theirs), true, true, true)
+	if !has {
+		merged = nil
+	}
+	return merged, m.conflicts
+}
+
+func simplifyNode(v any) any {
+	if n, ok := v.(gen.Node); ok {
+		return n.Simplify()
+	}
+	return v
+}
+
+func (m *merger) same(v0, v1 any, has0, has1 bool) bool {
+	if has0 != has1 {
+		return false
+	}
+	return !has0 || m.d.same(v0, v1, nil)
+}
+
+func (m *merger) merge(path Path, b, o, t any, hb, ho, ht bool) (any, bool) {
+	switch {
+	case m.same(o, t, ho, ht):
+		return o, ho
+	case m.same(b, o, hb, ho):
+		return t, ht
+	case m.same(b, t, hb, ht):
+		return o, ho
+	}
+	if ho && ht {
+		if isObject(o) && isObject(t) {
+			if !isObject(b) {
+				b = map[string]any{}
+			}
+			return m.mergeObjects(path, b, o, t), true
+		}
+		if ao, ok := o.([]any); ok {
+			if at, ok := t.([]any); ok {
+				ab, _ := b.([]any)
+				if key, ok := m.arrayKey(path); ok {
+					return m.mergeKeyed(path, key, ab, ao, at), true
+				}
+				if len(ab) == len(ao) && len(ab) == len(at) {
+					return m.mergeIndexed(path, ab, ao, at), true
+				}
+			}
+		}
+	}
+	c := Conflict{
+		Path:     append(Path{}, path...),
+		Base:     b,
+		Ours:     o,
+		Theirs:   t,
+		InBase:   hb,
+		InOurs:   ho,
+		InTheirs: ht,
+	}
+	m.conflicts = append(m.conflicts, c)
+
+	return m.Resolver(&c)
+}
+
+func (m *merger) mergeObjects(path Path, b, o, t any) any {
+	var keys []string
+	if oo, ok := o.(*gen.Ordered); ok {
+		keys = oo.Keys()
+	} else {
+		keys = sortedKeys(o.(map[string]any))
+	}
+	var extra []string
+	switch to := t.(type) {
+	case *gen.Ordered:
+		for _, k := range to.Keys() {
+			if _, has := memberValue(o, k); !has {
+				extra = append(extra, k)
+			}
+		}
+	case map[string]any:
+		for _, k := range sortedKeys(to) {
+			if _, has := memberValue(o, k); !has {
+				extra = append(extra, k)
+			}
+		}
+	}
+	keys = append(keys, extra...)
+	path = append(path, nil)
+	var ordered *gen.Ordered
+	var obj map[string]any
+	if _, ok := o.(*gen.Ordered); ok {
+		ordered = &gen.Ordered{}
+	} else {
+		obj = map[string]any{}
+	}
+	for _, k := range keys {
+		path[len(path)-1] = k
+		bv, hb := memberValue(b, k)
+		ov, ho := memberValue(o, k)
+		tv, ht := memberValue(t, k)
+		if v, has := m.merge(path, bv, ov, tv, hb, ho, ht); has {
+			setMember(ordered, obj, k, v)
+		}
+	}
+	if ordered != nil {
+		return ordered
+	}
+	return obj
+}
+
+func (m *merger) mergeIndexed(path Path, ab, ao, at []any) any {
+	path = append(path, nil)
+	merged := make([]any, 0, len(ao))
+	for i, ov := range ao {
+		path[len(path)-1] = i
+		if v, has := m.merge(path, ab[i], ov, at[i], true, true, true); has {
+			merged = append(merged, v)
+		}
+	}
+	return merged
+}
+
+// mergeKeyed merges arrays by matching elements with the same key. The
+// order of elements is the order in ours followed by elements only in
+// theirs.
+func (m *merger) mergeKeyed(path Path, key Path, ab, ao, at []any) any {
+	m.d.Key = key
+	kb := m.d.elementKeys(ab)
+	ko := m.d.elementKeys(ao)
+	kt := m.d.elementKeys(at)
+	find := func(keys []any, k any) int {
+		if k != nil {
+			for i, v := range keys {
+				if v != nil && m.d.same(v, k, nil) {
+					return i
+				}
+			}
+		}
+		return -1
+	}
+	path = append(path, nil)
+	merged := make([]any, 0, len(ao))
+	tUsed := make([]bool, len(at))
+	for i, ov := range ao {
+		path[len(path)-1] = i
+		if ko[i] == nil {
+			// Elements without a key can only be kept.
+			merged = append(merged, ov)
+			continue
+		}
+		var bv, tv any
+		bi := find(kb, ko[i])
+		if 0 <= bi {
+			bv = ab[bi]
+		}
+		ti := find(kt, ko[i])
+		if 0 <= ti {
+			tv = at[ti]
+			tUsed[ti] = true
+		}
+		if v, has := m.merge(path, bv, ov, tv, 0 <= bi, true, 0 <= ti); has {
+			merged = append(merged, v)
+		}
+	}
+	for i, tv := range at {
+		if tUsed[i] || kt[i] == nil {
+			continue
+		}
+		path[len(path)-1] = len(merged)
+		var bv any
+		bi := find(kb, kt[i])
+		if 0 <= bi {
+			bv = ab[bi]
+		}
+		if v, has := m.merge(path, bv, nil, tv, 0 <= bi, false, true); has {
+			merged = append(merged, v)
+		}
+	}
+	return merged
+}
+
+func (m *merger) arrayKey(path Path) (Path, bool) {
+	for _, ak := range m.ArrayKeys {
+		if len(ak.Path) != len(path) {
+			continue
+		}
+		match := true
+		for i, k := range ak.Path {
+			if k != nil && k != path[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return ak.Key, true
+		}
+	}
+	return nil, false
+}
+
+func isObject(v any) bool {
+	switch v.(type) {
+	case map[string]any, *gen.Ordered:
+		return true
+	}
+	return false
+}
+
+func setMember(ordered *gen.Ordered, obj map[string]any, k string, v any) {
+	if ordered != nil {
+		ordered.Members = append(ordered.Members, gen.Member{Key: k, Value: v})
+	} else {
+		obj[k] = v
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package alt_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/sen"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestMerge3(t *testing.T) {
+	for i, d := range []struct {
+		base      string
+		ours      string
+		theirs    string
+		opt       alt.MergeOptions
+		expect    string
+		conflicts string
+	}{
+		{base: "{a:1 b:2}", ours: "{a:1 b:2}", theirs: "{a:1 b:2}", expect: `{"a":1,"b":2}`, conflicts: "[]"},
+		{base: "{a:1 b:2}", ours: "{a:3 b:2}", theirs: "{a:1 b:4}", expect: `{"a":3,"b":4}`, conflicts: "[]"},
+		{base: "{a:1 b:2 c:3}", ours: "{a:1 c:3 d:4}", theirs: "{a:1 b:2 c:3 e:5}", expect: `{"a":1,"c":3,"d":4,"e":5}`, conflicts: "[]"},
+		{base: "{a:1}", ours: "{a:2}", theirs: "{a:3}", expect: `{"a":2}`, conflicts: "[[a]:1:2:3]"},
+		{
+			base:      "{a:1}",
+			ours:      "{a:2}",
+			theirs:    "{a:3}",
+			opt:       alt.MergeOptions{Resolver: alt.ResolveTheirs},
+			expect:    `{"a":3}`,
+			conflicts: "[[a]:1:2:3]",
+		},
+		{base: "{a:{x:1}}", ours: "{}", theirs: "{a:{x:2}}", expect: `{}`, conflicts: "[[a]:map[x:1]:<nil>:map[x:2]]"},
+		{base: "{}", ours: "{a:{x:1 y:2}}", theirs: "{a:{x:1 z:3}}", expect: `{"a":{"x":1,"y":2,"z":3}}`, conflicts: "[]"},
+		{base: "[1 2 3]", ours: "[1 5 3]", theirs: "[1 2 6]", expect: `[1,5,6]`, conflicts: "[]"},
+		{base: "[1 2 3]", ours: "[1 2 3 4]", theirs: "[0 2 3]", expect: `[1,2,3,4]`, conflicts: "[[]:[1 2 3]:[1 2 3 4]:[0 2 3]]"},
+		{
+			base:   "{items:[{id:1 q:1} {id:2 q:2} {id:3 q:3}]}",
+			ours:   "{items:[{id:2 q:2} {id:1 q:5} {id:3 q:3}]}",
+			theirs: "{items:[{id:1 q:1} {id:2 q:7} {id:4 q:4}]}",
+			opt: alt.MergeOptions{
+				ArrayKeys: []alt.ArrayKey{{Path: alt.Path{"items"}, Key: alt.Path{"id"}}},
+			},
+			expect:    `{"items":[{"id":2,"q":7},{"id":1,"q":5},{"id":4,"q":4}]}`,
+			conflicts: "[]",
+		},
+		{
+			base:   "[{id:1 q:1}]",
+			ours:   "[{id:1 q:2}]",
+			theirs: "[{id:1 q:3}]",
+			opt: alt.MergeOptions{
+				ArrayKeys: []alt.ArrayKey{{Key: alt.Path{"id"}}},
+			},
+			expect:    `[{"id":1,"q":2}]`,
+			conflicts: "[[0 q]:1:2:3]",
+		},
+		{
+			base:   "{a:1.0}",
+			ours:   "{a:1.01}",
+			theirs: "{a:1.02}",
+			opt:    alt.MergeOptions{DiffOptions: alt.DiffOptions{FloatTolerance: 0.1}},
+			expect: `{"a":1.01}`, conflicts: "[]",
+		},
+	} {
+		merged, conflicts := alt.Merge3(
+			sen.MustParse([]byte(d.base)),
+			sen.MustParse([]byte(d.ours)),
+			sen.MustParse([]byte(d.theirs)),
+			&d.opt,
+		)
+		tt.Equal(t, d.expect, oj.JSON(merged, &oj.Options{Sort: true}), i, ": ", d.ours, " ", d.theirs)
+		var cs []string
+		for _, c := range conflicts {
+			cs = append(cs, fmt.Sprintf("%v:%v:%v:%v", c.Path, c.Base, c.Ours, c.Theirs))
+		}
+		tt.Equal(t, d.conflicts, fmt.Sprint(cs), i, ": ", d.ours, " ", d.theirs)
+	}
+}
+
+func TestMerge3Resolver(t *testing.T) {
+	merged, conflicts := alt.Merge3(
+		map[string]any{"n": 1, "s": "x"},
+		map[string]any{"n": 2, "s": "y"},
+		map[string]any{"n": 3},
+		&alt.MergeOptions{Resolver: func(c *alt.Conflict) (any, bool) {
+			if n, ok := c.Ours.(int); ok {
+				return n + c.Theirs.(int), true
+			}
+			return nil, false
+		}},
+	)
+	tt.Equal(t, map[string]any{"n": 5}, merged)
+	tt.Equal(t, 2, len(conflicts))
+	tt.Equal(t, true, conflicts[1].InBase)
+	tt.Equal(t, true, conflicts[1].InOurs)
+	tt.Equal(t, false, conflicts[1].InTheirs)
+}
+
+func TestMerge3Ordered(t *testing.T) {
+	p := oj.Parser{Ordered: true}
+	base, _ := p.Parse([]byte(`{"z":1,"a":2}`))
+	ours, _ := p.Parse([]byte(`{"z":1,"a":3,"m":4}`))
+	theirs, _ := p.Parse([]byte(`{"z":5,"a":2,"b":6}`))
+	merged, conflicts := alt.Merge3(base, ours, theirs)
+	tt.Equal(t, 0, len(conflicts))
+	tt.Equal(t, `{"z":5,"a":3,"m":4,"b":6}`, oj.JSON(merged))
+
+	merged, _ = alt.Merge3(gen.Object{"a": gen.Int(1)}, gen.Object{"a": gen.Int(1)}, gen.Object{"a": gen.Int(2)})
+	tt.Equal(t, map[string]any{"a": int64(2)}, merged)
+}
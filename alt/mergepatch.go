@@ -0,0 +1,129 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package alt
+
+import (
+	"sort"
+
+	"github.com/ohler55/ojg/gen"
+)
+
+// MergePatch applies an RFC 7396 JSON Merge Patch to target and returns the
+// result. Members of a patch object with a null value are removed from the
+// target, members that are objects are merged recursively, and all other
+// values, including arrays, replace the target value. If the patch is not an
+// object it replaces the target. Target objects are modified in place. The
+// target and patch can be either simple data or gen.Node data. A
+// *gen.Ordered target is merged as simple data and members added by the
+// patch are appended in key order. The result is a gen.Node if the target
+// is a gen.Node or if the target is not an object and the patch is a
+// gen.Node.
+func MergePatch(target, patch any) any {
+	if _, ok := target.(*gen.Ordered); ok {
+		if pn, ok := patch.(gen.Node); ok {
+			patch = pn.Simplify()
+		}
+		return mergeSimple(target, patch)
+	}
+	if tn, ok := target.(gen.Node); ok {
+		return mergeGen(tn, Generify(patch, &Options{}))
+	}
+	if pn, ok := patch.(gen.Node); ok {
+		if _, ok = target.(map[string]any); !ok {
+			return mergeGen(nil, pn) // target is replaced
+		}
+		patch = pn.Simplify()
+	}
+	return mergeSimple(target, patch)
+}
+
+// DiffMergePatch returns an RFC 7396 JSON Merge Patch that transforms v0
+// into v1 when applied with MergePatch. A gen.Node is returned if v1 is a
+// gen.Node. Since a null in a merge patch indicates a removal, null values
+// in objects in v1 can not be represented.
+func DiffMergePatch(v0, v1 any) (patch any) {
+	if n, ok := v0.(gen.Node); ok {
+		v0 = n.Simplify()
+	}
+	if n, ok := v1.(gen.Node); ok {
+		return Generify(diffMerge(v0, n.Simplify()), &Options{})
+	}
+	return diffMerge(v0, v1)
+}
+
+func mergeSimple(target, patch any) any {
+	pm, ok := patch.(map[string]any)
+	if !ok {
+		return patchDup(patch)
+	}
+	if to, ok := target.(*gen.Ordered); ok {
+		keys := make([]string, 0, len(pm))
+		for k := range pm {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if v := pm[k]; v == nil {
+				to.Remove(k)
+			} else {
+				old, _ := to.Get(k)
+				to.Set(k, mergeSimple(old, v))
+			}
+		}
+		return to
+	}
+	tm, ok := target.(map[string]any)
+	if !ok {
+		tm = map[string]any{}
+	}
+	for k, v := range pm {
+		if v == nil {
+			delete(tm, k)
+		} else {
+			tm[k] = mergeSimple(tm[k], v)
+		}
+	}
+	return tm
+}
+
+func mergeGen(target, patch gen.Node) gen.Node {
+	po, ok := patch.(gen.Object)
+	if !ok {
+		if patch == nil {
+			return nil
+		}
+		return patch.Dup()
+	}
+	to, ok := target.(gen.Object)
+	if !ok {
+		to = gen.Object{}
+	}
+	for k, v := range po {
+		if v == nil {
+			delete(to, k)
+		} else {
+			to[k] = mergeGen(to[k], v)
+		}
+	}
+	return to
+}
+
+func diffMerge(v0, v1 any) any {
+	m0, ok0 := v0.(map[string]any)
+	m1, ok1 := v1.(map[string]any)
+	if !ok0 || !ok1 {
+		return patchDup(v1)
+	}
+	patch := map[string]any{}
+	for k := range m0 {
+		if _, has := m1[k]; !has {
+			patch[k] = nil
+		}
+	}
+	for k, v := range m1 {
+		if old, has := m0[k]; !has || diff(old, v, true, true) != nil {
+			patch[k] = diffMerge(old, v)
+		}
+	}
+	return patch
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package alt_test
+
+import (
+	"testing"
+
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+// Examples from RFC 7396 Appendix A.
+var mergePatchTestData = [][3]string{
+	{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+	{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+	{`{"a":"b"}`, `{"a":null}`, `{}`},
+	{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+	{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+	{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+	{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+	{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+	{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+	{`{"a":"b"}`, `["c"]`, `["c"]`},
+	{`{"a":"foo"}`, `null`, `null`},
+	{`{"a":"foo"}`, `"bar"`, `"bar"`},
+	{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+	{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+	{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+}
+
+func TestMergePatch(t *testing.T) {
+	for i, d := range mergePatchTestData {
+		result := alt.MergePatch(oj.MustParseString(d[0]), oj.MustParseString(d[1]))
+		tt.Equal(t, d[2], oj.JSON(result, &oj.Options{Sort: true}), i, ": ", d[1])
+
+		// gen target and simple patch
+		result = alt.MergePatch(alt.Generify(oj.MustParseString(d[0]), &alt.Options{}), oj.MustParseString(d[1]))
+		tt.Equal(t, d[2], oj.JSON(result, &oj.Options{Sort: true}), i, ": ", d[1])
+
+		// gen target and patch
+		result = alt.MergePatch(
+			alt.Generify(oj.MustParseString(d[0]), &alt.Options{}),
+			alt.Generify(oj.MustParseString(d[1]), &alt.Options{}))
+		tt.Equal(t, d[2], oj.JSON(result, &oj.Options{Sort: true}), i, ": ", d[1])
+		if result != nil {
+			_, ok := result.(gen.Node)
+			tt.Equal(t, true, ok, i, ": ", d[1])
+		}
+	}
+}
+
+func TestMergePatchOrdered(t *testing.T) {
+	p := oj.Parser{Ordered: true}
+	target, err := p.Parse([]byte(`{"z":1,"a":{"y":2,"b":3},"m":null}`))
+	tt.Nil(t, err)
+	result := alt.MergePatch(target, oj.MustParseString(`{"a":{"y":null,"c":4},"z":5,"n":6,"e":7}`))
+	tt.Equal(t, `{"z":5,"a":{"b":3,"c":4},"m":null,"e":7,"n":6}`, oj.JSON(result))
+}
+
+func TestDiffMergePatch(t *testing.T) {
+	for i, pair := range [][2]string{
+		{`{"a":1,"b":{"c":2,"d":3},"e":[1,2]}`, `{"a":1,"b":{"c":4},"e":[1],"f":{"g":true}}`},
+		{`{"a":1}`, `[1]`},
+		{`[1]`, `{"a":1}`},
+		{`{"a":1}`, `{"a":1}`},
+	} {
+		v0 := oj.MustParseString(pair[0])
+		v1 := oj.MustParseString(pair[1])
+		patch := alt.DiffMergePatch(v0, v1)
+		result := alt.MergePatch(v0, patch)
+		tt.Equal(t, pair[1], oj.JSON(result, &oj.Options{Sort: true}), i, ": ", oj.JSON(patch))
+	}
+	patch := alt.DiffMergePatch(
+		alt.Generify(oj.MustParseString(`{"a":1,"b":{"c":2,"d":3},"e":[1,2]}`)),
+		alt.Generify(oj.MustParseString(`{"a":1,"b":{"c":4},"e":[1]}`)))
+	_, ok := patch.(gen.Object)
+	tt.Equal(t, true, ok)
+	tt.Equal(t, `{"b":{"c":4,"d":null},"e":[1]}`, oj.JSON(patch, &oj.Options{Sort: true}))
+}
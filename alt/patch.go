@@ -0,0 +1,561 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package alt
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ohler55/ojg/gen"
+)
+
+// Patch applies an RFC 6902 JSON Patch to data and returns the patched
+// data. The patch must be an array of operation objects as either []any
+// or gen.Array. The data can be simple data or a gen.Node. A *gen.Ordered
+// is patched as simple data and keeps its member order. Containers in
+// the data are modified in place where possible so the returned value
+// should be used instead of the original data. If any operation fails all
+// the operations already applied are rolled back, the original data is
+// returned, and an error describing the failed operation is returned.
+func Patch(data, patch any) (result any, err error) {
+	var ops []any
+	switch tp := patch.(type) {
+	case []any:
+		ops = tp
+	case gen.Array:
+		ops, _ = tp.Simplify().([]any)
+	default:
+		return data, fmt.Errorf("a patch must be an array of operations")
+	}
+	_, isNode := data.(gen.Node)
+	if _, ok := data.(*gen.Ordered); ok {
+		isNode = false
+	}
+	p := patcher{root: data, node: isNode}
+	for i, op := range ops {
+		if err = p.apply(op); err != nil {
+			for j := len(p.undo) - 1; 0 <= j; j-- {
+				p.undo[j]()
+			}
+			return data, fmt.Errorf("patch operation %d failed, %w", i, err)
+		}
+	}
+	return p.root, nil
+}
+
+// DiffPatch returns an RFC 6902 JSON Patch as an []any of operation objects
+// that transforms v0 into v1 when applied with Patch. The differences are
+// determined with the same walk used by Diff. Values in the patch are
+// copies of the values in v1.
+func DiffPatch(v0, v1 any) (patch []any) {
+	if n, ok := v0.(gen.Node); ok {
+		v0 = n.Simplify()
+	}
+	if n, ok := v1.(gen.Node); ok {
+		v1 = n.Simplify()
+	}
+	diffs := diff(v0, v1, false, true)
+	sort.Slice(diffs, func(i, j int) bool { return comparePaths(diffs[i], diffs[j]) < 0 })
+	patch = []any{}
+	for _, d := range diffs {
+		if len(d) == 1 && d[0] == nil {
+			return append(patch, patchOp("replace", "", patchDup(v1)))
+		}
+		ptr := pointerString(d[:len(d)-1])
+		p0, _ := pathValue(v0, d[:len(d)-1])
+		p1, _ := pathValue(v1, d[:len(d)-1])
+		switch key := d[len(d)-1].(type) {
+		case string:
+			m0, _ := p0.(map[string]any)
+			m1, _ := p1.(map[string]any)
+			v, has := m1[key]
+			switch _, had := m0[key]; {
+			case had && has:
+				patch = append(patch, patchOp("replace", ptr+"/"+escapePointer(key), patchDup(v)))
+			case has:
+				patch = append(patch, patchOp("add", ptr+"/"+escapePointer(key), patchDup(v)))
+			default:
+				patch = append(patch, patchOp("remove", ptr+"/"+escapePointer(key), nil))
+			}
+		case int:
+			a0, _ := p0.([]any)
+			a1, _ := p1.([]any)
+			switch {
+			case key < len(a0) && key < len(a1):
+				patch = append(patch, patchOp("replace", ptr+"/"+strconv.Itoa(key), patchDup(a1[key])))
+			case len(a1) < len(a0):
+				for i := len(a0) - 1; len(a1) <= i; i-- {
+					patch = append(patch, patchOp("remove", ptr+"/"+strconv.Itoa(i), nil))
+				}
+			default:
+				for i := len(a0); i < len(a1); i++ {
+					patch = append(patch, patchOp("add", ptr+"/"+strconv.Itoa(i), patchDup(a1[i])))
+				}
+			}
+		}
+	}
+	return
+}
+
+type patcher struct {
+	root any
+	node bool
+	undo []func()
+}
+
+func (p *patcher) apply(op any) (err error) {
+	m, ok := op.(map[string]any)
+	if !ok {
+		return fmt.Errorf("an operation must be an object, not a %T", op)
+	}
+	name, _ := m["op"].(string)
+	ps, ok := m["path"].(string)
+	if !ok {
+		return fmt.Errorf("%s operation is missing a path", name)
+	}
+	var path []string
+	if path, err = parsePointer(ps); err != nil {
+		return
+	}
+	var from []string
+	switch name {
+	case "move", "copy":
+		fs, ok := m["from"].(string)
+		if !ok {
+			return fmt.Errorf("%s operation is missing a from", name)
+		}
+		if from, err = parsePointer(fs); err != nil {
+			return
+		}
+	}
+	value, hasValue := m["value"]
+	switch name {
+	case "add", "replace", "test":
+		if !hasValue {
+			return fmt.Errorf("%s operation is missing a value", name)
+		}
+		if p.node && name != "test" {
+			value = Generify(value, &Options{})
+		}
+	}
+	switch name {
+	case "add":
+		err = p.add(path, value)
+	case "remove":
+		_, err = p.remove(path)
+	case "replace":
+		if _, err = p.get(path); err == nil {
+			err = p.set(path, value)
+		}
+	case "move":
+		if len(from) < len(path) && strings.HasPrefix(ps, m["from"].(string)+"/") {
+			return fmt.Errorf("can not move %s into itself", m["from"])
+		}
+		if value, err = p.remove(from); err == nil {
+			err = p.add(path, value)
+		}
+	case "copy":
+		if value, err = p.get(from); err == nil {
+			err = p.add(path, patchDup(value))
+		}
+	case "test":
+		var v any
+		if v, err = p.get(path); err == nil {
+			if n, ok := v.(gen.Node); ok {
+				v = n.Simplify()
+			}
+			if diff(v, value, true, true) != nil {
+				err = fmt.Errorf("test of %s failed", ps)
+			}
+		}
+	default:
+		err = fmt.Errorf("%q is not a valid operation", name)
+	}
+	return
+}
+
+// containers returns the chain of containers from the root to the parent
+// of the last path element.
+func (p *patcher) containers(path []string) (chain []any, err error) {
+	v := p.root
+	chain = append(chain, v)
+	for i, key := range path[:len(path)-1] {
+		var has bool
+		if v, has = childValue(v, key); !has {
+			return nil, fmt.Errorf("%s does not exist", pointerString(path[:i+1]))
+		}
+		chain = append(chain, v)
+	}
+	return
+}
+
+func (p *patcher) get(path []string) (v any, err error) {
+	v = p.root
+	for i, key := range path {
+		var has bool
+		if v, has = childValue(v, key); !has {
+			return nil, fmt.Errorf("%s does not exist", pointerString(path[:i+1]))
+		}
+	}
+	return
+}
+
+// set a value in place for a path that is known to exist.
+func (p *patcher) set(path []string, value any) error {
+	if len(path) == 0 {
+		p.setRoot(value)
+		return nil
+	}
+	chain, err := p.containers(path)
+	if err != nil {
+		return err
+	}
+	return p.setSlot(chain[len(chain)-1], path[len(path)-1], value)
+}
+
+func (p *patcher) add(path []string, value any) error {
+	if len(path) == 0 {
+		p.setRoot(value)
+		return nil
+	}
+	chain, err := p.containers(path)
+	if err != nil {
+		return err
+	}
+	key := path[len(path)-1]
+	switch tc := chain[len(chain)-1].(type) {
+	case []any:
+		i, err := arrayIndex(key, len(tc), true, path)
+		if err != nil {
+			return err
+		}
+		na := make([]any, 0, len(tc)+1)
+		na = append(append(append(na, tc[:i]...), value), tc[i:]...)
+		return p.replaceContainer(chain, path, na)
+	case gen.Array:
+		i, err := arrayIndex(key, len(tc), true, path)
+		if err != nil {
+			return err
+		}
+		n, _ := value.(gen.Node)
+		na := make(gen.Array, 0, len(tc)+1)
+		na = append(append(append(na, tc[:i]...), n), tc[i:]...)
+		return p.replaceContainer(chain, path, na)
+	}
+	return p.setSlot(chain[len(chain)-1], key, value)
+}
+
+func (p *patcher) remove(path []string) (removed any, err error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("can not remove the root")
+	}
+	var chain []any
+	if chain, err = p.containers(path); err != nil {
+		return
+	}
+	key := path[len(path)-1]
+	switch tc := chain[len(chain)-1].(type) {
+	case map[string]any:
+		var has bool
+		if removed, has = tc[key]; !has {
+			return nil, fmt.Errorf("%s does not exist", pointerString(path))
+		}
+		delete(tc, key)
+		p.undo = append(p.undo, func() { tc[key] = removed })
+	case gen.Object:
+		n, has := tc[key]
+		if !has {
+			return nil, fmt.Errorf("%s does not exist", pointerString(path))
+		}
+		removed = n
+		delete(tc, key)
+		p.undo = append(p.undo, func() { tc[key] = n })
+	case *gen.Ordered:
+		i := 0
+		for ; i < len(tc.Members) && tc.Members[i].Key != key; i++ {
+		}
+		if len(tc.Members) <= i {
+			return nil, fmt.Errorf("%s does not exist", pointerString(path))
+		}
+		m := tc.Members[i]
+		removed = m.Value
+		tc.Members = append(tc.Members[:i:i], tc.Members[i+1:]...)
+		p.undo = append(p.undo, func() {
+			tc.Members = append(tc.Members[:i:i], append([]gen.Member{m}, tc.Members[i:]...)...)
+		})
+	case []any:
+		var i int
+		if i, err = arrayIndex(key, len(tc), false, path); err != nil {
+			return
+		}
+		removed = tc[i]
+		na := make([]any, 0, len(tc)-1)
+		err = p.replaceContainer(chain, path, append(append(na, tc[:i]...), tc[i+1:]...))
+	case gen.Array:
+		var i int
+		if i, err = arrayIndex(key, len(tc), false, path); err != nil {
+			return
+		}
+		removed = tc[i]
+		na := make(gen.Array, 0, len(tc)-1)
+		err = p.replaceContainer(chain, path, append(append(na, tc[:i]...), tc[i+1:]...))
+	default:
+		err = fmt.Errorf("%s does not exist", pointerString(path))
+	}
+	return
+}
+
+func (p *patcher) setRoot(value any) {
+	old := p.root
+	p.root = value
+	p.undo = append(p.undo, func() { p.root = old })
+}
+
+// replaceContainer replaces the parent container of the last path element
+// with a new array. Arrays are always replaced instead of being modified in
+// place so that rollback only has to restore the array in its parent.
+func (p *patcher) replaceContainer(chain []any, path []string, array any) error {
+	if len(chain) == 1 {
+		p.setRoot(array)
+		return nil
+	}
+	return p.setSlot(chain[len(chain)-2], path[len(path)-2], array)
+}
+
+func (p *patcher) setSlot(container any, key string, value any) error {
+	switch tc := container.(type) {
+	case map[string]any:
+		old, has := tc[key]
+		tc[key] = value
+		p.undo = append(p.undo, func() {
+			if has {
+				tc[key] = old
+			} else {
+				delete(tc, key)
+			}
+		})
+	case *gen.Ordered:
+		old, has := tc.Get(key)
+		tc.Set(key, value)
+		p.undo = append(p.undo, func() {
+			if has {
+				tc.Set(key, old)
+			} else {
+				tc.Remove(key)
+			}
+		})
+	case gen.Object:
+		old, has := tc[key]
+		tc[key], _ = value.(gen.Node)
+		p.undo = append(p.undo, func() {
+			if has {
+				tc[key] = old
+			} else {
+				delete(tc, key)
+			}
+		})
+	case []any:
+		i, err := arrayIndex(key, len(tc), false, nil)
+		if err != nil {
+			return err
+		}
+		old := tc[i]
+		tc[i] = value
+		p.undo = append(p.undo, func() { tc[i] = old })
+	case gen.Array:
+		i, err := arrayIndex(key, len(tc), false, nil)
+		if err != nil {
+			return err
+		}
+		old := tc[i]
+		tc[i], _ = value.(gen.Node)
+		p.undo = append(p.undo, func() { tc[i] = old })
+	default:
+		return fmt.Errorf("can not set %s on a %T", key, container)
+	}
+	return nil
+}
+
+// arrayIndex converts a pointer reference token to an index into an array
+// of the provided size. When adding the index can also be the size and the
+// "-" token is allowed to indicate the end of the array.
+func arrayIndex(key string, size int, add bool, path []string) (int, error) {
+	i := size
+	if key != "-" || !add {
+		var err error
+		if i, err = strconv.Atoi(key); err != nil || i < 0 || (1 < len(key) && key[0] == '0') || key[0] == '+' {
+			return 0, fmt.Errorf("%q is not a valid array index", key)
+		}
+	}
+	if add {
+		size++
+	}
+	if size <= i {
+		if path == nil {
+			return 0, fmt.Errorf("index %s is out of range", key)
+		}
+		return 0, fmt.Errorf("%s does not exist", pointerString(path))
+	}
+	return i, nil
+}
+
+func childValue(v any, key string) (any, bool) {
+	switch tv := v.(type) {
+	case map[string]any:
+		child, has := tv[key]
+		return child, has
+	case gen.Object:
+		child, has := tv[key]
+		return child, has
+	case *gen.Ordered:
+		return tv.Get(key)
+	case []any:
+		if i, err := arrayIndex(key, len(tv), false, nil); err == nil {
+			return tv[i], true
+		}
+	case gen.Array:
+		if i, err := arrayIndex(key, len(tv), false, nil); err == nil {
+			return tv[i], true
+		}
+	}
+	return nil, false
+}
+
+// pathValue returns the value at the path in simple data.
+func pathValue(v any, path Path) (any, bool) {
+	for _, key := range path {
+		switch tk := key.(type) {
+		case string:
+			var ok bool
+			switch tv := v.(type) {
+			case map[string]any:
+				v, ok = tv[tk]
+			case *gen.Ordered:
+				v, ok = tv.Get(tk)
+			}
+			if !ok {
+				return nil, false
+			}
+		case int:
+			a, ok := v.([]any)
+			if !ok || len(a) <= tk {
+				return nil, false
+			}
+			v = a[tk]
+		}
+	}
+	return v, true
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into unescaped reference
+// tokens.
+func parsePointer(s string) (tokens []string, err error) {
+	if len(s) == 0 {
+		return []string{}, nil
+	}
+	if s[0] != '/' {
+		return nil, fmt.Errorf("%q is not a valid JSON Pointer", s)
+	}
+	tokens = strings.Split(s[1:], "/")
+	for i, t := range tokens {
+		if strings.IndexByte(t, '~') < 0 {
+			continue
+		}
+		for j := 0; j < len(t); j++ {
+			if t[j] == '~' && (len(t) <= j+1 || (t[j+1] != '0' && t[j+1] != '1')) {
+				return nil, fmt.Errorf("%q is not a valid JSON Pointer", s)
+			}
+		}
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return
+}
+
+func escapePointer(token string) string {
+	if strings.ContainsAny(token, "~/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+	}
+	return token
+}
+
+// pointerString forms a JSON Pointer from either a Path or reference
+// tokens.
+func pointerString(path any) string {
+	var b strings.Builder
+	switch tp := path.(type) {
+	case Path:
+		for _, key := range tp {
+			b.WriteByte('/')
+			switch tk := key.(type) {
+			case string:
+				b.WriteString(escapePointer(tk))
+			case int:
+				b.WriteString(strconv.Itoa(tk))
+			}
+		}
+	case []string:
+		for _, key := range tp {
+			b.WriteByte('/')
+			b.WriteString(escapePointer(key))
+		}
+	}
+	return b.String()
+}
+
+func comparePaths(p0, p1 Path) int {
+	for i, k0 := range p0 {
+		if len(p1) <= i {
+			return 1
+		}
+		switch t0 := k0.(type) {
+		case string:
+			t1, ok := p1[i].(string)
+			if !ok {
+				return -1
+			}
+			if c := strings.Compare(t0, t1); c != 0 {
+				return c
+			}
+		case int:
+			t1, ok := p1[i].(int)
+			if !ok {
+				return 1
+			}
+			if t0 != t1 {
+				return t0 - t1
+			}
+		}
+	}
+	return len(p0) - len(p1)
+}
+
+func patchOp(name, path string, value any) map[string]any {
+	op := map[string]any{"op": name, "path": path}
+	if name != "remove" {
+		op["value"] = value
+	}
+	return op
+}
+
+// patchDup makes a deep copy of simple data or a gen.Node.
+func patchDup(v any) any {
+	switch tv := v.(type) {
+	case gen.Node:
+		return tv.Dup()
+	case []any:
+		a := make([]any, len(tv))
+		for i, m := range tv {
+			a[i] = patchDup(m)
+		}
+		return a
+	case map[string]any:
+		m := make(map[string]any, len(tv))
+		for k, mv := range tv {
+			m[k] = patchDup(mv)
+		}
+		return m
+	}
+	return v
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package alt_test
+
+import (
+	"testing"
+
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+type patchData struct {
+	doc    string
+	patch  string
+	expect string // empty if an error is expected
+	err    string
+}
+
+// Examples from RFC 6902 Appendix A plus a few more.
+var patchTestData = []*patchData{
+	{doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz","value":"qux"}]`, expect: `{"baz":"qux","foo":"bar"}`},
+	{doc: `{"foo":["bar","baz"]}`, patch: `[{"op":"add","path":"/foo/1","value":"qux"}]`, expect: `{"foo":["bar","qux","baz"]}`},
+	{doc: `{"baz":"qux","foo":"bar"}`, patch: `[{"op":"remove","path":"/baz"}]`, expect: `{"foo":"bar"}`},
+	{doc: `{"foo":["bar","qux","baz"]}`, patch: `[{"op":"remove","path":"/foo/1"}]`, expect: `{"foo":["bar","baz"]}`},
+	{doc: `{"baz":"qux","foo":"bar"}`, patch: `[{"op":"replace","path":"/baz","value":"boo"}]`, expect: `{"baz":"boo","foo":"bar"}`},
+	{
+		doc:    `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+		patch:  `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+		expect: `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+	},
+	{doc: `{"foo":["all","grass","cows","eat"]}`, patch: `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, expect: `{"foo":["all","cows","eat","grass"]}`},
+	{
+		doc:    `{"baz":"qux","foo":["a",2,"c"]}`,
+		patch:  `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+		expect: `{"baz":"qux","foo":["a",2,"c"]}`,
+	},
+	{doc: `{"baz":"qux"}`, patch: `[{"op":"test","path":"/baz","value":"bar"}]`, err: "patch operation 0 failed, test of /baz failed"},
+	{doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, expect: `{"child":{"grandchild":{}},"foo":"bar"}`},
+	{doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`, expect: `{"baz":"qux","foo":"bar"}`},
+	{doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz/bat","value":"qux"}]`, err: "patch operation 0 failed, /baz does not exist"},
+	{doc: `{"/":9,"~1":10}`, patch: `[{"op":"test","path":"/~01","value":10}]`, expect: `{"/":9,"~1":10}`},
+	{doc: `{"/":9,"~1":10}`, patch: `[{"op":"test","path":"/~01","value":"10"}]`, err: "patch operation 0 failed, test of /~01 failed"},
+	{doc: `{"foo":["bar"]}`, patch: `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, expect: `{"foo":["bar",["abc","def"]]}`},
+	{doc: `{"foo":1}`, patch: `[{"op":"test","path":"/foo","value":1.0}]`, expect: `{"foo":1}`},
+	{doc: `{"foo":null}`, patch: `[{"op":"test","path":"/bar","value":null}]`, err: "patch operation 0 failed, /bar does not exist"},
+	{doc: `{"a":{"b":null}}`, patch: `[{"op":"test","path":"/a","value":{}}]`, err: "patch operation 0 failed, test of /a failed"},
+
+	{doc: `{"a":[1,2]}`, patch: `[{"op":"copy","from":"/a","path":"/b"}]`, expect: `{"a":[1,2],"b":[1,2]}`},
+	{doc: `{"a":{"b":1}}`, patch: `[{"op":"move","from":"/a","path":"/a/c"}]`, err: "patch operation 0 failed, can not move /a into itself"},
+	{doc: `{"a":1}`, patch: `[{"op":"move","from":"/a","path":"/a"}]`, expect: `{"a":1}`},
+	{doc: `{"a":1}`, patch: `[{"op":"replace","path":"","value":[3]}]`, expect: `[3]`},
+	{doc: `[1,2]`, patch: `[{"op":"add","path":"/0","value":0}]`, expect: `[0,1,2]`},
+	{doc: `[1,2]`, patch: `[{"op":"add","path":"/3","value":0}]`, err: "patch operation 0 failed, /3 does not exist"},
+	{doc: `[1,2]`, patch: `[{"op":"add","path":"/01","value":0}]`, err: `patch operation 0 failed, "01" is not a valid array index`},
+	{doc: `[1,2]`, patch: `[{"op":"remove","path":"/-"}]`, err: `patch operation 0 failed, "-" is not a valid array index`},
+	{doc: `[1,2]`, patch: `[{"op":"remove","path":""}]`, err: "patch operation 0 failed, can not remove the root"},
+	{doc: `{"a":1}`, patch: `[{"op":"replace","path":"/b","value":2}]`, err: "patch operation 0 failed, /b does not exist"},
+	{doc: `{"a":1}`, patch: `[{"op":"add","path":"a","value":2}]`, err: `patch operation 0 failed, "a" is not a valid JSON Pointer`},
+	{doc: `{"a":1}`, patch: `[{"op":"add","path":"/~2","value":2}]`, err: `patch operation 0 failed, "/~2" is not a valid JSON Pointer`},
+	{doc: `{"a":1}`, patch: `[{"op":"add","path":"/b"}]`, err: "patch operation 0 failed, add operation is missing a value"},
+	{doc: `{"a":1}`, patch: `[{"op":"copy","path":"/b"}]`, err: "patch operation 0 failed, copy operation is missing a from"},
+	{doc: `{"a":1}`, patch: `[{"op":"add","value":2}]`, err: "patch operation 0 failed, add operation is missing a path"},
+	{doc: `{"a":1}`, patch: `[{"op":"bad","path":"/a"}]`, err: `patch operation 0 failed, "bad" is not a valid operation`},
+	{doc: `{"a":1}`, patch: `[7]`, err: "patch operation 0 failed, an operation must be an object, not a int64"},
+	{doc: `{"a":1}`, patch: `{}`, err: "a patch must be an array of operations"},
+}
+
+func TestPatch(t *testing.T) {
+	for i, pd := range patchTestData {
+		for _, node := range []bool{false, true} {
+			var data any = oj.MustParseString(pd.doc)
+			var patch any = oj.MustParseString(pd.patch)
+			if node {
+				data = alt.Generify(data, &alt.Options{})
+				patch = alt.Generify(patch, &alt.Options{})
+			}
+			result, err := alt.Patch(data, patch)
+			if 0 < len(pd.err) {
+				tt.NotNil(t, err, i, ": ", pd.patch)
+				tt.Equal(t, pd.err, err.Error(), i, ": ", pd.patch)
+				tt.Equal(t, pd.doc, oj.JSON(result, &oj.Options{Sort: true}), i, ": ", pd.patch)
+				continue
+			}
+			tt.Nil(t, err, i, ": ", pd.patch)
+			if node {
+				_, ok := result.(gen.Node)
+				tt.Equal(t, true, ok, i, ": ", pd.patch)
+			}
+			tt.Equal(t, pd.expect, oj.JSON(result, &oj.Options{Sort: true}), i, ": ", pd.patch)
+		}
+	}
+}
+
+func TestPatchRollback(t *testing.T) {
+	src := `{"a":{"b":[1,2,3]},"c":"x","d":[{"e":1}]}`
+	patch := oj.MustParseString(`[
+  {"op":"add","path":"/a/b/1","value":9},
+  {"op":"remove","path":"/c"},
+  {"op":"replace","path":"/d/0/e","value":2},
+  {"op":"move","from":"/a/b","path":"/z"},
+  {"op":"copy","from":"/d","path":"/c"},
+  {"op":"add","path":"/d/-","value":true},
+  {"op":"replace","path":"","value":{}},
+  {"op":"test","path":"/nope","value":1}
+]`)
+	for _, node := range []bool{false, true} {
+		var data any = oj.MustParseString(src)
+		if node {
+			data = alt.Generify(data, &alt.Options{})
+		}
+		result, err := alt.Patch(data, patch)
+		tt.NotNil(t, err)
+		tt.Equal(t, "patch operation 7 failed, /nope does not exist", err.Error())
+		tt.Equal(t, src, oj.JSON(result, &oj.Options{Sort: true}))
+		tt.Equal(t, src, oj.JSON(data, &oj.Options{Sort: true}))
+	}
+}
+
+func TestPatchOrdered(t *testing.T) {
+	p := oj.Parser{Ordered: true}
+	data, err := p.Parse([]byte(`{"z":1,"a":{"y":2,"b":3},"m":[1]}`))
+	tt.Nil(t, err)
+	patch := oj.MustParseString(`[
+  {"op":"replace","path":"/z","value":9},
+  {"op":"remove","path":"/a/y"},
+  {"op":"add","path":"/a/c","value":4},
+  {"op":"add","path":"/m/-","value":2}
+]`)
+	result, err := alt.Patch(data, patch)
+	tt.Nil(t, err)
+	tt.Equal(t, `{"z":9,"a":{"b":3,"c":4},"m":[1,2]}`, oj.JSON(result))
+
+	patch = oj.MustParseString(`[
+  {"op":"remove","path":"/a/b"},
+  {"op":"add","path":"/n","value":true},
+  {"op":"test","path":"/x","value":1}
+]`)
+	result, err = alt.Patch(result, patch)
+	tt.NotNil(t, err)
+	tt.Equal(t, `{"z":9,"a":{"b":3,"c":4},"m":[1,2]}`, oj.JSON(result))
+}
+
+func TestDiffPatch(t *testing.T) {
+	for i, pair := range [][2]string{
+		{`{"a":1,"b":[1,2,3],"c":{"d":true}}`, `{"a":2,"b":[1,5],"c":{"d":true,"e":null},"f":"x"}`},
+		{`{"a":null,"b":[1]}`, `{"b":[1,2,3]}`},
+		{`{"a/b":1,"c~d":2}`, `{"a/b":3}`},
+		{`[1,{"a":1}]`, `[1,{"a":[1]},3]`},
+		{`{"a":1}`, `[1]`},
+		{`{"a":1}`, `{"a":1}`},
+	} {
+		v0 := oj.MustParseString(pair[0])
+		v1 := oj.MustParseString(pair[1])
+		patch := alt.DiffPatch(v0, v1)
+		result, err := alt.Patch(v0, patch)
+		tt.Nil(t, err, i, ": ", oj.JSON(patch))
+		tt.Equal(t, pair[1], oj.JSON(result, &oj.Options{Sort: true}), i, ": ", oj.JSON(patch))
+	}
+	patch := alt.DiffPatch(
+		alt.Generify(oj.MustParseString(`{"a":1,"b":[1,2,3],"c":{"d":true}}`)),
+		alt.Generify(oj.MustParseString(`{"a":1,"b":[1],"c":{}}`)))
+	tt.Equal(t,
+		`[{"op":"remove","path":"/b/2"},{"op":"remove","path":"/b/1"},{"op":"remove","path":"/c/d"}]`,
+		oj.JSON(patch, &oj.Options{Sort: true}))
+}
@@ -36,7 +36,22 @@ type Recomposer struct {
 	// CreateKey identifies the creation key in decomposed objects.
 	CreateKey string
 
+	// RefKey if not empty identifies reference objects such as
+	// {"$ref": "$.a.b"} that are written when the OnCycle option is
+	// ojg.CycleRef. A reference object is replaced by the enclosing value
+	// already recomposed at the JSONPath it refers to so pointer cycles are
+	// restored as shared pointers.
+	RefKey string
+
 	composers map[string]*composer
+	refs      *recompRefs
+}
+
+// recompRefs tracks the values recomposed so far by path when resolving
+// reference objects.
+type recompRefs struct {
+	path   pathStack
+	values map[string]reflect.Value
 }
 
 var jsonUnmarshalerType reflect.Type
@@ -159,6 +174,12 @@ func (r *Recomposer) Recompose(v any, tv ...any) (out any, err error) {
 
 // MustRecompose simple data into more complex go types.
 func (r *Recomposer) MustRecompose(v any, tv ...any) (out any) {
+	if 0 < len(r.RefKey) {
+		// Use a copy so the references are not shared with other calls.
+		rc := *r
+		rc.refs = &recompRefs{values: map[string]reflect.Value{}}
+		r = &rc
+	}
 	if 0 < len(tv) {
 		if um, ok := tv[0].(json.Unmarshaler); ok {
 			if comp := r.composers["json.Unmarshaler"]; comp != nil {
@@ -179,6 +200,7 @@ func (r *Recomposer) MustRecompose(v any, tv ...any) (out any) {
 		case reflect.Map:
 			r.recomp(v, rv)
 		case reflect.Ptr:
+			r.addRef(rv)
 			r.recomp(v, rv)
 			switch rv.Elem().Kind() {
 			case reflect.Slice, reflect.Array, reflect.Map, reflect.Interface:
@@ -222,11 +244,17 @@ func (r *Recomposer) recompAny(v any) any {
 		v = math.Ldexp(f, i)
 	case []any:
 		a := make([]any, len(tv))
+		r.addRef(reflect.ValueOf(a))
 		for i, m := range tv {
+			r.pushIndex(i)
 			a[i] = r.recompAny(m)
+			r.popPath()
 		}
 		v = a
 	case map[string]any:
+		if ref, ok := r.ref(tv); ok {
+			return ref.Interface()
+		}
 		if cv := tv[r.CreateKey]; cv != nil {
 			tn, _ := cv.(string)
 			if c := r.composers[tn]; c != nil {
@@ -238,13 +266,49 @@ func (r *Recomposer) recompAny(v any) any {
 					return val
 				}
 				rv := reflect.New(c.rtype)
+				r.addRef(rv)
 				r.recomp(v, rv)
 				return rv.Interface()
 			}
 		}
 		o := map[string]any{}
+		r.addRef(reflect.ValueOf(o))
 		for k, m := range tv {
+			r.pushKey(k)
 			o[k] = r.recompAny(m)
+			r.popPath()
+		}
+		v = o
+	case *gen.Ordered:
+		if tv == nil {
+			return nil
+		}
+		if ref, ok := r.ref(tv); ok {
+			return ref.Interface()
+		}
+		if cv, _ := tv.Get(r.CreateKey); cv != nil {
+			tn, _ := cv.(string)
+			if c := r.composers[tn]; c != nil {
+				vm := orderedMap(tv)
+				if c.fun != nil {
+					val, err := c.fun(vm)
+					if err != nil {
+						panic(err)
+					}
+					return val
+				}
+				rv := reflect.New(c.rtype)
+				r.addRef(rv)
+				r.recomp(vm, rv)
+				return rv.Interface()
+			}
+		}
+		o := &gen.Ordered{Members: make([]gen.Member, len(tv.Members))}
+		r.addRef(reflect.ValueOf(o))
+		for i, m := range tv.Members {
+			r.pushKey(m.Key)
+			o.Members[i] = gen.Member{Key: m.Key, Value: r.recompAny(m.Value)}
+			r.popPath()
 		}
 		v = o
 
@@ -321,15 +385,16 @@ func (r *Recomposer) recomp(v any, rv reflect.Value) {
 		av := reflect.MakeSlice(rv.Type(), size, size)
 		et := av.Type().Elem()
 		if et.Kind() == reflect.Ptr {
-			et = et.Elem()
 			for i := 0; i < size; i++ {
-				ev := reflect.New(et)
-				r.recomp(va[i], ev)
-				av.Index(i).Set(ev)
+				r.pushIndex(i)
+				av.Index(i).Set(r.recompPtr(va[i], et))
+				r.popPath()
 			}
 		} else {
 			for i := 0; i < size; i++ {
+				r.pushIndex(i)
 				r.setValue(va[i], av.Index(i), nil)
+				r.popPath()
 			}
 		}
 		rv.Set(av)
@@ -339,6 +404,9 @@ func (r *Recomposer) recomp(v any, rv reflect.Value) {
 		}
 		et := rv.Type().Elem()
 		vm, ok := (v).(map[string]any)
+		if o, isOrdered := v.(*gen.Ordered); isOrdered {
+			vm, ok = orderedMap(o), true
+		}
 		if !ok {
 			vv := reflect.ValueOf(v)
 			if vv.Kind() != reflect.Map {
@@ -357,24 +425,30 @@ func (r *Recomposer) recomp(v any, rv reflect.Value) {
 		switch {
 		case et.Kind() == reflect.Interface:
 			for k, m := range vm {
+				r.pushKey(k)
 				rv.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(r.recompAny(m)))
+				r.popPath()
 			}
 		case et.Kind() == reflect.Ptr:
-			et = et.Elem()
 			for k, m := range vm {
-				ev := reflect.New(et)
-				r.recomp(m, ev)
-				rv.SetMapIndex(reflect.ValueOf(k), ev)
+				r.pushKey(k)
+				rv.SetMapIndex(reflect.ValueOf(k), r.recompPtr(m, et))
+				r.popPath()
 			}
 		default:
 			for k, m := range vm {
+				r.pushKey(k)
 				ev := reflect.New(et)
 				r.recomp(m, ev)
 				rv.SetMapIndex(reflect.ValueOf(k), ev.Elem())
+				r.popPath()
 			}
 		}
 	case reflect.Struct:
 		vm, ok := (v).(map[string]any)
+		if o, isOrdered := v.(*gen.Ordered); isOrdered {
+			vm, ok = orderedMap(o), true
+		}
 		if !ok {
 			if c := r.composers[rv.Type().Name()]; c != nil && c.any != nil {
 				if val, err := c.any(v); err == nil {
@@ -438,17 +512,23 @@ func (r *Recomposer) recomp(v any, rv reflect.Value) {
 			f := rv.FieldByIndex(sf.Index)
 			var m any
 			var has bool
-			if m, has = vm[k]; !has {
-				if m, has = vm[sf.Name]; !has {
+			key := k
+			if m, has = vm[key]; !has {
+				key = sf.Name
+				if m, has = vm[key]; !has {
 					name := []byte(sf.Name)
 					name[0] |= 0x20
-					if m, has = vm[string(name)]; !has {
-						m, has = vm[strings.ToLower(string(name))]
+					key = string(name)
+					if m, has = vm[key]; !has {
+						key = strings.ToLower(key)
+						m, has = vm[key]
 					}
 				}
 			}
 			if has && m != nil {
+				r.pushKey(key)
 				r.setValue(m, f, &sf)
+				r.popPath()
 			}
 		}
 	case reflect.Interface:
@@ -507,9 +587,7 @@ func (r *Recomposer) setValue(v any, rv reflect.Value, sf *reflect.StructField)
 		v = r.recompAny(v)
 		rv.Set(reflect.ValueOf(v))
 	case reflect.Ptr:
-		ev := reflect.New(rv.Type().Elem())
-		r.recomp(v, ev)
-		rv.Set(ev)
+		rv.Set(r.recompPtr(v, rv.Type()))
 	default:
 		if reflect.PtrTo(rv.Type()).Implements(jsonUnmarshalerType) {
 			ev := rv.Addr().Interface().(json.Unmarshaler)
@@ -524,3 +602,80 @@ func (r *Recomposer) setValue(v any, rv reflect.Value, sf *reflect.StructField)
 		r.recomp(v, rv)
 	}
 }
+
+// orderedMap returns the members of an Ordered as a map so it can be
+// recomposed the same way as a map[string]any.
+func orderedMap(o *gen.Ordered) map[string]any {
+	if o == nil {
+		return nil
+	}
+	m := make(map[string]any, len(o.Members))
+	for _, mem := range o.Members {
+		m[mem.Key] = mem.Value
+	}
+	return m
+}
+
+// recompPtr returns a new pointer of type pt recomposed from v or, if v is a
+// reference object, the pointer already recomposed at the referenced path.
+func (r *Recomposer) recompPtr(v any, pt reflect.Type) reflect.Value {
+	if ref, ok := r.ref(v); ok {
+		if !ref.Type().AssignableTo(pt) {
+			panic(fmt.Errorf("can not set a %s to a reference to a %s", pt, ref.Type()))
+		}
+		return ref
+	}
+	ev := reflect.New(pt.Elem())
+	r.addRef(ev)
+	r.recomp(v, ev)
+
+	return ev
+}
+
+// ref returns the value a reference object refers to. If v is not a
+// reference object false is returned.
+func (r *Recomposer) ref(v any) (reflect.Value, bool) {
+	if r.refs == nil {
+		return reflect.Value{}, false
+	}
+	if o, ok := v.(*gen.Ordered); ok && o != nil && len(o.Members) == 1 {
+		v = orderedMap(o)
+	}
+	m, _ := v.(map[string]any)
+	if len(m) != 1 {
+		return reflect.Value{}, false
+	}
+	path, ok := m[r.RefKey].(string)
+	if !ok {
+		return reflect.Value{}, false
+	}
+	ref, has := r.refs.values[path]
+	if !has {
+		panic(fmt.Errorf("reference to %s at %s can not be resolved", path, r.refs.path.String(len(r.refs.path))))
+	}
+	return ref, true
+}
+
+func (r *Recomposer) addRef(rv reflect.Value) {
+	if r.refs != nil {
+		r.refs.values[r.refs.path.String(len(r.refs.path))] = rv
+	}
+}
+
+func (r *Recomposer) pushKey(k string) {
+	if r.refs != nil {
+		r.refs.path.key(k)
+	}
+}
+
+func (r *Recomposer) pushIndex(i int) {
+	if r.refs != nil {
+		r.refs.path.index(i)
+	}
+}
+
+func (r *Recomposer) popPath() {
+	if r.refs != nil {
+		r.refs.path.pop()
+	}
+}
@@ -778,4 +778,24 @@ func TestRecomposeUnmarshallerList(t *testing.T) {
 	tt.Equal(t, 1, len(list))
 	tt.Equal(t, 1, len(list[0]))
 	tt.Equal(t, 1, list[0]["k1"])
-}
\ No newline at end of file
+}
+
+func TestRecomposeOrdered(t *testing.T) {
+	src := &gen.Ordered{Members: []gen.Member{
+		{Key: "type", Value: "Dummy"},
+		{Key: "val", Value: 3},
+		{Key: "nest", Value: &gen.Ordered{Members: []gen.Member{{Key: "b", Value: 1}, {Key: "a", Value: int8(2)}}}},
+	}}
+	r := alt.MustNewRecomposer("type", map[any]alt.RecomposeFunc{&Dummy{}: nil})
+	v, err := r.Recompose(src)
+	tt.Nil(t, err)
+	d, _ := v.(*Dummy)
+	tt.NotNil(t, d)
+	tt.Equal(t, 3, d.Val)
+	tt.Equal(t, `{"b":1,"a":2}`, oj.JSON(d.Nest))
+
+	var wl WithList
+	_, err = alt.Recompose(&gen.Ordered{Members: []gen.Member{{Key: "list", Value: []any{1, 2}}}}, &wl)
+	tt.Nil(t, err)
+	tt.Equal(t, []int{1, 2}, wl.List)
+}
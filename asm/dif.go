@@ -4,6 +4,7 @@ package asm
 
 import (
 	"fmt"
+	"math/big"
 )
 
 func init() {
@@ -12,14 +13,18 @@ func init() {
 		Eval: dif,
 		Desc: `Returns the difference of all arguments. All arguments must be
 numbers. If any of the arguments are not a number an error is
-raised.`,
+raised.
+Arbitrary precision numbers such as those from a parser with a
+NumConv are subtracted exactly and the result is a json.Number.`,
 	})
 	Define(&Fn{
 		Name: "-",
 		Eval: dif,
 		Desc: `Returns the difference of all arguments. All arguments must be
 numbers. If any of the arguments are not a number an error is
-raised.`,
+raised.
+Arbitrary precision numbers such as those from a parser with a
+NumConv are subtracted exactly and the result is a json.Number.`,
 	})
 }
 
@@ -27,8 +32,12 @@ func dif(root map[string]any, at any, args ...any) any {
 	var idif int64
 	var fdif float64
 	isFloat := false
-	for i, arg := range args {
-		switch v := evalArg(root, at, arg).(type) {
+	vals, hasBig := evalArgs(root, at, args)
+	if hasBig {
+		return bigReduce("dif", vals, (*big.Rat).Sub)
+	}
+	for i, v := range vals {
+		switch v := v.(type) {
 		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
 			ii, _ := asInt(v)
 			switch {
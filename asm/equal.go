@@ -5,6 +5,8 @@ package asm
 
 import (
 	"time"
+
+	"github.com/ohler55/ojg/gen"
 )
 
 func init() {
@@ -42,6 +44,8 @@ func equal(root map[string]any, at any, args ...any) any {
 }
 
 func equalVals(v0, v1 any) (eq bool) {
+	v0 = orderedAsMap(v0)
+	v1 = orderedAsMap(v1)
 	switch t0 := v0.(type) {
 	case nil:
 		eq = nil == v1
@@ -90,6 +94,19 @@ func equalVals(v0, v1 any) (eq bool) {
 	return
 }
 
+// orderedAsMap returns a map for a *gen.Ordered so that objects compare
+// equal regardless of member order.
+func orderedAsMap(v any) any {
+	if o, ok := v.(*gen.Ordered); ok {
+		m := make(map[string]any, len(o.Members))
+		for _, mem := range o.Members {
+			m[mem.Key] = mem.Value
+		}
+		return m
+	}
+	return v
+}
+
 func asInt(v any) (i int64, ok bool) {
 	ok = true
 	switch tv := v.(type) {
@@ -137,4 +137,21 @@ func TestEqualFloatOthers(t *testing.T) {
 	tt.Nil(t, err)
 
 	tt.Equal(t, "{float:true}", sen.String(root["asm"], &sopt))
-}
\ No newline at end of file
+}
+
+func TestEqualOrdered(t *testing.T) {
+	parser := sen.Parser{}
+	val, err := parser.Parse([]byte(`[
+           [set $.asm.a [eq {a:1 b:2} $.src]]
+           [set $.asm.b [eq {a:1 b:3} $.src]]
+           [set $.asm.c [size $.src]]
+         ]`))
+	tt.Nil(t, err)
+	p := asm.NewPlan(val.([]any))
+	parser.Ordered = true
+	src, err := parser.Parse([]byte("{b:2 a:1}"))
+	tt.Nil(t, err)
+	root := map[string]any{"src": src}
+	tt.Nil(t, p.Execute(root))
+	tt.Equal(t, "{a:true b:false c:2}", sen.String(root["asm"], &sopt))
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package asm
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// bigDigits is the number of digits after the decimal point used when a
+// result can not be represented exactly as a decimal.
+const bigDigits = 34
+
+// evalArgs evaluates all the arguments and returns the values along with an
+// indication of whether any of the values are arbitrary precision numbers.
+func evalArgs(root map[string]any, at any, args []any) (vals []any, hasBig bool) {
+	vals = make([]any, len(args))
+	for i, arg := range args {
+		vals[i] = evalArg(root, at, arg)
+		switch vals[i].(type) {
+		case json.Number, *big.Int, *big.Float:
+			hasBig = true
+		}
+	}
+	return
+}
+
+// bigReduce applies op to the values in order using exact rational
+// arithmetic and returns the result as a json.Number.
+func bigReduce(name string, vals []any, op func(z, x, y *big.Rat) *big.Rat) json.Number {
+	var result *big.Rat
+	for i, v := range vals {
+		r := asRat(v)
+		if r == nil {
+			panic(fmt.Errorf("a %T argument can not be an argument to %s", v, name))
+		}
+		if i == 0 {
+			result = r
+			continue
+		}
+		result = op(new(big.Rat), result, r)
+	}
+	if result == nil {
+		result = new(big.Rat)
+	}
+	return ratNumber(result)
+}
+
+func asRat(v any) *big.Rat {
+	switch tv := v.(type) {
+	case json.Number:
+		if r, ok := new(big.Rat).SetString(string(tv)); ok {
+			return r
+		}
+	case *big.Int:
+		if tv != nil {
+			return new(big.Rat).SetInt(tv)
+		}
+	case *big.Float:
+		if tv != nil && !tv.IsInf() {
+			r, _ := tv.Rat(nil)
+			return r
+		}
+	case float32, float64:
+		// Use the shortest decimal representation so that 0.1 is exactly
+		// one tenth.
+		f, _ := asFloat(v)
+		if r, ok := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64)); ok {
+			return r
+		}
+	default:
+		if i, ok := asInt(v); ok {
+			return new(big.Rat).SetInt64(i)
+		}
+	}
+	return nil
+}
+
+// ratNumber converts a *big.Rat to a json.Number. The result is exact if
+// the rational number can be written as a decimal, otherwise it is rounded
+// to bigDigits digits after the decimal point.
+func ratNumber(r *big.Rat) json.Number {
+	if r.IsInt() {
+		return json.Number(r.Num().String())
+	}
+	digits := 0
+	d := new(big.Int).Set(r.Denom())
+	m := new(big.Int)
+	for _, f := range []int64{2, 5} {
+		bf := big.NewInt(f)
+		cnt := 0
+		for {
+			q, rem := new(big.Int).QuoRem(d, bf, m)
+			if rem.Sign() != 0 {
+				break
+			}
+			d = q
+			cnt++
+		}
+		if digits < cnt {
+			digits = cnt
+		}
+	}
+	if d.Cmp(big.NewInt(1)) != 0 {
+		digits = bigDigits
+	}
+	s := r.FloatString(digits)
+	if strings.IndexByte(s, '.') < 0 {
+		return json.Number(s)
+	}
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		s = "0"
+	}
+	return json.Number(s)
+}
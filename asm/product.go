@@ -4,6 +4,7 @@ package asm
 
 import (
 	"fmt"
+	"math/big"
 )
 
 func init() {
@@ -12,14 +13,18 @@ func init() {
 		Eval: product,
 		Desc: `Returns the product of all arguments. All arguments must be
 numbers. If any of the arguments are not a number an error is
-raised.`,
+raised.
+Arbitrary precision numbers such as those from a parser with a
+NumConv are multiplied exactly and the result is a json.Number.`,
 	})
 	Define(&Fn{
 		Name: "*",
 		Eval: product,
 		Desc: `Returns the product of all arguments. All arguments must be
 numbers. If any of the arguments are not a number an error is
-raised.`,
+raised.
+Arbitrary precision numbers such as those from a parser with a
+NumConv are multiplied exactly and the result is a json.Number.`,
 	})
 }
 
@@ -27,8 +32,12 @@ func product(root map[string]any, at any, args ...any) any {
 	var ip int64
 	var fp float64
 	isFloat := false
-	for i, arg := range args {
-		switch v := evalArg(root, at, arg).(type) {
+	vals, hasBig := evalArgs(root, at, args)
+	if hasBig {
+		return bigReduce("product", vals, (*big.Rat).Mul)
+	}
+	for i, v := range vals {
+		switch v := v.(type) {
 		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
 			ii, _ := asInt(v)
 			switch {
@@ -5,6 +5,7 @@ package asm
 
 import (
 	"fmt"
+	"math/big"
 )
 
 func init() {
@@ -14,7 +15,9 @@ func init() {
 		Desc: `Returns the quotient of all arguments. All arguments must be
 numbers. If any of the arguments are not a number an error is
 raised. If an attempt is made to divide by zero and error will
-be raised.`,
+be raised.
+Arbitrary precision numbers such as those from a parser with a
+NumConv are divided exactly and the result is a json.Number.`,
 	})
 	Define(&Fn{
 		Name: "/",
@@ -22,7 +25,9 @@ be raised.`,
 		Desc: `Returns the quotient of all arguments. All arguments must be
 numbers. If any of the arguments are not a number an error is
 raised. If an attempt is made to divide by zero and error will
-be raised.`,
+be raised.
+Arbitrary precision numbers such as those from a parser with a
+NumConv are divided exactly and the result is a json.Number.`,
 	})
 }
 
@@ -30,8 +35,17 @@ func quotient(root map[string]any, at any, args ...any) any {
 	var iq int64
 	var fq float64
 	isFloat := false
-	for i, arg := range args {
-		switch v := evalArg(root, at, arg).(type) {
+	vals, hasBig := evalArgs(root, at, args)
+	if hasBig {
+		return bigReduce("quotient", vals, func(z, x, y *big.Rat) *big.Rat {
+			if y.Sign() == 0 {
+				panic(fmt.Errorf("divide by zero"))
+			}
+			return z.Quo(x, y)
+		})
+	}
+	for i, v := range vals {
+		switch v := v.(type) {
 		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
 			ii, _ := asInt(v)
 			switch {
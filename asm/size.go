@@ -4,6 +4,8 @@ package asm
 
 import (
 	"fmt"
+
+	"github.com/ohler55/ojg/gen"
 )
 
 func init() {
@@ -27,6 +29,8 @@ func size(root map[string]any, at any, args ...any) any {
 		length = len(tv)
 	case map[string]any:
 		length = len(tv)
+	case *gen.Ordered:
+		length = len(tv.Members)
 	}
 	return length
 }
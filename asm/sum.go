@@ -4,6 +4,7 @@ package asm
 
 import (
 	"fmt"
+	"math/big"
 )
 
 func init() {
@@ -13,7 +14,9 @@ func init() {
 		Desc: `Returns the sum of all arguments. All arguments must be numbers
 or strings. If any argument is a string then the result will be
 a string otherwise the result will be a number. If any of the
-arguments are not a number or a string an error is raised.`,
+arguments are not a number or a string an error is raised.
+Arbitrary precision numbers such as those from a parser with a
+NumConv are added exactly and the result is a json.Number.`,
 	})
 	Define(&Fn{
 		Name: "+",
@@ -21,7 +24,9 @@ arguments are not a number or a string an error is raised.`,
 		Desc: `Returns the sum of all arguments. All arguments must be numbers
 or strings. If any argument is a string then the result will be
 a string otherwise the result will be a number. If any of the
-arguments are not a number or a string an error is raised.`,
+arguments are not a number or a string an error is raised.
+Arbitrary precision numbers such as those from a parser with a
+NumConv are added exactly and the result is a json.Number.`,
 	})
 }
 
@@ -36,8 +41,12 @@ func sum(root map[string]any, at any, args ...any) any {
 	var ssum string
 	var isum int64
 	var fsum float64
-	for i, arg := range args {
-		switch v := evalArg(root, at, arg).(type) {
+	vals, hasBig := evalArgs(root, at, args)
+	if hasBig {
+		return bigReduce("sum", vals, (*big.Rat).Add)
+	}
+	for i, v := range vals {
+		switch v := v.(type) {
 		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
 			ii, _ := asInt(v)
 			if i == 0 {
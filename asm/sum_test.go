@@ -3,6 +3,8 @@
 package asm_test
 
 import (
+	"encoding/json"
+	"math/big"
 	"testing"
 
 	"github.com/ohler55/ojg/asm"
@@ -46,3 +48,32 @@ func TestSumArgType(t *testing.T) {
 	err := p.Execute(map[string]any{})
 	tt.NotNil(t, err)
 }
+
+func TestSumBigNumbers(t *testing.T) {
+	p := asm.NewPlan([]any{
+		[]any{"set", "$.asm.a", []any{"sum", json.Number("0.1"), json.Number("0.2")}},
+		[]any{"set", "$.asm.b", []any{"sum", json.Number("19.99"), 0.01, 2}},
+		[]any{"set", "$.asm.c", []any{"-", big.NewInt(10), json.Number("0.25")}},
+		[]any{"set", "$.asm.d", []any{"*", json.Number("19.99"), 3}},
+		[]any{"set", "$.asm.e", []any{"/", json.Number("1"), 8}},
+		[]any{"set", "$.asm.f", []any{"/", json.Number("1"), 3}},
+		[]any{"set", "$.asm.g", []any{"sum", json.Number("12345678901234567890"), json.Number("1")}},
+	})
+	root := map[string]any{}
+	err := p.Execute(root)
+	tt.Nil(t, err)
+	tt.Equal(t, `{
+  a: 0.3
+  b: 22
+  c: 9.75
+  d: 59.97
+  e: 0.125
+  f: 0.3333333333333333333333333333333333
+  g: 12345678901234567891
+}`, sen.String(root["asm"], &sen.Options{Sort: true, Indent: 2}))
+
+	p = asm.NewPlan([]any{[]any{"/", json.Number("1"), 0}})
+	tt.NotNil(t, p.Execute(map[string]any{}))
+	p = asm.NewPlan([]any{[]any{"sum", json.Number("1"), "x"}})
+	tt.NotNil(t, p.Execute(map[string]any{}))
+}
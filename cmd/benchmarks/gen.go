@@ -33,6 +33,17 @@ func genParseReuse(b *testing.B) {
 	}
 }
 
+func genParseNoDups(b *testing.B) {
+	sample, _ := ioutil.ReadFile(filename)
+	b.ResetTimer()
+	p := &gen.Parser{NoDuplicates: true}
+	for n := 0; n < b.N; n++ {
+		if _, err := p.Parse(sample); err != nil {
+			panic(err)
+		}
+	}
+}
+
 func genParseReader(b *testing.B) {
 	var p gen.Parser
 	f, err := os.Open(filename)
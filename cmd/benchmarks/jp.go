@@ -3,6 +3,9 @@
 package main
 
 import (
+	"io"
+	"log"
+	"os"
 	"testing"
 
 	"github.com/ohler55/ojg/jp"
@@ -25,3 +28,32 @@ func jpFirst(b *testing.B) {
 		_ = p.First(data)
 	}
 }
+
+func jpTokenReaderLoad(b *testing.B) {
+	tokenReaderLoad(b, jp.NewTokenReader)
+}
+
+func jpSENTokenReaderLoad(b *testing.B) {
+	tokenReaderLoad(b, jp.NewSENTokenReader)
+}
+
+func tokenReaderLoad(b *testing.B, newReader func(r io.Reader) *jp.TokenReader) {
+	f, err := os.Open(filename)
+	if err != nil {
+		log.Fatalf("Failed to read %s. %s\n", filename, err)
+	}
+	defer func() { _ = f.Close() }()
+	tr := newReader(f)
+	for n := 0; n < b.N; n++ {
+		_, _ = f.Seek(0, 0)
+		tr.Reset(f)
+		for {
+			if _, err = tr.Next(); err != nil {
+				if err == io.EOF {
+					break
+				}
+				panic(err)
+			}
+		}
+	}
+}
@@ -97,17 +97,27 @@ func main() {
 		{pkg: "sen", name: "Parse", fun: senParse},
 		{pkg: "sen-reuse", name: "Parse", fun: senParseReuse},
 	})
+	benchSuite("Parse string/[]byte with duplicate key checks", []*bench{
+		{pkg: "oj", name: "Parse", fun: ojParse},
+		{pkg: "oj-nodups", name: "Parse", fun: ojParseNoDups},
+		{pkg: "gen", name: "Parse", fun: genParse},
+		{pkg: "gen-nodups", name: "Parse", fun: genParseNoDups},
+		{pkg: "sen", name: "Parse", fun: senParse},
+		{pkg: "sen-nodups", name: "Parse", fun: senParseNoDups},
+	})
 
 	if *useCat {
 		benchSuite("Unmarshal []byte to type", []*bench{
 			{pkg: "json", name: "Unmarshal", fun: goUnmarshalCatalog},
 			{pkg: "oj", name: "Unmarshal", fun: ojUnmarshalCatalog},
+			{pkg: "oj", name: "Decoder", fun: ojDecodeCatalog},
 			{pkg: "sen", name: "Unmarshal", fun: senUnmarshalCatalog},
 		})
 	} else {
 		benchSuite("Unmarshal []byte to type", []*bench{
 			{pkg: "json", name: "Unmarshal", fun: goUnmarshalPatient},
 			{pkg: "oj", name: "Unmarshal", fun: ojUnmarshalPatient},
+			{pkg: "oj", name: "Decoder", fun: ojDecodePatient},
 			{pkg: "sen", name: "Unmarshal", fun: senUnmarshalPatient},
 		})
 	}
@@ -126,6 +136,8 @@ func main() {
 		{pkg: "sen-reuse", name: "ParseReader", fun: senParseReaderReuse},
 		{pkg: "oj", name: "TokenizeLoad", fun: ojTokenizeLoad},
 		{pkg: "sen", name: "TokenizeLoad", fun: senTokenizeLoad},
+		{pkg: "jp", name: "TokenReader", fun: jpTokenReaderLoad},
+		{pkg: "jp-sen", name: "TokenReader", fun: jpSENTokenReaderLoad},
 	})
 	benchSuite("Parse chan any", []*bench{
 		{pkg: "json", name: "Parse-chan", fun: goParseChan},
@@ -137,6 +149,7 @@ func main() {
 	benchSuite("Validate string/[]byte", []*bench{
 		{pkg: "json", name: "Valid", fun: goValidate},
 		{pkg: "oj", name: "Valdate", fun: ojValidate},
+		{pkg: "oj-nodups", name: "Valdate", fun: ojValidateNoDups},
 	})
 
 	benchSuite("Validate io.Reader", []*bench{
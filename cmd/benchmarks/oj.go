@@ -37,6 +37,17 @@ func ojParseReuse(b *testing.B) {
 	}
 }
 
+func ojParseNoDups(b *testing.B) {
+	sample, _ := ioutil.ReadFile(filename)
+	b.ResetTimer()
+	p := &oj.Parser{NoDuplicates: true}
+	for n := 0; n < b.N; n++ {
+		if _, err := p.Parse(sample); err != nil {
+			panic(err)
+		}
+	}
+}
+
 func ojParseReader(b *testing.B) {
 	var p oj.Parser
 	f, err := os.Open(filename)
@@ -181,6 +192,17 @@ func ojValidate(b *testing.B) {
 	}
 }
 
+func ojValidateNoDups(b *testing.B) {
+	sample, _ := ioutil.ReadFile(filename)
+	b.ResetTimer()
+	v := oj.Validator{NoDuplicates: true}
+	for n := 0; n < b.N; n++ {
+		if err := v.Validate(sample); err != nil {
+			panic(err)
+		}
+	}
+}
+
 func ojValidateReader(b *testing.B) {
 	var v oj.Validator
 	f, err := os.Open(filename)
@@ -233,4 +255,28 @@ func ojWriteIndent(b *testing.B) {
 	for n := 0; n < b.N; n++ {
 		wr.MustWrite(w, data)
 	}
-}
\ No newline at end of file
+}
+
+func ojDecodePatient(b *testing.B) {
+	sample, _ := ioutil.ReadFile(patFilename)
+	var d oj.Decoder
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		var out Patient
+		if err := d.Unmarshal(sample, &out); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func ojDecodeCatalog(b *testing.B) {
+	sample, _ := ioutil.ReadFile(catFilename)
+	var d oj.Decoder
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		var out Catalog
+		if err := d.Unmarshal(sample, &out); err != nil {
+			panic(err)
+		}
+	}
+}
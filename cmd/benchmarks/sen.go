@@ -48,6 +48,23 @@ func senParseReuse(b *testing.B) {
 	}
 }
 
+func senParseNoDups(b *testing.B) {
+	j, _ := ioutil.ReadFile(filename)
+	var sample []byte
+	if data, err := (&oj.Parser{}).Parse(j); err == nil {
+		sample = []byte(sen.String(data, &sen.Options{Indent: 2}))
+	} else {
+		panic(err)
+	}
+	b.ResetTimer()
+	p := &sen.Parser{NoDuplicates: true}
+	for n := 0; n < b.N; n++ {
+		if _, err := p.Parse(sample); err != nil {
+			panic(err)
+		}
+	}
+}
+
 func senTokenize(b *testing.B) {
 	sample, _ := ioutil.ReadFile(filename)
 	b.ResetTimer()
@@ -0,0 +1,167 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/sen"
+)
+
+// editFile reads the documents in a file, modifies them, and writes them
+// back to the file. The file is parsed as JSON if possible and as SEN
+// otherwise and is written in the same format with the indentation
+// detected in the original unless the -sen option is set. A file with a
+// single document that is only changed by deletes and sets is edited with
+// editDocument so that the text that is not changed is kept.
+func editFile(path string) (err error) {
+	var buf []byte
+	if buf, err = os.ReadFile(path); err != nil {
+		return
+	}
+	var docs []any
+	cb := func(v any) bool {
+		docs = append(docs, v)
+		return false
+	}
+	readSEN := false
+	if lazy || mongo {
+		p := sen.Parser{Ordered: true}
+		if mongo {
+			p.AddMongoFuncs()
+		}
+		_, err = p.Parse(buf, cb)
+		readSEN = true
+	} else if _, err = (&oj.Parser{Ordered: true}).Parse(buf, cb); err != nil {
+		docs = docs[:0]
+		if _, err2 := (&sen.Parser{Ordered: true}).Parse(buf, cb); err2 == nil {
+			err = nil
+			readSEN = true
+		}
+	}
+	if err != nil {
+		return
+	}
+	isSEN := senOut || readSEN
+	o := ojg.Options{
+		HTMLUnsafe: !safe,
+		TimeFormat: time.RFC3339Nano,
+		Sort:       sortKeys,
+	}
+	o.Indent, o.Tab = detectIndent(buf)
+	if len(docs) == 1 && isSEN == readSEN && !mongo && !sortKeys && !omit && conv == nil &&
+		len(patchFile) == 0 && len(mergeFile) == 0 {
+		// JSON values are also valid SEN so values are written as JSON
+		// unless -sen is set. That keeps JSONC files, which are read as
+		// SEN because of their comments, valid.
+		var out []byte
+		if out, err = editDocument(buf, !senOut, &o); err != nil {
+			return
+		}
+		return replaceFile(path, out)
+	}
+	var out []byte
+	for i, v := range docs {
+		if conv != nil {
+			v = conv.Convert(v)
+		}
+		v = modify(v)
+		if omit {
+			v = alt.Alter(v, &ojg.Options{OmitNil: true, OmitEmpty: true})
+		}
+		if 0 < i {
+			out = append(out, '\n')
+		}
+		if isSEN {
+			out = append(out, sen.String(v, &o)...)
+		} else {
+			out = append(out, oj.JSON(v, &o)...)
+		}
+	}
+	if 0 < len(buf) && buf[len(buf)-1] == '\n' {
+		out = append(out, '\n')
+	}
+	return replaceFile(path, out)
+}
+
+// editDocument applies the deletes and then the sets to the text of a single
+// document. Only the text of the values that are removed or set is changed
+// so comments and formatting elsewhere are kept byte for byte.
+func editDocument(buf []byte, asJSON bool, o *ojg.Options) ([]byte, error) {
+	doc, err := sen.ParseDocument(buf)
+	if err != nil {
+		return nil, err
+	}
+	doc.JSON = asJSON
+	doc.Options = *o
+	for _, x := range dels {
+		if err = x.RemoveDocument(doc); err != nil {
+			return nil, err
+		}
+	}
+	for _, sp := range sets {
+		x := sp.x
+		if sp.ptr != nil {
+			x = sp.ptr.Resolve(doc.Data())
+		}
+		if err = x.SetDocument(doc, alt.Dup(sp.value)); err != nil {
+			return nil, err
+		}
+	}
+	return doc.Bytes(), nil
+}
+
+// detectIndent returns the indentation of the first indented line or zero
+// if no lines are indented. If the line is indented with a tab then tab is
+// returned as true.
+func detectIndent(buf []byte) (indent int, tab bool) {
+	for _, line := range bytes.Split(buf, []byte{'\n'})[1:] {
+		trimmed := bytes.TrimLeft(line, " \t")
+		if len(trimmed) == 0 || len(trimmed) == len(line) {
+			continue
+		}
+		if line[0] == '\t' {
+			return 1, true
+		}
+		return len(line) - len(trimmed), false
+	}
+	return 0, false
+}
+
+// replaceFile writes the content to a temporary file in the same directory
+// and then renames it to the path so that the file is either completely
+// replaced or left unchanged.
+func replaceFile(path string, content []byte) (err error) {
+	var fi os.FileInfo
+	if fi, err = os.Stat(path); err != nil {
+		return
+	}
+	var f *os.File
+	if f, err = os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*"); err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(f.Name())
+		}
+	}()
+	if _, err = f.Write(content); err == nil {
+		err = f.Sync()
+	}
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), fi.Mode().Perm())
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	return
+}
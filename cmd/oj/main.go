@@ -5,6 +5,7 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -41,9 +42,10 @@ var (
 
 	// If true wrap extracts with an array.
 	wrapExtract = false
-	extracts    = []jp.Expr{}
+	extracts    = []extractor{}
 	matches     = []*jp.Script{}
 	dels        = []jp.Expr{}
+	sets        = []*setPair{}
 	planDef     = ""
 	showVersion bool
 	plan        *asm.Plan
@@ -57,9 +59,18 @@ var (
 	html        = false
 	convName    = ""
 	confFile    = ""
-
-	conv    *alt.Converter
-	options *ojg.Options
+	discover    = ""
+	mergeFile   = ""
+	patchFile   = ""
+	inPlace     = false
+	outFormat   = ""
+	inFormat    = ""
+	columns     = []jp.Expr{}
+
+	conv       *alt.Converter
+	options    *ojg.Options
+	mergePatch any
+	jsonPatch  any
 )
 
 func init() {
@@ -72,9 +83,16 @@ func init() {
 	flag.BoolVar(&lazy, "z", lazy, "lazy mode accepts Simple Encoding Notation (quotes and commas mostly optional)")
 	flag.BoolVar(&senOut, "sen", senOut, "output in Simple Encoding Notation")
 	flag.BoolVar(&tab, "t", tab, "indent with tabs")
-	flag.Var(&exValue{}, "x", "extract path")
+	flag.Var(&exValue{}, "x", "extract path or JSON Pointer if starting with a /")
 	flag.Var(&matchValue{}, "m", "match equation/script")
 	flag.Var(&delValue{}, "d", "delete path")
+	flag.Var(&setValue{}, "set", "set the value at a path as <path>=<value> with the value in SEN format")
+	flag.StringVar(&mergeFile, "merge", mergeFile, "apply the JSON Merge Patch (RFC 7396) in the named file")
+	flag.StringVar(&patchFile, "patch", patchFile, "apply the JSON Patch (RFC 6902) in the named file")
+	flag.BoolVar(&inPlace, "inplace", inPlace, "write the result back to each input file in the format detected")
+	flag.StringVar(&outFormat, "format", outFormat, "output format of json, sen, json5, ndjson, csv, or tsv")
+	flag.Var(&colValue{}, "col", "path to a column value for csv or tsv output, may be repeated")
+	flag.StringVar(&inFormat, "in", inFormat, "input format of json5, csv, tsv, or ndjson instead of JSON or SEN")
 	flag.BoolVar(&showVersion, "version", showVersion, "display version and exit")
 	flag.StringVar(&planDef, "a", planDef, "assembly plan or plan file using @<plan>")
 	flag.BoolVar(&showRoot, "r", showRoot, "print root if an assemble plan provided")
@@ -87,6 +105,10 @@ func init() {
 	flag.BoolVar(&showFilterDocs, "help-filter", showFilterDocs, "describe filter operators like [?(@.x == 3)]")
 	flag.BoolVar(&showConf, "help-config", showConf, "describe .oj-config.sen format")
 	flag.BoolVar(&mongo, "mongo", mongo, "parse mongo Javascript output")
+	flag.StringVar(&discover, "discover", discover, `find documents embedded in other text such as log files. Supported values are:
+  any - discover any value including strings, numbers, booleans, and null
+  sets - discover only objects and arrays
+`)
 	flag.StringVar(&convName, "conv", convName, `apply converter before writing. Supported values are:
   nano - converts integers over 946684800000000000 (2000-01-01) to time
   rcf3339 - converts string in RFC3339 or RFC3339Nano to time
@@ -115,6 +137,18 @@ the wrap option (-w).
 
   oj -x abc.def myfile.json "@.x[?(@.y > 1)]"
 
+The -x option also accepts a JSON Pointer (RFC 6901) such as /abc/def/0.
+
+When reading from stdin with a single extraction path and no other
+processing options the input is streamed and matches are written as soon as
+they are read so large documents are never loaded in full. Paths with a
+descent (..), a union of more than one key or index, a slice with a
+negative step, or a filter that refers to the root ($) are not streamed so
+that matches are the same and written in the same order as when reading a
+file.
+
+  cat huge.json | oj -x '$.records[*].id'
+
 To filter JSON documents the match option (-m) is used. If a JSON document
 matches at least one match option the JSON will be written. In addition to the
 -m option an argument starting with a '(' is assumed to be a match script that
@@ -132,14 +166,65 @@ then used as the input.
 Elements can be deleted from the JSON using the -d option. Multiple
 occurrences of -d are supported.
 
+Values can be set with the -set option which takes a path or JSON Pointer
+and a value in SEN format separated by an =. Missing objects and arrays on
+the path are created. Multiple occurrences of -set are supported.
+
+  oj -set 'server.port=8080' -set '/tags/0="blue"' myfile.json
+
+A JSON Merge Patch (RFC 7396) read from a file can be applied to each
+document with the -merge option and a JSON Patch (RFC 6902) with the -patch
+option. Deletions are made first, then values are set, then the JSON Patch
+and merge patch are applied.
+
+  oj -merge patch.json myfile.json
+
+The -inplace option writes the result back to each file instead of to
+stdout. The file is replaced atomically and keeps its member order, its
+indentation, and its format of either JSON or SEN. When a file holds a
+single document and only -d and -set are used the text that is not changed,
+including comments, is kept as it was. The -w option wraps extracts and is
+not used for writing back since that was its original use.
+
+  oj -inplace -set 'version=2' -d '$.debug' config.json
+
+The -format option selects json5, ndjson, csv, or tsv output in place of JSON
+or SEN. JSON5 output leaves keys that are identifiers unquoted. NDJSON is one
+compact document per line with each element of an array on its own line. CSV
+and TSV write a header and then a row for each element of an array of objects.
+Nested values are flattened with dotted keys such as address.city and
+items.0.name. Columns are the union of the keys in all the rows, in the order
+first seen, unless chosen by one or more -col paths. Without -col paths no
+rows are written until all the input has been read.
+
+  oj -format csv -col name -col address.city people.json
+
+The -in option reads csv, tsv, or ndjson input into an array that is then
+processed like any other document. The first CSV or TSV row is a header and
+dotted header names form nested objects. JSON5 input is read strictly as JSON5
+with each document processed as it would be for JSON.
+
+  oj -in csv -format ndjson people.csv
+  oj -in json5 -format json config.json5
+
 Oj can also be used to assemble new JSON output from input data. An assembly
 plan that describes how to assemble the new JSON if specified by the -a
 option. The -fn option will display the documentation for assembly.
 
+Documents embedded in other text such as log files can be extracted with the
+-discover option. Text that is not part of a document is skipped.
+
+  echo 'INFO {"a":1} done' | oj -discover sets
+  => {"a":1}
+
 Pretty mode output can be used with JSON or the -sen option. It indents
 according to a defined width and maximum depth in a best effort approach. The
 -p takes a pattern of <width>.<max-depth>.<align> where width and max-depth
-are integers and align is a boolean.
+are integers and align is a boolean. When aligned, arrays of arrays or objects
+and objects of arrays or objects are written as tables and the values of
+object members start in the same column.
+
+  oj -p 80.3.true myfile.json
 
 `, filepath.Base(os.Args[0]))
 		flag.PrintDefaults()
@@ -165,6 +250,8 @@ are integers and align is a boolean.
 	extracts = extracts[:0]
 	matches = matches[:0]
 	dels = dels[:0]
+	sets = sets[:0]
+	columns = columns[:0]
 	if err := run(); err != nil {
 		fmt.Fprintf(os.Stderr, "*-*-* %s\n", err)
 		os.Exit(1)
@@ -286,11 +373,62 @@ func run() (err error) {
 		}
 		plan = asm.NewPlan(plist)
 	}
+	if 0 < len(mergeFile) {
+		var f *os.File
+		if f, err = os.Open(mergeFile); err != nil {
+			panic(err)
+		}
+		mergePatch, err = sen.ParseReader(f)
+		_ = f.Close()
+		if err != nil {
+			panic(err)
+		}
+	}
+	if 0 < len(patchFile) {
+		var f *os.File
+		if f, err = os.Open(patchFile); err != nil {
+			panic(err)
+		}
+		jsonPatch, err = sen.ParseReader(f)
+		_ = f.Close()
+		if err != nil {
+			panic(err)
+		}
+	}
+	if inPlace {
+		if len(files) == 0 {
+			panic(fmt.Errorf("-inplace requires at least one file"))
+		}
+		if 0 < len(extracts) || 0 < len(matches) || plan != nil || 0 < len(input) || 0 < len(discover) {
+			panic(fmt.Errorf("-inplace can not be used with extractions, matches, plans, discovery, or argument input"))
+		}
+		for _, file := range files {
+			if err = editFile(file); err != nil {
+				panic(err)
+			}
+		}
+		return
+	}
+	args := []any{write}
+	switch strings.ToLower(discover) {
+	case "":
+		// not discovering
+	case "any":
+		args = append(args, ojg.DiscoverAny)
+	case "sets":
+		args = append(args, ojg.DiscoverSets)
+	default:
+		panic(fmt.Errorf("%s is not a valid discover option", discover))
+	}
 	if 0 < len(files) {
 		var f *os.File
 		for _, file := range files {
 			if f, err = os.Open(file); err == nil {
-				_, err = p.ParseReader(f, write)
+				if 0 < len(inFormat) {
+					err = readInput(p, f)
+				} else {
+					_, err = p.ParseReader(f, args...)
+				}
 				_ = f.Close()
 			}
 			if err != nil {
@@ -299,12 +437,20 @@ func run() (err error) {
 		}
 	}
 	if 0 < len(input) {
-		if _, err = p.Parse(input, write); err != nil {
+		if _, err = p.Parse(input, args...); err != nil {
 			panic(err)
 		}
 	}
 	if len(files) == 0 && len(input) == 0 {
-		if _, err = p.ParseReader(os.Stdin, write); err != nil {
+		switch {
+		case 0 < len(inFormat):
+			err = readInput(p, os.Stdin)
+		case streamable():
+			err = stream(os.Stdin)
+		default:
+			_, err = p.ParseReader(os.Stdin, args...)
+		}
+		if err != nil {
 			panic(err)
 		}
 	}
@@ -314,9 +460,107 @@ func run() (err error) {
 		delete(root, "asm")
 		write(root)
 	}
+	flushTable()
+
+	return
+}
+
+// readInput reads all the CSV, TSV, or NDJSON input into an array and
+// writes the array. JSON5 documents are written one at a time.
+func readInput(p oj.SimpleParser, r io.Reader) (err error) {
+	var list []any
+	switch strings.ToLower(inFormat) {
+	case "json5":
+		_, err = (&sen.JSON5Parser{}).ParseReader(r, write)
+		return
+	case "csv":
+		list, err = readTable(r, ',')
+	case "tsv":
+		list, err = readTable(r, '\t')
+	case "ndjson":
+		if _, ok := p.(*oj.Parser); ok {
+			// Maps can not be reused since all the documents are kept.
+			p = &oj.Parser{}
+		}
+		list, err = readLines(p, r)
+	default:
+		return fmt.Errorf("%s is not a valid input format", inFormat)
+	}
+	if err == nil {
+		write(list)
+	}
 	return
 }
 
+// streamable returns true if the extraction can be made without loading
+// the whole document and the matches are streamed in the same order as
+// Expr.Get returns them. A stream reports matches in document order with
+// nested matches first so descents, unions of more than one key or index,
+// and slices with a negative step are not streamed. Neither are filters
+// that refer to the root since the root has not been read yet.
+func streamable() bool {
+	if len(extracts) != 1 || wrapExtract || 0 < len(matches) || 0 < len(dels) ||
+		0 < len(sets) || conv != nil || plan != nil || 0 < len(discover) ||
+		0 < len(mergeFile) || 0 < len(patchFile) {
+		return false
+	}
+	x, ok := extracts[0].(jp.Expr)
+	if !ok {
+		return false
+	}
+	for _, f := range x {
+		switch tf := f.(type) {
+		case jp.Descent:
+			return false
+		case jp.Union:
+			if 1 < len(tf) {
+				return false
+			}
+		case jp.Slice:
+			if 2 < len(tf) && tf[2] < 0 {
+				return false
+			}
+		case *jp.Filter:
+			if refersToRoot(tf.Inspect()) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// refersToRoot returns true if a filter form has a $ operand, including an
+// operand of a filter nested in one of the form's paths.
+func refersToRoot(v any) bool {
+	switch tv := v.(type) {
+	case *jp.Form:
+		return refersToRoot(tv.Left) || refersToRoot(tv.Right)
+	case jp.Expr:
+		for _, f := range tv {
+			switch tf := f.(type) {
+			case jp.Root:
+				return true
+			case *jp.Filter:
+				if refersToRoot(tf.Inspect()) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func stream(r io.Reader) error {
+	cb := func(_ jp.Expr, v any) {
+		writeValue(v)
+	}
+	x := extracts[0].(jp.Expr)
+	if lazy {
+		return x.StreamSEN(r, cb)
+	}
+	return x.Stream(r, cb)
+}
+
 func write(v any) bool {
 	if conv != nil {
 		v = conv.Convert(v)
@@ -333,9 +577,7 @@ func write(v any) bool {
 			return false
 		}
 	}
-	for _, x := range dels {
-		_ = x.Del(v)
-	}
+	v = modify(v)
 	switch {
 	case 0 < len(extracts):
 		if wrapExtract {
@@ -343,24 +585,16 @@ func write(v any) bool {
 			for _, x := range extracts {
 				w = append(w, x.Get(v)...)
 			}
-			if senOut {
-				writeSEN(w)
-			} else {
-				writeJSON(w)
-			}
+			writeValue(w)
 		} else {
 			for _, x := range extracts {
 				for _, v2 := range x.Get(v) {
-					if senOut {
-						writeSEN(v2)
-					} else {
-						writeJSON(v2)
-					}
+					writeValue(v2)
 				}
 			}
 		}
 	case senOut:
-		writeSEN(v)
+		writeValue(v)
 	default:
 		if plan != nil {
 			root["src"] = v
@@ -371,11 +605,46 @@ func write(v any) bool {
 				v = root["asm"]
 			}
 		}
-		writeJSON(v)
+		writeValue(v)
 	}
 	return false
 }
 
+// modify applies the deletes, sets, and patches to a value.
+func modify(v any) any {
+	for _, x := range dels {
+		_ = x.Del(v)
+	}
+	for _, sp := range sets {
+		if sp.ptr != nil {
+			if err := sp.ptr.Set(v, alt.Dup(sp.value)); err != nil {
+				panic(err)
+			}
+			continue
+		}
+		if len(sp.x) == 1 {
+			switch sp.x[0].(type) {
+			case jp.Root, jp.At:
+				v = alt.Dup(sp.value)
+				continue
+			}
+		}
+		if err := sp.x.Set(v, alt.Dup(sp.value)); err != nil {
+			panic(err)
+		}
+	}
+	if 0 < len(patchFile) {
+		var err error
+		if v, err = alt.Patch(v, jsonPatch); err != nil {
+			panic(err)
+		}
+	}
+	if 0 < len(mergeFile) {
+		v = alt.MergePatch(v, mergePatch)
+	}
+	return v
+}
+
 func writeJSON(v any) {
 	if options == nil {
 		o := ojg.Options{}
@@ -488,6 +757,23 @@ func parsePrettyOpt() {
 	}
 }
 
+// extractor is a JSONPath or a JSON Pointer given as an extraction.
+type extractor interface {
+	Get(data any) []any
+}
+
+// pointerExtractor extracts the value a JSON Pointer refers to. The pointer
+// is resolved against each document so a token such as 0 is an object key
+// or an array index depending on the data.
+type pointerExtractor jp.Pointer
+
+func (pe pointerExtractor) Get(data any) []any {
+	if v, has := jp.Pointer(pe).Get(data); has {
+		return []any{v}
+	}
+	return nil
+}
+
 type exValue struct {
 }
 
@@ -496,6 +782,13 @@ func (xv exValue) String() string {
 }
 
 func (xv exValue) Set(s string) error {
+	if strings.HasPrefix(s, "/") {
+		p, err := jp.ParsePointer(s)
+		if err == nil {
+			extracts = append(extracts, pointerExtractor(p))
+		}
+		return err
+	}
 	x, err := jp.ParseString(s)
 	if err == nil {
 		extracts = append(extracts, x)
@@ -533,6 +826,69 @@ func (dv delValue) Set(s string) error {
 	return err
 }
 
+type setPair struct {
+	x     jp.Expr
+	ptr   jp.Pointer // resolved against each document if not nil
+	value any
+}
+
+type setValue struct {
+}
+
+func (sv setValue) String() string {
+	return ""
+}
+
+func (sv setValue) Set(s string) error {
+	i := setSplit(s)
+	if i < 0 {
+		return fmt.Errorf("a set must be of the form <path>=<value>")
+	}
+	var sp setPair
+	var err error
+	if strings.HasPrefix(s, "/") {
+		if sp.ptr, err = jp.ParsePointer(s[:i]); err != nil {
+			return err
+		}
+	} else if sp.x, err = jp.ParseString(s[:i]); err != nil {
+		return err
+	}
+	if sp.value, err = sen.Parse([]byte(s[i+1:])); err != nil {
+		return err
+	}
+	sets = append(sets, &sp)
+
+	return nil
+}
+
+// setSplit returns the index of the = that separates the path from the
+// value or -1 if there is none. An = in a filter, a bracket, or a
+// comparison such as == is not a separator.
+func setSplit(s string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+		switch {
+		case quote != 0:
+			if b == '\\' {
+				i++
+			} else if b == quote {
+				quote = 0
+			}
+		case b == '\'' || b == '"':
+			quote = b
+		case b == '[' || b == '(':
+			depth++
+		case b == ']' || b == ')':
+			depth--
+		case b == '=' && depth == 0:
+			return i
+		}
+	}
+	return -1
+}
+
 func loadConfig() {
 	var conf any
 	if 0 < len(confFile) {
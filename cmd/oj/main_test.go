@@ -0,0 +1,117 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ohler55/ojg/tt"
+)
+
+// TestMain runs the oj command instead of the tests when the test binary is
+// started by ojRun.
+func TestMain(m *testing.M) {
+	if os.Getenv("OJ_TEST_MAIN") == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// ojRun runs the oj command with the args and stdin and returns the output.
+func ojRun(t *testing.T, stdin string, args ...string) string {
+	cmd := exec.Command(os.Args[0], append([]string{"-f", "-"}, args...)...)
+	cmd.Env = append(os.Environ(), "OJ_TEST_MAIN=1")
+	cmd.Stdin = strings.NewReader(stdin)
+	out, err := cmd.Output()
+	tt.Nil(t, err, "oj ", args)
+
+	return string(out)
+}
+
+func TestExtractFileAndStdin(t *testing.T) {
+	src := `{"key":"b","list":[{"id":"a","n":1},{"id":"b","n":2}],"n":1}`
+	file := filepath.Join(t.TempDir(), "src.json")
+	tt.Nil(t, os.WriteFile(file, []byte(src), 0600))
+
+	for _, d := range []struct {
+		path   string
+		expect string
+	}{
+		{path: "$.list[*].id", expect: "\"a\"\n\"b\"\n"},
+		{path: "$.list[?(@.id == $.key)].n", expect: "2\n"},
+		{path: "$.list[?(@.n == $.n)].id", expect: "\"a\"\n"},
+		{path: "$.list[?(@.id == 'a' || $.n == 2)].id", expect: "\"a\"\n"},
+		{path: "$.list[?(length(@.id) == $.n)].n", expect: "1\n2\n"},
+	} {
+		tt.Equal(t, d.expect, ojRun(t, "", "-x", d.path, file), d.path)
+		tt.Equal(t, d.expect, ojRun(t, src, "-x", d.path), d.path)
+	}
+}
+
+func TestPointerKeys(t *testing.T) {
+	src := `{"a":{"0":"x","1":"y"},"b":["p","q"]}`
+	for _, d := range []struct {
+		args   []string
+		expect string
+	}{
+		{args: []string{"-x", "/a/0"}, expect: "\"x\"\n"},
+		{args: []string{"-x", "/b/1"}, expect: "\"q\"\n"},
+		{args: []string{"-x", "/a/0", "-x", "/b/0"}, expect: "\"x\"\n\"p\"\n"},
+		{args: []string{"-x", "/a/2"}, expect: ""},
+		{args: []string{"-set", "/a/1=z", "-x", "/a"}, expect: "{\"0\":\"x\",\"1\":\"z\"}\n"},
+		{args: []string{"-set", "/b/0=z", "-x", "/b"}, expect: "[\"z\",\"q\"]\n"},
+	} {
+		tt.Equal(t, d.expect, ojRun(t, src, append([]string{"-i", "0", "-s"}, d.args...)...), d.args)
+	}
+}
+
+func TestInPlaceDocument(t *testing.T) {
+	dir := t.TempDir()
+	for i, d := range []struct {
+		src    string
+		args   []string
+		expect string
+	}{
+		{
+			src: `{
+  // server settings
+  "host":   "localhost", /* keep */
+  "port": 80,
+  "debug": true,
+  "tags": ["a",  "b"]
+}
+`,
+			args: []string{"-set", "port=8080", "-set", "/tags/1=\"c\"", "-d", "$.debug"},
+			expect: `{
+  // server settings
+  "host":   "localhost", /* keep */
+  "port": 8080,
+  "tags": ["a",  "c"]
+}
+`,
+		},
+		{
+			src:    "{a: 1 // one\n b: [x y]}",
+			args:   []string{"-sen", "-set", "b[2]=z", "-set", "c=w"},
+			expect: "{a: 1 // one\n b: [x y z] c: w}",
+		},
+		{
+			// More than one document is rewritten.
+			src:    "{\"a\": 1}\n{\"a\": 2}\n",
+			args:   []string{"-set", "a=3"},
+			expect: "{\"a\":3}\n{\"a\":3}\n",
+		},
+	} {
+		file := filepath.Join(dir, "doc.json")
+		tt.Nil(t, os.WriteFile(file, []byte(d.src), 0600), i)
+		ojRun(t, "", append(append([]string{"-inplace"}, d.args...), file)...)
+		out, err := os.ReadFile(file)
+		tt.Nil(t, err, i)
+		tt.Equal(t, d.expect, string(out), i)
+	}
+}
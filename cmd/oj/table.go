@@ -0,0 +1,345 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/sen"
+)
+
+var (
+	tableWriter *csv.Writer
+	tableCols   []string
+	tableSeen   = map[string]bool{}
+	tableRows   []map[string]any
+)
+
+// writeValue writes a value in the output format selected.
+func writeValue(v any) {
+	switch strings.ToLower(outFormat) {
+	case "", "json":
+		if senOut {
+			writeSEN(v)
+		} else {
+			writeJSON(v)
+		}
+	case "sen":
+		writeSEN(v)
+	case "json5":
+		writeJSON5(v)
+	case "ndjson":
+		writeNDJSON(v)
+	case "csv":
+		writeTable(v, ',')
+	case "tsv":
+		writeTable(v, '\t')
+	default:
+		panic(fmt.Errorf("%s is not a valid output format", outFormat))
+	}
+}
+
+// writeJSON5 writes the value as JSON5. Colors and pretty options do not
+// apply.
+func writeJSON5(v any) {
+	o := ojg.Options{Indent: indent, Tab: tab, TimeFormat: time.RFC3339Nano, Sort: sortKeys}
+	if omit {
+		v = alt.Alter(v, &ojg.Options{OmitNil: true, OmitEmpty: true})
+	}
+	_ = sen.WriteJSON5(os.Stdout, v, &o)
+	_, _ = os.Stdout.Write([]byte{'\n'})
+}
+
+// writeNDJSON writes each element of an array or any other value as
+// compact JSON on a line by itself.
+func writeNDJSON(v any) {
+	o := ojg.Options{HTMLUnsafe: !safe, TimeFormat: time.RFC3339Nano, Sort: sortKeys}
+	list, ok := v.([]any)
+	if !ok {
+		list = []any{v}
+	}
+	for _, m := range list {
+		_ = oj.Write(os.Stdout, m, &o)
+		_, _ = os.Stdout.Write([]byte{'\n'})
+	}
+}
+
+// writeTable writes each element of an array or any other value as a
+// row. If -col paths are given they are the columns and rows are written
+// immediately. Otherwise the columns are the union of the flattened keys of
+// all the rows so the rows are collected and written by flushTable.
+func writeTable(v any, comma rune) {
+	list, ok := v.([]any)
+	if !ok {
+		list = []any{v}
+	}
+	if tableWriter == nil {
+		tableWriter = csv.NewWriter(os.Stdout)
+		tableWriter.Comma = comma
+		if 0 < len(columns) {
+			for _, x := range columns {
+				tableCols = append(tableCols, strings.TrimPrefix(x.String(), "$."))
+			}
+			_ = tableWriter.Write(tableCols)
+		}
+	}
+	if len(columns) == 0 {
+		for _, row := range list {
+			for _, k := range flatKeys(row, "") {
+				if !tableSeen[k] {
+					tableSeen[k] = true
+					tableCols = append(tableCols, k)
+				}
+			}
+			flat := map[string]any{}
+			flatten(flat, row, "")
+			tableRows = append(tableRows, flat)
+		}
+		return
+	}
+	record := make([]string, len(tableCols))
+	for _, row := range list {
+		for i, x := range columns {
+			record[i] = cellString(x.First(row))
+		}
+		_ = tableWriter.Write(record)
+	}
+	tableWriter.Flush()
+}
+
+// flushTable writes the header and the rows collected by writeTable when
+// no -col paths were given.
+func flushTable() {
+	if tableWriter == nil || 0 < len(columns) {
+		return
+	}
+	_ = tableWriter.Write(tableCols)
+	record := make([]string, len(tableCols))
+	for _, flat := range tableRows {
+		for i, k := range tableCols {
+			record[i] = cellString(flat[k])
+		}
+		_ = tableWriter.Write(record)
+	}
+	tableWriter.Flush()
+}
+
+// flatKeys returns the dotted keys of the leaf values in v in sorted order
+// or in member order for ordered objects.
+func flatKeys(v any, prefix string) (keys []string) {
+	switch tv := v.(type) {
+	case map[string]any:
+		names := make([]string, 0, len(tv))
+		for k := range tv {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			keys = append(keys, flatKeys(tv[k], joinKey(prefix, k))...)
+		}
+	case *gen.Ordered:
+		for _, m := range tv.Members {
+			keys = append(keys, flatKeys(m.Value, joinKey(prefix, m.Key))...)
+		}
+	case []any:
+		for i, m := range tv {
+			keys = append(keys, flatKeys(m, joinKey(prefix, strconv.Itoa(i)))...)
+		}
+	default:
+		if 0 < len(prefix) {
+			keys = append(keys, prefix)
+		}
+	}
+	return
+}
+
+// flatten sets the leaf values in v in flat using dotted keys.
+func flatten(flat map[string]any, v any, prefix string) {
+	switch tv := v.(type) {
+	case map[string]any:
+		for k, m := range tv {
+			flatten(flat, m, joinKey(prefix, k))
+		}
+	case *gen.Ordered:
+		for _, m := range tv.Members {
+			flatten(flat, m.Value, joinKey(prefix, m.Key))
+		}
+	case []any:
+		for i, m := range tv {
+			flatten(flat, m, joinKey(prefix, strconv.Itoa(i)))
+		}
+	default:
+		flat[prefix] = v
+	}
+}
+
+func joinKey(prefix, key string) string {
+	if len(prefix) == 0 {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func cellString(v any) string {
+	switch tv := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return tv
+	case time.Time:
+		return tv.Format(time.RFC3339Nano)
+	case map[string]any, *gen.Ordered, []any:
+		return oj.JSON(v, &ojg.Options{Sort: true, HTMLUnsafe: true})
+	}
+	return oj.JSON(v, &ojg.Options{HTMLUnsafe: true})
+}
+
+// readTable reads CSV or TSV with a header row and returns an array of
+// objects, one for each row. Dotted header names form nested objects or
+// arrays if all the keys are indexes, empty cells are left out, and cells
+// that are numbers, booleans, or null are converted.
+func readTable(r io.Reader, comma rune) (list []any, err error) {
+	cr := csv.NewReader(r)
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+	var header []string
+	if header, err = cr.Read(); err != nil {
+		if err == io.EOF {
+			err = nil
+		}
+		return
+	}
+	list = []any{}
+	for {
+		var record []string
+		if record, err = cr.Read(); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return
+		}
+		row := map[string]any{}
+		for i, cell := range record {
+			if len(header) <= i || len(cell) == 0 {
+				continue
+			}
+			setDotted(row, header[i], cellValue(cell))
+		}
+		list = append(list, unflattenArrays(row))
+	}
+}
+
+// setDotted sets a value in an object using a dotted key to create nested
+// objects.
+func setDotted(obj map[string]any, key string, value any) {
+	parts := strings.Split(key, ".")
+	for _, k := range parts[:len(parts)-1] {
+		child, ok := obj[k].(map[string]any)
+		if !ok {
+			child = map[string]any{}
+			obj[k] = child
+		}
+		obj = child
+	}
+	obj[parts[len(parts)-1]] = value
+}
+
+// unflattenArrays replaces objects with keys of 0 through n-1 with arrays.
+func unflattenArrays(v any) any {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+	for k, m := range obj {
+		obj[k] = unflattenArrays(m)
+	}
+	list := make([]any, len(obj))
+	for k, m := range obj {
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 0 || len(list) <= i || strconv.Itoa(i) != k {
+			return obj
+		}
+		list[i] = m
+	}
+	if len(list) == 0 {
+		return obj
+	}
+	return list
+}
+
+// cellValue converts cells that are booleans, null, or numbers. Numbers
+// are only converted if the value would be written as the same text so
+// cells such as 007 or 0.10 remain strings.
+func cellValue(cell string) any {
+	switch cell {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	if isNumber(cell) {
+		if v, err := oj.Parse([]byte(cell)); err == nil && oj.JSON(v) == cell {
+			return v
+		}
+	}
+	return cell
+}
+
+// isNumber returns true if the text has only the characters of a JSON
+// number and does not have a leading zero.
+func isNumber(s string) bool {
+	if strings.HasPrefix(s, "-") {
+		s = s[1:]
+	}
+	if len(s) == 0 || s[0] < '0' || '9' < s[0] || (s[0] == '0' && 1 < len(s) && s[1] != '.') {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch b := s[i]; {
+		case '0' <= b && b <= '9', b == '.', b == 'e', b == 'E', b == '+', b == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// readLines reads each document from the reader and returns them in an
+// array.
+func readLines(p oj.SimpleParser, r io.Reader) (list []any, err error) {
+	list = []any{}
+	_, err = p.ParseReader(r, func(v any) bool {
+		list = append(list, v)
+		return false
+	})
+	return
+}
+
+type colValue struct {
+}
+
+func (cv colValue) String() string {
+	return ""
+}
+
+func (cv colValue) Set(s string) error {
+	x, err := jp.ParseString(s)
+	if err == nil {
+		columns = append(columns, x)
+	}
+	return err
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package ojg
+
+import "fmt"
+
+const (
+	// CycleIgnore indicates cycles are not checked for. A pointer, map, or
+	// slice that contains itself will recurse until the stack overflows.
+	CycleIgnore = iota
+	// CycleFail indicates a cycle should stop encoding with a *CycleError.
+	CycleFail
+	// CycleNull indicates the value that would start a cycle should be
+	// replaced with a null.
+	CycleNull
+	// CycleRef indicates the value that would start a cycle should be
+	// replaced with a reference object such as {"$ref": "$.a.b"} where the
+	// value of the reference is the JSONPath to the value referred to.
+	CycleRef
+)
+
+// DefaultRefKey is the key used for reference objects when the RefKey option
+// is empty.
+const DefaultRefKey = "$ref"
+
+// CycleError is the error reported when a cycle is encountered and the
+// OnCycle option is CycleFail.
+type CycleError struct {
+	// Path is the JSONPath to where the cycle was encountered.
+	Path string
+
+	// Ref is the JSONPath to the value that the Path location refers back to.
+	Ref string
+}
+
+// Error returns a string representation of the error.
+func (err *CycleError) Error() string {
+	return fmt.Sprintf("cycle at %s refers back to %s", err.Path, err.Ref)
+}
@@ -0,0 +1,299 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package ojg
+
+import (
+	"errors"
+	"io"
+)
+
+const discoverReadSize = 4096
+
+// DiscoverMaxLen is the maximum length of a candidate document when
+// discovering documents read from an io.Reader. The input is scanned with a
+// buffer that only grows to hold the candidate being scanned so longer
+// candidates are skipped to keep the buffer bounded.
+var DiscoverMaxLen = 1 << 20
+
+// Discover is an option for the oj, sen, and gen parsers. When provided as an
+// argument to Parse or ParseReader the parser searches for documents
+// embedded in other text, such as log lines, instead of expecting the input
+// to be only JSON or SEN. Invalid candidates are skipped and the search
+// resumes after the start of the failed candidate so errors are never
+// returned for the content itself.
+type Discover byte
+
+const (
+	// DiscoverOff turns off discovery. This is the default.
+	DiscoverOff = Discover('o')
+	// DiscoverAny discovers any value including strings, numbers, booleans,
+	// and null as well as objects and arrays.
+	DiscoverAny = Discover('a')
+	// DiscoverSets discovers only objects and arrays.
+	DiscoverSets = Discover('s')
+)
+
+// Find the next candidate document in buf at or after off. The start and
+// end of the candidate are returned. If there are no more candidates then
+// start and end are -1. If sen is true single quoted strings are also
+// recognized. Candidates are not validated and are expected to be parsed
+// by the caller which should then continue searching from either end if
+// valid or start + 1 if not.
+func (d Discover) Find(buf []byte, off int, sen bool) (start, end int) {
+	return d.find(buf, off, sen, true)
+}
+
+// find is Find for a buffer that might not hold all of the input. If eof is
+// false and a candidate might continue past the end of the buffer then the
+// start of the candidate is returned with an end of -1.
+func (d Discover) find(buf []byte, off int, sen, eof bool) (start, end int) {
+	if d != DiscoverAny && d != DiscoverSets {
+		return -1, -1
+	}
+	for start = off; start < len(buf); start++ {
+		switch b := buf[start]; b {
+		case '{', '[':
+			if end = discoverClose(buf, start, sen); 0 < end || !eof {
+				return
+			}
+		case '"', '\'':
+			if d == DiscoverAny && (b == '"' || sen) {
+				if end = discoverQuote(buf, start+1, b); 0 < end || !eof {
+					return
+				}
+			}
+		case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+			if d == DiscoverAny && (start == 0 || !discoverWordByte(buf[start-1]) && buf[start-1] != '.') {
+				if !eof && discoverNumberToEnd(buf, start) {
+					return start, -1
+				}
+				if end = discoverNumber(buf, start); 0 < end && (len(buf) <= end || !discoverWordByte(buf[end])) {
+					return
+				}
+			}
+		case 't', 'f', 'n':
+			if d == DiscoverAny && (start == 0 || !discoverWordByte(buf[start-1])) {
+				for _, word := range []string{"true", "false", "null"} {
+					end = start + len(word)
+					if !eof && len(buf) <= end && word[:len(buf)-start] == string(buf[start:]) {
+						return start, -1
+					}
+					if end <= len(buf) && string(buf[start:end]) == word && (len(buf) <= end || !discoverWordByte(buf[end])) {
+						return
+					}
+				}
+			}
+		}
+	}
+	return -1, -1
+}
+
+// Each calls found with each candidate document in buf and the position of
+// the candidate in buf. If found returns valid as true the search continues
+// after the candidate otherwise the search continues after the start of the
+// candidate. The search ends when found returns stop as true.
+func (d Discover) Each(buf []byte, sen bool, found func(candidate []byte, pos Position) (valid, stop bool)) {
+	dp := discoverPos{line: 1, nl: -1}
+	for start, end := d.Find(buf, 0, sen); 0 <= start; {
+		valid, stop := found(buf[start:end], dp.at(buf, 0, start))
+		switch {
+		case stop:
+			return
+		case valid:
+			start, end = d.Find(buf, end, sen)
+		default:
+			start, end = d.Find(buf, start+1, sen)
+		}
+	}
+}
+
+// Read is the same as Each except that the candidates are read from r. The
+// input is not read all at once. Only the candidate being scanned is kept
+// in a buffer that grows to no more than about twice DiscoverMaxLen. If max
+// is greater than zero a *LimitError is returned once more than max bytes
+// have been read.
+func (d Discover) Read(r io.Reader, sen bool, max int, found func(candidate []byte, pos Position) (valid, stop bool)) error {
+	dp := discoverPos{line: 1, nl: -1}
+	buf := make([]byte, 0, discoverReadSize)
+	var base int // offset of buf in the input
+	var off int  // where to continue searching in buf
+	var eof bool
+	for {
+		start, end := d.find(buf, off, sen, eof)
+		switch {
+		case 0 <= end:
+			valid, stop := found(buf[start:end], dp.at(buf, base, start))
+			switch {
+			case stop:
+				return nil
+			case valid:
+				off = end
+			default:
+				off = start + 1
+			}
+			continue
+		case start < 0:
+			if eof {
+				return nil
+			}
+			off = len(buf)
+		case DiscoverMaxLen < len(buf)-start:
+			off = start + 1
+			continue
+		default:
+			off = start
+		}
+		// Drop what has been searched except for the byte before off which
+		// is needed to check word boundaries.
+		if keep := off - 1; 0 < keep {
+			dp.count(buf, base, keep)
+			base += keep
+			buf = buf[:copy(buf, buf[keep:])]
+			off -= keep
+		}
+		if free := cap(buf) - len(buf); free < discoverReadSize || free < len(buf) {
+			nb := make([]byte, len(buf), 2*len(buf)+discoverReadSize)
+			copy(nb, buf)
+			buf = nb
+		}
+		cnt, err := r.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+cnt]
+		if 0 < max && max < base+len(buf) {
+			pos := dp.at(buf, base, max-base)
+			return &LimitError{
+				Limit:  BytesLimit,
+				Max:    max,
+				Line:   pos.Line,
+				Column: pos.Column,
+			}
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				return err
+			}
+			eof = true
+		}
+	}
+}
+
+// discoverPos tracks the line and the offset of the last newline while
+// discovering so that positions do not have to be found by scanning from
+// the start of the input.
+type discoverPos struct {
+	line    int
+	nl      int // offset of the last newline in the input
+	counted int // offset in the input that newlines have been counted to
+}
+
+// at returns the position of buf[i] where buf starts at base in the input.
+func (dp *discoverPos) at(buf []byte, base, i int) Position {
+	dp.count(buf, base, i)
+	return Position{Offset: base + i, Line: dp.line, Column: base + i - dp.nl}
+}
+
+// count the newlines before buf[i] that have not already been counted.
+func (dp *discoverPos) count(buf []byte, base, i int) {
+	for j := dp.counted - base; j < i; j++ {
+		if buf[j] == '\n' {
+			dp.line++
+			dp.nl = base + j
+		}
+	}
+	if dp.counted < base+i {
+		dp.counted = base + i
+	}
+}
+
+// discoverClose returns the offset just after the close that matches the
+// open at start or -1 if there is no matching close.
+func discoverClose(buf []byte, start int, sen bool) int {
+	depth := 0
+	for i := start; i < len(buf); i++ {
+		switch b := buf[i]; b {
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		case '"', '\'':
+			if b == '"' || sen {
+				end := discoverQuote(buf, i+1, b)
+				if end < 0 {
+					return -1
+				}
+				i = end - 1
+			}
+		}
+	}
+	return -1
+}
+
+// discoverQuote returns the offset just after the closing quote or -1 if
+// the string is not terminated.
+func discoverQuote(buf []byte, off int, quote byte) int {
+	for i := off; i < len(buf); i++ {
+		switch buf[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// discoverNumber returns the offset just after a number that follows the
+// JSON number syntax or -1 if not a number.
+func discoverNumber(buf []byte, start int) int {
+	digits := func(i int) int {
+		for ; i < len(buf) && '0' <= buf[i] && buf[i] <= '9'; i++ {
+		}
+		return i
+	}
+	i := start
+	if buf[i] == '-' {
+		i++
+	}
+	end := digits(i)
+	if end == i {
+		return -1
+	}
+	if i+1 < end && buf[i] == '0' {
+		return -1
+	}
+	if end+1 < len(buf) && buf[end] == '.' {
+		if e := digits(end + 1); end+1 < e {
+			end = e
+		}
+	}
+	if end+1 < len(buf) && (buf[end] == 'e' || buf[end] == 'E') {
+		i = end + 1
+		if buf[i] == '+' || buf[i] == '-' {
+			i++
+		}
+		if e := digits(i); i < e {
+			end = e
+		}
+	}
+	return end
+}
+
+// discoverNumberToEnd returns true if all the bytes from start to the end
+// of buf could be part of a number.
+func discoverNumberToEnd(buf []byte, start int) bool {
+	for _, b := range buf[start:] {
+		switch b {
+		case '-', '+', '.', 'e', 'E', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// discoverWordByte returns true if the byte can be part of a word.
+func discoverWordByte(b byte) bool {
+	return 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9' || b == '_' || 0x80 <= b
+}
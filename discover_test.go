@@ -0,0 +1,119 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package ojg_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/tt"
+)
+
+func discoverAll(d ojg.Discover, src string, sen bool) (found []string) {
+	buf := []byte(src)
+	for start, end := d.Find(buf, 0, sen); 0 <= start; start, end = d.Find(buf, end, sen) {
+		found = append(found, src[start:end])
+	}
+	return
+}
+
+func TestDiscoverFind(t *testing.T) {
+	for i, d := range []struct {
+		mode   ojg.Discover
+		src    string
+		sen    bool
+		expect []string
+	}{
+		{mode: ojg.DiscoverSets, src: `INFO {"a":[1,2]} and [3] done`, expect: []string{`{"a":[1,2]}`, `[3]`}},
+		{mode: ojg.DiscoverSets, src: `{"a":"}"} [unclosed`, expect: []string{`{"a":"}"}`}},
+		{mode: ojg.DiscoverSets, src: `x {'a':'}'}`, sen: true, expect: []string{`{'a':'}'}`}},
+		{mode: ojg.DiscoverSets, src: `it's {'a':1}`, sen: false, expect: []string{`{'a':1}`}},
+		{mode: ojg.DiscoverSets, src: `"quoted" 12 true`, expect: nil},
+		{mode: ojg.DiscoverAny, src: `x=12 y=-1.5e3 at 10. "s\"q" true nullify v1.2 null`,
+			expect: []string{"12", "-1.5e3", "10", `"s\"q"`, "true", "null"}},
+		{mode: ojg.DiscoverAny, src: `'a' [1]`, sen: true, expect: []string{"'a'", "[1]"}},
+		{mode: ojg.DiscoverAny, src: `012 0.x`, expect: []string{"0"}},
+		{mode: ojg.DiscoverOff, src: `{"a":1}`, expect: nil},
+	} {
+		tt.Equal(t, d.expect, discoverAll(d.mode, d.src, d.sen), i, ": ", d.src)
+	}
+}
+
+type discovered struct {
+	candidate string
+	pos       ojg.Position
+}
+
+func discoverEach(d ojg.Discover, src string, sen bool) (found []discovered) {
+	d.Each([]byte(src), sen, func(candidate []byte, pos ojg.Position) (bool, bool) {
+		found = append(found, discovered{candidate: string(candidate), pos: pos})
+		return candidate[0] != '[', false
+	})
+	return
+}
+
+func discoverRead(d ojg.Discover, r io.Reader, sen bool, max int) (found []discovered, err error) {
+	err = d.Read(r, sen, max, func(candidate []byte, pos ojg.Position) (bool, bool) {
+		found = append(found, discovered{candidate: string(candidate), pos: pos})
+		return candidate[0] != '[', false
+	})
+	return
+}
+
+func TestDiscoverEach(t *testing.T) {
+	found := discoverEach(ojg.DiscoverAny, "a {\"b\":1}\n[x [2]] 3", false)
+	tt.Equal(t, []discovered{
+		{candidate: `{"b":1}`, pos: ojg.Position{Offset: 2, Line: 1, Column: 3}},
+		{candidate: `[x [2]]`, pos: ojg.Position{Offset: 10, Line: 2, Column: 1}},
+		{candidate: `[2]`, pos: ojg.Position{Offset: 13, Line: 2, Column: 4}},
+		{candidate: `2`, pos: ojg.Position{Offset: 14, Line: 2, Column: 5}},
+		{candidate: `3`, pos: ojg.Position{Offset: 18, Line: 2, Column: 9}},
+	}, found)
+
+	var cnt int
+	ojg.DiscoverSets.Each([]byte(`[1] [2]`), false, func(candidate []byte, pos ojg.Position) (bool, bool) {
+		cnt++
+		return true, true
+	})
+	tt.Equal(t, 1, cnt)
+}
+
+func TestDiscoverRead(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 500; i++ {
+		fmt.Fprintf(&b, "line %d {\"a\":[%d,true,\"x\"]} 'q' fals null%d\n[%d [nested]] -1.5e%d\n", i, i, i, i, i%3)
+	}
+	src := b.String()
+	for _, mode := range []ojg.Discover{ojg.DiscoverAny, ojg.DiscoverSets} {
+		for _, sen := range []bool{false, true} {
+			expect := discoverEach(mode, src, sen)
+			found, err := discoverRead(mode, strings.NewReader(src), sen, 0)
+			tt.Nil(t, err)
+			tt.Equal(t, expect, found)
+			found, err = discoverRead(mode, iotest.OneByteReader(strings.NewReader(src)), sen, 0)
+			tt.Nil(t, err)
+			tt.Equal(t, expect, found)
+		}
+	}
+	_, err := discoverRead(ojg.DiscoverSets, strings.NewReader(src), false, 100)
+	var le *ojg.LimitError
+	tt.Equal(t, true, errors.As(err, &le))
+	tt.Equal(t, ojg.Position{Line: 3, Column: 40}, ojg.Position{Line: le.Line, Column: le.Column})
+
+	// Candidates longer than DiscoverMaxLen that are not complete in the
+	// buffer are skipped.
+	orig := ojg.DiscoverMaxLen
+	defer func() { ojg.DiscoverMaxLen = orig }()
+	ojg.DiscoverMaxLen = 10
+	found, err := discoverRead(ojg.DiscoverSets, iotest.OneByteReader(strings.NewReader(`[1,2,3,4,5,6,7,8] {"a":1}`)), false, 0)
+	tt.Nil(t, err)
+	tt.Equal(t, []discovered{{candidate: `{"a":1}`, pos: ojg.Position{Offset: 18, Line: 1, Column: 19}}}, found)
+
+	_, err = discoverRead(ojg.DiscoverSets, iotest.ErrReader(io.ErrUnexpectedEOF), false, 0)
+	tt.NotNil(t, err)
+}
@@ -73,6 +73,12 @@ The alt package contains functions and types for altering values. It includes fu
 The asm package provides a means of building JSON or the corresponding simple
 types based on a JSON script represented by the Plan type.
 
+# Schema
+
+The schema package compiles JSON Schema draft 2020-12 documents and validates
+simple data and gen.Node data against them. Each failure identifies the
+instance and schema locations with a JSONPath.
+
 # Cmd oj
 
 The oj command is a general purpose tool for processing JSON
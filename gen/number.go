@@ -5,7 +5,9 @@ package gen
 import (
 	"encoding/json"
 	"math"
+	"math/big"
 	"strconv"
+	"strings"
 )
 
 // BigLimit is the limit before a number is converted into a Big
@@ -22,6 +24,13 @@ type Number struct {
 	NegExp     bool
 	BigBuf     []byte
 	ForceFloat bool
+
+	// Conv if not nil is called by AsNum() with the text of the number
+	// and the result is returned instead of an int64, float64, or
+	// json.Number.
+	Conv func(text string) any
+
+	text []byte
 }
 
 // Reset the number.
@@ -58,7 +67,7 @@ func (n *Number) AddFrac(b byte) {
 	switch {
 	case 0 < len(n.BigBuf):
 		n.BigBuf = append(n.BigBuf, b)
-	case n.Frac <= BigLimit:
+	case n.Frac <= BigLimit && n.Div <= math.MaxUint64/10:
 		n.Frac = n.Frac*10 + uint64(b-'0')
 		n.Div *= 10.0
 		if math.MaxInt64 < n.Frac {
@@ -92,7 +101,7 @@ func (n *Number) FillBig() {
 		n.BigBuf = append(n.BigBuf, '-')
 	}
 	n.BigBuf = append(n.BigBuf, strconv.FormatUint(n.I, 10)...)
-	if 0 < n.Frac {
+	if 1 < n.Div {
 		n.BigBuf = append(n.BigBuf, '.')
 		if 1000000000000000000 <= n.Frac { // nearest multiple of 10 below max int64
 			n.BigBuf = append(n.BigBuf, strconv.FormatUint(n.Frac, 10)...)
@@ -112,6 +121,10 @@ func (n *Number) FillBig() {
 
 // AsNum returns the number as best fit.
 func (n *Number) AsNum() (num any) {
+	if n.Conv != nil {
+		n.text = n.AppendText(n.text[:0])
+		return n.Conv(string(n.text))
+	}
 	switch {
 	case 0 < len(n.BigBuf):
 		num = json.Number(n.BigBuf)
@@ -145,6 +158,63 @@ func (n *Number) AsNum() (num any) {
 	return
 }
 
+// AppendText appends the text of the number to a byte slice. The digits of
+// the number are preserved including trailing zeros in the fraction.
+func (n *Number) AppendText(b []byte) []byte {
+	if 0 < len(n.BigBuf) {
+		return append(b, n.BigBuf...)
+	}
+	if n.Neg {
+		b = append(b, '-')
+	}
+	b = strconv.AppendUint(b, n.I, 10)
+	if 1 < n.Div {
+		// The fraction is always less than the divisor which is a power of
+		// 10 so the leading 1 of the sum is replaced with the decimal point
+		// to keep any leading zeros of the fraction.
+		start := len(b)
+		b = strconv.AppendUint(b, n.Frac+n.Div, 10)
+		b[start] = '.'
+	}
+	if 0 < n.Exp {
+		b = append(b, 'e')
+		if n.NegExp {
+			b = append(b, '-')
+		}
+		b = strconv.AppendUint(b, n.Exp, 10)
+	}
+	return b
+}
+
+// JSONNumber returns the number text as a json.Number. It is intended for
+// use as a parser NumConv function to decode numbers without loss.
+func JSONNumber(text string) any {
+	return json.Number(text)
+}
+
+// BigNumber returns the number text as a *big.Int if the number is an
+// integer and as a *big.Float otherwise. The precision of a *big.Float is
+// large enough to hold all the digits of the number. Note that a big.Float
+// is binary so a decimal fraction such as 0.1 is still not exact. Use
+// JSONNumber or a decimal constructor for exact decimal fractions. It is
+// intended for use as a parser NumConv function.
+func BigNumber(text string) any {
+	if strings.IndexAny(text, ".eE") < 0 {
+		if i, ok := new(big.Int).SetString(text, 10); ok {
+			return i
+		}
+	}
+	prec := uint(len(text))*4 + 1
+	if prec < 64 {
+		prec = 64
+	}
+	f, _, err := big.ParseFloat(text, 10, prec, big.ToNearestEven)
+	if err != nil {
+		return json.Number(text)
+	}
+	return f
+}
+
 // AsNode returns the number as best fit.
 func (n *Number) AsNode() (num Node) {
 	switch {
@@ -22,6 +22,8 @@ func TestNumber(t *testing.T) {
 		{src: "12345678901234567890", value: json.Number("12345678901234567890")},
 		{src: "0.12345678901234567890", value: "0.12345678901234567890"},
 		{src: "0.9223372036854775808", value: "0.9223372036854775808"},
+		{src: "0.000000000000000000000000001", value: "0.000000000000000000000000001"},
+		{src: "1.00000000000000000000000000000000001", value: "1.00000000000000000000000000000000001"},
 	} {
 		if testing.Verbose() {
 			fmt.Printf("... %d: %s\n", i, d.src)
@@ -70,3 +72,50 @@ func TestNumberForceFloat(t *testing.T) {
 	v := n.AsNum()
 	tt.Equal(t, 123.0, v)
 }
+
+func TestNumberConv(t *testing.T) {
+	for i, d := range []struct {
+		src    string
+		conv   func(string) any
+		expect string
+	}{
+		{src: "123", conv: gen.JSONNumber, expect: "json.Number 123"},
+		{src: "-1.25e-1", conv: gen.JSONNumber, expect: "json.Number -1.25e-1"},
+		{src: "0.10", conv: gen.JSONNumber, expect: "json.Number 0.10"},
+		{src: "12345678901234567890123", conv: gen.BigNumber, expect: "*big.Int 12345678901234567890123"},
+		{src: "-5", conv: gen.BigNumber, expect: "*big.Int -5"},
+		{src: "1.1", conv: gen.BigNumber, expect: "*big.Float 1.1"},
+		{src: "12.5e2", conv: gen.BigNumber, expect: "*big.Float 1250"},
+	} {
+		var n gen.Number
+		n.Reset()
+		n.Conv = d.conv
+		frac := false
+		exp := false
+		for _, b := range []byte(d.src) {
+			switch b {
+			case '.':
+				frac = true
+			case '-':
+				if exp {
+					n.NegExp = true
+				} else {
+					n.Neg = true
+				}
+			case 'e':
+				exp = true
+			default:
+				switch {
+				case exp:
+					n.AddExp(b)
+				case frac:
+					n.AddFrac(b)
+				default:
+					n.AddDigit(b)
+				}
+			}
+		}
+		v := n.AsNum()
+		tt.Equal(t, d.expect, fmt.Sprintf("%T %v", v, v), i, ": ", d.src)
+	}
+}
@@ -0,0 +1,259 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package gen
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Member of an Ordered object.
+type Member struct {
+	Key   string
+	Value any
+}
+
+// Ordered is an object that keeps its members in the order they were
+// added. Parsers produce an *Ordered in place of a map[string]any or an
+// Object when their Ordered option is set. The member values are Nodes when
+// produced by the gen.Parser or alt.Generify() and simple values when
+// produced by the oj and sen parsers or by Simplify(). Keys are looked up
+// with a linear search so very large objects are better held in a map.
+type Ordered struct {
+	Members []Member
+}
+
+// Get the value of a member and a flag indicating the member was found.
+func (n *Ordered) Get(key string) (any, bool) {
+	for _, m := range n.Members {
+		if m.Key == key {
+			return m.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Set the value of a member. If the member does not exist it is added to
+// the end of the members.
+func (n *Ordered) Set(key string, value any) {
+	for i, m := range n.Members {
+		if m.Key == key {
+			n.Members[i].Value = value
+			return
+		}
+	}
+	n.Members = append(n.Members, Member{Key: key, Value: value})
+}
+
+// orderedIndexMin is the number of members an Ordered must have before an
+// OrderedIndex builds a map of the keys. Below that a linear search is
+// faster than maintaining the map.
+const orderedIndexMin = 16
+
+// OrderedIndex is a key index used to build an Ordered without a linear
+// search of the members for each member added. Parsers keep one for each
+// open Ordered. The index is only valid while members are added to the
+// Ordered through the index.
+type OrderedIndex struct {
+	keys map[string]int
+}
+
+// Has returns true if the Ordered has a member with the key.
+func (x *OrderedIndex) Has(n *Ordered, key string) bool {
+	if x.build(n) {
+		_, has := x.keys[key]
+		return has
+	}
+	_, has := n.Get(key)
+	return has
+}
+
+// Set the value of a member of the Ordered. If the member does not exist it
+// is added to the end of the members.
+func (x *OrderedIndex) Set(n *Ordered, key string, value any) {
+	if !x.build(n) {
+		n.Set(key, value)
+		return
+	}
+	if i, has := x.keys[key]; has {
+		n.Members[i].Value = value
+		return
+	}
+	x.keys[key] = len(n.Members)
+	n.Members = append(n.Members, Member{Key: key, Value: value})
+}
+
+// build the key map once the Ordered is large enough and return true if
+// the map should be used.
+func (x *OrderedIndex) build(n *Ordered) bool {
+	if x.keys != nil {
+		return true
+	}
+	if len(n.Members) < orderedIndexMin {
+		return false
+	}
+	x.keys = make(map[string]int, len(n.Members)*2)
+	for i, m := range n.Members {
+		x.keys[m.Key] = i
+	}
+	return true
+}
+
+// Remove a member and return the value removed along with a flag indicating
+// the member was found.
+func (n *Ordered) Remove(key string) (any, bool) {
+	for i, m := range n.Members {
+		if m.Key == key {
+			n.Members = append(n.Members[:i], n.Members[i+1:]...)
+			return m.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Keys returns the member keys in order.
+func (n *Ordered) Keys() []string {
+	keys := make([]string, len(n.Members))
+	for i, m := range n.Members {
+		keys[i] = m.Key
+	}
+	return keys
+}
+
+// Len returns the number of members.
+func (n *Ordered) Len() int {
+	return len(n.Members)
+}
+
+// Sorted returns the members sorted by key.
+func (n *Ordered) Sorted() []Member {
+	members := append([]Member{}, n.Members...)
+	sort.Slice(members, func(i, j int) bool { return members[i].Key < members[j].Key })
+	return members
+}
+
+// String returns a string representation of the Node.
+func (n *Ordered) String() string {
+	b := []byte{'{'}
+	members := n.Members
+	if Sort {
+		members = n.Sorted()
+	}
+	for i, m := range members {
+		if 0 < i {
+			b = append(b, ',')
+		}
+		b = append(b, '"')
+		b = append(b, m.Key...)
+		b = append(b, '"')
+		b = append(b, ':')
+		b = appendValueString(b, m.Value)
+	}
+	b = append(b, '}')
+
+	return string(b)
+}
+
+// Alter the member values into simple types in place and return the
+// Ordered.
+func (n *Ordered) Alter() any {
+	for i, m := range n.Members {
+		if node, ok := m.Value.(Node); ok {
+			n.Members[i].Value = node.Alter()
+		}
+	}
+	return n
+}
+
+// Simplify creates a copy of the Ordered with simple member values.
+func (n *Ordered) Simplify() any {
+	dup := Ordered{Members: make([]Member, len(n.Members))}
+	for i, m := range n.Members {
+		dup.Members[i].Key = m.Key
+		if node, ok := m.Value.(Node); ok {
+			dup.Members[i].Value = node.Simplify()
+		} else {
+			dup.Members[i].Value = dupSimple(m.Value)
+		}
+	}
+	return &dup
+}
+
+// Dup creates a deep duplicate of the Node.
+func (n *Ordered) Dup() Node {
+	dup := Ordered{Members: make([]Member, len(n.Members))}
+	for i, m := range n.Members {
+		dup.Members[i].Key = m.Key
+		if node, ok := m.Value.(Node); ok {
+			dup.Members[i].Value = node.Dup()
+		} else {
+			dup.Members[i].Value = dupSimple(m.Value)
+		}
+	}
+	return &dup
+}
+
+// Empty returns true if the Ordered has no members.
+func (n *Ordered) Empty() bool {
+	return len(n.Members) == 0
+}
+
+func dupSimple(v any) any {
+	switch tv := v.(type) {
+	case []any:
+		dup := make([]any, len(tv))
+		for i, m := range tv {
+			dup[i] = dupSimple(m)
+		}
+		return dup
+	case map[string]any:
+		dup := make(map[string]any, len(tv))
+		for k, m := range tv {
+			dup[k] = dupSimple(m)
+		}
+		return dup
+	case *Ordered:
+		return tv.Dup()
+	}
+	return v
+}
+
+func appendValueString(b []byte, v any) []byte {
+	switch tv := v.(type) {
+	case nil:
+		b = append(b, "null"...)
+	case Node:
+		b = append(b, tv.String()...)
+	case string:
+		b = strconv.AppendQuote(b, tv)
+	case []any:
+		b = append(b, '[')
+		for i, m := range tv {
+			if 0 < i {
+				b = append(b, ',')
+			}
+			b = appendValueString(b, m)
+		}
+		b = append(b, ']')
+	case map[string]any:
+		keys := make([]string, 0, len(tv))
+		for k := range tv {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b = append(b, '{')
+		for i, k := range keys {
+			if 0 < i {
+				b = append(b, ',')
+			}
+			b = strconv.AppendQuote(b, k)
+			b = append(b, ':')
+			b = appendValueString(b, tv[k])
+		}
+		b = append(b, '}')
+	default:
+		b = append(b, fmt.Sprint(tv)...)
+	}
+	return b
+}
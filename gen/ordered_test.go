@@ -0,0 +1,105 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package gen_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestOrderedGetSetRemove(t *testing.T) {
+	var o gen.Ordered
+	o.Set("b", gen.Int(1))
+	o.Set("a", gen.Int(2))
+	o.Set("c", gen.Int(3))
+	o.Set("a", gen.Int(4))
+	tt.Equal(t, []string{"b", "a", "c"}, o.Keys())
+	tt.Equal(t, 3, o.Len())
+
+	v, has := o.Get("a")
+	tt.Equal(t, true, has)
+	tt.Equal(t, gen.Int(4), v)
+	_, has = o.Get("x")
+	tt.Equal(t, false, has)
+
+	v, has = o.Remove("b")
+	tt.Equal(t, true, has)
+	tt.Equal(t, gen.Int(1), v)
+	_, has = o.Remove("b")
+	tt.Equal(t, false, has)
+	tt.Equal(t, []string{"a", "c"}, o.Keys())
+	tt.Equal(t, false, o.Empty())
+	tt.Equal(t, true, (&gen.Ordered{}).Empty())
+}
+
+func TestOrderedString(t *testing.T) {
+	o := &gen.Ordered{Members: []gen.Member{
+		{Key: "z", Value: gen.Int(1)},
+		{Key: "a", Value: gen.Array{gen.True, nil}},
+		{Key: "m", Value: []any{"x", map[string]any{"y": 2}}},
+	}}
+	orig := gen.Sort
+	defer func() { gen.Sort = orig }()
+	gen.Sort = false
+	tt.Equal(t, `{"z":1,"a":[true,null],"m":["x",{"y":2}]}`, o.String())
+	gen.Sort = true
+	tt.Equal(t, `{"a":[true,null],"m":["x",{"y":2}],"z":1}`, o.String())
+}
+
+func TestOrderedSimplifyAlterDup(t *testing.T) {
+	orig := gen.Sort
+	defer func() { gen.Sort = orig }()
+	gen.Sort = false
+	inner := &gen.Ordered{Members: []gen.Member{{Key: "y", Value: gen.String("why")}}}
+	o := &gen.Ordered{Members: []gen.Member{
+		{Key: "b", Value: gen.Int(3)},
+		{Key: "a", Value: inner},
+	}}
+	simple := o.Simplify().(*gen.Ordered)
+	tt.Equal(t, int64(3), simple.Members[0].Value)
+	tt.Equal(t, "why", simple.Members[1].Value.(*gen.Ordered).Members[0].Value)
+	tt.Equal(t, gen.Int(3), o.Members[0].Value)
+
+	dup := o.Dup().(*gen.Ordered)
+	dup.Members[1].Value.(*gen.Ordered).Set("y", gen.String("not"))
+	tt.Equal(t, `{"b":3,"a":{"y":"why"}}`, o.String())
+	tt.Equal(t, `{"b":3,"a":{"y":"not"}}`, dup.String())
+
+	altered := o.Alter().(*gen.Ordered)
+	tt.Equal(t, int64(3), altered.Members[0].Value)
+	tt.Equal(t, "why", altered.Members[1].Value.(*gen.Ordered).Members[0].Value)
+}
+
+func TestOrderedParse(t *testing.T) {
+	orig := gen.Sort
+	defer func() { gen.Sort = orig }()
+	gen.Sort = false
+	p := gen.Parser{Ordered: true}
+	v, err := p.Parse([]byte(`{"z":1,"a":{"y":[1,{"c":2,"b":3}]}}`))
+	tt.Nil(t, err)
+	o, ok := v.(*gen.Ordered)
+	tt.Equal(t, true, ok)
+	tt.Equal(t, []string{"z", "a"}, o.Keys())
+	tt.Equal(t, `{"z":1,"a":{"y":[1,{"c":2,"b":3}]}}`, o.String())
+}
+
+func TestOrderedIndex(t *testing.T) {
+	var o gen.Ordered
+	var x gen.OrderedIndex
+	for i := 0; i < 40; i++ {
+		x.Set(&o, fmt.Sprintf("k%d", i), gen.Int(i))
+	}
+	x.Set(&o, "k3", gen.Int(-3))
+	x.Set(&o, "k30", gen.Int(-30))
+	tt.Equal(t, 40, o.Len())
+	tt.Equal(t, true, x.Has(&o, "k39"))
+	tt.Equal(t, false, x.Has(&o, "k40"))
+	v, _ := o.Get("k3")
+	tt.Equal(t, gen.Int(-3), v)
+	v, _ = o.Get("k30")
+	tt.Equal(t, gen.Int(-30), v)
+	tt.Equal(t, "k39", o.Members[39].Key)
+}
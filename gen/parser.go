@@ -9,6 +9,8 @@ import (
 	"fmt"
 	"io"
 	"unicode/utf8"
+
+	"github.com/ohler55/ojg"
 )
 
 const (
@@ -26,6 +28,7 @@ type Parser struct {
 	stack      []Node
 	starts     []int
 	maps       []Object
+	index      []OrderedIndex // key indexes of the open *Ordered objects
 	cb         func(Node)
 	resultChan chan Node
 	line       int
@@ -34,9 +37,13 @@ type Parser struct {
 	mi         int
 	num        Number
 	rn         rune
+	discover   ojg.Discover
 	result     Node
 	mode       string
 	nextMode   string
+	numStart   int
+	boff       int // offset of buf in the input
+	keyPos     ojg.Position
 
 	// OnlyOne returns an error if more than one JSON is in the string or stream.
 	OnlyOne bool
@@ -44,13 +51,36 @@ type Parser struct {
 	// Reuse maps. Previously returned maps will no longer be valid or rather
 	// could be modified during parsing.
 	Reuse bool
+
+	// NoDuplicates returns an error if an object has more than one member
+	// with the same key.
+	NoDuplicates bool
+
+	// Ordered if true produces *gen.Ordered objects that keep members in the
+	// order they appear instead of maps.
+	Ordered bool
+
+	// Spans if not nil records the source span of each value and key of the
+	// most recently parsed document.
+	Spans *ojg.Spans
+
+	// Limits are resource limits for parsing untrusted input. Exceeding a
+	// limit returns an *ojg.LimitError.
+	Limits ojg.Limits
 }
 
 // Parse a JSON string in to simple types. An error is returned if not valid JSON.
+// Arguments can be a func(Node) callback, a chan Node, or an ojg.Discover
+// value. When discovering, JSON documents embedded in other text are found and
+// each is passed to the callback or chan.
 func (p *Parser) Parse(buf []byte, args ...any) (Node, error) {
+	if err := p.Limits.CheckBytes(buf); err != nil {
+		return nil, err
+	}
 	p.cb = nil
 	p.resultChan = nil
 	p.OnlyOne = true
+	p.discover = ojg.DiscoverOff
 	for _, a := range args {
 		switch ta := a.(type) {
 		case func(Node) bool:
@@ -63,6 +93,8 @@ func (p *Parser) Parse(buf []byte, args ...any) (Node, error) {
 			p.resultChan = ta
 			p.OnlyOne = false
 			p.Reuse = false
+		case ojg.Discover:
+			p.discover = ta
 		default:
 			return nil, fmt.Errorf("a %T is not a valid option type", a)
 		}
@@ -76,16 +108,23 @@ func (p *Parser) Parse(buf []byte, args ...any) (Node, error) {
 		p.stack = p.stack[:0]
 		p.tmp = p.tmp[:0]
 		p.starts = p.starts[:0]
+		p.index = p.index[:0]
 	}
 	p.result = nil
 	p.noff = -1
 	p.line = 1
 	p.mode = valueMap
 	p.mi = 0
+	p.boff = 0
+	if p.Spans != nil {
+		p.Spans.Reset()
+	}
 	var err error
-	// Skip BOM if present.
-	if 3 < len(buf) && buf[0] == 0xEF {
+	if p.discover == ojg.DiscoverAny || p.discover == ojg.DiscoverSets {
+		p.discover.Each(buf, false, p.discovered)
+	} else if 3 < len(buf) && buf[0] == 0xEF { // Skip BOM if present.
 		if buf[1] == 0xBB && buf[2] == 0xBF {
+			p.boff = 3
 			err = p.parseBuffer(buf[3:], true)
 		} else {
 			return nil, fmt.Errorf("expected BOM at 1:3")
@@ -107,6 +146,7 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data Node, err error) {
 	p.cb = nil
 	p.resultChan = nil
 	p.OnlyOne = true
+	p.discover = ojg.DiscoverOff
 	for _, a := range args {
 		switch ta := a.(type) {
 		case func(Node) bool:
@@ -119,6 +159,8 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data Node, err error) {
 			p.resultChan = ta
 			p.OnlyOne = false
 			p.Reuse = false
+		case ojg.Discover:
+			p.discover = ta
 		default:
 			return nil, fmt.Errorf("a %T is not a valid option type", a)
 		}
@@ -132,16 +174,27 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data Node, err error) {
 		p.stack = p.stack[:0]
 		p.tmp = p.tmp[:0]
 		p.starts = p.starts[:0]
+		p.index = p.index[:0]
 	}
 	p.result = nil
 	p.noff = -1
 	p.line = 1
 	p.mi = 0
+	p.boff = 0
+	if p.Spans != nil {
+		p.Spans.Reset()
+	}
+	if p.discover == ojg.DiscoverAny || p.discover == ojg.DiscoverSets {
+		err = p.discover.Read(r, false, p.Limits.MaxBytes, p.discovered)
+		data = p.result
+		return
+	}
 	buf := make([]byte, readBufSize)
 	eof := false
 	var cnt int
 	cnt, err = r.Read(buf)
 	buf = buf[:cnt]
+	total := cnt
 	p.mode = valueMap
 	if err != nil {
 		if !errors.Is(err, io.EOF) {
@@ -153,13 +206,22 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data Node, err error) {
 	// Skip BOM if present.
 	if 3 < len(buf) && buf[0] == 0xEF && buf[1] == 0xBB && buf[2] == 0xBF {
 		skip = 3
+		p.boff = 3
 	}
 	for {
+		over := 0 < p.Limits.MaxBytes && p.Limits.MaxBytes < total
+		if over {
+			buf = buf[:len(buf)-(total-p.Limits.MaxBytes)]
+			eof = false
+		}
 		if 0 < skip {
 			err = p.parseBuffer(buf[skip:], eof)
 		} else {
 			err = p.parseBuffer(buf, eof)
 		}
+		if err == nil && over {
+			err = p.limitError(len(buf)-skip, ojg.BytesLimit, p.Limits.MaxBytes)
+		}
 		if err != nil {
 			p.stack = p.stack[:cap(p.stack)]
 			for i := len(p.stack) - 1; 0 <= i; i-- {
@@ -169,6 +231,9 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data Node, err error) {
 
 			return
 		}
+		// Offsets and columns in the next buffer continue from this one.
+		p.boff += len(buf) - skip
+		p.noff -= len(buf) - skip
 		skip = 0
 		if eof {
 			break
@@ -176,6 +241,7 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data Node, err error) {
 		buf = buf[:cap(buf)]
 		cnt, err = r.Read(buf)
 		buf = buf[:cnt]
+		total += cnt
 		if err != nil {
 			if !errors.Is(err, io.EOF) {
 				return
@@ -214,6 +280,12 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 		case strOk:
 			p.tmp = append(p.tmp, b)
 		case keyQuote:
+			if 0 < p.Limits.MaxMembers && p.Limits.MaxMembers <= p.memberCount() {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
+			if p.Spans != nil {
+				p.keyPos = p.spanPos(off)
+			}
 			start := off + 1
 			if len(buf) <= start {
 				p.tmp = p.tmp[:0]
@@ -229,7 +301,14 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			off += i
 			if b == '"' {
 				off++
+				if 0 < p.Limits.MaxStringLen && p.Limits.MaxStringLen < off-start {
+					return p.limitError(off, ojg.StringLimit, p.Limits.MaxStringLen)
+				}
+				if p.NoDuplicates && p.hasKey(buf[start:off]) {
+					return p.keyError(off, string(buf[start:off]))
+				}
 				p.stack = append(p.stack, Key(buf[start:off]))
+				p.spanKey(buf[start:off], off+1)
 				p.mode = colonMap
 			} else {
 				p.tmp = p.tmp[:0]
@@ -246,6 +325,10 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			}
 			continue
 		case valQuote:
+			if 0 < p.Limits.MaxMembers && p.Limits.MaxMembers <= p.memberCount() {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
+			p.spanBegin(off)
 			start := off + 1
 			if len(buf) <= start {
 				p.tmp = p.tmp[:0]
@@ -261,7 +344,11 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			off += i
 			if b == '"' {
 				off++
+				if 0 < p.Limits.MaxStringLen && p.Limits.MaxStringLen < off-start {
+					return p.limitError(off, ojg.StringLimit, p.Limits.MaxStringLen)
+				}
 				p.add(String(buf[start:off]))
+				p.spanEnd(off + 1)
 				p.mode = afterMap
 			} else {
 				p.tmp = p.tmp[:0]
@@ -271,7 +358,11 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 				continue
 			}
 		case numComma:
+			if p.numberTooLong(off) {
+				return p.limitError(p.numStart, ojg.NumberLimit, p.Limits.MaxNumberLen)
+			}
 			p.add(p.num.AsNode())
+			p.spanEnd(off)
 			if 0 < len(p.starts) && p.starts[len(p.starts)-1] == -1 {
 				p.mode = keyMap
 			} else {
@@ -285,8 +376,21 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			p.mode = stringMap
 			continue
 		case openObject:
+			if 0 < p.Limits.MaxMembers && p.Limits.MaxMembers <= p.memberCount() {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
+			p.spanBegin(off)
 			p.starts = append(p.starts, -1)
 			p.mode = key1Map
+			if 0 < p.Limits.MaxDepth && p.Limits.MaxDepth <= depth {
+				return p.limitError(off, ojg.DepthLimit, p.Limits.MaxDepth)
+			}
+			if p.Ordered {
+				p.stack = append(p.stack, &Ordered{})
+				p.index = append(p.index, OrderedIndex{})
+				depth++
+				continue
+			}
 			var m Object
 			if p.Reuse {
 				if p.mi < len(p.maps) {
@@ -311,18 +415,39 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 				return p.newError(off, "unexpected object close")
 			}
 			if 256 < len(p.mode) && p.mode[256] == 'n' {
+				if p.numberTooLong(off) {
+					return p.limitError(p.numStart, ojg.NumberLimit, p.Limits.MaxNumberLen)
+				}
 				p.add(p.num.AsNode())
+				p.spanEnd(off)
+			}
+			if _, ok := p.stack[len(p.stack)-1].(Key); ok {
+				return p.newError(off, "expected a value")
 			}
 			p.starts = p.starts[0:depth]
 			n := p.stack[len(p.stack)-1]
 			p.stack = p.stack[:len(p.stack)-1]
+			if _, ok := n.(*Ordered); ok {
+				p.index = p.index[:len(p.index)-1]
+			}
 			p.add(n)
+			p.spanEnd(off + 1)
 			p.mode = afterMap
 		case val0:
+			if 0 < p.Limits.MaxMembers && p.Limits.MaxMembers <= p.memberCount() {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
+			p.spanBegin(off)
 			p.mode = zeroMap
 			p.num.Reset()
+			p.numStart = off
 		case valDigit:
+			if 0 < p.Limits.MaxMembers && p.Limits.MaxMembers <= p.memberCount() {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
+			p.spanBegin(off)
 			p.num.Reset()
+			p.numStart = off
 			p.mode = digitMap
 			p.num.I = uint64(b - '0')
 			for i, b = range buf[off+1:] {
@@ -341,9 +466,14 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			}
 			off += i
 		case valNeg:
+			if 0 < p.Limits.MaxMembers && p.Limits.MaxMembers <= p.memberCount() {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
+			p.spanBegin(off)
 			p.mode = negMap
 			p.num.Reset()
 			p.num.Neg = true
+			p.numStart = off
 			continue
 		case escU:
 			p.mode = uMap
@@ -351,6 +481,13 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			p.ri = 0
 			continue
 		case openArray:
+			if 0 < p.Limits.MaxMembers && p.Limits.MaxMembers <= p.memberCount() {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
+			p.spanBegin(off)
+			if 0 < p.Limits.MaxDepth && p.Limits.MaxDepth <= depth {
+				return p.limitError(off, ojg.DepthLimit, p.Limits.MaxDepth)
+			}
 			p.starts = append(p.starts, len(p.stack))
 			p.stack = append(p.stack, EmptyArray)
 			p.mode = valueMap
@@ -364,7 +501,11 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			// Only modes with a close array are value, after, and numbers
 			// which are all over 256 long.
 			if p.mode[256] == 'n' {
+				if p.numberTooLong(off) {
+					return p.limitError(p.numStart, ojg.NumberLimit, p.Limits.MaxNumberLen)
+				}
 				p.add(p.num.AsNode())
+				p.spanEnd(off)
 			}
 			start := p.starts[len(p.starts)-1] + 1
 			p.starts = p.starts[:len(p.starts)-1]
@@ -373,30 +514,46 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			copy(n, p.stack[start:len(p.stack)])
 			p.stack = p.stack[0 : start-1]
 			p.add(n)
+			p.spanEnd(off + 1)
 			p.mode = afterMap
 		case valNull:
+			if 0 < p.Limits.MaxMembers && p.Limits.MaxMembers <= p.memberCount() {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
+			p.spanBegin(off)
 			if off+4 <= len(buf) && string(buf[off:off+4]) == "null" {
 				off += 3
 				p.mode = afterMap
 				p.add(nil)
+				p.spanEnd(off + 1)
 			} else {
 				p.mode = nullMap
 				p.ri = 0
 			}
 		case valTrue:
+			if 0 < p.Limits.MaxMembers && p.Limits.MaxMembers <= p.memberCount() {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
+			p.spanBegin(off)
 			if off+4 <= len(buf) && string(buf[off:off+4]) == "true" {
 				off += 3
 				p.mode = afterMap
 				p.add(True)
+				p.spanEnd(off + 1)
 			} else {
 				p.mode = trueMap
 				p.ri = 0
 			}
 		case valFalse:
+			if 0 < p.Limits.MaxMembers && p.Limits.MaxMembers <= p.memberCount() {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
+			p.spanBegin(off)
 			if off+5 <= len(buf) && string(buf[off:off+5]) == "false" {
 				off += 4
 				p.mode = afterMap
 				p.add(False)
+				p.spanEnd(off + 1)
 			} else {
 				p.mode = falseMap
 				p.ri = 0
@@ -433,11 +590,19 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			p.mode = expSignMap
 			continue
 		case strQuote:
+			if 0 < p.Limits.MaxStringLen && p.Limits.MaxStringLen < len(p.tmp) {
+				return p.limitError(off, ojg.StringLimit, p.Limits.MaxStringLen)
+			}
 			p.mode = p.nextMode
 			if p.mode[':'] == colonColon {
+				if p.NoDuplicates && p.hasKey(p.tmp) {
+					return p.keyError(off, string(p.tmp))
+				}
 				p.stack = append(p.stack, Key(p.tmp))
+				p.spanKey(p.tmp, off+1)
 			} else {
 				p.add(String(p.tmp))
+				p.spanEnd(off + 1)
 			}
 		case numZero:
 			p.mode = zeroMap
@@ -447,10 +612,18 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			p.num.AddDigit(b)
 			p.mode = digitMap
 		case numSpc:
+			if p.numberTooLong(off) {
+				return p.limitError(p.numStart, ojg.NumberLimit, p.Limits.MaxNumberLen)
+			}
 			p.add(p.num.AsNode())
+			p.spanEnd(off)
 			p.mode = afterMap
 		case numNewline:
+			if p.numberTooLong(off) {
+				return p.limitError(p.numStart, ojg.NumberLimit, p.Limits.MaxNumberLen)
+			}
 			p.add(p.num.AsNode())
+			p.spanEnd(off)
 			p.line++
 			p.noff = off
 			p.mode = afterMap
@@ -465,6 +638,9 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			if b == '-' {
 				p.num.NegExp = true
 			}
+			if 0 < len(p.num.BigBuf) {
+				p.num.BigBuf = append(p.num.BigBuf, b)
+			}
 			continue
 		case expDigit:
 			p.num.AddExp(b)
@@ -497,6 +673,7 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 				}
 				if 3 <= p.ri {
 					p.add(True)
+					p.spanEnd(off + 1)
 					p.mode = afterMap
 				}
 			case p.mode['a'] == tokenOk:
@@ -506,6 +683,7 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 				}
 				if 4 <= p.ri {
 					p.add(False)
+					p.spanEnd(off + 1)
 					p.mode = afterMap
 				}
 			case p.mode['u'] == tokenOk && p.mode['l'] == tokenOk:
@@ -515,6 +693,7 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 				}
 				if 3 <= p.ri {
 					p.add(nil)
+					p.spanEnd(off + 1)
 					p.mode = afterMap
 				}
 			}
@@ -546,7 +725,11 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			return p.newError(off, "incomplete JSON")
 		}
 		if p.mode[256] == 'n' {
+			if p.numberTooLong(off) {
+				return p.limitError(p.numStart, ojg.NumberLimit, p.Limits.MaxNumberLen)
+			}
 			p.add(p.num.AsNode())
+			p.spanEnd(off)
 			if p.cb == nil && p.resultChan == nil {
 				p.result = p.stack[0]
 			} else {
@@ -559,14 +742,14 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			}
 		}
 	}
-	return nil
+	return p.checkPending(len(buf))
 }
 
 func (p *Parser) add(n Node) {
 	if 2 <= len(p.stack) {
 		if k, ok := p.stack[len(p.stack)-1].(Key); ok {
-			obj, _ := p.stack[len(p.stack)-2].(Object)
-			obj[string(k)] = n
+			obj := p.stack[len(p.stack)-2]
+			p.setMember(obj, string(k), n)
 			p.stack = p.stack[0 : len(p.stack)-1]
 
 			return
@@ -583,6 +766,113 @@ func (p *Parser) newError(off int, format string, args ...any) error {
 	}
 }
 
+func (p *Parser) limitError(off int, limit ojg.Limit, max int) error {
+	return &ojg.LimitError{
+		Limit:  limit,
+		Max:    max,
+		Line:   p.line,
+		Column: off - p.noff,
+	}
+}
+
+func (p *Parser) keyError(off int, key string) error {
+	return &ParseError{
+		Message: fmt.Sprintf("duplicate key '%s'", key),
+		Line:    p.line,
+		Column:  off - p.noff,
+		Key:     key,
+	}
+}
+
+// spanPos returns the position of off in the input.
+func (p *Parser) spanPos(off int) ojg.Position {
+	return ojg.Position{Offset: p.boff + off, Line: p.line, Column: off - p.noff}
+}
+
+// spanBegin records the start of a value at off if spans are being
+// recorded.
+func (p *Parser) spanBegin(off int) {
+	if p.Spans != nil {
+		p.Spans.Begin(p.spanPos(off))
+	}
+}
+
+// spanEnd records the end of the value that ends just before off if spans
+// are being recorded.
+func (p *Parser) spanEnd(off int) {
+	if p.Spans != nil {
+		p.Spans.End(p.spanPos(off))
+	}
+}
+
+// spanKey records the key that ends just before off if spans are being
+// recorded.
+func (p *Parser) spanKey(key []byte, off int) {
+	if p.Spans != nil {
+		p.Spans.Key(string(key), p.keyPos, p.spanPos(off))
+	}
+}
+
+// memberCount returns the number of members in the object or array being
+// parsed.
+func (p *Parser) memberCount() int {
+	if len(p.starts) == 0 {
+		return 0
+	}
+	if start := p.starts[len(p.starts)-1]; 0 <= start {
+		return len(p.stack) - start - 1
+	}
+	switch obj := p.stack[len(p.stack)-1].(type) {
+	case Object:
+		return len(obj)
+	case *Ordered:
+		return len(obj.Members)
+	}
+	return 0
+}
+
+func (p *Parser) numberTooLong(off int) bool {
+	return 0 < p.Limits.MaxNumberLen && p.Limits.MaxNumberLen < off-p.numStart
+}
+
+// checkPending checks the string or number that continues past the end of
+// the buffer so that neither can grow past the limits while reading. The
+// number start is adjusted to be relative to the next buffer.
+func (p *Parser) checkPending(off int) error {
+	if 0 < p.Limits.MaxStringLen && p.Limits.MaxStringLen < len(p.tmp) {
+		return p.limitError(off, ojg.StringLimit, p.Limits.MaxStringLen)
+	}
+	if 0 < p.Limits.MaxNumberLen && p.Limits.MaxNumberLen < len(p.num.BigBuf) {
+		return p.limitError(p.numStart, ojg.NumberLimit, p.Limits.MaxNumberLen)
+	}
+	p.numStart -= off
+	return nil
+}
+
+// hasKey returns true if the object on the top of the stack already has a
+// member with the key.
+func (p *Parser) hasKey(key []byte) (has bool) {
+	switch obj := p.stack[len(p.stack)-1].(type) {
+	case Object:
+		_, has = obj[string(key)]
+	case *Ordered:
+		has = p.index[len(p.index)-1].Has(obj, string(key))
+	}
+	return
+}
+
+// setMember sets a member of a Object or an *Ordered. An *Ordered must be
+// the innermost open object so its key index is on the top of the index
+// stack.
+func (p *Parser) setMember(obj Node, key string, value Node) {
+	switch to := obj.(type) {
+	case Object:
+		to[key] = value
+	case *Ordered:
+		p.index[len(p.index)-1].Set(to, key, value)
+	}
+}
+
 func (p *Parser) byteError(off int, mode string, b byte, r rune) error {
 	err := &ParseError{
 		Line:   p.line,
@@ -617,4 +907,26 @@ func (p *Parser) byteError(off int, mode string, b byte, r rune) error {
 		err.Message = fmt.Sprintf("unexpected character '%c'", r)
 	}
 	return err
-}
\ No newline at end of file
+}
+
+// discovered parses a candidate document found while discovering and
+// reports whether it was valid and if discovery should stop. If there is no
+// callback or channel then only the first valid document is parsed.
+func (p *Parser) discovered(candidate []byte, pos ojg.Position) (valid, stop bool) {
+	p.stack = p.stack[:0]
+	p.starts = p.starts[:0]
+	p.index = p.index[:0]
+	p.tmp = p.tmp[:0]
+	p.mode = valueMap
+	p.mi = 0
+	p.line = pos.Line
+	p.noff = -pos.Column
+	p.boff = pos.Offset
+	if p.Spans != nil {
+		p.Spans.Abort()
+	}
+	if err := p.parseBuffer(candidate, true); err != nil {
+		return false, false
+	}
+	return true, p.cb == nil && p.resultChan == nil
+}
@@ -4,11 +4,13 @@
 package gen_test
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
 	"testing/iotest"
 
+	"github.com/ohler55/ojg"
 	"github.com/ohler55/ojg/gen"
 	"github.com/ohler55/ojg/tt"
 )
@@ -348,4 +350,86 @@ func TestParserParseReaderChan(t *testing.T) {
 		results = append(results, n.String()...)
 	}
 	tt.Equal(t, `1 [2] {"x":3} true false 123`, string(results))
-}
\ No newline at end of file
+}
+
+func TestParserNoDuplicates(t *testing.T) {
+	p := gen.Parser{NoDuplicates: true}
+	v, err := p.Parse([]byte(`{"a":1,"b":{"a":2}}`))
+	tt.Nil(t, err)
+	tt.Equal(t, `{"a":1,"b":{"a":2}}`, v.String())
+
+	for i, src := range []string{`{"a":1,"a":2}`, `{"x":{"a\n":1,"a\u000a":2}}`} {
+		_, err = p.Parse([]byte(src))
+		var pe *gen.ParseError
+		tt.Equal(t, true, errors.As(err, &pe), i, ": ", src)
+		tt.Equal(t, "duplicate key", pe.Message[:13], i, ": ", src)
+
+		_, err = p.ParseReader(strings.NewReader(src))
+		tt.NotNil(t, err, i, ": ", src)
+	}
+	_, err = p.Parse([]byte("{\n  \"abc\": 1,\n  \"abc\": 2\n}"))
+	tt.Equal(t, "duplicate key 'abc' at 3:7", err.Error())
+}
+
+func TestParserDiscover(t *testing.T) {
+	src := `INFO {"a":1} [ERROR] {"bad":} [true] end`
+	var results []string
+	cb := func(n gen.Node) { results = append(results, n.String()) }
+
+	var p gen.Parser
+	_, err := p.Parse([]byte(src), cb, ojg.DiscoverSets)
+	tt.Nil(t, err)
+	tt.Equal(t, []string{`{"a":1}`, `[true]`}, results)
+
+	results = results[:0]
+	_, err = p.ParseReader(strings.NewReader(src), cb, ojg.DiscoverAny)
+	tt.Nil(t, err)
+	tt.Equal(t, []string{`{"a":1}`, `"bad"`, `[true]`}, results)
+
+	results = results[:0]
+	_, err = p.ParseReader(iotest.OneByteReader(strings.NewReader(src)), cb, ojg.DiscoverAny)
+	tt.Nil(t, err)
+	tt.Equal(t, []string{`{"a":1}`, `"bad"`, `[true]`}, results)
+
+	n, err := p.Parse([]byte(src), ojg.DiscoverSets)
+	tt.Nil(t, err)
+	tt.Equal(t, `{"a":1}`, n.String())
+}
+
+func TestParserMissingValue(t *testing.T) {
+	var p gen.Parser
+	for _, src := range []string{`{"a":}`, `{"a":1,"b":}`, `[{"a":}]`} {
+		_, err := p.Parse([]byte(src))
+		tt.NotNil(t, err, src)
+	}
+}
+
+func TestParserLimits(t *testing.T) {
+	for i, d := range []struct {
+		src    string
+		limits ojg.Limits
+		expect string
+	}{
+		{src: `[[[1]]]`, limits: ojg.Limits{MaxDepth: 3}},
+		{src: `{"a":[[{}]]}`, limits: ojg.Limits{MaxDepth: 3}, expect: "maximum depth of 3 exceeded at 1:8"},
+		{src: `["abc","ab\ncd"]`, limits: ojg.Limits{MaxStringLen: 4}, expect: "maximum string length of 4 exceeded at 1:15"},
+		{src: `{"abcd":1}`, limits: ojg.Limits{MaxStringLen: 3}, expect: "maximum string length of 3 exceeded at 1:7"},
+		{src: `[1,2,3,4]`, limits: ojg.Limits{MaxMembers: 3}, expect: "maximum members of 3 exceeded at 1:8"},
+		{src: `{"a":1,"b":true,"c":3}`, limits: ojg.Limits{MaxMembers: 2}, expect: "maximum members of 2 exceeded at 1:17"},
+		{src: "[1,\n2] ", limits: ojg.Limits{MaxBytes: 6}, expect: "maximum bytes of 6 exceeded at 2:3"},
+		{src: `[1.5,123456]`, limits: ojg.Limits{MaxNumberLen: 5}, expect: "maximum number length of 5 exceeded at 1:6"},
+	} {
+		p := gen.Parser{Limits: d.limits}
+		_, err := p.Parse([]byte(d.src))
+		_, err2 := p.ParseReader(strings.NewReader(d.src))
+		if len(d.expect) == 0 {
+			tt.Nil(t, err, i, ": ", d.src)
+			tt.Nil(t, err2, i, ": ", d.src)
+			continue
+		}
+		var le *ojg.LimitError
+		tt.Equal(t, true, errors.As(err, &le), i, ": ", d.src)
+		tt.Equal(t, d.expect, err.Error(), i, ": ", d.src)
+		tt.Equal(t, d.expect, err2.Error(), i, ": ", d.src)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package jp
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/sen"
+)
+
+// SetDocument sets the values that match the Expr in a sen.Document. Only
+// the text of the matched values is replaced so comments and formatting
+// elsewhere in the document are preserved. If there are no matches and the
+// Expr is made up of only child, nth, and bracket fragments then the value
+// is added to the document along with any missing objects or arrays.
+func (x Expr) SetDocument(doc *sen.Document, value any) error {
+	locs := x.Locate(doc.Data(), 0)
+	if len(locs) == 0 {
+		path := x.simplePath()
+		if path == nil {
+			return fmt.Errorf("can not set with %s, a path of only child and nth fragments is required", x)
+		}
+		return doc.Set(path, value)
+	}
+	for _, loc := range locs {
+		if err := doc.Set(loc.simplePath(), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveDocument removes the values that match the Expr from a
+// sen.Document. Only the text of the removed values and their keys is
+// removed so comments and formatting elsewhere in the document are
+// preserved.
+func (x Expr) RemoveDocument(doc *sen.Document) error {
+	locs := x.Locate(doc.Data(), 0)
+	paths := make([]alt.Path, 0, len(locs))
+	for _, loc := range locs {
+		paths = append(paths, loc.simplePath())
+	}
+	// Remove deeper paths before their parents and higher indexes before
+	// lower ones in the same array so that the paths that remain still
+	// refer to the same values.
+	sort.Slice(paths, func(i, j int) bool { return 0 < comparePaths(paths[i], paths[j]) })
+	for i, path := range paths {
+		if 0 < i && comparePaths(path, paths[i-1]) == 0 {
+			continue
+		}
+		if err := doc.Remove(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// comparePaths returns -1, 0, or 1 depending on the order of the two paths.
+// Elements are compared in order, indexes numerically and keys as strings,
+// and a path is after the paths that are a prefix of it.
+func comparePaths(p0, p1 alt.Path) int {
+	for i := 0; i < len(p0) && i < len(p1); i++ {
+		switch v0 := p0[i].(type) {
+		case int:
+			v1, ok := p1[i].(int)
+			switch {
+			case !ok, v0 < v1:
+				return -1
+			case v1 < v0:
+				return 1
+			}
+		case string:
+			v1, ok := p1[i].(string)
+			switch {
+			case !ok:
+				return 1
+			case v0 < v1:
+				return -1
+			case v1 < v0:
+				return 1
+			}
+		}
+	}
+	switch {
+	case len(p0) < len(p1):
+		return -1
+	case len(p1) < len(p0):
+		return 1
+	}
+	return 0
+}
+
+// simplePath returns the Expr as an alt.Path if it is composed of only an
+// optional leading root or @, child, non-negative nth, and bracket
+// fragments. Nil is returned for other expressions.
+func (x Expr) simplePath() alt.Path {
+	path := alt.Path{}
+	for i, frag := range x {
+		switch tf := frag.(type) {
+		case Root, At:
+			if i != 0 {
+				return nil
+			}
+		case Bracket:
+		case Child:
+			path = append(path, string(tf))
+		case Nth:
+			if tf < 0 {
+				return nil
+			}
+			path = append(path, int(tf))
+		default:
+			return nil
+		}
+	}
+	return path
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package jp_test
+
+import (
+	"testing"
+
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/sen"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestExprSetDocument(t *testing.T) {
+	src := `{
+  // Colors to use.
+  colors: {
+    key: blue   // keys
+    value: red  // values
+  }
+  sizes: [1, 2, 3]
+}
+`
+	doc, err := sen.ParseDocument([]byte(src))
+	tt.Nil(t, err)
+
+	tt.Nil(t, jp.MustParseString("$.colors.key").SetDocument(doc, "green"))
+	tt.Nil(t, jp.MustParseString("$.sizes[*]").SetDocument(doc, 0))
+	tt.Nil(t, jp.MustParseString("$.colors.null").SetDocument(doc, "gray"))
+	tt.Equal(t, `{
+  // Colors to use.
+  colors: {
+    key: green   // keys
+    value: red  // values
+    null: gray
+  }
+  sizes: [0, 0, 0]
+}
+`, doc.String())
+
+	tt.NotNil(t, jp.MustParseString("$.x[*]").SetDocument(doc, 1))
+	tt.NotNil(t, jp.MustParseString("$.sizes.x").SetDocument(doc, 1))
+}
+
+func TestExprRemoveDocument(t *testing.T) {
+	src := `{
+  a: [1, 2, 3, 4] // numbers
+  b: {c: 1, d: 2}
+}`
+	doc, err := sen.ParseDocument([]byte(src))
+	tt.Nil(t, err)
+
+	tt.Nil(t, jp.MustParseString("$.a[?(@ > 1)]").RemoveDocument(doc))
+	tt.Nil(t, jp.MustParseString("$.b.c").RemoveDocument(doc))
+	tt.Equal(t, `{
+  a: [1] // numbers
+  b: {d: 2}
+}`, doc.String())
+}
+
+func TestExprRemoveDocumentOrder(t *testing.T) {
+	doc, err := sen.ParseDocument([]byte(`["a","b","c","d"]`))
+	tt.Nil(t, err)
+	tt.Nil(t, jp.MustParseString("$[2,0]").RemoveDocument(doc))
+	tt.Equal(t, `["b","d"]`, doc.String())
+
+	doc, err = sen.ParseDocument([]byte(`[[1, 2, 3], {a: [4, 5]}, 6]`))
+	tt.Nil(t, err)
+	tt.Nil(t, jp.MustParseString("$[0,1,0][1,0,0]").RemoveDocument(doc))
+	tt.Equal(t, `[[3], {a: [4, 5]}, 6]`, doc.String())
+
+	doc, err = sen.ParseDocument([]byte(`{x: [1, 2, 3], y: {z: [4, 5]}}`))
+	tt.Nil(t, err)
+	tt.Nil(t, jp.MustParseString("$..[0]").RemoveDocument(doc))
+	tt.Equal(t, `{x: [2, 3], y: {z: [5]}}`, doc.String())
+
+	doc, err = sen.ParseDocument([]byte(`[[1, 2], 3]`))
+	tt.Nil(t, err)
+	tt.Nil(t, jp.MustParseString("$..[0]").RemoveDocument(doc))
+	tt.Equal(t, `[3]`, doc.String())
+}
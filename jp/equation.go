@@ -4,6 +4,7 @@
 package jp
 
 import (
+	"encoding/json"
 	"regexp"
 	"strconv"
 )
@@ -187,12 +188,26 @@ func Search(left, right *Equation) *Equation {
 	return &Equation{o: search, left: left, right: right}
 }
 
+// UnaryFunction creates and returns an Equation for a function registered
+// with RegisterUnaryFunction. A panic occurs if the function has not been
+// registered.
+func UnaryFunction(name string, arg *Equation) *Equation {
+	return &Equation{o: lookupFunction(name, 1), left: arg}
+}
+
+// BinaryFunction creates and returns an Equation for a function registered
+// with RegisterBinaryFunction. A panic occurs if the function has not been
+// registered.
+func BinaryFunction(name string, left, right *Equation) *Equation {
+	return &Equation{o: lookupFunction(name, 2), left: left, right: right}
+}
+
 // Append a fragment string representation of the fragment to the buffer
 // then returning the expanded buffer.
 func (e *Equation) Append(buf []byte, parens bool) []byte {
 	if e.o != nil {
 		switch e.o.code {
-		case not.code, length.code, count.code, match.code, search.code:
+		case not.code, length.code, count.code, match.code, search.code, fnCode:
 			parens = false
 		}
 	}
@@ -224,6 +239,23 @@ func (e *Equation) Append(buf []byte, parens bool) []byte {
 			buf = append(buf, ',', ' ')
 			buf = e.right.Append(buf, false)
 			buf = append(buf, ')')
+		case fnCode:
+			buf = append(buf, e.o.name...)
+			buf = append(buf, '(')
+			if e.left != nil {
+				buf = e.left.Append(buf, false)
+			} else {
+				buf = append(buf, "null"...)
+			}
+			if 1 < e.o.cnt {
+				buf = append(buf, ',', ' ')
+				if e.right != nil {
+					buf = e.right.Append(buf, false)
+				} else {
+					buf = append(buf, "null"...)
+				}
+			}
+			buf = append(buf, ')')
 		default:
 			if e.left != nil {
 				buf = e.left.Append(buf, e.left.o != nil && e.left.o.prec >= e.o.prec)
@@ -256,6 +288,8 @@ func (e *Equation) appendValue(buf []byte, v any) []byte {
 		buf = append(buf, strconv.FormatInt(tv, 10)...)
 	case float64:
 		buf = append(buf, strconv.FormatFloat(tv, 'g', -1, 64)...)
+	case json.Number:
+		buf = append(buf, tv...)
 	case bool:
 		if tv {
 			buf = append(buf, "true"...)
@@ -303,6 +337,20 @@ func (e *Equation) buildScript(stack []any) []any {
 		} else {
 			stack = e.left.buildScript(stack)
 		}
+	case fnCode:
+		stack = append(stack, e.o)
+		if e.left == nil {
+			stack = append(stack, nil)
+		} else {
+			stack = e.left.buildScript(stack)
+		}
+		if 1 < e.o.cnt {
+			if e.right == nil {
+				stack = append(stack, nil)
+			} else {
+				stack = e.right.buildScript(stack)
+			}
+		}
 	default:
 		stack = append(stack, e.o)
 		if e.left == nil {
@@ -99,6 +99,16 @@ func (f Filter) remove(value any) (out any, changed bool) {
 				changed = true
 			}
 		}
+	case *gen.Ordered:
+		members := tv.Members[:0]
+		for _, m := range tv.Members {
+			if f.Match(m.Value) {
+				changed = true
+			} else {
+				members = append(members, m)
+			}
+		}
+		tv.Members = members
 	default:
 		rv := reflect.ValueOf(value)
 		switch rv.Kind() {
@@ -202,6 +212,14 @@ func (f Filter) removeOne(value any) (out any, changed bool) {
 				}
 			}
 		}
+	case *gen.Ordered:
+		for i, m := range tv.Members {
+			if f.Match(m.Value) {
+				tv.Members = append(tv.Members[:i], tv.Members[i+1:]...)
+				changed = true
+				break
+			}
+		}
 	default:
 		rv := reflect.ValueOf(value)
 		switch rv.Kind() {
@@ -0,0 +1,116 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package jp
+
+import (
+	"fmt"
+
+	"github.com/ohler55/ojg/gen"
+)
+
+// fnCode is the op code used for all registered script functions.
+const fnCode = 'F'
+
+var reservedNames = map[string]bool{
+	"null":    true,
+	"true":    true,
+	"false":   true,
+	"Nothing": true,
+}
+
+// RegisterUnaryFunction registers a function that can then be used in
+// scripts and filters such as [?(lower(@.name) == 'abc')]. If getArg is true
+// the argument, if a path, is evaluated with a Get and the function is called
+// with a []any of all the matches. If false the argument is evaluated to the
+// first matching value or Nothing. Integers are passed as int64 and floats as
+// float64. The function name must start with a letter followed by letters,
+// digits, or underscores. Registering a name that matches an existing
+// operation or function panics. Functions should be registered before any
+// scripts are parsed or evaluated as the registry is not protected against
+// concurrent access.
+func RegisterUnaryFunction(name string, getArg bool, f func(arg any) any) {
+	if f == nil {
+		panic(fmt.Errorf("a function is required for %s", name))
+	}
+	registerFunction(&op{prec: 0, code: fnCode, name: name, cnt: 1, getLeft: getArg, fn: f})
+}
+
+// RegisterBinaryFunction registers a function of two arguments that can then
+// be used in scripts and filters such as [?(daysSince(@.created, $.now) < 7)].
+// The getLeft and getRight flags indicate how the arguments are evaluated in
+// the same way as the getArg flag of RegisterUnaryFunction does.
+func RegisterBinaryFunction(name string, getLeft, getRight bool, f func(left, right any) any) {
+	if f == nil {
+		panic(fmt.Errorf("a function is required for %s", name))
+	}
+	registerFunction(&op{prec: 0, code: fnCode, name: name, cnt: 2, getLeft: getLeft, getRight: getRight, fn: f})
+}
+
+func registerFunction(o *op) {
+	if len(o.name) == 0 {
+		panic(fmt.Errorf("a function name can not be empty"))
+	}
+	for i, b := range []byte(o.name) {
+		switch {
+		case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z':
+		case 0 < i && ('0' <= b && b <= '9' || b == '_'):
+		default:
+			panic(fmt.Errorf("'%s' is not a valid function name", o.name))
+		}
+	}
+	if _, has := opMap[o.name]; has || reservedNames[o.name] {
+		panic(fmt.Errorf("%s already defined", o.name))
+	}
+	opMap[o.name] = o
+}
+
+func lookupFunction(name string, cnt byte) *op {
+	o := opMap[name]
+	if o == nil || o.code != fnCode {
+		panic(fmt.Errorf("%s is not a registered function", name))
+	}
+	if o.cnt != cnt {
+		panic(fmt.Errorf("%s takes %d arguments, not %d", name, o.cnt, cnt))
+	}
+	return o
+}
+
+// normalizeValue converts a value into one of the types used for comparison
+// in script evaluation.
+func normalizeValue(v any) any {
+	switch tv := v.(type) {
+	case int:
+		v = int64(tv)
+	case int8:
+		v = int64(tv)
+	case int16:
+		v = int64(tv)
+	case int32:
+		v = int64(tv)
+	case uint:
+		v = int64(tv)
+	case uint8:
+		v = int64(tv)
+	case uint16:
+		v = int64(tv)
+	case uint32:
+		v = int64(tv)
+	case uint64:
+		v = int64(tv)
+	case float32:
+		v = float64(tv)
+	case gen.Bool:
+		v = bool(tv)
+	case gen.String:
+		v = string(tv)
+	case gen.Int:
+		v = int64(tv)
+	case gen.Float:
+		v = float64(tv)
+	default:
+		if r := bigRat(v); r != nil {
+			v = r
+		}
+	}
+	return v
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package jp_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/pretty"
+	"github.com/ohler55/ojg/tt"
+)
+
+func init() {
+	jp.RegisterUnaryFunction("lower", false, func(arg any) any {
+		if s, ok := arg.(string); ok {
+			return strings.ToLower(s)
+		}
+		return jp.Nothing
+	})
+	jp.RegisterUnaryFunction("total", true, func(arg any) any {
+		var sum int64
+		list, _ := arg.([]any)
+		for _, v := range list {
+			switch tv := v.(type) {
+			case int:
+				sum += int64(tv)
+			case int64:
+				sum += tv
+			case gen.Int:
+				sum += int64(tv)
+			}
+		}
+		return sum
+	})
+	jp.RegisterBinaryFunction("daysSince", false, true, func(left, right any) any {
+		start, _ := left.(int64)
+		var now int
+		if list, _ := right.([]any); 0 < len(list) {
+			now, _ = list[0].(int)
+		}
+		return now - int(start)
+	})
+}
+
+func TestFunctionParse(t *testing.T) {
+	for i, d := range []xdata{
+		{src: "(lower(@.name) == 'abc')", expect: "(lower(@.name) == 'abc')"},
+		{src: "('abc' == lower(@.name))", expect: "('abc' == lower(@.name))"},
+		{src: "(total(@.x[*]) > 3)", expect: "(total(@.x[*]) > 3)"},
+		{src: "(daysSince(@.created, $.now) < 7)", expect: "(daysSince(@.created, $.now) < 7)"},
+		{src: "(daysSince(total(@.x[*]), $.now) < 7)", expect: "(daysSince(total(@.x[*]), $.now) < 7)"},
+		{src: "(lowered(@.name) == 'abc')", err: "expected a length function at 2 in (lowered(@.name) == 'abc')"},
+		{src: "(lower(@.name == 'abc')", err: "not terminated at 15 in (lower(@.name == 'abc')"},
+	} {
+		s, err := jp.NewScript(d.src)
+		if 0 < len(d.err) {
+			tt.NotNil(t, err, i, ": ", d.src)
+			tt.Equal(t, d.err, err.Error(), i, ": ", d.src)
+			continue
+		}
+		tt.Nil(t, err, i, ": ", d.src)
+		tt.Equal(t, d.expect, s.String(), i, ": ", d.src)
+	}
+}
+
+func TestFunctionEval(t *testing.T) {
+	root := map[string]any{"now": 10}
+	for i, d := range []edata{
+		{src: "(lower(@.name) == 'abc')", value: map[string]any{"name": "ABC"}},
+		{src: "(lower(@.name) == 'abc')", value: map[string]any{"name": "ABD"}, noMatch: true},
+		{src: "(lower(@.name) == Nothing)", value: map[string]any{"name": 3}},
+		{src: "(total(@.x[*]) == 6)", value: map[string]any{"x": []any{1, 2, 3}}},
+		{src: "(total(@.x[*]) == 6)", value: map[string]any{"x": []any{1, 2}}, noMatch: true},
+		{src: "(total(3) == 0)", value: map[string]any{"x": []any{1, 2}}},
+		{src: "(daysSince(@.created, $.now) < 7)", value: map[string]any{"created": 5}},
+		{src: "(daysSince(@.created, $.now) < 7)", value: map[string]any{"created": 2}, noMatch: true},
+	} {
+		s := jp.MustNewScript(d.src)
+		result, _ := s.EvalWithRoot([]any{}, []any{d.value}, root).([]any)
+		if d.noMatch {
+			tt.Equal(t, 0, len(result), i, ": ", d.src, " in ", d.value)
+		} else {
+			tt.Equal(t, 1, len(result), i, ": ", d.src, " in ", d.value)
+		}
+	}
+}
+
+func TestFunctionEvalNode(t *testing.T) {
+	s := jp.MustNewScript("(total(@.x[*]) == 6)")
+	result, _ := s.Eval([]any{}, gen.Array{gen.Object{"x": gen.Array{gen.Int(1), gen.Int(2), gen.Int(3)}}}).([]any)
+	tt.Equal(t, 1, len(result))
+}
+
+func TestFunctionGet(t *testing.T) {
+	data := map[string]any{
+		"now": 10,
+		"list": []any{
+			map[string]any{"name": "One", "created": 1},
+			map[string]any{"name": "Two", "created": 8},
+		},
+	}
+	x := jp.MustParseString("$.list[?(daysSince(@.created, $.now) < 7)].name")
+	tt.Equal(t, []any{"Two"}, x.Get(data))
+
+	x = jp.MustParseString("$.list[?(lower(@.name) == 'one')].created")
+	tt.Equal(t, []any{1}, x.Get(data))
+}
+
+func TestFunctionEquation(t *testing.T) {
+	eq := jp.Eq(jp.UnaryFunction("lower", jp.Get(jp.A().C("name"))), jp.ConstString("abc"))
+	tt.Equal(t, "(lower(@.name) == 'abc')", eq.String())
+	tt.Equal(t, "(lower(@.name) == 'abc')", eq.Script().String())
+	tt.Equal(t, "[?(lower(@.name) == 'abc')]", eq.Filter().String())
+
+	eq = jp.Lt(jp.BinaryFunction("daysSince", jp.Get(jp.A().C("created")), jp.Get(jp.R().C("now"))), jp.ConstInt(7))
+	tt.Equal(t, "(daysSince(@.created, $.now) < 7)", eq.String())
+	tt.Equal(t, "(daysSince(@.created, $.now) < 7)", eq.Script().String())
+
+	eq = jp.UnaryFunction("lower", nil)
+	tt.Equal(t, "lower(null)", eq.String())
+
+	tt.Panic(t, func() { _ = jp.UnaryFunction("nope", nil) })
+	tt.Panic(t, func() { _ = jp.UnaryFunction("length", nil) })
+	tt.Panic(t, func() { _ = jp.BinaryFunction("lower", nil, nil) })
+}
+
+func TestFunctionInspect(t *testing.T) {
+	for i, d := range []struct {
+		src    string
+		expect string
+	}{
+		{src: "(lower(@.x) == 'a')", expect: `{left: {left: @.x op: lower right: null} op: "==" right: a}`},
+		{src: "(daysSince(@.x, $.now) < 7)", expect: `{left: {left: @.x op: daysSince right: $.now} op: < right: 7}`},
+	} {
+		f := jp.MustNewScript(d.src).Inspect()
+		tt.Equal(t, d.expect, pretty.SEN(f), i, ": ", d.src)
+	}
+}
+
+func TestFunctionRegisterErrors(t *testing.T) {
+	fn := func(arg any) any { return arg }
+	tt.Panic(t, func() { jp.RegisterUnaryFunction("length", false, fn) })
+	tt.Panic(t, func() { jp.RegisterUnaryFunction("==", false, fn) })
+	tt.Panic(t, func() { jp.RegisterUnaryFunction("lower", false, fn) })
+	tt.Panic(t, func() { jp.RegisterUnaryFunction("null", false, fn) })
+	tt.Panic(t, func() { jp.RegisterUnaryFunction("", false, fn) })
+	tt.Panic(t, func() { jp.RegisterUnaryFunction("1abc", false, fn) })
+	tt.Panic(t, func() { jp.RegisterUnaryFunction("a-b", false, fn) })
+	tt.Panic(t, func() { jp.RegisterUnaryFunction("noFunc", false, nil) })
+	tt.Panic(t, func() {
+		jp.RegisterBinaryFunction("match", false, false, func(left, right any) any { return nil })
+	})
+}
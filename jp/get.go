@@ -78,7 +78,7 @@ func (x Expr) Get(data any) (results []any) {
 					switch v.(type) {
 					case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 						int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-					case map[string]any, []any, gen.Object, gen.Array:
+					case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 						stack = append(stack, v)
 					default:
 						if rt := reflect.TypeOf(v); rt != nil {
@@ -119,7 +119,7 @@ func (x Expr) Get(data any) (results []any) {
 					switch v.(type) {
 					case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 						int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-					case map[string]any, []any, gen.Object, gen.Array:
+					case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 						stack = append(stack, v)
 					default:
 						if rt := reflect.TypeOf(v); rt != nil {
@@ -143,7 +143,7 @@ func (x Expr) Get(data any) (results []any) {
 						switch v.(type) {
 						case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							if rt := reflect.TypeOf(v); rt != nil {
@@ -164,7 +164,7 @@ func (x Expr) Get(data any) (results []any) {
 						switch v.(type) {
 						case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							if rt := reflect.TypeOf(v); rt != nil {
@@ -184,7 +184,7 @@ func (x Expr) Get(data any) (results []any) {
 				} else {
 					for _, v = range tv {
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						}
 					}
@@ -198,7 +198,7 @@ func (x Expr) Get(data any) (results []any) {
 					for i := len(tv) - 1; 0 <= i; i-- {
 						v = tv[i]
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						}
 					}
@@ -214,7 +214,7 @@ func (x Expr) Get(data any) (results []any) {
 						switch v.(type) {
 						case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							if rt := reflect.TypeOf(v); rt != nil {
@@ -246,7 +246,7 @@ func (x Expr) Get(data any) (results []any) {
 						switch v.(type) {
 						case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 							stack = append(stack, fi|descentChildFlag)
 						default:
@@ -270,7 +270,7 @@ func (x Expr) Get(data any) (results []any) {
 						switch v.(type) {
 						case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 							stack = append(stack, fi|descentChildFlag)
 						default:
@@ -293,7 +293,7 @@ func (x Expr) Get(data any) (results []any) {
 					}
 					for _, v = range tv {
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 							stack = append(stack, fi|descentChildFlag)
 						}
@@ -310,7 +310,24 @@ func (x Expr) Get(data any) (results []any) {
 					for i := len(tv) - 1; 0 <= i; i-- {
 						v = tv[i]
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
+							stack = append(stack, v)
+							stack = append(stack, fi|descentChildFlag)
+						}
+					}
+				case *gen.Ordered:
+					// Put prev back and slide fi.
+					stack[len(stack)-1] = prev
+					stack = append(stack, di|descentFlag)
+					if int(fi) == len(x)-1 { // last one
+						for _, m := range tv.Members {
+							results = append(results, m.Value)
+						}
+					}
+					for i := len(tv.Members) - 1; 0 <= i; i-- {
+						v = tv.Members[i].Value
+						switch v.(type) {
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 							stack = append(stack, fi|descentChildFlag)
 						}
@@ -419,7 +436,7 @@ func (x Expr) Get(data any) (results []any) {
 						switch v.(type) {
 						case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							if rt := reflect.TypeOf(v); rt != nil {
@@ -477,7 +494,7 @@ func (x Expr) Get(data any) (results []any) {
 							switch v.(type) {
 							case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 								int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-							case map[string]any, []any, gen.Object, gen.Array:
+							case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 								stack = append(stack, v)
 							default:
 								if rt := reflect.TypeOf(v); rt != nil {
@@ -504,7 +521,7 @@ func (x Expr) Get(data any) (results []any) {
 							switch v.(type) {
 							case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 								int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-							case map[string]any, []any, gen.Object, gen.Array:
+							case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 								stack = append(stack, v)
 							default:
 								if rt := reflect.TypeOf(v); rt != nil {
@@ -543,7 +560,7 @@ func (x Expr) Get(data any) (results []any) {
 						for i := end; start <= i; i -= step {
 							v = tv[i]
 							switch v.(type) {
-							case map[string]any, []any, gen.Object, gen.Array:
+							case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 								stack = append(stack, v)
 							}
 						}
@@ -561,7 +578,7 @@ func (x Expr) Get(data any) (results []any) {
 						for i := end; i <= start; i -= step {
 							v = tv[i]
 							switch v.(type) {
-							case map[string]any, []any, gen.Object, gen.Array:
+							case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 								stack = append(stack, v)
 							}
 						}
@@ -578,7 +595,7 @@ func (x Expr) Get(data any) (results []any) {
 						switch v.(type) {
 						case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							if rt := reflect.TypeOf(v); rt != nil {
@@ -675,7 +692,7 @@ func (x Expr) FirstFound(data any) (any, bool) {
 				switch v.(type) {
 				case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 					int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-				case map[string]any, []any, gen.Object, gen.Array:
+				case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 					stack = append(stack, v)
 				default:
 					if rt := reflect.TypeOf(v); rt != nil {
@@ -715,7 +732,7 @@ func (x Expr) FirstFound(data any) (any, bool) {
 				switch v.(type) {
 				case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 					int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-				case map[string]any, []any, gen.Object, gen.Array:
+				case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 					stack = append(stack, v)
 				default:
 					if rt := reflect.TypeOf(v); rt != nil {
@@ -738,7 +755,7 @@ func (x Expr) FirstFound(data any) (any, bool) {
 						switch v.(type) {
 						case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							if rt := reflect.TypeOf(v); rt != nil {
@@ -761,7 +778,7 @@ func (x Expr) FirstFound(data any) (any, bool) {
 						switch v.(type) {
 						case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							if rt := reflect.TypeOf(v); rt != nil {
@@ -781,7 +798,7 @@ func (x Expr) FirstFound(data any) (any, bool) {
 				} else {
 					for _, v = range tv {
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						}
 					}
@@ -795,7 +812,21 @@ func (x Expr) FirstFound(data any) (any, bool) {
 					for i := len(tv) - 1; 0 <= i; i-- {
 						v = tv[i]
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
+							stack = append(stack, v)
+						}
+					}
+				}
+			case *gen.Ordered:
+				if int(fi) == len(x)-1 { // last one
+					for _, m := range tv.Members {
+						return m.Value, true
+					}
+				} else {
+					for i := len(tv.Members) - 1; 0 <= i; i-- {
+						v = tv.Members[i].Value
+						switch v.(type) {
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						}
 					}
@@ -808,7 +839,7 @@ func (x Expr) FirstFound(data any) (any, bool) {
 					switch v.(type) {
 					case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 						int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-					case map[string]any, []any, gen.Object, gen.Array:
+					case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 						stack = append(stack, v)
 					default:
 						if rt := reflect.TypeOf(v); rt != nil {
@@ -838,7 +869,7 @@ func (x Expr) FirstFound(data any) (any, bool) {
 						switch v.(type) {
 						case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 							stack = append(stack, fi|descentChildFlag)
 						default:
@@ -864,7 +895,7 @@ func (x Expr) FirstFound(data any) (any, bool) {
 						switch v.(type) {
 						case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 							stack = append(stack, fi|descentChildFlag)
 						default:
@@ -887,7 +918,7 @@ func (x Expr) FirstFound(data any) (any, bool) {
 					}
 					for _, v = range tv {
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 							stack = append(stack, fi|descentChildFlag)
 						}
@@ -904,7 +935,24 @@ func (x Expr) FirstFound(data any) (any, bool) {
 					for i := len(tv) - 1; 0 <= i; i-- {
 						v = tv[i]
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
+							stack = append(stack, v)
+							stack = append(stack, fi|descentChildFlag)
+						}
+					}
+				case *gen.Ordered:
+					// Put prev back and slide fi.
+					stack[len(stack)-1] = prev
+					stack = append(stack, di|descentFlag)
+					if int(fi) == len(x)-1 { // last one
+						if 0 < len(tv.Members) {
+							return tv.Members[0].Value, true
+						}
+					}
+					for i := len(tv.Members) - 1; 0 <= i; i-- {
+						v = tv.Members[i].Value
+						switch v.(type) {
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 							stack = append(stack, fi|descentChildFlag)
 						}
@@ -1005,7 +1053,7 @@ func (x Expr) FirstFound(data any) (any, bool) {
 						switch v.(type) {
 						case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							if rt := reflect.TypeOf(v); rt != nil {
@@ -1061,7 +1109,7 @@ func (x Expr) FirstFound(data any) (any, bool) {
 						switch v.(type) {
 						case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							if rt := reflect.TypeOf(v); rt != nil {
@@ -1085,7 +1133,7 @@ func (x Expr) FirstFound(data any) (any, bool) {
 						switch v.(type) {
 						case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							if rt := reflect.TypeOf(v); rt != nil {
@@ -1121,7 +1169,7 @@ func (x Expr) FirstFound(data any) (any, bool) {
 					for i := end; start <= i; i -= step {
 						v = tv[i]
 						switch v.(type) {
-						case gen.Object, gen.Array:
+						case gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						}
 					}
@@ -1136,7 +1184,7 @@ func (x Expr) FirstFound(data any) (any, bool) {
 					for i := end; i <= start; i -= step {
 						v = tv[i]
 						switch v.(type) {
-						case gen.Object, gen.Array:
+						case gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						}
 					}
@@ -1149,7 +1197,7 @@ func (x Expr) FirstFound(data any) (any, bool) {
 					switch v.(type) {
 					case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 						int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-					case map[string]any, []any, gen.Object, gen.Array:
+					case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 						stack = append(stack, v)
 					default:
 						if rt := reflect.TypeOf(v); rt != nil {
@@ -1184,6 +1232,9 @@ func (x Expr) FirstFound(data any) (any, bool) {
 }
 
 func (x Expr) reflectGetChild(data any, key string) (v any, has bool) {
+	if ordered, ok := data.(*gen.Ordered); ok {
+		return ordered.Get(key)
+	}
 	if !isNil(data) {
 		rd := reflect.ValueOf(data)
 		rt := rd.Type()
@@ -1232,6 +1283,12 @@ func (x Expr) reflectGetNth(data any, i int) (v any, has bool) {
 }
 
 func (x Expr) reflectGetWild(data any) (va []any) {
+	if ordered, ok := data.(*gen.Ordered); ok {
+		for i := len(ordered.Members) - 1; 0 <= i; i-- {
+			va = append(va, ordered.Members[i].Value)
+		}
+		return
+	}
 	if !isNil(data) {
 		rd := reflect.ValueOf(data)
 		rt := rd.Type()
@@ -1261,6 +1318,12 @@ func (x Expr) reflectGetWild(data any) (va []any) {
 }
 
 func (x Expr) reflectGetWildOne(data any) (any, bool) {
+	if ordered, ok := data.(*gen.Ordered); ok {
+		if 0 < len(ordered.Members) {
+			return ordered.Members[0].Value, true
+		}
+		return nil, false
+	}
 	if !isNil(data) {
 		rd := reflect.ValueOf(data)
 		rt := rd.Type()
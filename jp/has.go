@@ -62,7 +62,7 @@ func (x Expr) Has(data any) bool {
 				case nil, bool, string, float64, float32,
 					int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64,
 					gen.Bool, gen.Int, gen.Float, gen.String:
-				case map[string]any, []any, gen.Object, gen.Array:
+				case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 					stack = append(stack, v)
 				default:
 					if rt := reflect.TypeOf(v); rt != nil {
@@ -104,7 +104,7 @@ func (x Expr) Has(data any) bool {
 				switch v.(type) {
 				case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 					int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-				case map[string]any, []any, gen.Object, gen.Array:
+				case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 					stack = append(stack, v)
 				default:
 					if rt := reflect.TypeOf(v); rt != nil {
@@ -128,7 +128,7 @@ func (x Expr) Has(data any) bool {
 						case nil, bool, string, float64, float32,
 							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64,
 							gen.Bool, gen.Int, gen.Float, gen.String:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							if rt := reflect.TypeOf(v); rt != nil {
@@ -152,7 +152,7 @@ func (x Expr) Has(data any) bool {
 						case nil, bool, string, float64, float32,
 							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64,
 							gen.Bool, gen.Int, gen.Float, gen.String:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							if rt := reflect.TypeOf(v); rt != nil {
@@ -172,7 +172,7 @@ func (x Expr) Has(data any) bool {
 				} else {
 					for _, v = range tv {
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						}
 					}
@@ -186,7 +186,21 @@ func (x Expr) Has(data any) bool {
 					for i := len(tv) - 1; 0 <= i; i-- {
 						v = tv[i]
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
+							stack = append(stack, v)
+						}
+					}
+				}
+			case *gen.Ordered:
+				if int(fi) == len(x)-1 { // last one
+					if 0 < len(tv.Members) {
+						return true
+					}
+				} else {
+					for i := len(tv.Members) - 1; 0 <= i; i-- {
+						v = tv.Members[i].Value
+						switch v.(type) {
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						}
 					}
@@ -200,7 +214,7 @@ func (x Expr) Has(data any) bool {
 					case nil, bool, string, float64, float32,
 						int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64,
 						gen.Bool, gen.Int, gen.Float, gen.String:
-					case map[string]any, []any, gen.Object, gen.Array:
+					case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 						stack = append(stack, v)
 					default:
 						if rt := reflect.TypeOf(v); rt != nil {
@@ -231,7 +245,7 @@ func (x Expr) Has(data any) bool {
 						case nil, bool, string, float64, float32,
 							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64,
 							gen.Bool, gen.Int, gen.Float, gen.String:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 							stack = append(stack, fi|descentChildFlag)
 						default:
@@ -258,7 +272,7 @@ func (x Expr) Has(data any) bool {
 						case nil, bool, string, float64, float32,
 							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64,
 							gen.Bool, gen.Int, gen.Float, gen.String:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 							stack = append(stack, fi|descentChildFlag)
 						default:
@@ -281,7 +295,7 @@ func (x Expr) Has(data any) bool {
 					}
 					for _, v = range tv {
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 							stack = append(stack, fi|descentChildFlag)
 						}
@@ -298,7 +312,24 @@ func (x Expr) Has(data any) bool {
 					for i := len(tv) - 1; 0 <= i; i-- {
 						v = tv[i]
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
+							stack = append(stack, v)
+							stack = append(stack, fi|descentChildFlag)
+						}
+					}
+				case *gen.Ordered:
+					// Put prev back and slide fi.
+					stack[len(stack)-1] = prev
+					stack = append(stack, di|descentFlag)
+					if int(fi) == len(x)-1 { // last one
+						if 0 < len(tv.Members) {
+							return true
+						}
+					}
+					for i := len(tv.Members) - 1; 0 <= i; i-- {
+						v = tv.Members[i].Value
+						switch v.(type) {
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 							stack = append(stack, fi|descentChildFlag)
 						}
@@ -399,7 +430,7 @@ func (x Expr) Has(data any) bool {
 						switch v.(type) {
 						case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							if rt := reflect.TypeOf(v); rt != nil {
@@ -455,7 +486,7 @@ func (x Expr) Has(data any) bool {
 						switch v.(type) {
 						case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							if rt := reflect.TypeOf(v); rt != nil {
@@ -479,7 +510,7 @@ func (x Expr) Has(data any) bool {
 						switch v.(type) {
 						case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							if rt := reflect.TypeOf(v); rt != nil {
@@ -515,7 +546,7 @@ func (x Expr) Has(data any) bool {
 					for i := end; start <= i; i -= step {
 						v = tv[i]
 						switch v.(type) {
-						case gen.Object, gen.Array:
+						case gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						}
 					}
@@ -530,7 +561,7 @@ func (x Expr) Has(data any) bool {
 					for i := end; i <= start; i -= step {
 						v = tv[i]
 						switch v.(type) {
-						case gen.Object, gen.Array:
+						case gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						}
 					}
@@ -543,7 +574,7 @@ func (x Expr) Has(data any) bool {
 					switch v.(type) {
 					case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 						int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-					case map[string]any, []any, gen.Object, gen.Array:
+					case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 						stack = append(stack, v)
 					default:
 						if rt := reflect.TypeOf(v); rt != nil {
@@ -0,0 +1,310 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package jp
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/ohler55/ojg/gen"
+)
+
+// Locate the values described by the Expr and return a slice of normalized
+// paths to those values in the data. A normalized path is composed of only
+// Root, Child, and Nth fragments where Nth fragments are never negative. The
+// number of paths returned is limited to max unless max is zero or less in
+// which case all matching locations are returned. Unlike Get the order of
+// paths for map elements is sorted by key so results are repeatable.
+func (x Expr) Locate(data any, max int) (locs []Expr) {
+	if 0 < len(x) {
+		pp := make(Expr, 1, 16)
+		pp[0] = Root('$')
+		locs = locate(pp, data, data, x, max, locs)
+	}
+	return
+}
+
+func locate(pp Expr, data, root any, rest Expr, max int, locs []Expr) []Expr {
+	if len(rest) == 0 {
+		return append(locs, append(Expr{}, pp...))
+	}
+	switch tf := rest[0].(type) {
+	case Root, At, Bracket:
+		locs = locate(pp, data, root, rest[1:], max, locs)
+	case Child:
+		if v, has := locateChild(data, string(tf)); has {
+			locs = locate(append(pp, tf), v, root, rest[1:], max, locs)
+		}
+	case Nth:
+		if v, i, has := locateNth(data, int(tf)); has {
+			locs = locate(append(pp, Nth(i)), v, root, rest[1:], max, locs)
+		}
+	case Wildcard:
+		frags, values := locateChildren(data)
+		for i, f := range frags {
+			if 0 < max && max <= len(locs) {
+				break
+			}
+			locs = locate(append(pp, f), values[i], root, rest[1:], max, locs)
+		}
+	case Descent:
+		// The current node is considered first and then each of the children
+		// with the descent fragment still in place.
+		locs = locate(pp, data, root, rest[1:], max, locs)
+		frags, values := locateChildren(data)
+		for i, f := range frags {
+			if 0 < max && max <= len(locs) {
+				break
+			}
+			locs = locate(append(pp, f), values[i], root, rest, max, locs)
+		}
+	case Union:
+		for _, u := range tf {
+			if 0 < max && max <= len(locs) {
+				break
+			}
+			switch tu := u.(type) {
+			case string:
+				if v, has := locateChild(data, tu); has {
+					locs = locate(append(pp, Child(tu)), v, root, rest[1:], max, locs)
+				}
+			case int64:
+				if v, i, has := locateNth(data, int(tu)); has {
+					locs = locate(append(pp, Nth(i)), v, root, rest[1:], max, locs)
+				}
+			}
+		}
+	case Slice:
+		size := locateSize(data)
+		if size < 0 {
+			break
+		}
+		for _, i := range tf.indexes(size) {
+			if 0 < max && max <= len(locs) {
+				break
+			}
+			if v, _, has := locateNth(data, i); has {
+				locs = locate(append(pp, Nth(i)), v, root, rest[1:], max, locs)
+			}
+		}
+	case *Filter:
+		frags, values := locateChildren(data)
+		for i, f := range frags {
+			if 0 < max && max <= len(locs) {
+				break
+			}
+			if tf.matchWithRoot(values[i], root) {
+				locs = locate(append(pp, f), values[i], root, rest[1:], max, locs)
+			}
+		}
+	}
+	if 0 < max && max < len(locs) {
+		locs = locs[:max]
+	}
+	return locs
+}
+
+// indexes returns the indexes selected by the slice in the order they are
+// selected for an array of the provided size.
+func (f Slice) indexes(size int) (indexes []int) {
+	start := 0
+	end := maxEnd
+	step := 1
+	if 0 < len(f) {
+		start = f[0]
+	}
+	if 1 < len(f) {
+		end = f[1]
+	}
+	if 2 < len(f) {
+		step = f[2]
+		if step == 0 {
+			return
+		}
+	}
+	if start < 0 {
+		start = size + start
+		if start < 0 {
+			start = 0
+		}
+	}
+	if end < 0 {
+		end = size + end
+	}
+	if size <= start {
+		return
+	}
+	if size < end {
+		end = size
+	}
+	if 0 < step {
+		for i := start; i < end; i += step {
+			indexes = append(indexes, i)
+		}
+	} else {
+		if end < -1 {
+			end = -1
+		}
+		for i := start; end < i; i += step {
+			indexes = append(indexes, i)
+		}
+	}
+	return
+}
+
+func (f *Filter) matchWithRoot(data, root any) bool {
+	var stack []any
+	if node, ok := data.(gen.Node); ok {
+		stack, _ = f.Script.EvalWithRoot(stack, gen.Array{node}, root).([]any)
+	} else {
+		stack, _ = f.Script.EvalWithRoot(stack, []any{data}, root).([]any)
+	}
+	return 0 < len(stack)
+}
+
+func locateChild(data any, key string) (v any, has bool) {
+	switch td := data.(type) {
+	case nil:
+	case map[string]any:
+		v, has = td[key]
+	case gen.Object:
+		v, has = td[key]
+	default:
+		var x Expr
+		v, has = x.reflectGetChild(data, key)
+	}
+	return
+}
+
+// locateNth returns the value at the index along with the non-negative
+// version of the index.
+func locateNth(data any, i int) (v any, index int, has bool) {
+	size := locateSize(data)
+	if i < 0 {
+		i = size + i
+	}
+	if 0 <= i && i < size {
+		switch td := data.(type) {
+		case []any:
+			v = td[i]
+			has = true
+		case gen.Array:
+			v = td[i]
+			has = true
+		default:
+			var x Expr
+			v, has = x.reflectGetNth(data, i)
+		}
+	}
+	return v, i, has
+}
+
+// locateSize returns the length of an array or -1 if the data is not an
+// array.
+func locateSize(data any) int {
+	switch td := data.(type) {
+	case nil:
+	case []any:
+		return len(td)
+	case gen.Array:
+		return len(td)
+	default:
+		if !isNil(data) {
+			rv := reflect.ValueOf(data)
+			switch rv.Kind() {
+			case reflect.Slice, reflect.Array:
+				return rv.Len()
+			}
+		}
+	}
+	return -1
+}
+
+// locateChildren returns the fragments and values of the members of the data
+// in a predictable order. Map keys are sorted, array elements are in index
+// order, and struct fields and gen.Ordered members are in the order they are
+// declared.
+func locateChildren(data any) (frags []Frag, values []any) {
+	switch td := data.(type) {
+	case nil, bool, int64, float64, string, []byte:
+		// leaf node
+	case []any:
+		for i, v := range td {
+			frags = append(frags, Nth(i))
+			values = append(values, v)
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(td))
+		for k := range td {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			frags = append(frags, Child(k))
+			values = append(values, td[k])
+		}
+	case gen.Array:
+		for i, v := range td {
+			frags = append(frags, Nth(i))
+			values = append(values, v)
+		}
+	case gen.Object:
+		keys := make([]string, 0, len(td))
+		for k := range td {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			frags = append(frags, Child(k))
+			values = append(values, td[k])
+		}
+	case *gen.Ordered:
+		for _, m := range td.Members {
+			frags = append(frags, Child(m.Key))
+			values = append(values, m.Value)
+		}
+	case gen.Node:
+		// leaf node
+	default:
+		if isNil(data) {
+			break
+		}
+		rv := reflect.ValueOf(data)
+		if rv.Kind() == reflect.Ptr {
+			rv = rv.Elem()
+		}
+		switch rv.Kind() {
+		case reflect.Struct:
+			rt := rv.Type()
+			for i := 0; i < rv.NumField(); i++ {
+				fv := rv.Field(i)
+				if fv.CanInterface() {
+					frags = append(frags, Child(rt.Field(i).Name))
+					values = append(values, fv.Interface())
+				}
+			}
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < rv.Len(); i++ {
+				iv := rv.Index(i)
+				if iv.CanInterface() {
+					frags = append(frags, Nth(i))
+					values = append(values, iv.Interface())
+				}
+			}
+		case reflect.Map:
+			if rv.Type().Key().Kind() != reflect.String {
+				break
+			}
+			keys := rv.MapKeys()
+			sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+			for _, k := range keys {
+				mv := rv.MapIndex(k)
+				if mv.CanInterface() {
+					frags = append(frags, Child(k.String()))
+					values = append(values, mv.Interface())
+				}
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package jp_test
+
+import (
+	"testing"
+
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/sen"
+	"github.com/ohler55/ojg/tt"
+)
+
+type locateData struct {
+	path   string
+	max    int
+	expect []string
+}
+
+var locateTestData = []*locateData{
+	{path: "", expect: []string{}},
+	{path: "$", expect: []string{"$"}},
+	{path: "@.a", expect: []string{"$.a"}},
+	{path: "$.a.b", expect: []string{"$.a.b"}},
+	{path: "$.a.x", expect: []string{}},
+	{path: "$.c[1]", expect: []string{"$.c[1]"}},
+	{path: "$.c[-1]", expect: []string{"$.c[2]"}},
+	{path: "$.c[5]", expect: []string{}},
+	{path: "$.a.*", expect: []string{"$.a.b", "$.a.c"}},
+	{path: "$.c[*]", expect: []string{"$.c[0]", "$.c[1]", "$.c[2]"}},
+	{path: "$.c[*]", max: 2, expect: []string{"$.c[0]", "$.c[1]"}},
+	{path: "$..b", expect: []string{"$.a.b", "$.c[1].b"}},
+	{path: "$..b", max: 1, expect: []string{"$.a.b"}},
+	{path: "$.c..", expect: []string{"$.c", "$.c[0]", "$.c[1]", "$.c[1].b", "$.c[2]"}},
+	{path: "$.c[0,-1,7]", expect: []string{"$.c[0]", "$.c[2]"}},
+	{path: "$['a','x','c']", expect: []string{"$.a", "$.c"}},
+	{path: "$.c[1:]", expect: []string{"$.c[1]", "$.c[2]"}},
+	{path: "$.c[2:0:-1]", expect: []string{"$.c[2]", "$.c[1]"}},
+	{path: "$.c[-2:-1]", expect: []string{"$.c[1]"}},
+	{path: "$.c[?(@.b == 2)]", expect: []string{"$.c[1]"}},
+	{path: "$.c[?(@ > 2)]", expect: []string{"$.c[2]"}},
+	{path: "$.a[?(@ == $.c[0])]", expect: []string{"$.a.b", "$.a.c"}},
+	{path: "$..[?(@.b exists true)].b", expect: []string{"$.a.b", "$.c[1].b"}},
+}
+
+func locateTestSource() any {
+	return map[string]any{
+		"a": map[string]any{"b": 1, "c": 1},
+		"c": []any{1, map[string]any{"b": 2}, 3},
+	}
+}
+
+func TestExprLocate(t *testing.T) {
+	for i, d := range locateTestData {
+		x := jp.MustParseString(d.path)
+		locs := x.Locate(locateTestSource(), d.max)
+		result := make([]string, len(locs))
+		for j, loc := range locs {
+			result[j] = loc.String()
+		}
+		tt.Equal(t, d.expect, result, i, ": ", d.path)
+	}
+}
+
+func TestExprLocateNode(t *testing.T) {
+	data := alt.Generify(locateTestSource())
+	for i, d := range locateTestData {
+		x := jp.MustParseString(d.path)
+		locs := x.Locate(data, d.max)
+		result := make([]string, len(locs))
+		for j, loc := range locs {
+			result[j] = loc.String()
+		}
+		tt.Equal(t, d.expect, result, i, ": ", d.path)
+	}
+}
+
+func TestExprLocateReflect(t *testing.T) {
+	data := []any{
+		&Sample{A: 1, B: "one"},
+		Sample{A: 2, B: "two"},
+		[]*One{{A: 3}, {A: 4}},
+		map[string]int{"x": 5, "y": 6},
+	}
+	for i, d := range []*locateData{
+		{path: "$[0].a", expect: []string{"$[0].a"}},
+		{path: "$[1].*", expect: []string{"$[1].A", "$[1].B"}},
+		{path: "$[2][-1].a", expect: []string{"$[2][1].a"}},
+		{path: "$[2][:1]", expect: []string{"$[2][0]"}},
+		{path: "$[3].*", expect: []string{"$[3].x", "$[3].y"}},
+		{path: "$..A", expect: []string{"$[0].A", "$[1].A", "$[2][0].A", "$[2][1].A"}},
+		{path: "$[?(@.a == 2)]", expect: []string{"$[1]"}},
+	} {
+		x := jp.MustParseString(d.path)
+		locs := x.Locate(data, d.max)
+		result := make([]string, len(locs))
+		for j, loc := range locs {
+			result[j] = loc.String()
+		}
+		tt.Equal(t, d.expect, result, i, ": ", d.path)
+	}
+}
+
+func TestExprLocateGet(t *testing.T) {
+	data := locateTestSource()
+	x := jp.MustParseString("$..[?(@ == 1)]")
+	locs := x.Locate(data, 0)
+	paths := make([]string, len(locs))
+	for i, loc := range locs {
+		paths[i] = loc.String()
+		tt.Equal(t, 1, loc.First(data), paths[i])
+	}
+	tt.Equal(t, `[$.a.b $.a.c "$.c[0]"]`, string(sen.Bytes(paths)))
+}
@@ -125,7 +125,7 @@ done:
 						}
 					} else {
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							kind := reflect.Invalid
@@ -150,7 +150,7 @@ done:
 						}
 					} else {
 						switch v.(type) {
-						case gen.Object, gen.Array:
+						case gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						}
 					}
@@ -166,7 +166,7 @@ done:
 						}
 					} else {
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							kind := reflect.Invalid
@@ -199,7 +199,7 @@ done:
 					} else {
 						v = tv[i]
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							kind := reflect.Invalid
@@ -228,7 +228,7 @@ done:
 					} else {
 						v = tv[i]
 						switch v.(type) {
-						case gen.Object, gen.Array:
+						case gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						}
 					}
@@ -245,7 +245,7 @@ done:
 						}
 					} else {
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							kind := reflect.Invalid
@@ -276,7 +276,7 @@ done:
 				} else {
 					for _, v = range tv {
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							kind := reflect.Invalid
@@ -303,7 +303,7 @@ done:
 				} else {
 					for _, v = range tv {
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							kind := reflect.Invalid
@@ -331,7 +331,7 @@ done:
 				} else {
 					for _, v = range tv {
 						switch v.(type) {
-						case gen.Object, gen.Array:
+						case gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						}
 					}
@@ -349,11 +349,29 @@ done:
 				} else {
 					for _, v = range tv {
 						switch v.(type) {
-						case gen.Object, gen.Array:
+						case gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						}
 					}
 				}
+			case *gen.Ordered:
+				if int(fi) == len(wx)-1 { // last one
+					for i, m := range tv.Members {
+						if nv, changed := modifier(m.Value); changed {
+							tv.Members[i].Value = nv
+							if one && changed {
+								break done
+							}
+						}
+					}
+				} else {
+					for _, m := range tv.Members {
+						switch m.Value.(type) {
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
+							stack = append(stack, m.Value)
+						}
+					}
+				}
 			default:
 				if int(fi) == len(wx)-1 { // last one
 					rv := reflect.ValueOf(tv)
@@ -387,7 +405,7 @@ done:
 				} else {
 					for _, v := range wx.reflectGetWild(tv) {
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							kind := reflect.Invalid
@@ -419,7 +437,7 @@ done:
 								}
 							} else {
 								switch v.(type) {
-								case map[string]any, []any, gen.Object, gen.Array:
+								case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 									stack = append(stack, v)
 								default:
 									kind := reflect.Invalid
@@ -444,7 +462,7 @@ done:
 								}
 							} else {
 								switch v.(type) {
-								case gen.Object, gen.Array:
+								case gen.Object, gen.Array, *gen.Ordered:
 									stack = append(stack, v)
 								}
 							}
@@ -461,7 +479,7 @@ done:
 								}
 							} else {
 								switch v.(type) {
-								case map[string]any, []any, gen.Object, gen.Array:
+								case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 									stack = append(stack, v)
 								default:
 									kind := reflect.Invalid
@@ -494,7 +512,7 @@ done:
 								}
 							} else {
 								switch v.(type) {
-								case map[string]any, []any, gen.Object, gen.Array:
+								case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 									stack = append(stack, v)
 								default:
 									kind := reflect.Invalid
@@ -523,7 +541,7 @@ done:
 							} else {
 								v = tv[i]
 								switch v.(type) {
-								case gen.Object, gen.Array:
+								case gen.Object, gen.Array, *gen.Ordered:
 									stack = append(stack, v)
 								}
 							}
@@ -550,7 +568,7 @@ done:
 							var has bool
 							if v, has = wx.reflectGetNth(tv, i); has {
 								switch v.(type) {
-								case map[string]any, []any, gen.Object, gen.Array:
+								case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 									stack = append(stack, v)
 								default:
 									kind := reflect.Invalid
@@ -606,7 +624,7 @@ done:
 							}
 						} else {
 							switch v.(type) {
-							case map[string]any, []any, gen.Object, gen.Array:
+							case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 								stack = append(stack, v)
 							}
 						}
@@ -623,7 +641,7 @@ done:
 							}
 						} else {
 							switch v.(type) {
-							case map[string]any, []any, gen.Object, gen.Array:
+							case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 								stack = append(stack, v)
 							}
 						}
@@ -654,7 +672,7 @@ done:
 						} else {
 							v = tv[i]
 							switch v.(type) {
-							case gen.Object, gen.Array:
+							case gen.Object, gen.Array, *gen.Ordered:
 								stack = append(stack, v)
 							}
 						}
@@ -671,7 +689,7 @@ done:
 						} else {
 							v = tv[i]
 							switch v.(type) {
-							case gen.Object, gen.Array:
+							case gen.Object, gen.Array, *gen.Ordered:
 								stack = append(stack, v)
 							}
 						}
@@ -719,7 +737,7 @@ done:
 				} else {
 					for _, v := range wx.reflectGetSlice(tv, start, end, step) {
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							kind := reflect.Invalid
@@ -811,7 +829,7 @@ done:
 						switch v.(type) {
 						case nil, gen.Bool, gen.Int, gen.Float, gen.String,
 							bool, string, float64, float32, int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 							stack = append(stack, fi|descentChildFlag)
 						default:
@@ -834,7 +852,7 @@ done:
 						switch v.(type) {
 						case nil, gen.Bool, gen.Int, gen.Float, gen.String,
 							bool, string, float64, float32, int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 							stack = append(stack, fi|descentChildFlag)
 						default:
@@ -854,7 +872,7 @@ done:
 					stack = append(stack, di|descentFlag)
 					for _, v = range tv {
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 							stack = append(stack, fi|descentChildFlag)
 						}
@@ -866,7 +884,19 @@ done:
 					for i := len(tv) - 1; 0 <= i; i-- {
 						v = tv[i]
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
+							stack = append(stack, v)
+							stack = append(stack, fi|descentChildFlag)
+						}
+					}
+				case *gen.Ordered:
+					// Put prev back and slide fi.
+					stack[len(stack)-1] = prev
+					stack = append(stack, di|descentFlag)
+					for i := len(tv.Members) - 1; 0 <= i; i-- {
+						v = tv.Members[i].Value
+						switch v.(type) {
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 							stack = append(stack, fi|descentChildFlag)
 						}
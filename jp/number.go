@@ -0,0 +1,82 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package jp
+
+import (
+	"encoding"
+	"encoding/json"
+	"math/big"
+	"regexp"
+	"strconv"
+
+	"github.com/ohler55/ojg/gen"
+)
+
+// bigRat converts an arbitrary precision number into a *big.Rat so that
+// comparisons and arithmetic are exact. A json.Number, gen.Big, *big.Int,
+// *big.Float, or a decimal type that implements encoding.TextMarshaler are
+// converted. Nil is returned for any other type or if the value is not a
+// finite number.
+func bigRat(v any) *big.Rat {
+	switch tv := v.(type) {
+	case json.Number:
+		if r, ok := new(big.Rat).SetString(string(tv)); ok {
+			return r
+		}
+	case gen.Big:
+		if r, ok := new(big.Rat).SetString(string(tv)); ok {
+			return r
+		}
+	case *big.Int:
+		if tv != nil {
+			return new(big.Rat).SetInt(tv)
+		}
+	case *big.Float:
+		if tv != nil && !tv.IsInf() {
+			r, _ := tv.Rat(nil)
+			return r
+		}
+	case string, bool, int64, float64, nil, *regexp.Regexp:
+		// The common types and regular expressions are never arbitrary
+		// precision numbers.
+	case encoding.TextMarshaler:
+		if text, err := tv.MarshalText(); err == nil && 0 < len(text) && (text[0] == '-' || ('0' <= text[0] && text[0] <= '9')) {
+			if r, ok := new(big.Rat).SetString(string(text)); ok {
+				return r
+			}
+		}
+	}
+	return nil
+}
+
+// ratPair returns the left and right values as *big.Rat if either is a
+// *big.Rat and the other is a number. A float64 is converted using its
+// shortest decimal representation so that 0.1 is equal to a decimal 0.1.
+func ratPair(left, right any) (lr, rr *big.Rat) {
+	_, lok := left.(*big.Rat)
+	_, rok := right.(*big.Rat)
+	if !lok && !rok {
+		return nil, nil
+	}
+	if lr = asRat(left); lr == nil {
+		return nil, nil
+	}
+	if rr = asRat(right); rr == nil {
+		return nil, nil
+	}
+	return
+}
+
+func asRat(v any) *big.Rat {
+	switch tv := v.(type) {
+	case *big.Rat:
+		return tv
+	case int64:
+		return new(big.Rat).SetInt64(tv)
+	case float64:
+		if r, ok := new(big.Rat).SetString(strconv.FormatFloat(tv, 'g', -1, 64)); ok {
+			return r
+		}
+	}
+	return nil
+}
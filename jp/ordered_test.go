@@ -0,0 +1,70 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package jp_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+const orderedJSON = `{"z":1,"a":{"y":[1,2],"b":3},"m":{"q":4}}`
+
+func parseOrdered(t *testing.T, src string) any {
+	p := oj.Parser{Ordered: true}
+	data, err := p.Parse([]byte(src))
+	tt.Nil(t, err)
+	return data
+}
+
+func TestOrderedGet(t *testing.T) {
+	data := parseOrdered(t, orderedJSON)
+	for i, d := range []struct {
+		path   string
+		expect string
+	}{
+		{path: "$.a.b", expect: `[3]`},
+		{path: "$.*", expect: `[1,{"y":[1,2],"b":3},{"q":4}]`},
+		{path: "$.a.y[1]", expect: `[2]`},
+		{path: "$..q", expect: `[4]`},
+		{path: "$['m','z']", expect: `[{"q":4},1]`},
+		{path: "$[?(@.q == 4)]", expect: `[{"q":4}]`},
+		{path: "$[?(length(@.y) == 2)].b", expect: `[3]`},
+	} {
+		tt.Equal(t, d.expect, oj.JSON(jp.MustParseString(d.path).Get(data)), i, ": ", d.path)
+	}
+	tt.Equal(t, int64(1), jp.MustParseString("$.*").First(data))
+	tt.Equal(t, int64(4), jp.MustParseString("$..q").First(data))
+	tt.Equal(t, true, jp.MustParseString("$.*.q").Has(data))
+	tt.Equal(t, false, jp.MustParseString("$.*.x").Has(data))
+
+	locs := jp.MustParseString("$.*").Locate(data, 0)
+	tt.Equal(t, "[$.z $.a $.m]", fmt.Sprint(locs))
+
+	gd := alt.Generify(data)
+	tt.Equal(t, gen.Int(3), jp.MustParseString("$.a.b").First(gd))
+}
+
+func TestOrderedSetRemove(t *testing.T) {
+	data := parseOrdered(t, orderedJSON)
+	jp.MustParseString("$.a.c.d").MustSet(data, true)
+	jp.MustParseString("$.a.e[1]").MustSet(data, "x")
+	jp.MustParseString("$.z").MustSet(data, 2)
+	tt.Equal(t, `{"z":2,"a":{"y":[1,2],"b":3,"c":{"d":true},"e":[null,"x"]},"m":{"q":4}}`, oj.JSON(data))
+
+	jp.MustParseString("$.a.b").MustRemove(data)
+	jp.MustParseString("$['z','m']").MustRemove(data)
+	tt.Equal(t, `{"a":{"y":[1,2],"c":{"d":true},"e":[null,"x"]}}`, oj.JSON(data))
+
+	jp.MustParseString("$.a[?(@.d == true)]").MustRemove(data)
+	jp.MustParseString("$.a.*").MustRemoveOne(data)
+	tt.Equal(t, `{"a":{"e":[null,"x"]}}`, oj.JSON(data))
+
+	jp.MustParseString("$..e[1]").MustModify(data, func(v any) (any, bool) { return "y", true })
+	tt.Equal(t, `{"a":{"e":[null,"y"]}}`, oj.JSON(data))
+}
@@ -4,7 +4,9 @@ package jp
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"regexp"
 	"strconv"
 
@@ -279,6 +281,9 @@ func (p *parser) readInt(b byte) (int, byte) {
 	return i, b
 }
 
+// readNum reads a number literal. An int64 or float64 is returned unless the
+// literal can not be held exactly in one, in which case a json.Number is
+// returned so comparisons with arbitrary precision values remain exact.
 func (p *parser) readNum(b byte) any {
 	var num []byte
 
@@ -312,8 +317,7 @@ func (p *parser) readNum(b byte) any {
 			}
 			b = p.buf[p.pos]
 		} else {
-			f, _ := strconv.ParseFloat(string(num), 64)
-			return f
+			return literalFloat(num)
 		}
 	case 'e', 'E':
 		p.pos++
@@ -325,6 +329,9 @@ func (p *parser) readNum(b byte) any {
 	default:
 		i, err := strconv.ParseInt(string(num), 10, 64)
 		if err != nil {
+			if ne, _ := err.(*strconv.NumError); ne != nil && ne.Err == strconv.ErrRange {
+				return json.Number(num)
+			}
 			p.raise(err.Error())
 		}
 		return i
@@ -344,7 +351,37 @@ func (p *parser) readNum(b byte) any {
 		num = append(num, b)
 		p.pos++
 	}
-	f, _ := strconv.ParseFloat(string(num), 64)
+	return literalFloat(num)
+}
+
+// literalFloat returns a float64 for a decimal number literal if the
+// float64 has the same value as the literal and a json.Number otherwise.
+func literalFloat(num []byte) any {
+	f, err := strconv.ParseFloat(string(num), 64)
+	if err != nil {
+		if ne, _ := err.(*strconv.NumError); ne != nil && ne.Err == strconv.ErrRange {
+			return json.Number(num)
+		}
+		return f
+	}
+	digits := 0
+	for _, b := range num {
+		if b == 'e' || b == 'E' {
+			break
+		}
+		if '0' <= b && b <= '9' && (0 < digits || b != '0') {
+			digits++
+		}
+	}
+	// Up to 15 significant digits always survive the conversion to a
+	// float64 and back.
+	if 15 < digits {
+		lit, _ := new(big.Rat).SetString(string(num))
+		fr, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+		if lit != nil && fr != nil && lit.Cmp(fr) != 0 {
+			return json.Number(num)
+		}
+	}
 	return f
 }
 
@@ -491,29 +528,33 @@ func (p *parser) readEquation() (eq *Equation) {
 	eq = &Equation{}
 
 	b := p.nextNonSpace()
-	switch b {
-	case '!':
-		eq.o = not
-		p.pos++
-		eq.left = p.readEqValue()
-		b := p.nextNonSpace()
-		if b != ')' {
-			p.raise("not terminated")
+	if fo := p.peekFunction(); fo != nil {
+		p.readFunc(fo, eq)
+	} else {
+		switch b {
+		case '!':
+			eq.o = not
+			p.pos++
+			eq.left = p.readEqValue()
+			b := p.nextNonSpace()
+			if b != ')' {
+				p.raise("not terminated")
+			}
+			p.pos++
+			return
+		case 'l':
+			p.readFunc(length, eq)
+		case 'c':
+			p.readFunc(count, eq)
+		case 'm':
+			p.readFunc(match, eq)
+		case 's':
+			p.readFunc(search, eq)
+		default:
+			eq.left = p.readEqValue()
+			eq.o = p.readEqOp()
+			eq.right = p.readEqValue()
 		}
-		p.pos++
-		return
-	case 'l':
-		p.readFunc(length, eq)
-	case 'c':
-		p.readFunc(count, eq)
-	case 'm':
-		p.readFunc(match, eq)
-	case 's':
-		p.readFunc(search, eq)
-	default:
-		eq.left = p.readEqValue()
-		eq.o = p.readEqOp()
-		eq.right = p.readEqValue()
 	}
 	for p.pos < len(p.buf) {
 		b = p.nextNonSpace()
@@ -538,6 +579,11 @@ func (p *parser) readEquation() (eq *Equation) {
 
 func (p *parser) readEqValue() (eq *Equation) {
 	b := p.nextNonSpace()
+	if fo := p.peekFunction(); fo != nil {
+		eq = &Equation{}
+		p.readFunc(fo, eq)
+		return
+	}
 	switch b {
 	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
 		var v any
@@ -611,6 +657,25 @@ func (p *parser) readFunc(o *op, eq *Equation) {
 	p.raise("expected a %s function", o.name)
 }
 
+// peekFunction returns the registered function op if the next token is the
+// name of a registered function followed by an open parenthesis. The parse
+// position is not changed.
+func (p *parser) peekFunction() *op {
+	end := p.pos
+	for ; end < len(p.buf); end++ {
+		b := p.buf[end]
+		if (b < 'a' || 'z' < b) && (b < 'A' || 'Z' < b) && (b < '0' || '9' < b) && b != '_' {
+			break
+		}
+	}
+	if p.pos < end && end < len(p.buf) && p.buf[end] == '(' {
+		if o := opMap[string(p.buf[p.pos:end])]; o != nil && o.code == fnCode {
+			return o
+		}
+	}
+	return nil
+}
+
 func (p *parser) readEqToken(token []byte) {
 	for _, t := range token {
 		if len(p.buf) <= p.pos || p.buf[p.pos] != t {
@@ -0,0 +1,211 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package jp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ohler55/ojg/alt"
+)
+
+// Pointer is an RFC 6901 JSON Pointer held as a list of unescaped reference
+// tokens. The empty Pointer refers to the whole document.
+type Pointer []string
+
+// ParsePointer parses a JSON Pointer such as /a/b~1c/0 where ~1 is an
+// escaped / and ~0 is an escaped ~.
+func ParsePointer(s string) (Pointer, error) {
+	if len(s) == 0 {
+		return Pointer{}, nil
+	}
+	if s[0] != '/' {
+		return nil, fmt.Errorf("%q is not a valid JSON Pointer, it must start with a /", s)
+	}
+	p := Pointer(strings.Split(s[1:], "/"))
+	for i, token := range p {
+		if strings.IndexByte(token, '~') < 0 {
+			continue
+		}
+		for j := 0; j < len(token); j++ {
+			if token[j] == '~' && (len(token) <= j+1 || (token[j+1] != '0' && token[j+1] != '1')) {
+				return nil, fmt.Errorf("%q is not a valid JSON Pointer, ~ must be followed by a 0 or 1", s)
+			}
+		}
+		p[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return p, nil
+}
+
+// MustParsePointer parses a JSON Pointer and panics on error.
+func MustParsePointer(s string) Pointer {
+	p, err := ParsePointer(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns the escaped JSON Pointer.
+func (p Pointer) String() string {
+	var b strings.Builder
+	for _, token := range p {
+		b.WriteByte('/')
+		if strings.ContainsAny(token, "~/") {
+			token = strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+		}
+		b.WriteString(token)
+	}
+	return b.String()
+}
+
+// Expr returns the Pointer as an Expr. Since a JSON Pointer does not
+// distinguish between array indexes and object keys, tokens that are valid
+// array indexes become Nth fragments and all others become Child
+// fragments. Use Resolve() to form an Expr that matches the data.
+func (p Pointer) Expr() Expr {
+	x := R()
+	for _, token := range p {
+		x = append(x, pointerFrag(token))
+	}
+	return x
+}
+
+// Resolve returns an Expr for the Pointer that follows the data. A token is
+// an Nth fragment if the value it is applied to is an array and a Child
+// fragment otherwise. Once the Pointer leads outside the data the remaining
+// tokens are converted as with Expr().
+func (p Pointer) Resolve(data any) Expr {
+	x := R()
+	for i, token := range p {
+		if size := locateSize(data); 0 <= size {
+			n, ok := pointerIndex(token)
+			if !ok || size <= n {
+				return append(x, p[i:].Expr()[1:]...)
+			}
+			x = append(x, Nth(n))
+			data, _, _ = locateNth(data, n)
+			continue
+		}
+		x = append(x, Child(token))
+		var has bool
+		if data, has = locateChild(data, token); !has {
+			return append(x, p[i+1:].Expr()[1:]...)
+		}
+	}
+	return x
+}
+
+// Path returns the Pointer as an alt.Path. Tokens that are valid array
+// indexes become int elements and all others string elements.
+func (p Pointer) Path() alt.Path {
+	path := make(alt.Path, 0, len(p))
+	for _, frag := range p.Expr()[1:] {
+		switch tf := frag.(type) {
+		case Nth:
+			path = append(path, int(tf))
+		case Child:
+			path = append(path, string(tf))
+		}
+	}
+	return path
+}
+
+// Get the value the Pointer refers to in the data and a flag indicating
+// the value was found.
+func (p Pointer) Get(data any) (any, bool) {
+	return p.Resolve(data).FirstFound(data)
+}
+
+// Set the value the Pointer refers to in the data using the same rules as
+// Expr.SetOne().
+func (p Pointer) Set(data, value any) error {
+	return p.Resolve(data).SetOne(data, value)
+}
+
+// Remove the value the Pointer refers to from the data using the same rules
+// as Expr.RemoveOne().
+func (p Pointer) Remove(data any) (any, error) {
+	return p.Resolve(data).RemoveOne(data)
+}
+
+// PathPointer converts an alt.Path to a Pointer.
+func PathPointer(path alt.Path) Pointer {
+	p := make(Pointer, 0, len(path))
+	for _, key := range path {
+		switch tk := key.(type) {
+		case string:
+			p = append(p, tk)
+		case int:
+			p = append(p, strconv.Itoa(tk))
+		}
+	}
+	return p
+}
+
+// PathExpr converts an alt.Path to an Expr that starts with a Root. A nil
+// path element becomes a Wildcard.
+func PathExpr(path alt.Path) Expr {
+	x := make(Expr, 1, len(path)+1)
+	x[0] = Root('$')
+	for _, key := range path {
+		switch tk := key.(type) {
+		case string:
+			x = append(x, Child(tk))
+		case int:
+			x = append(x, Nth(tk))
+		case nil:
+			x = append(x, Wildcard('*'))
+		}
+	}
+	return x
+}
+
+// Pointer converts the Expr to a JSON Pointer. Only expressions composed of
+// an optional leading root or @, child, non-negative nth, and bracket
+// fragments can be converted.
+func (x Expr) Pointer() (Pointer, error) {
+	p := Pointer{}
+	for i, frag := range x {
+		switch tf := frag.(type) {
+		case Root, At:
+			if i == 0 {
+				continue
+			}
+		case Bracket:
+			continue
+		case Child:
+			p = append(p, string(tf))
+			continue
+		case Nth:
+			if 0 <= tf {
+				p = append(p, strconv.Itoa(int(tf)))
+				continue
+			}
+		}
+		return nil, fmt.Errorf("%s can not be represented as a JSON Pointer", x)
+	}
+	return p, nil
+}
+
+// pointerFrag returns an Nth for tokens that are array indexes and a Child
+// for all others.
+func pointerFrag(token string) Frag {
+	if n, ok := pointerIndex(token); ok {
+		return Nth(n)
+	}
+	return Child(token)
+}
+
+// pointerIndex returns the array index for a token and true if the token is
+// a valid RFC 6901 array index, a non-negative integer without a sign or
+// leading zeros.
+func pointerIndex(token string) (int, bool) {
+	if 0 < len(token) && (token[0] != '0' || len(token) == 1) && token[0] != '+' && token[0] != '-' {
+		if n, err := strconv.Atoi(token); err == nil {
+			return n, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package jp_test
+
+import (
+	"testing"
+
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestPointerParse(t *testing.T) {
+	for i, d := range []struct {
+		src    string
+		tokens []string
+		expr   string
+	}{
+		{src: "", tokens: []string{}, expr: "$"},
+		{src: "/", tokens: []string{""}, expr: "$."},
+		{src: "/a/b", tokens: []string{"a", "b"}, expr: "$.a.b"},
+		{src: "/a~1b/c~0d/~01", tokens: []string{"a/b", "c~d", "~1"}, expr: "$['a/b'].c~d.~1"},
+		{src: "/x/0/10/01/-", tokens: []string{"x", "0", "10", "01", "-"}, expr: "$.x[0][10].01['-']"},
+	} {
+		p, err := jp.ParsePointer(d.src)
+		tt.Nil(t, err, i, ": ", d.src)
+		tt.Equal(t, d.tokens, []string(p), i, ": ", d.src)
+		tt.Equal(t, d.src, p.String(), i, ": ", d.src)
+		tt.Equal(t, d.expr, p.Expr().String(), i, ": ", d.src)
+
+		back, err := p.Expr().Pointer()
+		tt.Nil(t, err, i, ": ", d.src)
+		tt.Equal(t, d.src, back.String(), i, ": ", d.src)
+	}
+	for _, src := range []string{"a", "/a~", "/a~2"} {
+		_, err := jp.ParsePointer(src)
+		tt.NotNil(t, err, src)
+	}
+	tt.Panic(t, func() { _ = jp.MustParsePointer("x") })
+}
+
+func TestPointerExpr(t *testing.T) {
+	p, err := jp.MustParseString("@.a[2]['b/c']").Pointer()
+	tt.Nil(t, err)
+	tt.Equal(t, "/a/2/b~1c", p.String())
+
+	for _, src := range []string{"$.a[-1]", "$.a[*]", "$..a", "$.a[1:2]", "$.a[?(@.x == 1)]"} {
+		_, err = jp.MustParseString(src).Pointer()
+		tt.NotNil(t, err, src)
+	}
+}
+
+func TestPointerPath(t *testing.T) {
+	p := jp.MustParsePointer("/a/1/01")
+	tt.Equal(t, alt.Path{"a", 1, "01"}, p.Path())
+	tt.Equal(t, "/a/1/01", jp.PathPointer(alt.Path{"a", 1, "01"}).String())
+
+	data := oj.MustParseString(`{"a":[1,{"b":2}]}`)
+	for _, path := range alt.Diff(data, oj.MustParseString(`{"a":[1,{"b":3}]}`)) {
+		tt.Equal(t, "/a/1/b", jp.PathPointer(path).String())
+	}
+}
+
+func TestPathExpr(t *testing.T) {
+	tt.Equal(t, "$.a[1].*['b c']", jp.PathExpr(alt.Path{"a", 1, nil, "b c"}).String())
+	tt.Equal(t, "$", jp.PathExpr(nil).String())
+
+	data := oj.MustParseString(`{"a":[1,{"b":2}]}`)
+	tt.Equal(t, int64(2), jp.PathExpr(alt.Path{"a", 1, "b"}).First(data))
+}
+
+type pointerSample struct {
+	A []int
+	M map[string]any
+}
+
+func TestPointerGetSetRemove(t *testing.T) {
+	data := oj.MustParseString(`{"a":[1,{"0":"zero","b":2}],"c/d":3}`)
+	for i, d := range []struct {
+		ptr   string
+		value any
+		found bool
+	}{
+		{ptr: "", value: data, found: true},
+		{ptr: "/a/0", value: int64(1), found: true},
+		{ptr: "/a/1/0", value: "zero", found: true},
+		{ptr: "/a/1/b", value: int64(2), found: true},
+		{ptr: "/c~1d", value: int64(3), found: true},
+		{ptr: "/a/2"},
+		{ptr: "/a/-"},
+		{ptr: "/a/01"},
+		{ptr: "/a/+1"},
+		{ptr: "/a/-0"},
+		{ptr: "/x/y"},
+	} {
+		v, found := jp.MustParsePointer(d.ptr).Get(data)
+		tt.Equal(t, d.found, found, i, ": ", d.ptr)
+		tt.Equal(t, d.value, v, i, ": ", d.ptr)
+	}
+	err := jp.MustParsePointer("/a/1/0").Set(data, "cero")
+	tt.Nil(t, err)
+	err = jp.MustParsePointer("/x/y").Set(data, true)
+	tt.Nil(t, err)
+	// Tokens that are not valid array indexes do not match array elements.
+	err = jp.MustParsePointer("/a/01").Set(data, true)
+	tt.Nil(t, err)
+	_, err = jp.MustParsePointer("/a/+1").Remove(data)
+	tt.Nil(t, err)
+	tt.Equal(t, `{"a":[1,{"0":"cero","b":2}],"c/d":3,"x":{"y":true}}`, oj.JSON(data, &oj.Options{Sort: true}))
+	result, err := jp.MustParsePointer("/a/0").Remove(data)
+	tt.Nil(t, err)
+	tt.Equal(t, `{"a":[{"0":"cero","b":2}],"c/d":3,"x":{"y":true}}`, oj.JSON(result, &oj.Options{Sort: true}))
+
+	gd := alt.Generify(oj.MustParseString(`{"a":[1,{"0":"zero"}]}`))
+	v, found := jp.MustParsePointer("/a/1/0").Get(gd)
+	tt.Equal(t, true, found)
+	tt.Equal(t, gen.String("zero"), v)
+	err = jp.MustParsePointer("/a/0").Set(gd, gen.Int(7))
+	tt.Nil(t, err)
+	tt.Equal(t, `{"a":[7,{"0":"zero"}]}`, oj.JSON(gd, &oj.Options{Sort: true}))
+
+	sample := &pointerSample{A: []int{1, 2}, M: map[string]any{"k": "v"}}
+	v, found = jp.MustParsePointer("/A/1").Get(sample)
+	tt.Equal(t, true, found)
+	tt.Equal(t, 2, v)
+	err = jp.MustParsePointer("/A/0").Set(sample, 5)
+	tt.Nil(t, err)
+	tt.Equal(t, []int{5, 2}, sample.A)
+	v, found = jp.MustParsePointer("/M/k").Get(sample)
+	tt.Equal(t, true, found)
+	tt.Equal(t, "v", v)
+}
@@ -3,6 +3,8 @@
 package jp
 
 import (
+	"encoding/json"
+	"math/big"
 	"reflect"
 	"regexp"
 	"strconv"
@@ -79,6 +81,7 @@ type op struct {
 	code     byte
 	getLeft  bool
 	getRight bool
+	fn       any
 }
 
 type precBuf struct {
@@ -197,6 +200,13 @@ func (s *Script) EvalWithRoot(stack any, data, root any) any {
 			da = append(da, v)
 		}
 		data = da
+	case *gen.Ordered:
+		dlen = len(td.Members)
+		da := make([]any, 0, dlen)
+		for _, m := range td.Members {
+			da = append(da, m.Value)
+		}
+		data = da
 	default:
 		rv := reflect.ValueOf(td)
 		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
@@ -209,6 +219,7 @@ func (s *Script) EvalWithRoot(stack any, data, root any) any {
 		}
 		data = da
 	}
+	getArgs := s.getArgs()
 	sstack := make([]any, len(s.template))
 	var v any
 	for vi := dlen - 1; 0 <= vi; vi-- {
@@ -222,17 +233,17 @@ func (s *Script) EvalWithRoot(stack any, data, root any) any {
 		copy(sstack, s.template)
 		// resolve all expr members
 		for i, ev := range sstack {
-			if 0 < i {
-				if o, ok := sstack[i-1].(*op); ok && o.getLeft {
-					var x Expr
-					if x, ok = ev.(Expr); ok {
-						ev = x.Get(v)
+			if getArgs != nil && getArgs[i] {
+				if x, ok := ev.(Expr); ok {
+					if _, ok = x[0].(Root); ok {
+						ev = x.Get(root)
 					} else {
-						ev = nil
+						ev = x.Get(v)
 					}
-					sstack[i] = ev
+				} else {
+					ev = nil
 				}
-				// TBD one more for getRight once function extensions are supported
+				sstack[i] = ev
 			}
 			var has bool
 			// Normalize into nil, bool, int64, float64, and string early so
@@ -302,8 +313,12 @@ func (s *Script) EvalWithRoot(stack any, data, root any) any {
 				sstack[i] = float64(x)
 
 			default:
-				// Any other type are already simplified or are not
+				// Arbitrary precision numbers are converted to *big.Rat
+				// and any other type are already simplified or are not
 				// handled and will fail later.
+				if r := bigRat(x); r != nil {
+					sstack[i] = r
+				}
 			}
 		}
 		for i := len(sstack) - 1; 0 <= i; i-- {
@@ -325,6 +340,10 @@ func (s *Script) EvalWithRoot(stack any, data, root any) any {
 				if left == right {
 					sstack[i] = true
 				} else {
+					if lr, rr := ratPair(left, right); lr != nil {
+						sstack[i] = lr.Cmp(rr) == 0
+						break
+					}
 					sstack[i] = false
 					switch tl := left.(type) {
 					case int64:
@@ -340,6 +359,10 @@ func (s *Script) EvalWithRoot(stack any, data, root any) any {
 				if left == right {
 					sstack[i] = false
 				} else {
+					if lr, rr := ratPair(left, right); lr != nil {
+						sstack[i] = lr.Cmp(rr) != 0
+						break
+					}
 					sstack[i] = true
 					switch tl := left.(type) {
 					case int64:
@@ -353,6 +376,10 @@ func (s *Script) EvalWithRoot(stack any, data, root any) any {
 				}
 			case lt.code:
 				sstack[i] = false
+				if lr, rr := ratPair(left, right); lr != nil {
+					sstack[i] = lr.Cmp(rr) < 0
+					break
+				}
 				switch tl := left.(type) {
 				case int64:
 					switch tr := right.(type) {
@@ -374,6 +401,10 @@ func (s *Script) EvalWithRoot(stack any, data, root any) any {
 				}
 			case gt.code:
 				sstack[i] = false
+				if lr, rr := ratPair(left, right); lr != nil {
+					sstack[i] = lr.Cmp(rr) > 0
+					break
+				}
 				switch tl := left.(type) {
 				case int64:
 					switch tr := right.(type) {
@@ -395,6 +426,10 @@ func (s *Script) EvalWithRoot(stack any, data, root any) any {
 				}
 			case lte.code:
 				sstack[i] = false
+				if lr, rr := ratPair(left, right); lr != nil {
+					sstack[i] = lr.Cmp(rr) <= 0
+					break
+				}
 				switch tl := left.(type) {
 				case int64:
 					switch tr := right.(type) {
@@ -416,6 +451,10 @@ func (s *Script) EvalWithRoot(stack any, data, root any) any {
 				}
 			case gte.code:
 				sstack[i] = false
+				if lr, rr := ratPair(left, right); lr != nil {
+					sstack[i] = lr.Cmp(rr) >= 0
+					break
+				}
 				switch tl := left.(type) {
 				case int64:
 					switch tr := right.(type) {
@@ -450,6 +489,10 @@ func (s *Script) EvalWithRoot(stack any, data, root any) any {
 				sstack[i] = !lb
 			case add.code:
 				sstack[i] = nil
+				if lr, rr := ratPair(left, right); lr != nil {
+					sstack[i] = new(big.Rat).Add(lr, rr)
+					break
+				}
 				switch tl := left.(type) {
 				case int64:
 					switch tr := right.(type) {
@@ -472,6 +515,10 @@ func (s *Script) EvalWithRoot(stack any, data, root any) any {
 				}
 			case sub.code:
 				sstack[i] = nil
+				if lr, rr := ratPair(left, right); lr != nil {
+					sstack[i] = new(big.Rat).Sub(lr, rr)
+					break
+				}
 				switch tl := left.(type) {
 				case int64:
 					switch tr := right.(type) {
@@ -490,6 +537,10 @@ func (s *Script) EvalWithRoot(stack any, data, root any) any {
 				}
 			case mult.code:
 				sstack[i] = nil
+				if lr, rr := ratPair(left, right); lr != nil {
+					sstack[i] = new(big.Rat).Mul(lr, rr)
+					break
+				}
 				switch tl := left.(type) {
 				case int64:
 					switch tr := right.(type) {
@@ -508,6 +559,12 @@ func (s *Script) EvalWithRoot(stack any, data, root any) any {
 				}
 			case divide.code:
 				sstack[i] = nil
+				if lr, rr := ratPair(left, right); lr != nil {
+					if rr.Sign() != 0 {
+						sstack[i] = new(big.Rat).Quo(lr, rr)
+					}
+					break
+				}
 				switch tl := left.(type) {
 				case int64:
 					switch tr := right.(type) {
@@ -541,6 +598,10 @@ func (s *Script) EvalWithRoot(stack any, data, root any) any {
 							sstack[i] = true
 							break
 						}
+						if lr, rr := ratPair(left, ev); lr != nil && lr.Cmp(rr) == 0 {
+							sstack[i] = true
+							break
+						}
 					}
 				}
 			case empty.code:
@@ -553,6 +614,8 @@ func (s *Script) EvalWithRoot(stack any, data, root any) any {
 						sstack[i] = boo == (len(tl) == 0)
 					case map[string]any:
 						sstack[i] = boo == (len(tl) == 0)
+					case *gen.Ordered:
+						sstack[i] = boo == (len(tl.Members) == 0)
 					}
 				}
 			case has.code, exists.code:
@@ -583,6 +646,8 @@ func (s *Script) EvalWithRoot(stack any, data, root any) any {
 					sstack[i] = int64(len(tl))
 				case map[string]any:
 					sstack[i] = int64(len(tl))
+				case *gen.Ordered:
+					sstack[i] = int64(len(tl.Members))
 				}
 			case count.code:
 				sstack[i] = Nothing
@@ -613,6 +678,13 @@ func (s *Script) EvalWithRoot(stack any, data, root any) any {
 						}
 					}
 				}
+			case fnCode:
+				switch tf := o.fn.(type) {
+				case func(any) any:
+					sstack[i] = normalizeValue(tf(left))
+				case func(any, any) any:
+					sstack[i] = normalizeValue(tf(left, right))
+				}
 			}
 			if i+int(o.cnt)+1 <= len(sstack) {
 				copy(sstack[i+1:], sstack[i+int(o.cnt)+1:])
@@ -637,6 +709,42 @@ func (s *Script) EvalWithRoot(stack any, data, root any) any {
 	return stack
 }
 
+// getArgs returns a slice of flags that indicate which members of the
+// template are arguments that should be evaluated with a Get instead of the
+// usual first value. If there are no such arguments nil is returned.
+func (s *Script) getArgs() (flags []bool) {
+	for i, v := range s.template {
+		if o, ok := v.(*op); ok && (o.getLeft || o.getRight) {
+			if flags == nil {
+				flags = make([]bool, len(s.template))
+			}
+			if o.getLeft && i+1 < len(flags) {
+				flags[i+1] = true
+			}
+			if o.getRight && 1 < o.cnt {
+				if ri := i + 1 + argLen(s.template[i+1:]); ri < len(flags) {
+					flags[ri] = true
+				}
+			}
+		}
+	}
+	return
+}
+
+// argLen returns the number of template members that make up the argument
+// at the start of the template.
+func argLen(st []any) (n int) {
+	if 0 < len(st) {
+		n = 1
+		if o, ok := st[0].(*op); ok {
+			for i := byte(0); i < o.cnt; i++ {
+				n += argLen(st[n:])
+			}
+		}
+	}
+	return
+}
+
 // Inspect the script.
 func (s *Script) Inspect() *Form {
 	f, _ := nextForm(s.template)
@@ -652,7 +760,9 @@ func nextForm(st []any) (any, []any) {
 		if ov, ok := v.(*op); ok {
 			f := Form{Op: ov.name}
 			f.Left, st = nextForm(st)
-			f.Right, st = nextForm(st)
+			if 1 < ov.cnt {
+				f.Right, st = nextForm(st)
+			}
 			v = &f
 		}
 	}
@@ -677,6 +787,15 @@ func (s *Script) appendOp(o *op, left, right any) (pb *precBuf) {
 		pb.buf = append(pb.buf, ',', ' ')
 		pb.buf = s.appendValue(pb.buf, right, o.prec)
 		pb.buf = append(pb.buf, ')')
+	case fnCode:
+		pb.buf = append(pb.buf, o.name...)
+		pb.buf = append(pb.buf, '(')
+		pb.buf = s.appendValue(pb.buf, left, o.prec)
+		if 1 < o.cnt {
+			pb.buf = append(pb.buf, ',', ' ')
+			pb.buf = s.appendValue(pb.buf, right, o.prec)
+		}
+		pb.buf = append(pb.buf, ')')
 	default:
 		pb.buf = s.appendValue(pb.buf, left, o.prec)
 		pb.buf = append(pb.buf, ' ')
@@ -701,6 +820,8 @@ func (s *Script) appendValue(buf []byte, v any, prec byte) []byte {
 		buf = append(buf, strconv.FormatInt(tv, 10)...)
 	case float64:
 		buf = append(buf, strconv.FormatFloat(tv, 'g', -1, 64)...)
+	case json.Number:
+		buf = append(buf, tv...)
 	case bool:
 		if tv {
 			buf = append(buf, "true"...)
@@ -5,6 +5,7 @@ package jp_test
 
 import (
 	"fmt"
+	"math/big"
 	"testing"
 
 	"github.com/ohler55/ojg/gen"
@@ -358,4 +359,46 @@ func BenchmarkOjScriptDev(b *testing.B) {
 		stack = stack[:0]
 		stack, _ = s.Eval(stack, data).([]any)
 	}
-}
\ No newline at end of file
+}
+
+func TestScriptBigNumbers(t *testing.T) {
+	p := oj.Parser{NumConv: gen.JSONNumber}
+	data, err := p.Parse([]byte(`[
+  {"id":1,"price":0.10,"tax":0.20,"qty":12345678901234567890123},
+  {"id":2,"price":19.99,"tax":1.6,"qty":3},
+  {"id":3,"price":20,"tax":0,"qty":0.5}
+]`))
+	tt.Nil(t, err)
+	for i, d := range []struct {
+		path   string
+		expect string
+	}{
+		{path: "$[?(@.price == 0.1)].id", expect: "[1]"},
+		{path: "$[?(@.price + @.tax == 0.3)].id", expect: "[1]"},
+		{path: "$[?(@.price != 0.1)].id", expect: "[2,3]"},
+		{path: "$[?(@.price < 20)].id", expect: "[1,2]"},
+		{path: "$[?(@.price <= 20)].id", expect: "[1,2,3]"},
+		{path: "$[?(@.price > 19.99)].id", expect: "[3]"},
+		{path: "$[?(@.price >= 19.99)].id", expect: "[2,3]"},
+		{path: "$[?(@.qty > 1.2e22)].id", expect: "[1]"},
+		{path: "$[?(@.price * @.qty == 59.97)].id", expect: "[2]"},
+		{path: "$[?(@.price - @.tax == 18.39)].id", expect: "[2]"},
+		{path: "$[?(@.price / @.qty == 40)].id", expect: "[3]"},
+		{path: "$[?(@.price / @.tax == 0.5)].id", expect: "[1]"},
+		{path: "$[?(@.qty in [3, 0.5])].id", expect: "[2,3]"},
+		{path: "$[?(@.qty == 12345678901234567890123)].id", expect: "[1]"},
+		{path: "$[?(@.qty > 12345678901234567890122)].id", expect: "[1]"},
+		{path: "$[?(@.qty < 12345678901234567890124)].id", expect: "[1,2,3]"},
+		{path: "$[?(@.qty < -12345678901234567890124)].id", expect: "[]"},
+		{path: "$[?(@.qty > 12345678901234567890.1225)].id", expect: "[1]"},
+		{path: "$[?(@.price < 1e400)].id", expect: "[1,2,3]"},
+	} {
+		tt.Equal(t, d.expect, oj.JSON(jp.MustParseString(d.path).Get(data)), i, ": ", d.path)
+	}
+	x := jp.MustParseString("$[?(@.qty > 12345678901234567890122 && @.price < 0.10000000000000000001)]")
+	tt.Equal(t, "$[?(@.qty > 12345678901234567890122 && @.price < 0.10000000000000000001)]", x.String())
+	tt.Equal(t, "[1]", oj.JSON(x.C("id").Get(data)))
+
+	data = []any{big.NewInt(7), new(big.Float).SetFloat64(7.5), gen.Big("8")}
+	tt.Equal(t, "[7,8]", oj.JSON(jp.MustParseString("$[?(@ == 7 || @ == 8)]").Get(data)))
+}
@@ -80,6 +80,11 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 	var v any
 	var nv gen.Node
 	_, isNode := data.(gen.Node)
+	if _, ok := data.(*gen.Ordered); ok {
+		// An Ordered can hold either simple values or Nodes. Values are set
+		// as provided.
+		isNode = false
+	}
 	nodeValue, ok := value.(gen.Node)
 	if isNode && !ok {
 		if value != nil {
@@ -128,7 +133,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 					case nil, gen.Bool, gen.Int, gen.Float, gen.String,
 						bool, string, float64, float32, int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
 						return fmt.Errorf("can not follow a %T at '%s'", v, x[:fi+1])
-					case map[string]any, []any, gen.Object, gen.Array:
+					case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 						stack = append(stack, v)
 					default:
 						kind := reflect.Invalid
@@ -171,7 +176,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 					}
 				} else if v, has = tv[string(tf)]; has {
 					switch v.(type) {
-					case gen.Object, gen.Array:
+					case gen.Object, gen.Array, *gen.Ordered:
 						stack = append(stack, v)
 					default:
 						return fmt.Errorf("can not follow a %T at '%s'", v, x[:fi+1])
@@ -193,6 +198,47 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 						return fmt.Errorf("can not deduce what element to add at '%s'", x[:fi+1])
 					}
 				}
+			case *gen.Ordered:
+				if int(fi) == len(x)-1 { // last one
+					switch {
+					case value == delFlag:
+						tv.Remove(string(tf))
+					case isNode:
+						tv.Set(string(tf), nodeValue)
+					default:
+						tv.Set(string(tf), value)
+					}
+					if one {
+						return nil
+					}
+				} else if v, has = tv.Get(string(tf)); has {
+					switch v.(type) {
+					case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
+						stack = append(stack, v)
+					default:
+						return fmt.Errorf("can not follow a %T at '%s'", v, x[:fi+1])
+					}
+				} else if value != delFlag {
+					switch tc := x[fi+1].(type) {
+					case Child:
+						v = &gen.Ordered{}
+						tv.Set(string(tf), v)
+						stack = append(stack, v)
+					case Nth:
+						if int(tc) < 0 {
+							return fmt.Errorf("can not deduce the length of the array to add at '%s'", x[:fi+1])
+						}
+						if isNode {
+							v = make(gen.Array, int(tc)+1)
+						} else {
+							v = make([]any, int(tc)+1)
+						}
+						tv.Set(string(tf), v)
+						stack = append(stack, v)
+					default:
+						return fmt.Errorf("can not deduce what element to add at '%s'", x[:fi+1])
+					}
+				}
 			default:
 				if int(fi) == len(x)-1 { // last one
 					if value != delFlag {
@@ -205,7 +251,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 					case nil, gen.Bool, gen.Int, gen.Float, gen.String,
 						bool, string, float64, float32, int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
 						return fmt.Errorf("can not follow a %T at '%s'", v, x[:fi+1])
-					case map[string]any, []any, gen.Object, gen.Array:
+					case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 						stack = append(stack, v)
 					default:
 						kind := reflect.Invalid
@@ -244,7 +290,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 						case bool, string, float64, float32, int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64,
 							nil, gen.Bool, gen.Int, gen.Float, gen.String:
 							return fmt.Errorf("can not follow a %T at '%s'", v, x[:fi+1])
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							kind := reflect.Invalid
@@ -279,7 +325,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 					} else {
 						v = tv[i]
 						switch v.(type) {
-						case gen.Object, gen.Array:
+						case gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							return fmt.Errorf("can not follow a %T at '%s'", v, x[:fi+1])
@@ -301,7 +347,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 					case bool, string, float64, float32, int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64,
 						nil, gen.Bool, gen.Int, gen.Float, gen.String:
 						return fmt.Errorf("can not follow a %T at '%s'", v, x[:fi+1])
-					case map[string]any, []any, gen.Object, gen.Array:
+					case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 						stack = append(stack, v)
 					default:
 						kind := reflect.Invalid
@@ -342,7 +388,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 						switch v.(type) {
 						case nil, gen.Bool, gen.Int, gen.Float, gen.String,
 							bool, string, float64, float32, int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							kind := reflect.Invalid
@@ -377,7 +423,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 						switch v.(type) {
 						case nil, gen.Bool, gen.Int, gen.Float, gen.String,
 							bool, string, float64, float32, int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							kind := reflect.Invalid
@@ -412,7 +458,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 				} else {
 					for _, v = range tv {
 						switch v.(type) {
-						case gen.Object, gen.Array:
+						case gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						}
 					}
@@ -436,7 +482,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 					for i := len(tv) - 1; 0 <= i; i-- {
 						v = tv[i]
 						switch v.(type) {
-						case gen.Object, gen.Array:
+						case gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						}
 					}
@@ -455,7 +501,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 						switch v.(type) {
 						case nil, gen.Bool, gen.Int, gen.Float, gen.String,
 							bool, string, float64, float32, int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							kind := reflect.Invalid
@@ -483,7 +529,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 						switch v.(type) {
 						case nil, gen.Bool, gen.Int, gen.Float, gen.String,
 							bool, string, float64, float32, int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 							stack = append(stack, fi|descentChildFlag)
 						default:
@@ -506,7 +552,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 						switch v.(type) {
 						case nil, gen.Bool, gen.Int, gen.Float, gen.String,
 							bool, string, float64, float32, int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 							stack = append(stack, fi|descentChildFlag)
 						default:
@@ -526,7 +572,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 					stack = append(stack, di|descentFlag)
 					for _, v = range tv {
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 							stack = append(stack, fi|descentChildFlag)
 						}
@@ -538,7 +584,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 					for i := len(tv) - 1; 0 <= i; i-- {
 						v = tv[i]
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 							stack = append(stack, fi|descentChildFlag)
 						}
@@ -567,7 +613,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 							switch v.(type) {
 							case nil, gen.Bool, gen.Int, gen.Float, gen.String,
 								bool, string, float64, float32, int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-							case map[string]any, []any, gen.Object, gen.Array:
+							case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 								stack = append(stack, v)
 							default:
 								kind := reflect.Invalid
@@ -592,7 +638,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 							}
 						} else if v, has = tv[tu]; has {
 							switch v.(type) {
-							case map[string]any, []any, gen.Object, gen.Array:
+							case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 								stack = append(stack, v)
 							}
 						}
@@ -609,7 +655,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 							switch v.(type) {
 							case nil, gen.Bool, gen.Int, gen.Float, gen.String,
 								bool, string, float64, float32, int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-							case map[string]any, []any, gen.Object, gen.Array:
+							case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 								stack = append(stack, v)
 							default:
 								kind := reflect.Invalid
@@ -645,7 +691,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 								switch v.(type) {
 								case nil, gen.Bool, gen.Int, gen.Float, gen.String,
 									bool, string, float64, float32, int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-								case map[string]any, []any, gen.Object, gen.Array:
+								case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 									stack = append(stack, v)
 								default:
 									kind := reflect.Invalid
@@ -677,7 +723,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 							}
 						} else {
 							switch v.(type) {
-							case map[string]any, []any, gen.Object, gen.Array:
+							case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 								stack = append(stack, v)
 							}
 						}
@@ -693,7 +739,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 							switch v.(type) {
 							case nil, gen.Bool, gen.Int, gen.Float, gen.String,
 								bool, string, float64, float32, int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-							case map[string]any, []any, gen.Object, gen.Array:
+							case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 								stack = append(stack, v)
 							default:
 								kind := reflect.Invalid
@@ -743,7 +789,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 						switch v.(type) {
 						case nil, gen.Bool, gen.Int, gen.Float, gen.String,
 							bool, string, float64, float32, int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							kind := reflect.Invalid
@@ -762,7 +808,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 						switch v.(type) {
 						case nil, gen.Bool, gen.Int, gen.Float, gen.String,
 							bool, string, float64, float32, int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							kind := reflect.Invalid
@@ -794,7 +840,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 					for i := end; start <= i; i -= step {
 						v = tv[i]
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						}
 					}
@@ -802,7 +848,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 					for i := end; i <= start; i -= step {
 						v = tv[i]
 						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						}
 					}
@@ -813,7 +859,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 						switch v.(type) {
 						case nil, gen.Bool, gen.Int, gen.Float, gen.String,
 							bool, string, float64, float32, int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-						case map[string]any, []any, gen.Object, gen.Array:
+						case map[string]any, []any, gen.Object, gen.Array, *gen.Ordered:
 							stack = append(stack, v)
 						default:
 							kind := reflect.Invalid
@@ -847,6 +893,10 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 }
 
 func (x Expr) reflectSetChild(data any, key string, v any) bool {
+	if ordered, ok := data.(*gen.Ordered); ok {
+		ordered.Set(key, v)
+		return true
+	}
 	if !isNil(data) {
 		rd := reflect.ValueOf(data)
 		rt := rd.Type()
@@ -0,0 +1,20 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package jp
+
+import (
+	"github.com/ohler55/ojg"
+)
+
+// Span returns the source span of the value the Expr refers to as recorded
+// by a parser with Spans set. Only expressions composed of an optional
+// leading root or @, child, non-negative nth, and bracket fragments can be
+// looked up. Nil is returned for other expressions or if no span was
+// recorded for the location.
+func (x Expr) Span(spans *ojg.Spans) *ojg.Span {
+	path := x.simplePath()
+	if path == nil {
+		return nil
+	}
+	return spans.Get(path...)
+}
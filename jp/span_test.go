@@ -0,0 +1,34 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package jp_test
+
+import (
+	"testing"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestExprSpan(t *testing.T) {
+	var spans ojg.Spans
+	p := oj.Parser{Spans: &spans}
+	src := "{\"a\": [1,\n {\"b\": true}]}"
+	_, err := p.Parse([]byte(src))
+	tt.Nil(t, err)
+
+	span := jp.MustParseString("$.a[1].b").Span(&spans)
+	tt.NotNil(t, span)
+	tt.Equal(t, "true", src[span.Start.Offset:span.End.Offset])
+	tt.Equal(t, 2, span.Start.Line)
+	tt.Equal(t, 8, span.Start.Column)
+
+	span = jp.MustParseString("$['a'][0]").Span(&spans)
+	tt.NotNil(t, span)
+	tt.Equal(t, "1", src[span.Start.Offset:span.End.Offset])
+
+	for _, x := range []string{"$.a[*]", "$.a[-1]", "$..b", "$.x"} {
+		tt.Nil(t, jp.MustParseString(x).Span(&spans), x)
+	}
+}
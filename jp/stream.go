@@ -0,0 +1,322 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package jp
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/sen"
+)
+
+// Streamer is an oj.TokenHandler that evaluates a JSONPath expression
+// against the tokens of JSON or SEN documents as they are read. The
+// normalized path and value of each match are passed to the callback as
+// soon as the matched value has been completely read so values nested in a
+// match are reported before the match itself. Only matched values are built
+// along with the values needed to evaluate filters and negative indexes or
+// slices. Root references in filters do not match anything since the root is
+// not available while streaming. The path passed to the callback is reused
+// so if the path needs to be saved it should be copied.
+type Streamer struct {
+	x        Expr
+	cb       func(path Expr, value any)
+	path     Expr
+	frames   []streamFrame
+	states   []int
+	deferred []streamDefer
+}
+
+// streamFrame tracks the state of a value being read. States are indexes
+// into the expression of the fragments that remain to be matched. A state
+// equal to the expression length indicates a match.
+type streamFrame struct {
+	states   []int
+	deferred []streamDefer
+	value    any
+	key      string
+	index    int
+	object   bool
+	build    bool
+}
+
+// streamDefer is the remainder of an expression to be evaluated once the
+// value has been built. If filter is not nil then the value must match the
+// filter before the rest is evaluated.
+type streamDefer struct {
+	filter *Filter
+	rest   Expr
+}
+
+// NewStreamer creates a new Streamer that calls cb for each match of x.
+func NewStreamer(x Expr, cb func(path Expr, value any)) *Streamer {
+	return &Streamer{
+		x:      x,
+		cb:     cb,
+		path:   Expr{Root('$')},
+		frames: []streamFrame{{}},
+	}
+}
+
+// Stream JSON documents from r and call cb with the normalized path and
+// value of each match as it is read. The documents are never built in full.
+func (x Expr) Stream(r io.Reader, cb func(path Expr, value any)) error {
+	return oj.TokenizeLoad(r, NewStreamer(x, cb))
+}
+
+// StreamSEN is the same as Stream except the documents read are SEN
+// documents.
+func (x Expr) StreamSEN(r io.Reader, cb func(path Expr, value any)) error {
+	return sen.TokenizeLoad(r, NewStreamer(x, cb))
+}
+
+// Null is called when a JSON null is encountered.
+func (s *Streamer) Null() {
+	s.scalar(nil)
+}
+
+// Bool is called when a JSON true or false is encountered.
+func (s *Streamer) Bool(v bool) {
+	s.scalar(v)
+}
+
+// Int is called when a JSON integer is encountered.
+func (s *Streamer) Int(v int64) {
+	s.scalar(v)
+}
+
+// Float is called when a JSON decimal is encountered.
+func (s *Streamer) Float(v float64) {
+	s.scalar(v)
+}
+
+// Number is called when a JSON number is encountered that does not fit
+// into an int64 or float64.
+func (s *Streamer) Number(v string) {
+	s.scalar(json.Number(v))
+}
+
+// String is called when a JSON string is encountered.
+func (s *Streamer) String(v string) {
+	s.scalar(v)
+}
+
+// ObjectStart is called when a JSON object start '{' is encountered.
+func (s *Streamer) ObjectStart() {
+	s.push(true, map[string]any{})
+}
+
+// ObjectEnd is called when a JSON object end '}' is encountered.
+func (s *Streamer) ObjectEnd() {
+	s.pop()
+}
+
+// Key is called when a JSON object key is encountered.
+func (s *Streamer) Key(k string) {
+	s.frames[len(s.frames)-1].key = k
+}
+
+// ArrayStart is called when a JSON array start '[' is encountered.
+func (s *Streamer) ArrayStart() {
+	s.push(false, []any{})
+}
+
+// ArrayEnd is called when a JSON array end ']' is encountered.
+func (s *Streamer) ArrayEnd() {
+	s.pop()
+}
+
+func (s *Streamer) scalar(v any) {
+	s.push(false, nil)
+	s.frames[len(s.frames)-1].value = v
+	s.pop()
+}
+
+// push a frame for a new value. The value is only kept if the new value or
+// one of its ancestors is needed for a match.
+func (s *Streamer) push(object bool, value any) {
+	s.states = s.states[:0]
+	s.deferred = s.deferred[:0]
+	parent := &s.frames[len(s.frames)-1]
+	if len(s.frames) == 1 {
+		s.addState(0)
+	} else {
+		if parent.object {
+			s.path = append(s.path, Child(parent.key))
+		} else {
+			s.path = append(s.path, Nth(parent.index))
+			parent.index++
+		}
+		s.childStates(parent)
+	}
+	build := parent.build || 0 < len(s.deferred)
+	for _, i := range s.states {
+		if i == len(s.x) {
+			build = true
+		}
+	}
+	if len(s.frames) < cap(s.frames) {
+		s.frames = s.frames[:len(s.frames)+1]
+	} else {
+		s.frames = append(s.frames, streamFrame{})
+	}
+	f := &s.frames[len(s.frames)-1]
+	f.states = append(f.states[:0], s.states...)
+	f.deferred = append(f.deferred[:0], s.deferred...)
+	f.key = ""
+	f.index = 0
+	f.object = object
+	f.build = build
+	f.value = nil
+	if build {
+		f.value = value
+	}
+}
+
+// pop the current frame, reporting matches and adding the value to the
+// parent if the parent is being built.
+func (s *Streamer) pop() {
+	f := &s.frames[len(s.frames)-1]
+	v := f.value
+	for _, i := range f.states {
+		if i == len(s.x) {
+			s.cb(s.path, v)
+		}
+	}
+	for _, d := range f.deferred {
+		if d.filter != nil && !d.filter.matchWithRoot(v, nil) {
+			continue
+		}
+		if len(d.rest) == 0 {
+			s.cb(s.path, v)
+			continue
+		}
+		for _, loc := range d.rest.Locate(v, 0) {
+			s.cb(append(s.path, loc[1:]...), loc.First(v))
+		}
+	}
+	s.frames = s.frames[:len(s.frames)-1]
+	if len(s.frames) == 1 {
+		return
+	}
+	s.path = s.path[:len(s.path)-1]
+	if parent := &s.frames[len(s.frames)-1]; parent.build {
+		if parent.object {
+			parent.value.(map[string]any)[parent.key] = v
+		} else {
+			parent.value = append(parent.value.([]any), v)
+		}
+	}
+}
+
+// childStates sets the states for the next value in the parent.
+func (s *Streamer) childStates(parent *streamFrame) {
+	for _, i := range parent.states {
+		if len(s.x) <= i {
+			continue
+		}
+		switch tf := s.x[i].(type) {
+		case Child:
+			if parent.object && parent.key == string(tf) {
+				s.addState(i + 1)
+			}
+		case Nth:
+			if !parent.object && parent.index-1 == int(tf) {
+				s.addState(i + 1)
+			}
+		case Wildcard:
+			s.addState(i + 1)
+		case Descent:
+			s.addState(i)
+		case Union:
+			for _, k := range tf {
+				switch tk := k.(type) {
+				case string:
+					if parent.object && parent.key == tk {
+						s.addState(i + 1)
+					}
+				case int64:
+					if !parent.object && parent.index-1 == int(tk) {
+						s.addState(i + 1)
+					}
+				}
+			}
+		case Slice:
+			start, end, step := streamSlice(tf)
+			if n := parent.index - 1; !parent.object && start <= n && n < end && (n-start)%step == 0 {
+				s.addState(i + 1)
+			}
+		case *Filter:
+			s.deferred = append(s.deferred, streamDefer{filter: tf, rest: s.x[i+1:]})
+		}
+	}
+}
+
+// addState adds the state i after skipping over fragments that have no
+// effect. A descent adds both the descent state and the state following the
+// descent since descent includes the current value. Fragments that can not
+// be evaluated without the whole value, such as negative indexes, are
+// deferred until the value has been built.
+func (s *Streamer) addState(i int) {
+	streams := true
+	for ; i < len(s.x); i++ {
+		switch tf := s.x[i].(type) {
+		case Root, At, Bracket:
+			continue
+		case Descent:
+			s.appendState(i)
+			continue
+		case Child, Wildcard, *Filter:
+		case Nth:
+			streams = 0 <= tf
+		case Union:
+			for _, k := range tf {
+				if n, ok := k.(int64); ok && n < 0 {
+					streams = false
+				}
+			}
+		case Slice:
+			_, _, step := streamSlice(tf)
+			streams = 0 < step
+		default:
+			streams = false
+		}
+		break
+	}
+	if streams {
+		s.appendState(i)
+	} else {
+		s.deferred = append(s.deferred, streamDefer{rest: s.x[i:]})
+	}
+}
+
+func (s *Streamer) appendState(i int) {
+	for _, si := range s.states {
+		if si == i {
+			return
+		}
+	}
+	s.states = append(s.states, i)
+}
+
+// streamSlice returns the start, end, and step of a slice if the slice can
+// be evaluated without knowing the length of the array. Otherwise step is
+// zero.
+func streamSlice(f Slice) (start, end, step int) {
+	end = maxEnd
+	step = 1
+	if 0 < len(f) {
+		start = f[0]
+	}
+	if 1 < len(f) {
+		end = f[1]
+	}
+	if 2 < len(f) {
+		step = f[2]
+	}
+	if start < 0 || end < 0 || step < 0 {
+		step = 0
+	}
+	return
+}
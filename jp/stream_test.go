@@ -0,0 +1,118 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package jp_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/sen"
+	"github.com/ohler55/ojg/tt"
+)
+
+const streamTestJSON = `{
+  "a": {"b": 1, "c": [true, null, 2.5]},
+  "records": [
+    {"id": 1, "tags": ["x", "y"], "n": {"id": 10}},
+    {"id": 2, "tags": [], "size": 7},
+    {"id": 3, "tags": ["z"], "size": 2}
+  ],
+  "big": 123456789012345678901234567890
+}`
+
+func TestStreamMatchesLocate(t *testing.T) {
+	data := oj.MustParseString(streamTestJSON)
+	for _, path := range []string{
+		"$",
+		"$.a",
+		"$.a.b",
+		"@.a.c[2]",
+		"$.records[*].id",
+		"$.records[1].size",
+		"$.records[-1].id",
+		"$.records[0,2].tags[0]",
+		"$['a','records'][0]",
+		"$.records[1:].id",
+		"$.records[::2].id",
+		"$.records[-2:].id",
+		"$.records[2:0:-1].id",
+		"$..id",
+		"$..tags[*]",
+		"$.records..",
+		"$.*",
+		"$.records[?(@.size > 1)].id",
+		"$..[?(@.id == 10)]",
+		"$.records[?(@.size == 7)].tags",
+		"$.x.y",
+		"$.big",
+	} {
+		x := jp.MustParseString(path)
+		var got []string
+		err := x.Stream(strings.NewReader(streamTestJSON), func(p jp.Expr, v any) {
+			got = append(got, p.String())
+			tt.Equal(t, oj.JSON(p.First(data), &oj.Options{Sort: true}), oj.JSON(v, &oj.Options{Sort: true}), "%s %s", path, p)
+		})
+		tt.Nil(t, err, path)
+		var expect []string
+		for _, loc := range x.Locate(data, 0) {
+			expect = append(expect, loc.String())
+		}
+		sort.Strings(got)
+		sort.Strings(expect)
+		tt.Equal(t, expect, got, path)
+	}
+}
+
+func TestStreamOrder(t *testing.T) {
+	var got []string
+	err := jp.MustParseString("$..id").Stream(strings.NewReader(streamTestJSON), func(p jp.Expr, v any) {
+		got = append(got, p.String())
+	})
+	tt.Nil(t, err)
+	tt.Equal(t, []string{"$.records[0].id", "$.records[0].n.id", "$.records[1].id", "$.records[2].id"}, got)
+
+	// Nested matches are reported before the enclosing match.
+	got = got[:0]
+	err = jp.MustParseString("$..c").Stream(strings.NewReader(`{"c":{"c":1}}`), func(p jp.Expr, v any) {
+		got = append(got, p.String()+"="+oj.JSON(v))
+	})
+	tt.Nil(t, err)
+	tt.Equal(t, []string{`$.c.c=1`, `$.c={"c":1}`}, got)
+}
+
+func TestStreamMultipleDocuments(t *testing.T) {
+	var got []any
+	err := jp.C("a").Stream(strings.NewReader(`{"a":1}{"b":2}[{"a":3}]{"a":[4]}`), func(p jp.Expr, v any) {
+		got = append(got, v)
+	})
+	tt.Nil(t, err)
+	tt.Equal(t, []any{int64(1), []any{int64(4)}}, got)
+}
+
+func TestStreamSEN(t *testing.T) {
+	var got []any
+	err := jp.MustParseString("$.records[*].id").StreamSEN(strings.NewReader(`{records: [{id: a} {id: 'b c'}]}`),
+		func(p jp.Expr, v any) {
+			got = append(got, v)
+		})
+	tt.Nil(t, err)
+	tt.Equal(t, []any{"a", "b c"}, got)
+}
+
+func TestStreamError(t *testing.T) {
+	err := jp.C("a").Stream(strings.NewReader(`{"a":1`), func(p jp.Expr, v any) {})
+	tt.NotNil(t, err)
+}
+
+func TestStreamerTokenize(t *testing.T) {
+	var got []any
+	h := jp.NewStreamer(jp.MustParseString("$[1]"), func(p jp.Expr, v any) {
+		got = append(got, v)
+	})
+	err := sen.TokenizeString("[1 [2 3] 4]", h)
+	tt.Nil(t, err)
+	tt.Equal(t, []any{[]any{int64(2), int64(3)}}, got)
+}
@@ -0,0 +1,358 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package jp
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// StrictExpr is a JSONPath query that conforms to RFC 9535. It is created
+// with ParseStrict or MustParseStrict which reject any of the OjG extensions
+// accepted by Parse. Evaluation also follows the RFC so the results of a
+// StrictExpr may differ from an Expr with the same string representation.
+// Some of the differences are:
+//
+//   - Descendant segments visit a node before its children.
+//   - Slices with a negative step and no start or end select in reverse.
+//   - Filter comparisons are only allowed between literals, singular queries,
+//     and functions that return a value. A missing value compares equal only
+//     to another missing value and arrays and objects are compared deeply.
+//   - The length, count, match, search, and value functions are type checked
+//     according to the function extension rules of the RFC.
+//
+// Object members are visited in key order so that results are repeatable.
+type StrictExpr struct {
+	segments []*strictSegment
+}
+
+type strictSegment struct {
+	descent   bool
+	selectors []strictSelector
+}
+
+type strictSelector interface {
+	appendSelector(buf []byte) []byte
+	selectNodes(ns []strictNode, n strictNode, root any, track bool) []strictNode
+}
+
+type strictNode struct {
+	value any
+	path  Expr
+}
+
+type strictName string
+
+type strictIndex int64
+
+type strictWildcard struct{}
+
+type strictSlice struct {
+	start    int64
+	end      int64
+	step     int64
+	hasStart bool
+	hasEnd   bool
+	hasStep  bool
+}
+
+type strictFilter struct {
+	expr strictLogical
+}
+
+// String returns a string representation of the expression.
+func (x *StrictExpr) String() string {
+	return string(x.Append(nil))
+}
+
+// Append a string representation of the expression to a byte slice and return
+// the expanded buffer.
+func (x *StrictExpr) Append(buf []byte) []byte {
+	buf = append(buf, '$')
+	return appendStrictSegments(buf, x.segments)
+}
+
+// Get the elements of the data identified by the expression. The order of
+// the results follows RFC 9535 with object members visited in key order.
+func (x *StrictExpr) Get(data any) (results []any) {
+	for _, n := range evalStrictSegments(x.segments, []strictNode{{value: data}}, data, false) {
+		results = append(results, n.value)
+	}
+	return
+}
+
+// First element of the data identified by the expression or nil if there is
+// no match.
+func (x *StrictExpr) First(data any) any {
+	if results := x.Get(data); 0 < len(results) {
+		return results[0]
+	}
+	return nil
+}
+
+// Locate the values described by the expression and return a slice of the
+// normalized paths to those values in the data. The number of paths returned
+// is limited to max unless max is zero or less in which case all matching
+// locations are returned.
+func (x *StrictExpr) Locate(data any, max int) (locs []Expr) {
+	for _, n := range evalStrictSegments(x.segments, []strictNode{{value: data, path: Expr{Root('$')}}}, data, true) {
+		if 0 < max && max <= len(locs) {
+			break
+		}
+		locs = append(locs, n.path)
+	}
+	return
+}
+
+func evalStrictSegments(segments []*strictSegment, nodes []strictNode, root any, track bool) []strictNode {
+	for _, seg := range segments {
+		var out []strictNode
+		for _, n := range nodes {
+			if seg.descent {
+				out = seg.descend(out, n, root, track)
+			} else {
+				for _, sel := range seg.selectors {
+					out = sel.selectNodes(out, n, root, track)
+				}
+			}
+		}
+		nodes = out
+		if len(nodes) == 0 {
+			break
+		}
+	}
+	return nodes
+}
+
+// descend visits the node and then all the descendants of the node, applying
+// the selectors to each one.
+func (seg *strictSegment) descend(ns []strictNode, n strictNode, root any, track bool) []strictNode {
+	for _, sel := range seg.selectors {
+		ns = sel.selectNodes(ns, n, root, track)
+	}
+	frags, values := locateChildren(n.value)
+	for i, f := range frags {
+		ns = seg.descend(ns, strictChild(n, f, values[i], track), root, track)
+	}
+	return ns
+}
+
+func strictChild(n strictNode, f Frag, v any, track bool) (child strictNode) {
+	child.value = v
+	if track {
+		child.path = make(Expr, len(n.path), len(n.path)+1)
+		copy(child.path, n.path)
+		child.path = append(child.path, f)
+	}
+	return
+}
+
+func (sel strictName) selectNodes(ns []strictNode, n strictNode, root any, track bool) []strictNode {
+	if strictIsObject(n.value) {
+		if v, has := locateChild(n.value, string(sel)); has {
+			ns = append(ns, strictChild(n, Child(sel), v, track))
+		}
+	}
+	return ns
+}
+
+func (sel strictName) appendSelector(buf []byte) []byte {
+	return appendStrictString(buf, string(sel))
+}
+
+func (sel strictIndex) selectNodes(ns []strictNode, n strictNode, root any, track bool) []strictNode {
+	if v, i, has := locateNth(n.value, int(sel)); has {
+		ns = append(ns, strictChild(n, Nth(i), v, track))
+	}
+	return ns
+}
+
+func (sel strictIndex) appendSelector(buf []byte) []byte {
+	return strconv.AppendInt(buf, int64(sel), 10)
+}
+
+func (sel strictWildcard) selectNodes(ns []strictNode, n strictNode, root any, track bool) []strictNode {
+	frags, values := locateChildren(n.value)
+	for i, f := range frags {
+		ns = append(ns, strictChild(n, f, values[i], track))
+	}
+	return ns
+}
+
+func (sel strictWildcard) appendSelector(buf []byte) []byte {
+	return append(buf, '*')
+}
+
+func (sel *strictSlice) selectNodes(ns []strictNode, n strictNode, root any, track bool) []strictNode {
+	size := int64(locateSize(n.value))
+	if size < 0 || sel.step == 0 {
+		return ns
+	}
+	norm := func(i int64) int64 {
+		if i < 0 {
+			return size + i
+		}
+		return i
+	}
+	bound := func(i, low, high int64) int64 {
+		if i < low {
+			return low
+		}
+		if high < i {
+			return high
+		}
+		return i
+	}
+	if 0 < sel.step {
+		start := int64(0)
+		end := size
+		if sel.hasStart {
+			start = bound(norm(sel.start), 0, size)
+		}
+		if sel.hasEnd {
+			end = bound(norm(sel.end), 0, size)
+		}
+		for i := start; i < end; i += sel.step {
+			if v, _, has := locateNth(n.value, int(i)); has {
+				ns = append(ns, strictChild(n, Nth(i), v, track))
+			}
+		}
+	} else {
+		start := size - 1
+		end := -size - 1
+		if sel.hasStart {
+			start = sel.start
+		}
+		if sel.hasEnd {
+			end = sel.end
+		}
+		start = bound(norm(start), -1, size-1)
+		end = bound(norm(end), -1, size-1)
+		for i := start; end < i; i += sel.step {
+			if v, _, has := locateNth(n.value, int(i)); has {
+				ns = append(ns, strictChild(n, Nth(i), v, track))
+			}
+		}
+	}
+	return ns
+}
+
+func (sel *strictSlice) appendSelector(buf []byte) []byte {
+	if sel.hasStart {
+		buf = strconv.AppendInt(buf, sel.start, 10)
+	}
+	buf = append(buf, ':')
+	if sel.hasEnd {
+		buf = strconv.AppendInt(buf, sel.end, 10)
+	}
+	if sel.hasStep {
+		buf = append(buf, ':')
+		buf = strconv.AppendInt(buf, sel.step, 10)
+	}
+	return buf
+}
+
+func (sel *strictFilter) selectNodes(ns []strictNode, n strictNode, root any, track bool) []strictNode {
+	if strictIsObject(n.value) || 0 <= locateSize(n.value) {
+		frags, values := locateChildren(n.value)
+		for i, f := range frags {
+			if sel.expr.evalLogical(values[i], root) {
+				ns = append(ns, strictChild(n, f, values[i], track))
+			}
+		}
+	}
+	return ns
+}
+
+func (sel *strictFilter) appendSelector(buf []byte) []byte {
+	buf = append(buf, '?')
+	return sel.expr.appendLogical(buf, 0)
+}
+
+func appendStrictSegments(buf []byte, segments []*strictSegment) []byte {
+	for _, seg := range segments {
+		if seg.descent {
+			buf = append(buf, '.', '.')
+		}
+		if len(seg.selectors) == 1 {
+			switch ts := seg.selectors[0].(type) {
+			case strictName:
+				if strictShorthandOk(string(ts)) {
+					if !seg.descent {
+						buf = append(buf, '.')
+					}
+					buf = append(buf, ts...)
+					continue
+				}
+			case strictWildcard:
+				if !seg.descent {
+					buf = append(buf, '.')
+				}
+				buf = append(buf, '*')
+				continue
+			}
+		}
+		buf = append(buf, '[')
+		for i, sel := range seg.selectors {
+			if 0 < i {
+				buf = append(buf, ',')
+			}
+			buf = sel.appendSelector(buf)
+		}
+		buf = append(buf, ']')
+	}
+	return buf
+}
+
+// strictShorthandOk returns true if the name can be represented in the
+// member-name-shorthand form.
+func strictShorthandOk(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		case 0 < i && '0' <= r && r <= '9':
+		case 0x80 <= r && r <= 0xD7FF, 0xE000 <= r && r <= 0x10FFFF:
+			if r == utf8.RuneError {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// appendStrictString appends a single quoted string with the escapes
+// described for normalized paths in RFC 9535.
+func appendStrictString(buf []byte, s string) []byte {
+	buf = append(buf, '\'')
+	for _, r := range s {
+		switch r {
+		case '\b':
+			buf = append(buf, '\\', 'b')
+		case '\f':
+			buf = append(buf, '\\', 'f')
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case '\t':
+			buf = append(buf, '\\', 't')
+		case '\'':
+			buf = append(buf, '\\', '\'')
+		case '\\':
+			buf = append(buf, '\\', '\\')
+		default:
+			if r < 0x20 {
+				buf = append(buf, `\u00`...)
+				buf = append(buf, "0123456789abcdef"[r>>4], "0123456789abcdef"[r&0x0f])
+			} else {
+				buf = utf8.AppendRune(buf, r)
+			}
+		}
+	}
+	return append(buf, '\'')
+}
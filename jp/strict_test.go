@@ -0,0 +1,157 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package jp_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+// strictSkips lists the JSONPath Compliance Test Suite cases, by name, that
+// are not run along with the reason each is left out. Every entry must have
+// a reason and must name a case in the suite so that entries are removed
+// when the suite or the implementation changes.
+var strictSkips = map[string]string{}
+
+// TestStrictCompliance runs the JSONPath Compliance Test Suite vendored in
+// testdata/cts. See testdata/cts/README.md for the pinned version. The test
+// fails if the suite is missing.
+func TestStrictCompliance(t *testing.T) {
+	_, err := os.Stat("testdata/cts/cts.json")
+	tt.Nil(t, err, "testdata/cts/cts.json has not been vendored, see testdata/cts/README.md")
+	runStrictSuite(t, "testdata/cts/cts.json", strictSkips)
+}
+
+// TestStrictCases runs the hand-written cases in testdata/strict.json which
+// use the format of the JSONPath Compliance Test Suite.
+func TestStrictCases(t *testing.T) {
+	runStrictSuite(t, "testdata/strict.json", nil)
+}
+
+func runStrictSuite(t *testing.T, path string, skips map[string]string) {
+	buf, err := os.ReadFile(path)
+	tt.Nil(t, err)
+	suite, _ := oj.MustParse(buf).(map[string]any)
+	tests, _ := suite["tests"].([]any)
+	tt.NotEqual(t, 0, len(tests))
+	names := map[string]bool{}
+	for _, v := range tests {
+		test, _ := v.(map[string]any)
+		name, _ := test["name"].(string)
+		names[name] = true
+		if reason, has := skips[name]; has {
+			tt.NotEqual(t, "", reason, "%s: skipped without a reason", name)
+			continue
+		}
+		src, _ := test["selector"].(string)
+		x, err := jp.ParseStrictString(src)
+		if invalid, _ := test["invalid_selector"].(bool); invalid {
+			tt.NotNil(t, err, "%s: %s", name, src)
+			continue
+		}
+		tt.Nil(t, err, "%s: %s", name, src)
+		result := oj.JSON(x.Get(test["document"]), &oj.Options{Sort: true})
+		if expect, has := test["result"]; has {
+			tt.Equal(t, oj.JSON(expect, &oj.Options{Sort: true}), result, "%s: %s", name, src)
+			continue
+		}
+		alts, _ := test["results"].([]any)
+		var found bool
+		for _, expect := range alts {
+			if oj.JSON(expect, &oj.Options{Sort: true}) == result {
+				found = true
+				break
+			}
+		}
+		tt.Equal(t, true, found, "%s: %s returned %s", name, src, result)
+	}
+	for name := range skips {
+		tt.Equal(t, true, names[name], "%s: skipped but not in %s", name, path)
+	}
+}
+
+func TestStrictString(t *testing.T) {
+	for i, d := range []struct {
+		src    string
+		expect string
+	}{
+		{src: "$", expect: "$"},
+		{src: "$ .a ['b c']", expect: "$.a['b c']"},
+		{src: `$["a",1,-2,*]`, expect: "$['a',1,-2,*]"},
+		{src: "$..a..[0]..*", expect: "$..a..[0]..*"},
+		{src: "$[1:3][::-1][:5:]", expect: "$[1:3][::-1][:5]"},
+		{src: `$['☺\n\'']`, expect: `$['☺\n\'']`},
+		{src: "$[? @.a == 1 && (@.b || !@.c)]", expect: "$[?@.a == 1 && (@.b || !@.c)]"},
+		{src: "$[?(@.a == 1 || @.b) && @.c]", expect: "$[?(@.a == 1 || @.b) && @.c]"},
+		{src: "$[?length(@.a) >= 2.5e3 && match(@.b, 'x.*')]", expect: "$[?length(@.a) >= 2500 && match(@.b, 'x.*')]"},
+		{src: "$[?@.a == null || @.a != true || $.b < \"x\"]", expect: "$[?@.a == null || @.a != true || $.b < 'x']"},
+	} {
+		x, err := jp.ParseStrictString(d.src)
+		tt.Nil(t, err, i, ": ", d.src)
+		tt.Equal(t, d.expect, x.String(), i, ": ", d.src)
+		// The output must parse to the same expression.
+		tt.Equal(t, d.expect, jp.MustParseStrictString(x.String()).String(), i, ": ", d.src)
+	}
+}
+
+func TestStrictParseError(t *testing.T) {
+	_, err := jp.ParseStrictString("$[?(@.a ~= 'b')]")
+	tt.NotNil(t, err)
+	tt.Panic(t, func() { _ = jp.MustParseStrictString("$.a[b]") })
+	tt.Panic(t, func() { _ = jp.MustParseStrict([]byte("$..")) })
+}
+
+func TestStrictLocate(t *testing.T) {
+	data := map[string]any{
+		"a": []any{
+			map[string]any{"b": 1, "c": "x"},
+			map[string]any{"b": 2},
+			map[string]any{"b": 3, "c": "y"},
+		},
+	}
+	x := jp.MustParseStrictString("$.a[?@.c].b")
+	var paths []string
+	for _, loc := range x.Locate(data, 0) {
+		paths = append(paths, loc.String())
+	}
+	tt.Equal(t, []string{"$.a[0].b", "$.a[2].b"}, paths)
+	tt.Equal(t, 1, len(x.Locate(data, 1)))
+
+	x = jp.MustParseStrictString("$.a[-1:0:-1].b")
+	paths = paths[:0]
+	for _, loc := range x.Locate(data, 0) {
+		paths = append(paths, loc.String())
+	}
+	tt.Equal(t, []string{"$.a[2].b", "$.a[1].b"}, paths)
+}
+
+func TestStrictFirst(t *testing.T) {
+	data := []any{1, 2, 3}
+	tt.Equal(t, 2, jp.MustParseStrictString("$[?@>1]").First(data))
+	tt.Nil(t, jp.MustParseStrictString("$[?@>3]").First(data))
+}
+
+func TestStrictNode(t *testing.T) {
+	data := gen.Object{
+		"list": gen.Array{
+			gen.Object{"name": gen.String("one"), "n": gen.Int(1)},
+			gen.Object{"name": gen.String("two"), "n": gen.Float(2.0)},
+		},
+		"min": gen.Int(2),
+	}
+	x := jp.MustParseStrictString("$.list[?@.n >= $.min && length(@.name) == 3].name")
+	tt.Equal(t, []any{gen.String("two")}, x.Get(data))
+	x = jp.MustParseStrictString("$..n")
+	tt.Equal(t, []any{gen.Int(1), gen.Float(2.0)}, x.Get(data))
+}
+
+func TestStrictReflect(t *testing.T) {
+	data := []any{&Sample{A: 1, B: "a"}, &Sample{A: 2, B: "b"}}
+	x := jp.MustParseStrictString("$[?@.a > 1].b")
+	tt.Equal(t, []any{"b"}, x.Get(data))
+}
@@ -0,0 +1,507 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package jp
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ohler55/ojg/gen"
+)
+
+// The function extension types described in RFC 9535.
+const (
+	valueType   = 'V'
+	logicalType = 'L'
+	nodesType   = 'N'
+)
+
+// strictLogical is the LogicalType of RFC 9535, a boolean result of a filter
+// expression.
+type strictLogical interface {
+	evalLogical(cur, root any) bool
+	appendLogical(buf []byte, prec int) []byte
+}
+
+// strictValue is the ValueType of RFC 9535, a value or Nothing.
+type strictValue interface {
+	evalValue(cur, root any) any
+	appendValue(buf []byte) []byte
+}
+
+// Logical operator precedence used when building a string representation.
+const (
+	orPrec  = 1
+	andPrec = 2
+	notPrec = 3
+)
+
+type strictOr []strictLogical
+
+type strictAnd []strictLogical
+
+type strictNot struct {
+	arg strictLogical
+}
+
+type strictCompare struct {
+	op    string
+	left  strictValue
+	right strictValue
+}
+
+// strictLiteral is a literal value in a filter, either nil, a bool, an
+// int64, a float64, or a string.
+type strictLiteral struct {
+	value any
+}
+
+// strictQuery is a filter query that starts with either the current node (@)
+// or the root ($).
+type strictQuery struct {
+	segments []*strictSegment
+	relative bool
+}
+
+// strictExists is a test expression that is true if a query selects at least
+// one node.
+type strictExists struct {
+	query *strictQuery
+}
+
+type strictFuncDef struct {
+	name   string
+	params []byte
+	result byte
+	eval   func(args []any) any
+}
+
+type strictFunc struct {
+	def  *strictFuncDef
+	args []any // strictValue, strictLogical, or *strictQuery depending on the param type
+	rx   *regexp.Regexp
+}
+
+var strictFuncs = map[string]*strictFuncDef{
+	"length": {name: "length", params: []byte{valueType}, result: valueType, eval: strictLength},
+	"count":  {name: "count", params: []byte{nodesType}, result: valueType, eval: strictCount},
+	"match":  {name: "match", params: []byte{valueType, valueType}, result: logicalType},
+	"search": {name: "search", params: []byte{valueType, valueType}, result: logicalType},
+	"value":  {name: "value", params: []byte{nodesType}, result: valueType, eval: strictNodesValue},
+}
+
+func (x strictOr) evalLogical(cur, root any) bool {
+	for _, arg := range x {
+		if arg.evalLogical(cur, root) {
+			return true
+		}
+	}
+	return false
+}
+
+func (x strictOr) appendLogical(buf []byte, prec int) []byte {
+	if orPrec < prec {
+		buf = append(buf, '(')
+	}
+	for i, arg := range x {
+		if 0 < i {
+			buf = append(buf, " || "...)
+		}
+		buf = arg.appendLogical(buf, orPrec)
+	}
+	if orPrec < prec {
+		buf = append(buf, ')')
+	}
+	return buf
+}
+
+func (x strictAnd) evalLogical(cur, root any) bool {
+	for _, arg := range x {
+		if !arg.evalLogical(cur, root) {
+			return false
+		}
+	}
+	return true
+}
+
+func (x strictAnd) appendLogical(buf []byte, prec int) []byte {
+	if andPrec < prec {
+		buf = append(buf, '(')
+	}
+	for i, arg := range x {
+		if 0 < i {
+			buf = append(buf, " && "...)
+		}
+		buf = arg.appendLogical(buf, andPrec)
+	}
+	if andPrec < prec {
+		buf = append(buf, ')')
+	}
+	return buf
+}
+
+func (x *strictNot) evalLogical(cur, root any) bool {
+	return !x.arg.evalLogical(cur, root)
+}
+
+func (x *strictNot) appendLogical(buf []byte, prec int) []byte {
+	buf = append(buf, '!')
+	switch x.arg.(type) {
+	case *strictExists, *strictFunc:
+		return x.arg.appendLogical(buf, notPrec)
+	}
+	buf = append(buf, '(')
+	buf = x.arg.appendLogical(buf, 0)
+	return append(buf, ')')
+}
+
+func (x *strictCompare) evalLogical(cur, root any) bool {
+	left := x.left.evalValue(cur, root)
+	right := x.right.evalValue(cur, root)
+	switch x.op {
+	case "==":
+		return strictEqual(left, right)
+	case "!=":
+		return !strictEqual(left, right)
+	case "<":
+		return strictLess(left, right)
+	case "<=":
+		return strictLess(left, right) || strictEqual(left, right)
+	case ">":
+		return strictLess(right, left)
+	case ">=":
+		return strictLess(right, left) || strictEqual(left, right)
+	}
+	return false
+}
+
+func (x *strictCompare) appendLogical(buf []byte, prec int) []byte {
+	buf = x.left.appendValue(buf)
+	buf = append(buf, ' ')
+	buf = append(buf, x.op...)
+	buf = append(buf, ' ')
+	return x.right.appendValue(buf)
+}
+
+func (x *strictLiteral) evalValue(cur, root any) any {
+	return x.value
+}
+
+func (x *strictLiteral) appendValue(buf []byte) []byte {
+	switch tv := x.value.(type) {
+	case nil:
+		buf = append(buf, "null"...)
+	case bool:
+		buf = strconv.AppendBool(buf, tv)
+	case int64:
+		buf = strconv.AppendInt(buf, tv, 10)
+	case float64:
+		buf = strconv.AppendFloat(buf, tv, 'g', -1, 64)
+	case string:
+		buf = appendStrictString(buf, tv)
+	}
+	return buf
+}
+
+func (x *strictQuery) nodes(cur, root any) []strictNode {
+	start := root
+	if x.relative {
+		start = cur
+	}
+	return evalStrictSegments(x.segments, []strictNode{{value: start}}, root, false)
+}
+
+// evalValue is only used for singular queries.
+func (x *strictQuery) evalValue(cur, root any) any {
+	if ns := x.nodes(cur, root); len(ns) == 1 {
+		return ns[0].value
+	}
+	return Nothing
+}
+
+func (x *strictQuery) appendValue(buf []byte) []byte {
+	if x.relative {
+		buf = append(buf, '@')
+	} else {
+		buf = append(buf, '$')
+	}
+	return appendStrictSegments(buf, x.segments)
+}
+
+// singular returns true if the query is a singular query which is one that
+// can select at most one node.
+func (x *strictQuery) singular() bool {
+	for _, seg := range x.segments {
+		if seg.descent || len(seg.selectors) != 1 {
+			return false
+		}
+		switch seg.selectors[0].(type) {
+		case strictName, strictIndex:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (x *strictExists) evalLogical(cur, root any) bool {
+	return 0 < len(x.query.nodes(cur, root))
+}
+
+func (x *strictExists) appendLogical(buf []byte, prec int) []byte {
+	return x.query.appendValue(buf)
+}
+
+func (x *strictFunc) evalArgs(cur, root any) []any {
+	args := make([]any, len(x.args))
+	for i, arg := range x.args {
+		switch x.def.params[i] {
+		case nodesType:
+			var values []any
+			for _, n := range arg.(*strictQuery).nodes(cur, root) {
+				values = append(values, n.value)
+			}
+			args[i] = values
+		case logicalType:
+			args[i] = arg.(strictLogical).evalLogical(cur, root)
+		default:
+			args[i] = arg.(strictValue).evalValue(cur, root)
+		}
+	}
+	return args
+}
+
+func (x *strictFunc) evalValue(cur, root any) any {
+	return x.def.eval(x.evalArgs(cur, root))
+}
+
+func (x *strictFunc) evalLogical(cur, root any) bool {
+	args := x.evalArgs(cur, root)
+	switch x.def.name {
+	case "match", "search":
+		s, ok := normalizeValue(args[0]).(string)
+		if !ok {
+			return false
+		}
+		rx := x.rx
+		if rx == nil {
+			pat, ok := normalizeValue(args[1]).(string)
+			if !ok {
+				return false
+			}
+			var err error
+			if rx, err = compileIRegexp(pat, x.def.name == "match"); err != nil {
+				return false
+			}
+		}
+		return rx.MatchString(s)
+	}
+	switch tr := x.def.eval(args).(type) {
+	case bool:
+		return tr
+	case []any:
+		return 0 < len(tr)
+	}
+	return false
+}
+
+func (x *strictFunc) appendValue(buf []byte) []byte {
+	buf = append(buf, x.def.name...)
+	buf = append(buf, '(')
+	for i, arg := range x.args {
+		if 0 < i {
+			buf = append(buf, ',', ' ')
+		}
+		if x.def.params[i] == logicalType {
+			buf = arg.(strictLogical).appendLogical(buf, 0)
+		} else {
+			buf = arg.(strictValue).appendValue(buf)
+		}
+	}
+	return append(buf, ')')
+}
+
+func (x *strictFunc) appendLogical(buf []byte, prec int) []byte {
+	return x.appendValue(buf)
+}
+
+func strictLength(args []any) any {
+	switch ta := normalizeValue(args[0]).(type) {
+	case string:
+		return int64(utf8.RuneCountInString(ta))
+	case []any:
+		return int64(len(ta))
+	case map[string]any:
+		return int64(len(ta))
+	case gen.Array:
+		return int64(len(ta))
+	case gen.Object:
+		return int64(len(ta))
+	}
+	return Nothing
+}
+
+func strictCount(args []any) any {
+	list, _ := args[0].([]any)
+	return int64(len(list))
+}
+
+func strictNodesValue(args []any) any {
+	if list, _ := args[0].([]any); len(list) == 1 {
+		return list[0]
+	}
+	return Nothing
+}
+
+// compileIRegexp converts an I-Regexp (RFC 9485) to a go regexp. The only
+// difference that must be accounted for is that a '.' outside of a character
+// class does not match a carriage return or line feed. If full is true the
+// expression must match the entire string.
+func compileIRegexp(pat string, full bool) (*regexp.Regexp, error) {
+	var b strings.Builder
+	if full {
+		b.WriteString(`^(?:`)
+	}
+	inClass := false
+	for i := 0; i < len(pat); i++ {
+		c := pat[i]
+		switch {
+		case c == '\\' && i+1 < len(pat):
+			b.WriteByte(c)
+			i++
+			b.WriteByte(pat[i])
+		case c == '[':
+			inClass = true
+			b.WriteByte(c)
+		case c == ']':
+			inClass = false
+			b.WriteByte(c)
+		case c == '.' && !inClass:
+			b.WriteString(`[^\n\r]`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	if full {
+		b.WriteString(`)$`)
+	}
+	return regexp.Compile(b.String())
+}
+
+func strictIsObject(v any) bool {
+	switch v.(type) {
+	case map[string]any, gen.Object:
+		return true
+	case nil, []any, gen.Array, string, bool, int64, float64:
+		return false
+	}
+	if isNil(v) {
+		return false
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Struct:
+		return true
+	case reflect.Map:
+		return rv.Type().Key().Kind() == reflect.String
+	}
+	return false
+}
+
+// strictEqual compares two values according to RFC 9535. Numbers compare by
+// value regardless of type, arrays and objects are compared deeply, and
+// Nothing is only equal to Nothing.
+func strictEqual(left, right any) bool {
+	left = normalizeValue(left)
+	right = normalizeValue(right)
+	switch tl := left.(type) {
+	case nothing:
+		_, ok := right.(nothing)
+		return ok
+	case nil:
+		return right == nil
+	case bool:
+		tr, ok := right.(bool)
+		return ok && tl == tr
+	case string:
+		tr, ok := right.(string)
+		return ok && tl == tr
+	case int64:
+		switch tr := right.(type) {
+		case int64:
+			return tl == tr
+		case float64:
+			return float64(tl) == tr
+		}
+		return false
+	case float64:
+		switch tr := right.(type) {
+		case int64:
+			return tl == float64(tr)
+		case float64:
+			return tl == tr
+		}
+		return false
+	}
+	if 0 <= locateSize(left) && 0 <= locateSize(right) {
+		_, lv := locateChildren(left)
+		_, rv := locateChildren(right)
+		if len(lv) != len(rv) {
+			return false
+		}
+		for i, v := range lv {
+			if !strictEqual(v, rv[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	if strictIsObject(left) && strictIsObject(right) {
+		lf, lv := locateChildren(left)
+		rf, rv := locateChildren(right)
+		if len(lf) != len(rf) {
+			return false
+		}
+		// Keys are sorted so matching members line up.
+		for i, f := range lf {
+			if f != rf[i] || !strictEqual(lv[i], rv[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// strictLess returns true if left is less than right. Only numbers and
+// strings can be ordered.
+func strictLess(left, right any) bool {
+	left = normalizeValue(left)
+	right = normalizeValue(right)
+	switch tl := left.(type) {
+	case int64:
+		switch tr := right.(type) {
+		case int64:
+			return tl < tr
+		case float64:
+			return float64(tl) < tr
+		}
+	case float64:
+		switch tr := right.(type) {
+		case int64:
+			return tl < float64(tr)
+		case float64:
+			return tl < tr
+		}
+	case string:
+		tr, ok := right.(string)
+		return ok && tl < tr
+	}
+	return false
+}
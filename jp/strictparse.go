@@ -0,0 +1,670 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package jp
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/ohler55/ojg"
+)
+
+// The largest and smallest integers allowed by I-JSON and RFC 9535 for array
+// indexes and slice parameters.
+const (
+	maxStrictInt = 1<<53 - 1
+	minStrictInt = -(1<<53 - 1)
+)
+
+// The strict parser follows the ABNF of RFC 9535 closely. Unlike the OjG
+// parser it is recursive descent since each construct has a well defined
+// start and the rules for whitespace are specific to each construct.
+type strictParser struct {
+	buf []byte
+	pos int
+}
+
+// ParseStrictString parses a string into a StrictExpr according to RFC 9535.
+func ParseStrictString(s string) (x *StrictExpr, err error) {
+	return ParseStrict([]byte(s))
+}
+
+// MustParseStrictString parses a string into a StrictExpr according to RFC
+// 9535 and panics on error.
+func MustParseStrictString(s string) (x *StrictExpr) {
+	return MustParseStrict([]byte(s))
+}
+
+// ParseStrict parses a []byte into a StrictExpr according to RFC 9535. Any
+// syntax not defined by the RFC such as the OjG script operators, unquoted
+// bracket keys, or whitespace around the expression is rejected as are
+// filters that do not satisfy the function extension type rules.
+func ParseStrict(buf []byte) (x *StrictExpr, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = ojg.NewError(r)
+		}
+	}()
+	x = MustParseStrict(buf)
+
+	return
+}
+
+// MustParseStrict parses a []byte into a StrictExpr according to RFC 9535
+// and panics on error.
+func MustParseStrict(buf []byte) (x *StrictExpr) {
+	p := &strictParser{buf: buf}
+	if len(buf) == 0 || buf[0] != '$' {
+		p.raise("expected a '$'")
+	}
+	p.pos++
+	x = &StrictExpr{segments: p.readSegments()}
+	if p.pos < len(buf) {
+		p.raise("parse error")
+	}
+	return
+}
+
+func (p *strictParser) readSegments() (segments []*strictSegment) {
+	for {
+		start := p.pos
+		p.skipSpace()
+		if len(p.buf) <= p.pos {
+			p.pos = start
+			return
+		}
+		var seg *strictSegment
+		switch p.buf[p.pos] {
+		case '.':
+			p.pos++
+			if p.pos < len(p.buf) && p.buf[p.pos] == '.' {
+				p.pos++
+				seg = p.readDescent()
+			} else {
+				seg = p.readDotChild()
+			}
+		case '[':
+			seg = &strictSegment{selectors: p.readBracket()}
+		default:
+			p.pos = start
+			return
+		}
+		segments = append(segments, seg)
+	}
+}
+
+func (p *strictParser) readDescent() *strictSegment {
+	if len(p.buf) <= p.pos {
+		p.raise("descendant segment not terminated")
+	}
+	seg := &strictSegment{descent: true}
+	switch p.buf[p.pos] {
+	case '[':
+		seg.selectors = p.readBracket()
+	case '*':
+		p.pos++
+		seg.selectors = []strictSelector{strictWildcard{}}
+	default:
+		seg.selectors = []strictSelector{strictName(p.readShorthand())}
+	}
+	return seg
+}
+
+func (p *strictParser) readDotChild() *strictSegment {
+	if len(p.buf) <= p.pos {
+		p.raise("child segment not terminated")
+	}
+	if p.buf[p.pos] == '*' {
+		p.pos++
+		return &strictSegment{selectors: []strictSelector{strictWildcard{}}}
+	}
+	return &strictSegment{selectors: []strictSelector{strictName(p.readShorthand())}}
+}
+
+func (p *strictParser) readShorthand() string {
+	start := p.pos
+	for p.pos < len(p.buf) {
+		r, size := utf8.DecodeRune(p.buf[p.pos:])
+		switch {
+		case r == '_', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		case start < p.pos && '0' <= r && r <= '9':
+		case r == utf8.RuneError && size <= 1:
+			p.raise("invalid UTF-8")
+		case 0x80 <= r && r <= 0xD7FF, 0xE000 <= r && r <= 0x10FFFF:
+		default:
+			if start == p.pos {
+				p.raise("expected a member name")
+			}
+			return string(p.buf[start:p.pos])
+		}
+		p.pos += size
+	}
+	if start == p.pos {
+		p.raise("expected a member name")
+	}
+	return string(p.buf[start:p.pos])
+}
+
+func (p *strictParser) readBracket() (selectors []strictSelector) {
+	p.pos++ // past [
+	for {
+		p.skipSpace()
+		selectors = append(selectors, p.readSelector())
+		p.skipSpace()
+		if len(p.buf) <= p.pos {
+			p.raise("bracket not terminated")
+		}
+		switch p.buf[p.pos] {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return
+		default:
+			p.raise("expected a ',' or ']'")
+		}
+	}
+}
+
+func (p *strictParser) readSelector() strictSelector {
+	if len(p.buf) <= p.pos {
+		p.raise("bracket not terminated")
+	}
+	switch b := p.buf[p.pos]; b {
+	case '\'', '"':
+		p.pos++
+		return strictName(p.readString(b))
+	case '*':
+		p.pos++
+		return strictWildcard{}
+	case '?':
+		p.pos++
+		p.skipSpace()
+		return &strictFilter{expr: p.toLogical(p.readOr())}
+	case ':', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return p.readIndexOrSlice()
+	}
+	p.raise("expected a selector")
+	return nil
+}
+
+func (p *strictParser) readIndexOrSlice() strictSelector {
+	var sel strictSlice
+	if p.buf[p.pos] != ':' {
+		sel.start = p.readInt()
+		sel.hasStart = true
+		start := p.pos
+		p.skipSpace()
+		if len(p.buf) <= p.pos || p.buf[p.pos] != ':' {
+			p.pos = start
+			return strictIndex(sel.start)
+		}
+	}
+	p.pos++ // past :
+	sel.step = 1
+	p.skipSpace()
+	if p.pos < len(p.buf) && p.buf[p.pos] != ':' && p.buf[p.pos] != ']' && p.buf[p.pos] != ',' {
+		sel.end = p.readInt()
+		sel.hasEnd = true
+		p.skipSpace()
+	}
+	if p.pos < len(p.buf) && p.buf[p.pos] == ':' {
+		p.pos++
+		p.skipSpace()
+		if p.pos < len(p.buf) && p.buf[p.pos] != ']' && p.buf[p.pos] != ',' {
+			sel.step = p.readInt()
+			sel.hasStep = true
+		}
+	}
+	return &sel
+}
+
+// readInt reads an integer that must not have leading zeros, must not be -0,
+// and must be within the I-JSON range.
+func (p *strictParser) readInt() int64 {
+	start := p.pos
+	if p.pos < len(p.buf) && p.buf[p.pos] == '-' {
+		p.pos++
+	}
+	digits := p.pos
+	for p.pos < len(p.buf) && '0' <= p.buf[p.pos] && p.buf[p.pos] <= '9' {
+		p.pos++
+	}
+	switch {
+	case digits == p.pos:
+		p.raise("expected an integer")
+	case p.buf[digits] == '0' && (1 < p.pos-digits || digits != start):
+		p.raise("invalid integer")
+	}
+	i, err := strconv.ParseInt(string(p.buf[start:p.pos]), 10, 64)
+	if err != nil || i < minStrictInt || maxStrictInt < i {
+		p.raise("integer out of range")
+	}
+	return i
+}
+
+func (p *strictParser) readString(quote byte) string {
+	var out []byte
+	for {
+		if len(p.buf) <= p.pos {
+			p.raise("string not terminated")
+		}
+		b := p.buf[p.pos]
+		switch {
+		case b == quote:
+			p.pos++
+			return string(out)
+		case b == '\\':
+			p.pos++
+			if len(p.buf) <= p.pos {
+				p.raise("string not terminated")
+			}
+			b = p.buf[p.pos]
+			p.pos++
+			switch b {
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case '/', '\\':
+				out = append(out, b)
+			case 'u':
+				out = utf8.AppendRune(out, p.readEscapedRune())
+			default:
+				if b != quote {
+					p.pos--
+					p.raise("invalid escape character '%c'", b)
+				}
+				out = append(out, b)
+			}
+		case b < 0x20:
+			p.raise("control character in string")
+		case b < 0x80:
+			out = append(out, b)
+			p.pos++
+		default:
+			r, size := utf8.DecodeRune(p.buf[p.pos:])
+			if r == utf8.RuneError && size <= 1 {
+				p.raise("invalid UTF-8")
+			}
+			out = append(out, p.buf[p.pos:p.pos+size]...)
+			p.pos += size
+		}
+	}
+}
+
+// readEscapedRune reads the hex digits of a \u escape including a following
+// low surrogate if the first is a high surrogate.
+func (p *strictParser) readEscapedRune() rune {
+	r := p.readHex4()
+	switch {
+	case 0xDC00 <= r && r <= 0xDFFF:
+		p.raise("invalid surrogate")
+	case 0xD800 <= r && r <= 0xDBFF:
+		if len(p.buf) < p.pos+2 || p.buf[p.pos] != '\\' || p.buf[p.pos+1] != 'u' {
+			p.raise("missing low surrogate")
+		}
+		p.pos += 2
+		low := p.readHex4()
+		if low < 0xDC00 || 0xDFFF < low {
+			p.raise("invalid low surrogate")
+		}
+		r = (r-0xD800)<<10 + (low - 0xDC00) + 0x10000
+	}
+	return r
+}
+
+func (p *strictParser) readHex4() (r rune) {
+	if len(p.buf) < p.pos+4 {
+		p.raise("invalid unicode escape")
+	}
+	for _, b := range p.buf[p.pos : p.pos+4] {
+		r <<= 4
+		switch {
+		case '0' <= b && b <= '9':
+			r += rune(b - '0')
+		case 'a' <= b && b <= 'f':
+			r += rune(b - 'a' + 10)
+		case 'A' <= b && b <= 'F':
+			r += rune(b - 'A' + 10)
+		default:
+			p.raise("invalid unicode escape")
+		}
+	}
+	p.pos += 4
+	return
+}
+
+// The filter expression readers return either a strictLogical or one of the
+// operands that may appear in a comparison or as a function argument which
+// are a *strictLiteral, a *strictQuery, or a *strictFunc. That allows the
+// caller to decide if the result is valid in the context it appears in.
+
+func (p *strictParser) readOr() any {
+	first := p.readAnd()
+	var list strictOr
+	for {
+		start := p.pos
+		p.skipSpace()
+		if !p.hasPrefix("||") {
+			p.pos = start
+			break
+		}
+		if list == nil {
+			list = strictOr{p.toLogical(first)}
+		}
+		p.pos += 2
+		p.skipSpace()
+		list = append(list, p.toLogical(p.readAnd()))
+	}
+	if list == nil {
+		return first
+	}
+	return list
+}
+
+func (p *strictParser) readAnd() any {
+	first := p.readBasic()
+	var list strictAnd
+	for {
+		start := p.pos
+		p.skipSpace()
+		if !p.hasPrefix("&&") {
+			p.pos = start
+			break
+		}
+		if list == nil {
+			list = strictAnd{p.toLogical(first)}
+		}
+		p.pos += 2
+		p.skipSpace()
+		list = append(list, p.toLogical(p.readBasic()))
+	}
+	if list == nil {
+		return first
+	}
+	return list
+}
+
+func (p *strictParser) readBasic() any {
+	if len(p.buf) <= p.pos {
+		p.raise("filter not terminated")
+	}
+	switch p.buf[p.pos] {
+	case '!':
+		p.pos++
+		p.skipSpace()
+		if p.pos < len(p.buf) && p.buf[p.pos] == '(' {
+			return &strictNot{arg: p.readParen()}
+		}
+		arg := p.readOperand()
+		if _, ok := arg.(*strictLiteral); ok {
+			p.raise("a literal can not be negated")
+		}
+		return &strictNot{arg: p.toLogical(arg)}
+	case '(':
+		return p.readParen()
+	}
+	left := p.readOperand()
+	start := p.pos
+	p.skipSpace()
+	op := p.readCompareOp()
+	if len(op) == 0 {
+		p.pos = start
+		return left
+	}
+	p.skipSpace()
+	right := p.readOperand()
+	return &strictCompare{op: op, left: p.toComparable(left), right: p.toComparable(right)}
+}
+
+func (p *strictParser) readParen() strictLogical {
+	p.pos++ // past (
+	p.skipSpace()
+	expr := p.toLogical(p.readOr())
+	p.skipSpace()
+	if len(p.buf) <= p.pos || p.buf[p.pos] != ')' {
+		p.raise("expected a ')'")
+	}
+	p.pos++
+	return expr
+}
+
+func (p *strictParser) readCompareOp() string {
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.hasPrefix(op) {
+			p.pos += len(op)
+			return op
+		}
+	}
+	return ""
+}
+
+func (p *strictParser) readOperand() any {
+	if len(p.buf) <= p.pos {
+		p.raise("filter not terminated")
+	}
+	switch b := p.buf[p.pos]; b {
+	case '@', '$':
+		p.pos++
+		return &strictQuery{relative: b == '@', segments: p.readSegments()}
+	case '\'', '"':
+		p.pos++
+		return &strictLiteral{value: p.readString(b)}
+	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return &strictLiteral{value: p.readNumber()}
+	}
+	switch {
+	case p.hasKeyword("true"):
+		p.pos += 4
+		return &strictLiteral{value: true}
+	case p.hasKeyword("false"):
+		p.pos += 5
+		return &strictLiteral{value: false}
+	case p.hasKeyword("null"):
+		p.pos += 4
+		return &strictLiteral{value: nil}
+	}
+	if b := p.buf[p.pos]; 'a' <= b && b <= 'z' {
+		return p.readFunction()
+	}
+	p.raise("expected a value")
+	return nil
+}
+
+func (p *strictParser) readNumber() any {
+	start := p.pos
+	if p.buf[p.pos] == '-' {
+		p.pos++
+	}
+	digits := p.pos
+	for p.pos < len(p.buf) && '0' <= p.buf[p.pos] && p.buf[p.pos] <= '9' {
+		p.pos++
+	}
+	if digits == p.pos || (p.buf[digits] == '0' && 1 < p.pos-digits) {
+		p.raise("invalid number")
+	}
+	isFloat := false
+	if p.pos < len(p.buf) && p.buf[p.pos] == '.' {
+		isFloat = true
+		p.pos++
+		frac := p.pos
+		for p.pos < len(p.buf) && '0' <= p.buf[p.pos] && p.buf[p.pos] <= '9' {
+			p.pos++
+		}
+		if frac == p.pos {
+			p.raise("invalid number")
+		}
+	}
+	if p.pos < len(p.buf) && (p.buf[p.pos] == 'e' || p.buf[p.pos] == 'E') {
+		isFloat = true
+		p.pos++
+		if p.pos < len(p.buf) && (p.buf[p.pos] == '-' || p.buf[p.pos] == '+') {
+			p.pos++
+		}
+		exp := p.pos
+		for p.pos < len(p.buf) && '0' <= p.buf[p.pos] && p.buf[p.pos] <= '9' {
+			p.pos++
+		}
+		if exp == p.pos {
+			p.raise("invalid number")
+		}
+	}
+	str := string(p.buf[start:p.pos])
+	if !isFloat {
+		if i, err := strconv.ParseInt(str, 10, 64); err == nil {
+			return i
+		}
+	}
+	f, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		p.raise("invalid number")
+	}
+	return f
+}
+
+func (p *strictParser) readFunction() *strictFunc {
+	start := p.pos
+	for p.pos < len(p.buf) {
+		b := p.buf[p.pos]
+		if ('a' <= b && b <= 'z') || ('0' <= b && b <= '9') || b == '_' {
+			p.pos++
+			continue
+		}
+		break
+	}
+	name := string(p.buf[start:p.pos])
+	def := strictFuncs[name]
+	if def == nil {
+		p.pos = start
+		p.raise("unknown function '%s'", name)
+	}
+	if len(p.buf) <= p.pos || p.buf[p.pos] != '(' {
+		p.raise("expected a '('")
+	}
+	p.pos++
+	f := &strictFunc{def: def}
+	p.skipSpace()
+	for p.pos < len(p.buf) && p.buf[p.pos] != ')' {
+		if 0 < len(f.args) {
+			if p.buf[p.pos] != ',' {
+				p.raise("expected a ','")
+			}
+			p.pos++
+			p.skipSpace()
+		}
+		if len(def.params) <= len(f.args) {
+			p.raise("too many arguments to %s", name)
+		}
+		f.args = append(f.args, p.toArg(p.readOr(), def.params[len(f.args)]))
+		p.skipSpace()
+	}
+	if len(p.buf) <= p.pos {
+		p.raise("function not terminated")
+	}
+	p.pos++ // past )
+	if len(f.args) != len(def.params) {
+		p.raise("%s requires %d arguments", name, len(def.params))
+	}
+	if def.name == "match" || def.name == "search" {
+		// A literal pattern is compiled once.
+		if lit, ok := f.args[1].(*strictLiteral); ok {
+			if pat, ok := lit.value.(string); ok {
+				f.rx, _ = compileIRegexp(pat, def.name == "match")
+			}
+		}
+	}
+	return f
+}
+
+// toArg checks that an argument is well typed for the function parameter
+// type and converts it if necessary.
+func (p *strictParser) toArg(arg any, param byte) any {
+	switch param {
+	case valueType:
+		return p.toComparable(arg)
+	case nodesType:
+		if q, ok := arg.(*strictQuery); ok {
+			return q
+		}
+		p.raise("function argument must be a query")
+	}
+	return p.toLogical(arg)
+}
+
+// toComparable checks that the operand is a literal, singular query, or a
+// function that returns a ValueType.
+func (p *strictParser) toComparable(v any) strictValue {
+	switch tv := v.(type) {
+	case *strictLiteral:
+		return tv
+	case *strictQuery:
+		if tv.singular() {
+			return tv
+		}
+		p.raise("a non-singular query can not be compared or used as a value")
+	case *strictFunc:
+		if tv.def.result == valueType {
+			return tv
+		}
+		p.raise("%s does not return a value", tv.def.name)
+	}
+	p.raise("a logical expression can not be compared or used as a value")
+	return nil
+}
+
+// toLogical converts a query to an existence test and checks that other
+// operands are valid as a logical expression.
+func (p *strictParser) toLogical(v any) strictLogical {
+	switch tv := v.(type) {
+	case strictLogical:
+		if f, ok := v.(*strictFunc); ok && f.def.result == valueType {
+			p.raise("%s does not return a logical or nodes type", f.def.name)
+		}
+		return tv
+	case *strictQuery:
+		return &strictExists{query: tv}
+	}
+	p.raise("a literal must be compared")
+	return nil
+}
+
+func (p *strictParser) hasPrefix(s string) bool {
+	return len(s) <= len(p.buf)-p.pos && string(p.buf[p.pos:p.pos+len(s)]) == s
+}
+
+// hasKeyword returns true if the keyword is next and is not followed by a
+// function name character.
+func (p *strictParser) hasKeyword(s string) bool {
+	if !p.hasPrefix(s) {
+		return false
+	}
+	if end := p.pos + len(s); end < len(p.buf) {
+		b := p.buf[end]
+		return !(('a' <= b && b <= 'z') || ('0' <= b && b <= '9') || b == '_' || b == '(')
+	}
+	return true
+}
+
+func (p *strictParser) skipSpace() {
+	for p.pos < len(p.buf) {
+		switch p.buf[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *strictParser) raise(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	panic(fmt.Errorf("%s at %d in %s", msg, p.pos+1, p.buf))
+}
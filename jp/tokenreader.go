@@ -0,0 +1,387 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package jp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/sen"
+)
+
+// TokenKind identifies the kind of a Token.
+type TokenKind byte
+
+const (
+	// NullToken is a JSON null.
+	NullToken = TokenKind('n')
+	// BoolToken is a JSON true or false.
+	BoolToken = TokenKind('b')
+	// IntToken is a number that fits in an int64.
+	IntToken = TokenKind('i')
+	// FloatToken is a number that fits in a float64.
+	FloatToken = TokenKind('f')
+	// NumberToken is a number that does not fit in an int64 or float64
+	// without a loss of precision. The text of the number is in the String
+	// field.
+	NumberToken = TokenKind('N')
+	// StringToken is a JSON string.
+	StringToken = TokenKind('s')
+	// KeyToken is the key of an object member.
+	KeyToken = TokenKind('k')
+	// ObjectStartToken is the start of a JSON object.
+	ObjectStartToken = TokenKind('{')
+	// ObjectEndToken is the end of a JSON object.
+	ObjectEndToken = TokenKind('}')
+	// ArrayStartToken is the start of a JSON array.
+	ArrayStartToken = TokenKind('[')
+	// ArrayEndToken is the end of a JSON array.
+	ArrayEndToken = TokenKind(']')
+)
+
+// Token is a token read by a TokenReader. Only the field that matches the
+// Kind is set.
+type Token struct {
+	Kind   TokenKind
+	Bool   bool
+	Int    int64
+	Float  float64
+	String string
+}
+
+// Value returns the value of a scalar token or the key of a KeyToken. Nil
+// is returned for the start and end of objects and arrays.
+func (t Token) Value() (v any) {
+	switch t.Kind {
+	case BoolToken:
+		v = t.Bool
+	case IntToken:
+		v = t.Int
+	case FloatToken:
+		v = t.Float
+	case NumberToken:
+		v = json.Number(t.String)
+	case StringToken, KeyToken:
+		v = t.String
+	}
+	return
+}
+
+const trReadSize = 4096
+
+// trTokenizer is the incremental interface shared by oj.Tokenizer and
+// sen.Tokenizer.
+type trTokenizer interface {
+	Start(handler oj.TokenHandler)
+	Feed(buf []byte, last bool) error
+}
+
+// TokenReader reads the tokens of JSON or SEN documents from an io.Reader
+// one at a time. It is a pull alternative to the push oj.Tokenizer and
+// sen.Tokenizer that is better suited to recursive-descent decoders. The
+// tokenizer is fed a buffer at a time and the tokens it produces are queued
+// until they are read. Multiple documents can be read from the same
+// reader. The path of the most recent token and the current depth are
+// available to help the caller keep track of where it is in a document.
+type TokenReader struct {
+	r         io.Reader
+	tokenizer trTokenizer
+	buf       []byte
+	queue     tokenQueue
+	head      int
+	eof       bool
+	err       error
+	frames    []trFrame
+	path      Expr
+}
+
+type trFrame struct {
+	key    string
+	index  int
+	object bool
+	child  bool
+}
+
+// NewTokenReader returns a new TokenReader that reads JSON from r.
+func NewTokenReader(r io.Reader) *TokenReader {
+	return newTokenReader(r, &oj.Tokenizer{})
+}
+
+// NewSENTokenReader returns a new TokenReader that reads SEN from r. Since
+// SEN is a superset of JSON, JSON can also be read.
+func NewSENTokenReader(r io.Reader) *TokenReader {
+	return newTokenReader(r, &sen.Tokenizer{})
+}
+
+func newTokenReader(r io.Reader, tokenizer trTokenizer) *TokenReader {
+	tr := TokenReader{
+		tokenizer: tokenizer,
+		buf:       make([]byte, trReadSize),
+		queue:     make(tokenQueue, 0, 64),
+		frames:    make([]trFrame, 0, 16),
+		path:      Expr{Root('$')},
+	}
+	tr.Reset(r)
+
+	return &tr
+}
+
+// Reset discards any state and buffered data and switches the reader to
+// read from r. Buffers are reused so a TokenReader can be reset instead of
+// creating a new TokenReader for each input.
+func (tr *TokenReader) Reset(r io.Reader) {
+	tr.r = r
+	tr.queue = tr.queue[:0]
+	tr.head = 0
+	tr.eof = false
+	tr.err = nil
+	tr.frames = tr.frames[:0]
+	tr.path = tr.path[:1]
+	tr.tokenizer.Start(&tr.queue)
+}
+
+// Next reads and returns the next token. At the end of the input io.EOF is
+// returned. If the input is not valid an *oj.ParseError is returned.
+func (tr *TokenReader) Next() (Token, error) {
+	if len(tr.queue) <= tr.head {
+		if err := tr.fill(); err != nil {
+			return Token{}, err
+		}
+	}
+	t := &tr.queue[tr.head]
+	tr.head++
+	tr.apply(t)
+
+	return *t, nil
+}
+
+// Peek returns the next token without consuming it. The path and depth are
+// not changed.
+func (tr *TokenReader) Peek() (t Token, err error) {
+	if len(tr.queue) <= tr.head {
+		if err = tr.fill(); err != nil {
+			return
+		}
+	}
+	return tr.queue[tr.head], nil
+}
+
+// Path returns the path of the most recent token returned by Next. The path
+// of a key or value is the path to the value and the path of the start or
+// end of an object or array is the path to the object or array. The path
+// returned is reused so it should be copied if it needs to be saved.
+func (tr *TokenReader) Path() Expr {
+	// The path is built when asked for to avoid the cost of building
+	// fragments for every token.
+	tr.path = tr.path[:1]
+	for _, f := range tr.frames {
+		switch {
+		case !f.child:
+		case f.object:
+			tr.path = append(tr.path, Child(f.key))
+		default:
+			tr.path = append(tr.path, Nth(f.index))
+		}
+	}
+	return tr.path
+}
+
+// Depth returns the number of objects and arrays that have been started but
+// not yet ended.
+func (tr *TokenReader) Depth() int {
+	return len(tr.frames)
+}
+
+// Skip reads past the next value including all of its contents. If the next
+// token is a key then both the key and the member value are skipped. If the
+// next token ends an object or array then the end is read and the reader
+// moves out of that object or array.
+func (tr *TokenReader) Skip() (err error) {
+	var t Token
+	if t, err = tr.Next(); err != nil {
+		return
+	}
+	if t.Kind == KeyToken {
+		if t, err = tr.Next(); err != nil {
+			return
+		}
+	}
+	if t.Kind == ObjectStartToken || t.Kind == ArrayStartToken {
+		for depth := len(tr.frames) - 1; depth < len(tr.frames); {
+			if _, err = tr.Next(); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// Decode reads the next value and decodes it into the value pointed to by
+// vp. If vp is a *any then the value is set to the simple data read
+// otherwise alt.Recompose is used to decode into structs and other types.
+// If the next token is a key then the member value is decoded.
+func (tr *TokenReader) Decode(vp any) (err error) {
+	var v any
+	if v, err = tr.value(); err != nil {
+		return
+	}
+	if ap, ok := vp.(*any); ok {
+		*ap = v
+		return
+	}
+	_, err = alt.Recompose(v, vp)
+
+	return
+}
+
+func (tr *TokenReader) value() (v any, err error) {
+	var t Token
+	if t, err = tr.Next(); err != nil {
+		return
+	}
+	if t.Kind == KeyToken {
+		if t, err = tr.Next(); err != nil {
+			return
+		}
+	}
+	switch t.Kind {
+	case ObjectStartToken:
+		obj := map[string]any{}
+		for {
+			if t, err = tr.Next(); err != nil {
+				return
+			}
+			if t.Kind == ObjectEndToken {
+				break
+			}
+			if obj[t.String], err = tr.value(); err != nil {
+				return
+			}
+		}
+		v = obj
+	case ArrayStartToken:
+		list := []any{}
+		for {
+			if t, err = tr.Peek(); err != nil {
+				return
+			}
+			if t.Kind == ArrayEndToken {
+				_, err = tr.Next()
+				break
+			}
+			var m any
+			if m, err = tr.value(); err != nil {
+				return
+			}
+			list = append(list, m)
+		}
+		v = list
+	case ObjectEndToken, ArrayEndToken:
+		err = fmt.Errorf("expected a value, not '%c'", t.Kind)
+	default:
+		v = t.Value()
+	}
+	return
+}
+
+// apply updates the frames for a token returned from Next.
+func (tr *TokenReader) apply(t *Token) {
+	switch t.Kind {
+	case KeyToken:
+		f := &tr.frames[len(tr.frames)-1]
+		f.key = t.String
+		f.child = true
+	case ObjectEndToken, ArrayEndToken:
+		tr.frames = tr.frames[:len(tr.frames)-1]
+	default:
+		if 0 < len(tr.frames) {
+			if f := &tr.frames[len(tr.frames)-1]; !f.object {
+				f.index++
+				f.child = true
+			}
+		}
+		switch t.Kind {
+		case ObjectStartToken:
+			tr.frames = append(tr.frames, trFrame{object: true})
+		case ArrayStartToken:
+			tr.frames = append(tr.frames, trFrame{index: -1})
+		}
+	}
+}
+
+// fill feeds the tokenizer until at least one token has been queued. Any
+// tokens completed before an error are returned before the error.
+func (tr *TokenReader) fill() error {
+	tr.queue = tr.queue[:0]
+	tr.head = 0
+	for len(tr.queue) == 0 {
+		switch {
+		case tr.err != nil:
+			return tr.err
+		case tr.eof:
+			return io.EOF
+		}
+		cnt, err := tr.r.Read(tr.buf)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				tr.err = err
+				continue
+			}
+			tr.eof = true
+		}
+		tr.err = tr.tokenizer.Feed(tr.buf[:cnt], tr.eof)
+	}
+	return nil
+}
+
+// tokenQueue is the oj.TokenHandler that queues the tokens produced by the
+// tokenizer.
+type tokenQueue []Token
+
+func (q *tokenQueue) Null() {
+	*q = append(*q, Token{Kind: NullToken})
+}
+
+func (q *tokenQueue) Bool(v bool) {
+	*q = append(*q, Token{Kind: BoolToken, Bool: v})
+}
+
+func (q *tokenQueue) Int(v int64) {
+	*q = append(*q, Token{Kind: IntToken, Int: v})
+}
+
+func (q *tokenQueue) Float(v float64) {
+	*q = append(*q, Token{Kind: FloatToken, Float: v})
+}
+
+func (q *tokenQueue) Number(v string) {
+	*q = append(*q, Token{Kind: NumberToken, String: v})
+}
+
+func (q *tokenQueue) String(v string) {
+	*q = append(*q, Token{Kind: StringToken, String: v})
+}
+
+func (q *tokenQueue) ObjectStart() {
+	*q = append(*q, Token{Kind: ObjectStartToken})
+}
+
+func (q *tokenQueue) ObjectEnd() {
+	*q = append(*q, Token{Kind: ObjectEndToken})
+}
+
+func (q *tokenQueue) Key(v string) {
+	*q = append(*q, Token{Kind: KeyToken, String: v})
+}
+
+func (q *tokenQueue) ArrayStart() {
+	*q = append(*q, Token{Kind: ArrayStartToken})
+}
+
+func (q *tokenQueue) ArrayEnd() {
+	*q = append(*q, Token{Kind: ArrayEndToken})
+}
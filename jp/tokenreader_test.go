@@ -0,0 +1,275 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package jp_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+const tokenReaderTestJSON = `{
+  "a": [1, -2.5, "x\tyé😀", true, null],
+  "b": {"c": {}, "d": []},
+  "big": 123456789012345678901234567890
+}
+[false]`
+
+func tokenReaderTrace(tr *jp.TokenReader) (trace []string, err error) {
+	for {
+		var tok jp.Token
+		if tok, err = tr.Next(); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return
+		}
+		trace = append(trace, fmt.Sprintf("%c %v %s %d", tok.Kind, tok.Value(), tr.Path(), tr.Depth()))
+	}
+}
+
+func TestTokenReaderNext(t *testing.T) {
+	expect := []string{
+		"{ <nil> $ 1",
+		"k a $.a 1",
+		"[ <nil> $.a 2",
+		"i 1 $.a[0] 2",
+		"f -2.5 $.a[1] 2",
+		"s x\tyé😀 $.a[2] 2",
+		"b true $.a[3] 2",
+		"n <nil> $.a[4] 2",
+		"] <nil> $.a 1",
+		"k b $.b 1",
+		"{ <nil> $.b 2",
+		"k c $.b.c 2",
+		"{ <nil> $.b.c 3",
+		"} <nil> $.b.c 2",
+		"k d $.b.d 2",
+		"[ <nil> $.b.d 3",
+		"] <nil> $.b.d 2",
+		"} <nil> $.b 1",
+		"k big $.big 1",
+		"N 123456789012345678901234567890 $.big 1",
+		"} <nil> $ 0",
+		"[ <nil> $ 1",
+		"b false $[0] 1",
+		"] <nil> $ 0",
+	}
+	for _, r := range []io.Reader{
+		strings.NewReader(tokenReaderTestJSON),
+		iotest.OneByteReader(strings.NewReader(tokenReaderTestJSON)),
+	} {
+		trace, err := tokenReaderTrace(jp.NewTokenReader(r))
+		tt.Nil(t, err)
+		tt.Equal(t, strings.Join(expect, "\n"), strings.Join(trace, "\n"))
+	}
+}
+
+func TestTokenReaderNumbers(t *testing.T) {
+	src := `[0, -0, 12, -12, 999999999999999999, 9223372036854775807, 9223372036854775808, -9223372036854775808,
+ 1.5, -0.25e2, 1E+2, 2e-1, 0.1234567890123456789012]`
+	for _, r := range []io.Reader{strings.NewReader(src), iotest.OneByteReader(strings.NewReader(src))} {
+		trace, err := tokenReaderTrace(jp.NewTokenReader(r))
+		tt.Nil(t, err)
+		tt.Equal(t, `[ <nil> $ 1
+i 0 $[0] 1
+i 0 $[1] 1
+i 12 $[2] 1
+i -12 $[3] 1
+i 999999999999999999 $[4] 1
+i 9223372036854775807 $[5] 1
+N 9223372036854775808 $[6] 1
+N -9223372036854775808 $[7] 1
+f 1.5 $[8] 1
+f -25 $[9] 1
+f 100 $[10] 1
+f 0.2 $[11] 1
+N 0.1234567890123456789012 $[12] 1
+] <nil> $ 0`, strings.Join(trace, "\n"))
+	}
+}
+
+func TestTokenReaderLarge(t *testing.T) {
+	long := strings.Repeat("abc\\n", 3000)
+	src := fmt.Sprintf(`["%s", %s]`, long, strings.Repeat("1", 5000))
+	tr := jp.NewTokenReader(strings.NewReader(src))
+	var v any
+	err := tr.Decode(&v)
+	tt.Nil(t, err)
+	list := v.([]any)
+	tt.Equal(t, strings.Repeat("abc\n", 3000), list[0])
+	tt.Equal(t, strings.Repeat("1", 5000), fmt.Sprint(list[1]))
+}
+
+func TestTokenReaderPeekSkip(t *testing.T) {
+	tr := jp.NewTokenReader(strings.NewReader(tokenReaderTestJSON))
+	tok, err := tr.Next()
+	tt.Nil(t, err)
+	tt.Equal(t, jp.ObjectStartToken, tok.Kind)
+
+	tok, err = tr.Peek()
+	tt.Nil(t, err)
+	tt.Equal(t, "a", tok.String)
+	tt.Equal(t, "$", tr.Path().String())
+
+	// Skip the a member.
+	tt.Nil(t, tr.Skip())
+	tt.Equal(t, "$.a", tr.Path().String())
+	tt.Equal(t, 1, tr.Depth())
+
+	tok, err = tr.Next()
+	tt.Nil(t, err)
+	tt.Equal(t, "b", tok.String)
+
+	// Skip the value of b.
+	tt.Nil(t, tr.Skip())
+	tt.Equal(t, "$.b", tr.Path().String())
+	tt.Equal(t, 1, tr.Depth())
+
+	tok, err = tr.Next()
+	tt.Nil(t, err)
+	tt.Equal(t, "big", tok.String)
+
+	// Skip the value of big and then the end of the object.
+	tt.Nil(t, tr.Skip())
+	tt.Nil(t, tr.Skip())
+	tt.Equal(t, 0, tr.Depth())
+
+	tok, err = tr.Next()
+	tt.Nil(t, err)
+	tt.Equal(t, jp.ArrayStartToken, tok.Kind)
+	tt.Nil(t, tr.Skip())
+	tt.Nil(t, tr.Skip())
+
+	_, err = tr.Next()
+	tt.Equal(t, io.EOF, err)
+
+	tr.Reset(strings.NewReader(`{"x":[1]}`))
+	trace, err := tokenReaderTrace(tr)
+	tt.Nil(t, err)
+	tt.Equal(t, "{ <nil> $ 1|k x $.x 1|[ <nil> $.x 2|i 1 $.x[0] 2|] <nil> $.x 1|} <nil> $ 0", strings.Join(trace, "|"))
+}
+
+type tokenReaderSample struct {
+	C map[string]any
+	D []int
+}
+
+func TestTokenReaderDecode(t *testing.T) {
+	tr := jp.NewTokenReader(strings.NewReader(tokenReaderTestJSON))
+	_, err := tr.Next()
+	tt.Nil(t, err)
+
+	var a any
+	tt.Nil(t, tr.Decode(&a))
+	tt.Equal(t, `[1,-2.5,"x\tyé😀",true,null]`, oj.JSON(a))
+	tt.Equal(t, "$.a", tr.Path().String())
+
+	var sample tokenReaderSample
+	tt.Nil(t, tr.Decode(&sample))
+	tt.Equal(t, 0, len(sample.C))
+	tt.NotNil(t, sample.D)
+	tt.Equal(t, "$.b", tr.Path().String())
+	tt.Equal(t, 1, tr.Depth())
+
+	var big any
+	tt.Nil(t, tr.Decode(&big))
+	tt.Equal(t, "123456789012345678901234567890", fmt.Sprint(big))
+}
+
+func TestTokenReaderErrors(t *testing.T) {
+	for i, d := range []struct {
+		src    string
+		expect string
+	}{
+		{src: `[1,]`, expect: "unexpected character ']' at 1:4"},
+		{src: `{"a" 1}`, expect: "expected a colon, not '1' at 1:6"},
+		{src: `{"a":1,}`, expect: "expected a string start, not '}' at 1:8"},
+		{src: `{"a":1]`, expect: "unexpected array close at 1:7"},
+		{src: "[\n  tru]", expect: "expected true at 2:6"},
+		{src: `[01]`, expect: "invalid number at 1:3"},
+		{src: `[1e]`, expect: "invalid number at 1:4"},
+		{src: `[-]`, expect: "invalid number at 1:3"},
+		{src: `["a\x"]`, expect: `invalid JSON escape character '\x' at 1:5`},
+		{src: `["\u12x4"]`, expect: "invalid JSON unicode character 'x' at 1:7"},
+		{src: "[\"a\tb\"]", expect: "invalid JSON character 0x09 at 1:4"},
+		{src: `["abc`, expect: "incomplete JSON at 1:6"},
+		{src: `[1`, expect: "incomplete JSON at 1:3"},
+		{src: `[1 2]`, expect: "expected a comma or close, not '2' at 1:4"},
+		{src: `}`, expect: "unexpected object close at 1:1"},
+	} {
+		for _, r := range []io.Reader{
+			strings.NewReader(d.src),
+			iotest.OneByteReader(strings.NewReader(d.src)),
+		} {
+			_, err := tokenReaderTrace(jp.NewTokenReader(r))
+			tt.NotNil(t, err, i, ": ", d.src)
+			tt.Equal(t, d.expect, strings.Split(err.Error(), "\n")[0], i, ": ", d.src)
+		}
+	}
+	// Tokens before an error are returned before the error.
+	tr := jp.NewTokenReader(strings.NewReader(`[1 2]`))
+	trace, err := tokenReaderTrace(tr)
+	tt.NotNil(t, err)
+	tt.Equal(t, "[ <nil> $ 1|i 1 $[0] 1", strings.Join(trace, "|"))
+
+	tr = jp.NewTokenReader(strings.NewReader(`[]`))
+	_, err = tr.Next()
+	tt.Nil(t, err)
+	var v any
+	err = tr.Decode(&v)
+	tt.NotNil(t, err)
+	tt.Equal(t, "expected a value, not ']'", err.Error())
+}
+
+func TestTokenReaderSEN(t *testing.T) {
+	src := `{a: [1 -2.5 "x" true null] // comment
+  b: {c: {} d: [abc]}
+}
+[false]`
+	expect := []string{
+		"{ <nil> $ 1",
+		"k a $.a 1",
+		"[ <nil> $.a 2",
+		"i 1 $.a[0] 2",
+		"f -2.5 $.a[1] 2",
+		"s x $.a[2] 2",
+		"b true $.a[3] 2",
+		"n <nil> $.a[4] 2",
+		"] <nil> $.a 1",
+		"k b $.b 1",
+		"{ <nil> $.b 2",
+		"k c $.b.c 2",
+		"{ <nil> $.b.c 3",
+		"} <nil> $.b.c 2",
+		"k d $.b.d 2",
+		"[ <nil> $.b.d 3",
+		"s abc $.b.d[0] 3",
+		"] <nil> $.b.d 2",
+		"} <nil> $.b 1",
+		"} <nil> $ 0",
+		"[ <nil> $ 1",
+		"b false $[0] 1",
+		"] <nil> $ 0",
+	}
+	for _, r := range []io.Reader{strings.NewReader(src), iotest.OneByteReader(strings.NewReader(src))} {
+		trace, err := tokenReaderTrace(jp.NewSENTokenReader(r))
+		tt.Nil(t, err)
+		tt.Equal(t, strings.Join(expect, "\n"), strings.Join(trace, "\n"))
+	}
+	tr := jp.NewSENTokenReader(strings.NewReader("{a: 1\n  b: [2}"))
+	_, err := tokenReaderTrace(tr)
+	tt.NotNil(t, err)
+	tt.Equal(t, "unexpected object close at 2:8", strings.Split(err.Error(), "\n")[0])
+
+	tr.Reset(strings.NewReader("{a:[1 2]}"))
+	var v any
+	tt.Nil(t, tr.Decode(&v))
+	tt.Equal(t, `{"a":[1,2]}`, oj.JSON(v))
+}
@@ -128,6 +128,14 @@ func (f Union) removeOne(value any) (out any, changed bool) {
 				}
 			}
 		}
+	case *gen.Ordered:
+		for i, m := range tv.Members {
+			if f.hasKey(m.Key) {
+				tv.Members = append(tv.Members[:i], tv.Members[i+1:]...)
+				changed = true
+				break
+			}
+		}
 	default:
 		rv := reflect.ValueOf(value)
 		switch rv.Kind() {
@@ -217,6 +225,16 @@ func (f Union) remove(value any) (out any, changed bool) {
 				changed = true
 			}
 		}
+	case *gen.Ordered:
+		members := tv.Members[:0]
+		for _, m := range tv.Members {
+			if f.hasKey(m.Key) {
+				changed = true
+			} else {
+				members = append(members, m)
+			}
+		}
+		tv.Members = members
 	default:
 		rv := reflect.ValueOf(value)
 		switch rv.Kind() {
@@ -38,6 +38,10 @@ func walk(path Expr, data any, cb func(path Expr, value any)) {
 		for k, v := range td {
 			walk(append(path, Child(k)), v, cb)
 		}
+	case *gen.Ordered:
+		for _, m := range td.Members {
+			walk(append(path, Child(m.Key)), m.Value, cb)
+		}
 	default:
 		// TBD use reflection
 	}
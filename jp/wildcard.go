@@ -56,6 +56,11 @@ func (f Wildcard) remove(value any) (out any, changed bool) {
 				delete(tv, k)
 			}
 		}
+	case *gen.Ordered:
+		if 0 < len(tv.Members) {
+			changed = true
+			tv.Members = tv.Members[:0]
+		}
 	default:
 		rv := reflect.ValueOf(value)
 		switch rv.Kind() {
@@ -107,6 +112,11 @@ func (f Wildcard) removeOne(value any) (out any, changed bool) {
 			sort.Strings(keys)
 			delete(tv, keys[0])
 		}
+	case *gen.Ordered:
+		if 0 < len(tv.Members) {
+			changed = true
+			tv.Members = tv.Members[1:]
+		}
 	default:
 		rv := reflect.ValueOf(value)
 		switch rv.Kind() {
@@ -0,0 +1,95 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package ojg
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Limit identifies one of the Limits in a LimitError.
+type Limit byte
+
+const (
+	// DepthLimit is the limit on the nesting depth of objects and arrays.
+	DepthLimit = Limit('d')
+	// StringLimit is the limit on the length of a string or key in bytes.
+	StringLimit = Limit('s')
+	// MembersLimit is the limit on the number of members in an object or
+	// elements in an array.
+	MembersLimit = Limit('m')
+	// BytesLimit is the limit on the total number of bytes read.
+	BytesLimit = Limit('b')
+	// NumberLimit is the limit on the length of a number literal.
+	NumberLimit = Limit('n')
+)
+
+// String returns a description of the limit.
+func (l Limit) String() string {
+	switch l {
+	case DepthLimit:
+		return "depth"
+	case StringLimit:
+		return "string length"
+	case MembersLimit:
+		return "members"
+	case BytesLimit:
+		return "bytes"
+	case NumberLimit:
+		return "number length"
+	}
+	return fmt.Sprintf("limit %c", l)
+}
+
+// Limits are resource limits for parsing untrusted input with the oj, sen,
+// and gen parsers, the oj.Tokenizer, and the oj.Validator. A limit of zero
+// means there is no limit. When a limit is exceeded parsing stops and a
+// *LimitError is returned.
+type Limits struct {
+	// MaxDepth is the maximum nesting depth of objects and arrays.
+	MaxDepth int
+
+	// MaxStringLen is the maximum length in bytes of a string or key after
+	// escape sequences are replaced.
+	MaxStringLen int
+
+	// MaxMembers is the maximum number of members in an object or elements
+	// in an array.
+	MaxMembers int
+
+	// MaxBytes is the maximum number of bytes in the input.
+	MaxBytes int
+
+	// MaxNumberLen is the maximum length of a number literal including the
+	// sign, decimal point, and exponent.
+	MaxNumberLen int
+}
+
+// CheckBytes returns a *LimitError if buf is longer than MaxBytes. The
+// position in the error is that of the first byte over the limit.
+func (l *Limits) CheckBytes(buf []byte) error {
+	if 0 < l.MaxBytes && l.MaxBytes < len(buf) {
+		head := buf[:l.MaxBytes]
+		return &LimitError{
+			Limit:  BytesLimit,
+			Max:    l.MaxBytes,
+			Line:   bytes.Count(head, []byte{'\n'}) + 1,
+			Column: l.MaxBytes - bytes.LastIndexByte(head, '\n'),
+		}
+	}
+	return nil
+}
+
+// LimitError is returned when parsing stops because one of the Limits was
+// exceeded.
+type LimitError struct {
+	Limit  Limit
+	Max    int
+	Line   int
+	Column int
+}
+
+// Error returns a string representation of the error.
+func (err *LimitError) Error() string {
+	return fmt.Sprintf("maximum %s of %d exceeded at %d:%d", err.Limit, err.Max, err.Line, err.Column)
+}
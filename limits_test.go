@@ -0,0 +1,98 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package ojg_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/sen"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestLimitsCheckBytes(t *testing.T) {
+	limits := ojg.Limits{MaxBytes: 8}
+	tt.Nil(t, limits.CheckBytes([]byte("[1,\n2,3]")))
+
+	err := limits.CheckBytes([]byte("[1,\n2,3,4]"))
+	tt.Equal(t, "maximum bytes of 8 exceeded at 2:5", err.Error())
+	le, _ := err.(*ojg.LimitError)
+	tt.Equal(t, ojg.BytesLimit, le.Limit)
+	tt.Equal(t, 8, le.Max)
+
+	limits.MaxBytes = 0
+	tt.Nil(t, limits.CheckBytes([]byte("[1,2,3,4]")))
+}
+
+func TestLimitString(t *testing.T) {
+	for _, d := range []struct {
+		limit  ojg.Limit
+		expect string
+	}{
+		{limit: ojg.DepthLimit, expect: "depth"},
+		{limit: ojg.StringLimit, expect: "string length"},
+		{limit: ojg.MembersLimit, expect: "members"},
+		{limit: ojg.BytesLimit, expect: "bytes"},
+		{limit: ojg.NumberLimit, expect: "number length"},
+		{limit: ojg.Limit('x'), expect: "limit x"},
+	} {
+		tt.Equal(t, d.expect, d.limit.String())
+	}
+}
+
+func TestLimitsPosition(t *testing.T) {
+	pad := strings.Repeat(" ", 4094)
+	for i, d := range []struct {
+		src    string
+		limits ojg.Limits
+		expect string
+	}{
+		{src: `[1, 1234567]`, limits: ojg.Limits{MaxNumberLen: 5}, expect: "maximum number length of 5 exceeded at 1:5"},
+		{src: "[1,\n -1.5e100]", limits: ojg.Limits{MaxNumberLen: 5}, expect: "maximum number length of 5 exceeded at 2:2"},
+		{src: pad + "[123456789]", limits: ojg.Limits{MaxNumberLen: 5}, expect: "maximum number length of 5 exceeded at 1:4096"},
+		{src: "[1,\n 2, 3]", limits: ojg.Limits{MaxMembers: 2}, expect: "maximum members of 2 exceeded at 2:5"},
+		{src: `{"a":1, "b":[] , "c":3}`, limits: ojg.Limits{MaxMembers: 2}, expect: "maximum members of 2 exceeded at 1:18"},
+		{src: `[[], {} , [], {}]`, limits: ojg.Limits{MaxMembers: 3}, expect: "maximum members of 3 exceeded at 1:15"},
+		{src: pad + `[1,2,3]`, limits: ojg.Limits{MaxMembers: 2}, expect: "maximum members of 2 exceeded at 1:4100"},
+	} {
+		readers := func() []io.Reader {
+			return []io.Reader{strings.NewReader(d.src), iotest.OneByteReader(strings.NewReader(d.src))}
+		}
+		errs := []error{}
+		_, err := (&oj.Parser{Limits: d.limits}).Parse([]byte(d.src))
+		errs = append(errs, err)
+		_, err = (&sen.Parser{Limits: d.limits}).Parse([]byte(d.src))
+		errs = append(errs, err)
+		_, err = (&gen.Parser{Limits: d.limits}).Parse([]byte(d.src))
+		errs = append(errs, err)
+		errs = append(errs, (&oj.Tokenizer{Limits: d.limits}).Parse([]byte(d.src), &oj.ZeroHandler{}))
+		errs = append(errs, (&oj.Validator{Limits: d.limits}).Validate([]byte(d.src)))
+		for _, r := range readers() {
+			_, err = (&oj.Parser{Limits: d.limits}).ParseReader(r)
+			errs = append(errs, err)
+		}
+		for _, r := range readers() {
+			_, err = (&sen.Parser{Limits: d.limits}).ParseReader(r)
+			errs = append(errs, err)
+		}
+		for _, r := range readers() {
+			_, err = (&gen.Parser{Limits: d.limits}).ParseReader(r)
+			errs = append(errs, err)
+		}
+		for _, r := range readers() {
+			errs = append(errs, (&oj.Tokenizer{Limits: d.limits}).Load(r, &oj.ZeroHandler{}))
+		}
+		for _, r := range readers() {
+			errs = append(errs, (&oj.Validator{Limits: d.limits}).ValidateReader(r))
+		}
+		for j, err := range errs {
+			tt.NotNil(t, err, i, ".", j)
+			tt.Equal(t, d.expect, strings.Split(err.Error(), "\n")[0], i, ".", j)
+		}
+	}
+}
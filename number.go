@@ -0,0 +1,39 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package ojg
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// AppendNumber appends an arbitrary precision number to a buffer without
+// converting it to a float64. The number must be a json.Number, *big.Int,
+// or *big.Float. An empty json.Number is appended as 0, the same as the
+// encoding/json package, and a nil *big.Int or *big.Float is appended as
+// null. An infinite *big.Float can not be represented in JSON so an error
+// is returned.
+func AppendNumber(buf []byte, num any) ([]byte, error) {
+	switch tn := num.(type) {
+	case json.Number:
+		if len(tn) == 0 {
+			return append(buf, '0'), nil
+		}
+		return append(buf, tn...), nil
+	case *big.Int:
+		if tn == nil {
+			return append(buf, "null"...), nil
+		}
+		return tn.Append(buf, 10), nil
+	case *big.Float:
+		if tn == nil {
+			return append(buf, "null"...), nil
+		}
+		if tn.IsInf() {
+			return buf, fmt.Errorf("%s is not a valid JSON number", tn.String())
+		}
+		return tn.Append(buf, 'g', -1), nil
+	}
+	return append(buf, "null"...), nil
+}
@@ -0,0 +1,355 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package oj
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/gen"
+)
+
+const lowerHex = "0123456789abcdef"
+
+// Canonical returns the RFC 8785 JSON Canonicalization Scheme (JCS)
+// encoding of the data. Members are sorted by the UTF-16 code units of
+// their keys, strings are escaped only where required, and numbers are
+// written as IEEE 754 doubles in the ECMAScript format. Integers that do not
+// fit in a double lose precision as required by JCS. Values other than
+// simple types, gen.Node, and gen.Ordered such as structs are converted as
+// they would be by Marshal using the Writer options so the struct related
+// options apply while the formatting options are ignored. An error is
+// returned for NaN, infinities, and strings that are not valid UTF-8.
+func (wr *Writer) Canonical(data any) (out []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			wr.buf = wr.buf[:0]
+			err = ojg.NewError(r)
+		}
+	}()
+	wr.buf = wr.buf[:0]
+	wr.appendCanonical(data)
+	out = make([]byte, len(wr.buf))
+	copy(out, wr.buf)
+
+	return
+}
+
+// Canonical returns the RFC 8785 JSON Canonicalization Scheme (JCS)
+// encoding of the data. The args, if supplied, can be a *ojg.Options or a
+// *Writer. See Writer.Canonical for details.
+func Canonical(data any, args ...any) ([]byte, error) {
+	var wr *Writer
+	if 0 < len(args) {
+		wr = pickWriter(args[0], true)
+	}
+	if wr == nil {
+		wr, _ = marshalPool.Get().(*Writer)
+		defer marshalPool.Put(wr)
+	}
+	return wr.Canonical(data)
+}
+
+// Digest returns the SHA-256 digest of the Canonical encoding of the data.
+// Equivalent data has the same digest regardless of the order of object
+// members or how numbers and strings were originally formatted.
+func Digest(data any, args ...any) (sum [sha256.Size]byte, err error) {
+	var js []byte
+	if js, err = Canonical(data, args...); err == nil {
+		sum = sha256.Sum256(js)
+	}
+	return
+}
+
+func (wr *Writer) appendCanonical(data any) {
+	switch td := data.(type) {
+	case nil:
+		wr.buf = append(wr.buf, "null"...)
+	case bool:
+		if td {
+			wr.buf = append(wr.buf, "true"...)
+		} else {
+			wr.buf = append(wr.buf, "false"...)
+		}
+	case int:
+		wr.buf = appendES6Number(wr.buf, float64(td))
+	case int8:
+		wr.buf = appendES6Number(wr.buf, float64(td))
+	case int16:
+		wr.buf = appendES6Number(wr.buf, float64(td))
+	case int32:
+		wr.buf = appendES6Number(wr.buf, float64(td))
+	case int64:
+		wr.buf = appendES6Number(wr.buf, float64(td))
+	case uint:
+		wr.buf = appendES6Number(wr.buf, float64(td))
+	case uint8:
+		wr.buf = appendES6Number(wr.buf, float64(td))
+	case uint16:
+		wr.buf = appendES6Number(wr.buf, float64(td))
+	case uint32:
+		wr.buf = appendES6Number(wr.buf, float64(td))
+	case uint64:
+		wr.buf = appendES6Number(wr.buf, float64(td))
+	case float32:
+		// Use the value as it would be written in JSON.
+		f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(td), 'g', -1, 32), 64)
+		wr.buf = appendES6Number(wr.buf, f)
+	case float64:
+		wr.buf = appendES6Number(wr.buf, td)
+	case json.Number:
+		wr.buf = appendES6Number(wr.buf, parseCanonicalFloat(string(td)))
+	case gen.Big:
+		wr.buf = appendES6Number(wr.buf, parseCanonicalFloat(string(td)))
+	case *big.Int:
+		if td == nil {
+			wr.buf = append(wr.buf, "null"...)
+			break
+		}
+		f, _ := new(big.Float).SetInt(td).Float64()
+		wr.buf = appendES6Number(wr.buf, f)
+	case *big.Float:
+		if td == nil {
+			wr.buf = append(wr.buf, "null"...)
+			break
+		}
+		f, _ := td.Float64()
+		wr.buf = appendES6Number(wr.buf, f)
+	case string:
+		wr.buf = appendCanonicalString(wr.buf, td)
+	case []any:
+		wr.buf = append(wr.buf, '[')
+		for i, v := range td {
+			if 0 < i {
+				wr.buf = append(wr.buf, ',')
+			}
+			wr.appendCanonical(v)
+		}
+		wr.buf = append(wr.buf, ']')
+	case map[string]any:
+		keys := make([]string, 0, len(td))
+		for k := range td {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return utf16Less(keys[i], keys[j]) })
+		wr.buf = append(wr.buf, '{')
+		for i, k := range keys {
+			if 0 < i {
+				wr.buf = append(wr.buf, ',')
+			}
+			wr.buf = appendCanonicalString(wr.buf, k)
+			wr.buf = append(wr.buf, ':')
+			wr.appendCanonical(td[k])
+		}
+		wr.buf = append(wr.buf, '}')
+	case *gen.Ordered:
+		if td == nil {
+			wr.buf = append(wr.buf, "null"...)
+			break
+		}
+		members := append([]gen.Member{}, td.Members...)
+		sort.SliceStable(members, func(i, j int) bool { return utf16Less(members[i].Key, members[j].Key) })
+		wr.buf = append(wr.buf, '{')
+		for i, m := range members {
+			if 0 < i {
+				wr.buf = append(wr.buf, ',')
+			}
+			wr.buf = appendCanonicalString(wr.buf, m.Key)
+			wr.buf = append(wr.buf, ':')
+			wr.appendCanonical(m.Value)
+		}
+		wr.buf = append(wr.buf, '}')
+	case gen.Array:
+		wr.buf = append(wr.buf, '[')
+		for i, v := range td {
+			if 0 < i {
+				wr.buf = append(wr.buf, ',')
+			}
+			wr.appendCanonical(v)
+		}
+		wr.buf = append(wr.buf, ']')
+	case gen.Object:
+		keys := make([]string, 0, len(td))
+		for k := range td {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return utf16Less(keys[i], keys[j]) })
+		wr.buf = append(wr.buf, '{')
+		for i, k := range keys {
+			if 0 < i {
+				wr.buf = append(wr.buf, ',')
+			}
+			wr.buf = appendCanonicalString(wr.buf, k)
+			wr.buf = append(wr.buf, ':')
+			wr.appendCanonical(td[k])
+		}
+		wr.buf = append(wr.buf, '}')
+	case gen.Node:
+		// Containers are handled above so only leaf values are left and
+		// they simplify to the values handled above.
+		wr.appendCanonical(td.Simplify())
+	default:
+		// Anything else is written as JSON using the writer options and
+		// then read back to be written in canonical form.
+		sub := Writer{Options: wr.Options, strict: true}
+		sub.Indent = 0
+		sub.Tab = false
+		sub.Color = false
+		sub.Sort = false
+		sub.MustJSON(data)
+		v, err := (&Parser{NumConv: gen.JSONNumber}).Parse(sub.buf)
+		if err != nil {
+			panic(err)
+		}
+		wr.appendCanonical(v)
+	}
+}
+
+func parseCanonicalFloat(s string) float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		panic(fmt.Errorf("%s can not be represented as a canonical JSON number", s))
+	}
+	return f
+}
+
+// appendES6Number appends a number in the format of the ECMAScript
+// Number.prototype.toString() as required by RFC 8785.
+func appendES6Number(buf []byte, f float64) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		panic(fmt.Errorf("%v can not be represented as a canonical JSON number", f))
+	}
+	if f == 0 { // also -0
+		return append(buf, '0')
+	}
+	if f < 0 {
+		buf = append(buf, '-')
+		f = -f
+	}
+	// The shortest digits that round trip are the same as the ECMAScript
+	// digits. Go formats them as d.ddde±xx.
+	var tmp [32]byte
+	e := strconv.AppendFloat(tmp[:0], f, 'e', -1, 64)
+	x := 0
+	for i := len(e) - 1; 0 < i; i-- {
+		if e[i] == 'e' {
+			x, _ = strconv.Atoi(string(e[i+1:]))
+			e = e[:i]
+			break
+		}
+	}
+	digits := make([]byte, 0, len(e))
+	for _, b := range e {
+		if b != '.' {
+			digits = append(digits, b)
+		}
+	}
+	k := len(digits)
+	n := x + 1 // position of the decimal point
+	switch {
+	case k <= n && n <= 21:
+		buf = append(buf, digits...)
+		for i := k; i < n; i++ {
+			buf = append(buf, '0')
+		}
+	case 0 < n && n <= 21:
+		buf = append(buf, digits[:n]...)
+		buf = append(buf, '.')
+		buf = append(buf, digits[n:]...)
+	case -6 < n && n <= 0:
+		buf = append(buf, "0."...)
+		for i := n; i < 0; i++ {
+			buf = append(buf, '0')
+		}
+		buf = append(buf, digits...)
+	default:
+		buf = append(buf, digits[0])
+		if 1 < k {
+			buf = append(buf, '.')
+			buf = append(buf, digits[1:]...)
+		}
+		buf = append(buf, 'e')
+		if 0 < n {
+			buf = append(buf, '+')
+		}
+		buf = strconv.AppendInt(buf, int64(n-1), 10)
+	}
+	return buf
+}
+
+// appendCanonicalString appends a string escaping only the quote,
+// backslash, and control characters as required by RFC 8785.
+func appendCanonicalString(buf []byte, s string) []byte {
+	if !utf8.ValidString(s) {
+		panic(fmt.Errorf("%q is not a valid UTF-8 string", s))
+	}
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+		if b != '"' && b != '\\' && 0x20 <= b {
+			continue
+		}
+		buf = append(buf, s[start:i]...)
+		switch b {
+		case '"':
+			buf = append(buf, `\"`...)
+		case '\\':
+			buf = append(buf, `\\`...)
+		case '\b':
+			buf = append(buf, `\b`...)
+		case '\f':
+			buf = append(buf, `\f`...)
+		case '\n':
+			buf = append(buf, `\n`...)
+		case '\r':
+			buf = append(buf, `\r`...)
+		case '\t':
+			buf = append(buf, `\t`...)
+		default:
+			buf = append(buf, `\u00`...)
+			buf = append(buf, lowerHex[b>>4], lowerHex[b&0x0f])
+		}
+		start = i + 1
+	}
+	buf = append(buf, s[start:]...)
+
+	return append(buf, '"')
+}
+
+// utf16Less returns true if s0 is before s1 when compared by UTF-16 code
+// units.
+func utf16Less(s0, s1 string) bool {
+	for 0 < len(s0) && 0 < len(s1) {
+		r0, n0 := utf8.DecodeRuneInString(s0)
+		r1, n1 := utf8.DecodeRuneInString(s1)
+		if r0 != r1 {
+			u0, l0 := utf16Units(r0)
+			u1, l1 := utf16Units(r1)
+			if u0 != u1 {
+				return u0 < u1
+			}
+			return l0 < l1
+		}
+		s0 = s0[n0:]
+		s1 = s1[n1:]
+	}
+	return len(s0) < len(s1)
+}
+
+// utf16Units returns the first and second UTF-16 code units of a rune. The
+// second is zero unless the rune requires a surrogate pair.
+func utf16Units(r rune) (rune, rune) {
+	if r < 0x10000 {
+		return r, 0
+	}
+	r -= 0x10000
+	return 0xd800 + (r>>10)&0x3ff, 0xdc00 + r&0x3ff
+}
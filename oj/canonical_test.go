@@ -0,0 +1,168 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package oj_test
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestCanonicalNumbers(t *testing.T) {
+	// Test vectors from RFC 8785 Appendix B.
+	for i, d := range []struct {
+		bits   uint64
+		expect string
+	}{
+		{bits: 0x0000000000000000, expect: "0"},
+		{bits: 0x8000000000000000, expect: "0"},
+		{bits: 0x0000000000000001, expect: "5e-324"},
+		{bits: 0x8000000000000001, expect: "-5e-324"},
+		{bits: 0x7fefffffffffffff, expect: "1.7976931348623157e+308"},
+		{bits: 0xffefffffffffffff, expect: "-1.7976931348623157e+308"},
+		{bits: 0x4340000000000000, expect: "9007199254740992"},
+		{bits: 0xc340000000000000, expect: "-9007199254740992"},
+		{bits: 0x4430000000000000, expect: "295147905179352830000"},
+		{bits: 0x44b52d02c7e14af5, expect: "9.999999999999997e+22"},
+		{bits: 0x44b52d02c7e14af6, expect: "1e+23"},
+		{bits: 0x44b52d02c7e14af7, expect: "1.0000000000000001e+23"},
+		{bits: 0x444b1ae4d6e2ef4e, expect: "999999999999999700000"},
+		{bits: 0x444b1ae4d6e2ef4f, expect: "999999999999999900000"},
+		{bits: 0x444b1ae4d6e2ef50, expect: "1e+21"},
+		{bits: 0x3eb0c6f7a0b5ed8c, expect: "9.999999999999997e-7"},
+		{bits: 0x3eb0c6f7a0b5ed8d, expect: "0.000001"},
+		{bits: 0x41b3de4355555553, expect: "333333333.3333332"},
+		{bits: 0x41b3de4355555554, expect: "333333333.33333325"},
+		{bits: 0x41b3de4355555555, expect: "333333333.3333333"},
+		{bits: 0x41b3de4355555556, expect: "333333333.3333334"},
+		{bits: 0x41b3de4355555557, expect: "333333333.33333343"},
+		{bits: 0xbecbf647612f3696, expect: "-0.0000033333333333333333"},
+		{bits: 0x43143ff3c1cb0959, expect: "1424953923781206.2"},
+	} {
+		out, err := oj.Canonical(math.Float64frombits(d.bits))
+		tt.Nil(t, err, i, ": ", d.expect)
+		tt.Equal(t, d.expect, string(out), i, ": ", d.expect)
+	}
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		_, err := oj.Canonical([]any{f})
+		tt.NotNil(t, err, f)
+	}
+	out, err := oj.Canonical([]any{
+		int64(9007199254740993),
+		uint8(7),
+		float32(0.1),
+		json.Number("1.50"),
+		big.NewInt(1000),
+		gen.Big("12345678901234567890"),
+	})
+	tt.Nil(t, err)
+	tt.Equal(t, "[9007199254740992,7,0.1,1.5,1000,12345678901234567000]", string(out))
+
+	_, err = oj.Canonical(json.Number("1e999"))
+	tt.NotNil(t, err)
+
+	// A gen.Big nested in gen containers is still a number.
+	out, err = oj.Canonical(gen.Object{
+		"b": gen.Array{gen.Big("1.50"), gen.Object{"c": gen.Big("12345678901234567890")}},
+		"a": gen.Big("-0.0"),
+	})
+	tt.Nil(t, err)
+	tt.Equal(t, `{"a":0,"b":[1.5,{"c":12345678901234567000}]}`, string(out))
+
+	_, err = oj.Canonical(gen.Array{gen.Big("1e999")})
+	tt.NotNil(t, err)
+}
+
+func TestCanonicalSample(t *testing.T) {
+	// Example from RFC 8785 section 3.2.2.
+	src := `{
+  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`
+	v, err := (&oj.Parser{NumConv: gen.JSONNumber}).Parse([]byte(src))
+	tt.Nil(t, err)
+	out, err := oj.Canonical(v)
+	tt.Nil(t, err)
+	tt.Equal(t,
+		`{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		string(out))
+}
+
+func TestCanonicalSort(t *testing.T) {
+	// Example from RFC 8785 section 3.2.3.
+	v := map[string]any{
+		"\u20ac":       "Euro Sign",
+		"\r":           "Carriage Return",
+		"\ufb33":       "Hebrew Letter Dalet With Dagesh",
+		"1":            "One",
+		"\U0001f600":   "Emoji: Grinning Face",
+		"\u0080":       "Control",
+		"\u00f6":       "Latin Small Letter O With Diaeresis",
+		"\u0080\u0080": "Control Control",
+	}
+	out, err := oj.Canonical(v)
+	tt.Nil(t, err)
+	tt.Equal(t,
+		"{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"\u0080\u0080\":\"Control Control\","+
+			"\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\","+
+			"\"\U0001f600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		string(out))
+
+	_, err = oj.Canonical("bad \xff")
+	tt.NotNil(t, err)
+}
+
+type canonSample struct {
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+	Tags  []string
+	Skip  *int `json:",omitempty"`
+}
+
+func TestCanonicalTypes(t *testing.T) {
+	expect := `{"Tags":["<a>","b"],"name":"x\u001f","score":2.5}`
+	for i, v := range []any{
+		&canonSample{Name: "x\x1f", Score: 2.5, Tags: []string{"<a>", "b"}},
+		map[string]any{"score": 2.5, "name": "x\x1f", "Tags": []any{"<a>", "b"}},
+		&gen.Ordered{Members: []gen.Member{
+			{Key: "score", Value: 2.5},
+			{Key: "name", Value: "x\x1f"},
+			{Key: "Tags", Value: []any{"<a>", "b"}},
+		}},
+		gen.Object{"score": gen.Float(2.5), "name": gen.String("x\x1f"), "Tags": gen.Array{gen.String("<a>"), gen.String("b")}},
+	} {
+		out, err := oj.Canonical(v)
+		tt.Nil(t, err, i)
+		tt.Equal(t, expect, string(out), i)
+	}
+	// Formatting options are ignored.
+	out, err := oj.Canonical(map[string]any{"b": 1, "a": []any{true}}, &ojg.Options{Indent: 2, Color: true})
+	tt.Nil(t, err)
+	tt.Equal(t, `{"a":[true],"b":1}`, string(out))
+}
+
+func TestDigest(t *testing.T) {
+	d0, err := oj.Digest(oj.MustParseString(`{"a": 1.0, "b": [1, "x"], "c": {"y": null, "x": 2e0}}`))
+	tt.Nil(t, err)
+	d1, err := oj.Digest(&gen.Ordered{Members: []gen.Member{
+		{Key: "c", Value: map[string]any{"x": 2, "y": nil}},
+		{Key: "b", Value: []any{1.0, "x"}},
+		{Key: "a", Value: 1},
+	}})
+	tt.Nil(t, err)
+	tt.Equal(t, d0, d1)
+
+	d2, err := oj.Digest(map[string]any{"a": 1, "b": []any{"x", 1}})
+	tt.Nil(t, err)
+	tt.Equal(t, true, d0 != d2)
+
+	_, err = oj.Digest(math.NaN())
+	tt.NotNil(t, err)
+}
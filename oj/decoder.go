@@ -0,0 +1,794 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package oj
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/ohler55/ojg"
+)
+
+const (
+	hookChecked     = byte(0x01)
+	hookUnmarshaler = byte(0x02)
+	hookText        = byte(0x04)
+)
+
+var (
+	unmarshalerType     = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Decoder unmarshals JSON directly into Go values. Unlike Unmarshal no
+// intermediate map[string]any and []any tree is built. Instead structs,
+// slices, arrays, maps, and pointers are filled in as the JSON is read. The
+// struct field information is shared with the Writer so the same keys are
+// used for decoding that are used when writing with the same options.
+//
+// Values that implement json.Unmarshaler are passed the raw JSON for the
+// value. Values that implement encoding.TextUnmarshaler are passed the
+// contents of JSON strings. Values decoded into an empty interface are the
+// same simple types returned by Unmarshal with all numbers as float64.
+//
+// A Decoder can be reused but is not safe for concurrent use.
+type Decoder struct {
+	buf   []byte
+	off   int
+	tmp   []byte
+	hooks map[reflect.Type]byte
+
+	// UseTags if true matches keys against the json tag names of struct
+	// fields as the Writer does when the UseTags option is set.
+	UseTags bool
+
+	// KeyExact if true requires keys to match exactly. Otherwise keys are
+	// matched against field names with a lowercase first letter, the same
+	// as the Writer, and then by a case insensitive comparison.
+	KeyExact bool
+}
+
+// Unmarshal decodes the JSON in data and stores the result in the value
+// pointed to by vp.
+func (d *Decoder) Unmarshal(data []byte, vp any) (err error) {
+	rv := reflect.ValueOf(vp)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("can only decode into a non-nil pointer, not a %T", vp)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = e
+			} else {
+				err = ojg.NewError(r)
+			}
+		}
+		d.buf = nil
+	}()
+	d.buf = data
+	d.off = 0
+	// Skip BOM if present.
+	if 3 <= len(data) && data[0] == 0xEF && data[1] == 0xBB && data[2] == 0xBF {
+		d.off = 3
+	}
+	d.value(rv.Elem())
+	d.skipSpace()
+	if d.off < len(d.buf) {
+		d.fail("extra characters after close, '%c'", d.buf[d.off])
+	}
+	return
+}
+
+// MustUnmarshal decodes the JSON in data and stores the result in the value
+// pointed to by vp. Panics on error.
+func (d *Decoder) MustUnmarshal(data []byte, vp any) {
+	if err := d.Unmarshal(data, vp); err != nil {
+		panic(err)
+	}
+}
+
+func (d *Decoder) fail(format string, args ...any) {
+	err := &ParseError{Message: fmt.Sprintf(format, args...), Line: 1, Column: d.off + 1}
+	for i, b := range d.buf[:d.off] {
+		if b == '\n' {
+			err.Line++
+			err.Column = d.off - i
+		}
+	}
+	panic(err)
+}
+
+func (d *Decoder) skipSpace() {
+	for ; d.off < len(d.buf); d.off++ {
+		switch d.buf[d.off] {
+		case ' ', '\t', '\n', '\r':
+		default:
+			return
+		}
+	}
+}
+
+// next skips white space and returns the next byte without consuming it.
+func (d *Decoder) next() byte {
+	d.skipSpace()
+	if len(d.buf) <= d.off {
+		d.fail("incomplete JSON")
+	}
+	return d.buf[d.off]
+}
+
+// open consumes the opening byte of an object or array and returns true if
+// the object or array is not empty.
+func (d *Decoder) open(end byte) bool {
+	d.off++
+	if d.next() == end {
+		d.off++
+		return false
+	}
+	return true
+}
+
+// more returns true if there is another member or element after a comma or
+// false if the object or array ends.
+func (d *Decoder) more(end byte) bool {
+	switch d.next() {
+	case ',':
+		d.off++
+		return true
+	case end:
+		d.off++
+		return false
+	}
+	d.fail("expected a comma or close, not '%c'", d.buf[d.off])
+	return false
+}
+
+// key reads an object key and the following colon.
+func (d *Decoder) key() []byte {
+	if d.next() != '"' {
+		d.fail("expected a string start, not '%c'", d.buf[d.off])
+	}
+	key := d.str()
+	if d.next() != ':' {
+		d.fail("expected a colon, not '%c'", d.buf[d.off])
+	}
+	d.off++
+	return key
+}
+
+func (d *Decoder) literal(word string) {
+	if len(d.buf) < d.off+len(word) || string(d.buf[d.off:d.off+len(word)]) != word {
+		d.fail("expected %s", word)
+	}
+	d.off += len(word)
+}
+
+// str reads a string starting at the opening quote. The returned slice
+// references either the data being decoded or the tmp buffer so it is only
+// valid until the next string is read.
+func (d *Decoder) str() []byte {
+	start := d.off + 1
+	for i := start; i < len(d.buf); i++ {
+		b := d.buf[i]
+		if b == '"' {
+			d.off = i + 1
+			return d.buf[start:i]
+		}
+		if b == '\\' {
+			return d.escStr(start, i)
+		}
+		if b < 0x20 {
+			d.off = i
+			d.fail("invalid JSON character 0x%02x", b)
+		}
+	}
+	d.off = len(d.buf)
+	d.fail("incomplete JSON")
+	return nil
+}
+
+func (d *Decoder) escStr(start, i int) []byte {
+	d.tmp = append(d.tmp[:0], d.buf[start:i]...)
+	for ; i < len(d.buf); i++ {
+		b := d.buf[i]
+		switch {
+		case b == '"':
+			d.off = i + 1
+			return d.tmp
+		case b < 0x20:
+			d.off = i
+			d.fail("invalid JSON character 0x%02x", b)
+		case b != '\\':
+			d.tmp = append(d.tmp, b)
+			continue
+		}
+		if i++; len(d.buf) <= i {
+			break
+		}
+		switch b = d.buf[i]; b {
+		case '"', '\\', '/':
+			d.tmp = append(d.tmp, b)
+		case 'b':
+			d.tmp = append(d.tmp, '\b')
+		case 'f':
+			d.tmp = append(d.tmp, '\f')
+		case 'n':
+			d.tmp = append(d.tmp, '\n')
+		case 'r':
+			d.tmp = append(d.tmp, '\r')
+		case 't':
+			d.tmp = append(d.tmp, '\t')
+		case 'u':
+			r := d.hex4(i + 1)
+			i += 4
+			if utf16.IsSurrogate(r) && i+6 < len(d.buf) && d.buf[i+1] == '\\' && d.buf[i+2] == 'u' {
+				if r2 := utf16.DecodeRune(r, d.hex4(i+3)); r2 != utf8.RuneError {
+					r = r2
+					i += 6
+				}
+			}
+			d.tmp = utf8.AppendRune(d.tmp, r)
+		default:
+			d.off = i
+			d.fail("invalid JSON escape character '\\%c'", b)
+		}
+	}
+	d.off = len(d.buf)
+	d.fail("incomplete JSON")
+	return nil
+}
+
+func (d *Decoder) hex4(start int) (r rune) {
+	if len(d.buf) < start+4 {
+		d.off = len(d.buf)
+		d.fail("incomplete JSON")
+	}
+	for i, b := range d.buf[start : start+4] {
+		switch {
+		case '0' <= b && b <= '9':
+			r = r<<4 | rune(b-'0')
+		case 'a' <= b && b <= 'f':
+			r = r<<4 | rune(b-'a'+10)
+		case 'A' <= b && b <= 'F':
+			r = r<<4 | rune(b-'A'+10)
+		default:
+			d.off = start + i
+			d.fail("invalid JSON unicode character '%c'", b)
+		}
+	}
+	return
+}
+
+// number reads a number and returns the bytes of the number. The second
+// return value is true if the number is an integer.
+func (d *Decoder) number() ([]byte, bool) {
+	start := d.off
+	i := start
+	digits := func() int {
+		n := i
+		for ; i < len(d.buf) && '0' <= d.buf[i] && d.buf[i] <= '9'; i++ {
+		}
+		return i - n
+	}
+	if i < len(d.buf) && d.buf[i] == '-' {
+		i++
+	}
+	if n := digits(); n == 0 || (1 < n && d.buf[i-n] == '0') {
+		if n == 0 && i == start {
+			d.fail("unexpected character '%c'", d.buf[i])
+		}
+		if d.off = i; 0 < n {
+			d.off = i - n + 1 // leading zero
+		}
+		d.fail("invalid number")
+	}
+	isInt := true
+	if i < len(d.buf) && d.buf[i] == '.' {
+		i++
+		if digits() == 0 {
+			d.off = i
+			d.fail("invalid number")
+		}
+		isInt = false
+	}
+	if i < len(d.buf) && (d.buf[i] == 'e' || d.buf[i] == 'E') {
+		i++
+		if i < len(d.buf) && (d.buf[i] == '+' || d.buf[i] == '-') {
+			i++
+		}
+		if digits() == 0 {
+			d.off = i
+			d.fail("invalid number")
+		}
+		isInt = false
+	}
+	d.off = i
+	return d.buf[start:i], isInt
+}
+
+// skip reads and discards a value and returns the raw bytes for the value.
+func (d *Decoder) skip() []byte {
+	start := d.off
+	switch d.next() {
+	case '{':
+		if d.open('}') {
+			for {
+				d.key()
+				d.skip()
+				if !d.more('}') {
+					break
+				}
+			}
+		}
+	case '[':
+		if d.open(']') {
+			for {
+				d.skip()
+				if !d.more(']') {
+					break
+				}
+			}
+		}
+	case '"':
+		d.str()
+	case 't':
+		d.literal("true")
+	case 'f':
+		d.literal("false")
+	case 'n':
+		d.literal("null")
+	default:
+		d.number()
+	}
+	return d.buf[start:d.off]
+}
+
+// simple reads a value as one of the simple types.
+func (d *Decoder) simple() (v any) {
+	switch d.next() {
+	case '{':
+		m := map[string]any{}
+		if d.open('}') {
+			for {
+				k := string(d.key())
+				m[k] = d.simple()
+				if !d.more('}') {
+					break
+				}
+			}
+		}
+		v = m
+	case '[':
+		a := []any{}
+		if d.open(']') {
+			for {
+				a = append(a, d.simple())
+				if !d.more(']') {
+					break
+				}
+			}
+		}
+		v = a
+	case '"':
+		v = string(d.str())
+	case 't':
+		d.literal("true")
+		v = true
+	case 'f':
+		d.literal("false")
+		v = false
+	case 'n':
+		d.literal("null")
+	default:
+		num, _ := d.number()
+		f, _ := strconv.ParseFloat(string(num), 64)
+		v = f
+	}
+	return
+}
+
+func (d *Decoder) hook(rt reflect.Type) byte {
+	if rt.PkgPath() == "" && rt.Kind() != reflect.Struct {
+		return 0 // unnamed types other than structs can not have methods
+	}
+	if d.hooks == nil {
+		d.hooks = map[reflect.Type]byte{}
+	}
+	h := d.hooks[rt]
+	if h == 0 {
+		h = hookChecked
+		pt := reflect.PointerTo(rt)
+		if pt.Implements(unmarshalerType) {
+			h |= hookUnmarshaler
+		}
+		if pt.Implements(textUnmarshalerType) {
+			h |= hookText
+		}
+		d.hooks[rt] = h
+	}
+	return h
+}
+
+func (d *Decoder) value(rv reflect.Value) {
+	b := d.next()
+	rt := rv.Type()
+	if h := d.hook(rt); hookChecked < h && rv.CanAddr() {
+		if (h & hookUnmarshaler) != 0 {
+			start := d.off
+			raw := d.skip()
+			if err := rv.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(raw); err != nil {
+				d.off = start
+				d.fail("%s", err)
+			}
+			return
+		}
+		if (h&hookText) != 0 && b == '"' {
+			start := d.off
+			if err := rv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(d.str()); err != nil {
+				d.off = start
+				d.fail("%s", err)
+			}
+			return
+		}
+	}
+	if b == 'n' {
+		d.literal("null")
+		switch rt.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+			rv.Set(reflect.Zero(rt))
+		}
+		return
+	}
+	switch rt.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rt.Elem()))
+		}
+		d.value(rv.Elem())
+	case reflect.Struct:
+		if b != '{' {
+			d.mismatch(rt)
+		}
+		d.object(rv)
+	case reflect.Map:
+		if b != '{' {
+			d.mismatch(rt)
+		}
+		d.mapValue(rv)
+	case reflect.Slice:
+		switch {
+		case b == '[':
+			d.slice(rv)
+		case b == '"' && rt.Elem().Kind() == reflect.Uint8:
+			start := d.off
+			s := d.str()
+			buf := make([]byte, base64.StdEncoding.DecodedLen(len(s)))
+			n, err := base64.StdEncoding.Decode(buf, s)
+			if err != nil {
+				d.off = start
+				d.fail("%s", err)
+			}
+			rv.SetBytes(buf[:n])
+		default:
+			d.mismatch(rt)
+		}
+	case reflect.Array:
+		if b != '[' {
+			d.mismatch(rt)
+		}
+		d.array(rv)
+	case reflect.Interface:
+		switch {
+		case !rv.IsNil() && rv.Elem().Kind() == reflect.Ptr && !rv.Elem().IsNil():
+			d.value(rv.Elem())
+		case rt.NumMethod() == 0:
+			if v := d.simple(); v != nil {
+				rv.Set(reflect.ValueOf(v))
+			} else {
+				rv.Set(reflect.Zero(rt))
+			}
+		default:
+			d.mismatch(rt)
+		}
+	case reflect.String:
+		if b != '"' {
+			d.mismatch(rt)
+		}
+		rv.SetString(string(d.str()))
+	case reflect.Bool:
+		switch b {
+		case 't':
+			d.literal("true")
+			rv.SetBool(true)
+		case 'f':
+			d.literal("false")
+			rv.SetBool(false)
+		default:
+			d.mismatch(rt)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		start := d.off
+		if num, isInt := d.integer(rt); isInt {
+			i, err := strconv.ParseInt(string(num), 10, 64)
+			if err != nil || rv.OverflowInt(i) {
+				d.off = start
+				d.fail("%s overflows a %s", num, rt)
+			}
+			rv.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		start := d.off
+		if num, isInt := d.integer(rt); isInt {
+			u, err := strconv.ParseUint(string(num), 10, 64)
+			if err != nil || rv.OverflowUint(u) {
+				d.off = start
+				d.fail("%s overflows a %s", num, rt)
+			}
+			rv.SetUint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		if b != '-' && (b < '0' || '9' < b) {
+			d.mismatch(rt)
+		}
+		start := d.off
+		num, _ := d.number()
+		f, err := strconv.ParseFloat(string(num), rt.Bits())
+		if err != nil {
+			d.off = start
+			d.fail("%s overflows a %s", num, rt)
+		}
+		rv.SetFloat(f)
+	default:
+		d.fail("can not decode into a %s", rt)
+	}
+}
+
+// integer reads an integer for the type rt. Fails if the value is not an
+// integer.
+func (d *Decoder) integer(rt reflect.Type) ([]byte, bool) {
+	start := d.off
+	if b := d.buf[d.off]; b != '-' && (b < '0' || '9' < b) {
+		d.mismatch(rt)
+	}
+	num, isInt := d.number()
+	if !isInt {
+		d.off = start
+		d.fail("can not decode %s into a %s", num, rt)
+	}
+	return num, isInt
+}
+
+func (d *Decoder) mismatch(rt reflect.Type) {
+	var kind string
+	switch d.buf[d.off] {
+	case '{':
+		kind = "an object"
+	case '[':
+		kind = "an array"
+	case '"':
+		kind = "a string"
+	case 't', 'f':
+		kind = "a boolean"
+	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		kind = "a number"
+	default:
+		d.fail("unexpected character '%c'", d.buf[d.off])
+	}
+	d.fail("can not decode %s into a %s", kind, rt)
+}
+
+func (d *Decoder) object(rv reflect.Value) {
+	if !d.open('}') {
+		return
+	}
+	fields := d.fields(rv.Type())
+	for {
+		switch fi := d.field(fields, d.key()); {
+		case fi == nil:
+			d.skip()
+		case fi.asString:
+			d.quoted(fieldValue(rv, fi.index))
+		default:
+			d.value(fieldValue(rv, fi.index))
+		}
+		if !d.more('}') {
+			break
+		}
+	}
+}
+
+// quoted decodes a bool or number that is written as a JSON string as
+// directed by the string option of a json tag, the same as encoding/json.
+// Other kinds and null are decoded as usual.
+func (d *Decoder) quoted(rv reflect.Value) {
+	rt := rv.Type()
+	switch rt.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		if hookChecked < d.hook(rt) || d.next() == 'n' {
+			d.value(rv)
+			return
+		}
+	default:
+		d.value(rv)
+		return
+	}
+	if d.buf[d.off] != '"' {
+		d.mismatch(rt)
+	}
+	start := d.off
+	s := d.str()
+	sub := Decoder{}
+	if err := sub.Unmarshal(s, rv.Addr().Interface()); err != nil {
+		d.off = start
+		d.fail("can not decode %q into a %s", s, rt)
+	}
+}
+
+func (d *Decoder) fields(rt reflect.Type) []*finfo {
+	var fx byte
+	if d.UseTags {
+		fx |= maskByTag
+	}
+	if d.KeyExact {
+		fx |= maskExact
+	}
+	structMut.Lock()
+	st := getTypeStruct(rt, false, false)
+	structMut.Unlock()
+
+	return st.fields[fx]
+}
+
+// field finds the field that matches the key with a binary search of the
+// sorted fields, falling back to a case insensitive match unless KeyExact
+// is set.
+func (d *Decoder) field(fields []*finfo, key []byte) *finfo {
+	lo, hi := 0, len(fields)
+	for lo < hi {
+		m := int(uint(lo+hi) >> 1)
+		if fields[m].key < string(key) {
+			lo = m + 1
+		} else {
+			hi = m
+		}
+	}
+	if lo < len(fields) && fields[lo].key == string(key) {
+		return fields[lo]
+	}
+	if !d.KeyExact {
+		k := string(key)
+		for _, fi := range fields {
+			if strings.EqualFold(fi.key, k) {
+				return fi
+			}
+		}
+	}
+	return nil
+}
+
+// fieldValue returns the field at index, allocating any nil embedded
+// struct pointers along the way.
+func fieldValue(rv reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if 0 < i && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv
+}
+
+func (d *Decoder) mapValue(rv reflect.Value) {
+	rt := rv.Type()
+	kt := rt.Key()
+	switch kt.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		d.fail("can not decode into a map with %s keys", kt)
+	}
+	if rv.IsNil() {
+		rv.Set(reflect.MakeMap(rt))
+	}
+	if !d.open('}') {
+		return
+	}
+	kv := reflect.New(kt).Elem()
+	ev := reflect.New(rt.Elem()).Elem()
+	zero := reflect.Zero(rt.Elem())
+	for {
+		start := d.off
+		key := d.key()
+		switch kt.Kind() {
+		case reflect.String:
+			kv.SetString(string(key))
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i, err := strconv.ParseInt(string(key), 10, 64)
+			if err != nil || kv.OverflowInt(i) {
+				d.off = start
+				d.fail("can not decode key '%s' into a %s", key, kt)
+			}
+			kv.SetInt(i)
+		default:
+			u, err := strconv.ParseUint(string(key), 10, 64)
+			if err != nil || kv.OverflowUint(u) {
+				d.off = start
+				d.fail("can not decode key '%s' into a %s", key, kt)
+			}
+			kv.SetUint(u)
+		}
+		ev.Set(zero)
+		d.value(ev)
+		rv.SetMapIndex(kv, ev)
+		if !d.more('}') {
+			break
+		}
+	}
+}
+
+func (d *Decoder) slice(rv reflect.Value) {
+	rt := rv.Type()
+	if rv.IsNil() {
+		rv.Set(reflect.MakeSlice(rt, 0, 0))
+	}
+	zero := reflect.Zero(rt.Elem())
+	stale := rv.Cap()
+	n := 0
+	if d.open(']') {
+		for {
+			if rv.Cap() <= n {
+				ns := reflect.MakeSlice(rt, n, n*2+4)
+				reflect.Copy(ns, rv)
+				rv.Set(ns)
+			}
+			rv.SetLen(n + 1)
+			ev := rv.Index(n)
+			if n < stale {
+				ev.Set(zero)
+			}
+			d.value(ev)
+			n++
+			if !d.more(']') {
+				break
+			}
+		}
+	}
+	rv.SetLen(n)
+}
+
+func (d *Decoder) array(rv reflect.Value) {
+	n := 0
+	if d.open(']') {
+		for {
+			if n < rv.Len() {
+				d.value(rv.Index(n))
+			} else {
+				d.skip()
+			}
+			n++
+			if !d.more(']') {
+				break
+			}
+		}
+	}
+	if n < rv.Len() {
+		zero := reflect.Zero(rv.Type().Elem())
+		for ; n < rv.Len(); n++ {
+			rv.Index(n).Set(zero)
+		}
+	}
+}
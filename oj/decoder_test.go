@@ -0,0 +1,263 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package oj_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+type decInner struct {
+	Name string
+	Tags []string
+}
+
+type decBase struct {
+	ID int
+}
+
+type decOuter struct {
+	*decBase
+	Base
+	Inner  decInner
+	Ptr    *decInner
+	List   []*decInner
+	Counts map[string]int
+	ByNum  map[int]bool
+	Pair   [2]float64
+	Any    any
+	Raw    []byte
+	When   time.Time
+	Upper  Upper
+}
+
+type Base struct {
+	Kind string
+}
+
+type Upper string
+
+func (u *Upper) UnmarshalText(text []byte) error {
+	*u = Upper(strings.ToUpper(string(text)))
+	return nil
+}
+
+type tagged struct {
+	First  string  `json:"first_name"`
+	Age    int     `json:"age,omitempty"`
+	Weight float32 `json:"wt"`
+	Plain  uint8
+	Skip   string `json:"-"`
+}
+
+func TestDecoderStruct(t *testing.T) {
+	src := `{
+  "kind": "test",
+  "inner": {"name": "in", "tags": ["a", "b\tc", "é😀"]},
+  "ptr": {"name": "pointer"},
+  "list": [{"name": "x"}, null, {"name": "y"}],
+  "counts": {"one": 1, "two": 2},
+  "byNum": {"3": true},
+  "pair": [1.5, -2e2, 7],
+  "any": {"a": [1, true, null, "s"]},
+  "raw": "aGVsbG8=",
+  "when": "2023-01-02T03:04:05Z",
+  "upper": "shout",
+  "unknown": {"deep": [1, {"x": null}]}
+}`
+	var out decOuter
+	var d oj.Decoder
+	err := d.Unmarshal([]byte(src), &out)
+	tt.Nil(t, err)
+	tt.Equal(t, "test", out.Kind)
+	tt.Equal(t, "in", out.Inner.Name)
+	tt.Equal(t, []string{"a", "b\tc", "é😀"}, out.Inner.Tags)
+	tt.Equal(t, "pointer", out.Ptr.Name)
+	tt.Equal(t, 3, len(out.List))
+	tt.Equal(t, "x", out.List[0].Name)
+	tt.Nil(t, out.List[1])
+	tt.Equal(t, "y", out.List[2].Name)
+	tt.Equal(t, map[string]int{"one": 1, "two": 2}, out.Counts)
+	tt.Equal(t, true, out.ByNum[3])
+	tt.Equal(t, [2]float64{1.5, -200.0}, out.Pair)
+	tt.Equal(t, `{"a":[1,true,null,"s"]}`, oj.JSON(out.Any))
+	tt.Equal(t, 1.0, out.Any.(map[string]any)["a"].([]any)[0])
+	tt.Equal(t, "hello", string(out.Raw))
+	tt.Equal(t, int64(1672628645), out.When.Unix())
+	tt.Equal(t, Upper("SHOUT"), out.Upper)
+
+	// Reuse the decoder and existing values.
+	err = d.Unmarshal([]byte(`{"ptr":null,"list":[{"name":"z"}],"counts":{"three":3}}`), &out)
+	tt.Nil(t, err)
+	tt.Nil(t, out.Ptr)
+	tt.Equal(t, 1, len(out.List))
+	tt.Equal(t, "z", out.List[0].Name)
+	tt.Equal(t, map[string]int{"one": 1, "two": 2, "three": 3}, out.Counts)
+}
+
+func TestDecoderEmbeddedPointer(t *testing.T) {
+	type withBase struct {
+		*Base
+		Size int
+	}
+	var out withBase
+	err := (&oj.Decoder{}).Unmarshal([]byte(`{"kind":"embedded","size":3}`), &out)
+	tt.Nil(t, err)
+	tt.NotNil(t, out.Base)
+	tt.Equal(t, "embedded", out.Kind)
+	tt.Equal(t, 3, out.Size)
+}
+
+func TestDecoderKeys(t *testing.T) {
+	src := []byte(`{"first_name":"Bob","age":42,"wt":80.5,"Plain":7,"Skip":"x"}`)
+	var out tagged
+	d := oj.Decoder{UseTags: true}
+	err := d.Unmarshal(src, &out)
+	tt.Nil(t, err)
+	tt.Equal(t, tagged{First: "Bob", Age: 42, Weight: 80.5, Plain: 7}, out)
+
+	// Without tags the field names are used, ignoring case.
+	out = tagged{}
+	err = (&oj.Decoder{}).Unmarshal([]byte(`{"FIRST":"Al","age":3,"plain":1}`), &out)
+	tt.Nil(t, err)
+	tt.Equal(t, tagged{First: "Al", Age: 3, Plain: 1}, out)
+
+	// Exact keys only.
+	out = tagged{}
+	d = oj.Decoder{KeyExact: true}
+	err = d.Unmarshal([]byte(`{"first":"Al","Age":3}`), &out)
+	tt.Nil(t, err)
+	tt.Equal(t, tagged{Age: 3}, out)
+}
+
+type quotedFields struct {
+	ID    int64   `json:"id,string"`
+	Count uint8   `json:"count,string"`
+	Ratio float64 `json:"ratio,string"`
+	On    bool    `json:"on,string"`
+	Name  string  `json:"name,string"`
+	Opt   *int    `json:"opt,string"`
+	Plain int     `json:"plain"`
+}
+
+func TestDecoderQuoted(t *testing.T) {
+	one := 1
+	in := quotedFields{ID: 9007199254740993, Count: 7, Ratio: 1.25, On: true, Name: "x", Opt: &one, Plain: 3}
+	js := oj.JSON(in, &oj.Options{UseTags: true, Sort: true})
+	tt.Equal(t, `{"count":"7","id":"9007199254740993","name":"x","on":"true","opt":1,"plain":3,"ratio":"1.25"}`, js)
+
+	var out quotedFields
+	d := oj.Decoder{UseTags: true}
+	tt.Nil(t, d.Unmarshal([]byte(js), &out))
+	tt.Equal(t, in, out)
+
+	out = quotedFields{ID: 5}
+	tt.Nil(t, d.Unmarshal([]byte(`{"id":null,"ratio":"2.5"}`), &out))
+	tt.Equal(t, quotedFields{ID: 5, Ratio: 2.5}, out)
+
+	for _, x := range []struct {
+		src    string
+		expect string
+	}{
+		{src: `{"id":12}`, expect: "can not decode a number into a int64 at 1:7"},
+		{src: `{"id":"12x"}`, expect: `can not decode "12x" into a int64 at 1:7`},
+		{src: `{"count":"300"}`, expect: `can not decode "300" into a uint8 at 1:10`},
+		{src: `{"on":"yes"}`, expect: `can not decode "yes" into a bool at 1:7`},
+	} {
+		err := d.Unmarshal([]byte(x.src), &out)
+		tt.NotNil(t, err, x.src)
+		tt.Equal(t, x.expect, err.Error(), x.src)
+	}
+}
+
+func TestDecoderScalars(t *testing.T) {
+	var i int8
+	var u uint
+	var f float32
+	var b bool
+	var s string
+	var a any
+	var d oj.Decoder
+	for _, x := range []struct {
+		src    string
+		target any
+		expect any
+	}{
+		{src: "-12", target: &i, expect: int8(-12)},
+		{src: "12", target: &u, expect: uint(12)},
+		{src: "1.25e1", target: &f, expect: float32(12.5)},
+		{src: "true", target: &b, expect: true},
+		{src: `"\"x\"\/\b\f\n\r\t"`, target: &s, expect: "\"x\"/\b\f\n\r\t"},
+		{src: "null", target: &a, expect: nil},
+		{src: "[]", target: &a, expect: []any{}},
+		{src: "\xef\xbb\xbf 3 ", target: &a, expect: 3.0},
+	} {
+		err := d.Unmarshal([]byte(x.src), x.target)
+		tt.Nil(t, err, x.src)
+		tt.Equal(t, x.expect, reflectElem(x.target), x.src)
+	}
+}
+
+func reflectElem(v any) any {
+	switch tv := v.(type) {
+	case *int8:
+		return *tv
+	case *uint:
+		return *tv
+	case *float32:
+		return *tv
+	case *bool:
+		return *tv
+	case *string:
+		return *tv
+	case *any:
+		return *tv
+	}
+	return nil
+}
+
+func TestDecoderUnmarshaler(t *testing.T) {
+	var tags TagMap
+	err := (&oj.Decoder{}).Unmarshal([]byte(`[{"key": "k1", "value": 1}]`), &tags)
+	tt.Nil(t, err)
+	tt.Equal(t, 1, tags["k1"])
+}
+
+func TestDecoderErrors(t *testing.T) {
+	var d oj.Decoder
+	for _, x := range []struct {
+		src    string
+		target any
+		expect string
+	}{
+		{src: `{"x":1}`, target: decOuter{}, expect: "can only decode into a non-nil pointer, not a oj_test.decOuter"},
+		{src: `{"inner":[]}`, target: &decOuter{}, expect: "can not decode an array into a oj_test.decInner at 1:10"},
+		{src: `1.5`, target: new(int), expect: "can not decode 1.5 into a int at 1:1"},
+		{src: `300`, target: new(int8), expect: "300 overflows a int8 at 1:1"},
+		{src: `-1`, target: new(uint), expect: "-1 overflows a uint at 1:1"},
+		{src: `"x"`, target: new(bool), expect: "can not decode a string into a bool at 1:1"},
+		{src: `[1,2,]`, target: new([]int), expect: "unexpected character ']' at 1:6"},
+		{src: "{\n\"a\" 1}", target: new(map[string]int), expect: "expected a colon, not '1' at 2:5"},
+		{src: `{"a":1 "b":2}`, target: new(map[string]int), expect: "expected a comma or close, not '\"' at 1:8"},
+		{src: `{"a":1}x`, target: new(map[string]int), expect: "extra characters after close, 'x' at 1:8"},
+		{src: `{"a":1`, target: new(any), expect: "incomplete JSON at 1:7"},
+		{src: `"abc`, target: new(string), expect: "incomplete JSON at 1:5"},
+		{src: `"a\qc"`, target: new(string), expect: "invalid JSON escape character '\\q' at 1:4"},
+		{src: `"a\u00g0"`, target: new(string), expect: "invalid JSON unicode character 'g' at 1:7"},
+		{src: `01`, target: new(int), expect: "invalid number at 1:2"},
+		{src: `1.`, target: new(float64), expect: "invalid number at 1:3"},
+		{src: `tru`, target: new(bool), expect: "expected true at 1:1"},
+		{src: `{"x":1}`, target: new(map[float64]int), expect: "can not decode into a map with float64 keys at 1:1"},
+		{src: `{"x":1}`, target: new(map[int]int), expect: "can not decode key 'x' into a int at 1:2"},
+		{src: `"x"`, target: new(fmt.Stringer), expect: "can not decode a string into a fmt.Stringer at 1:1"},
+	} {
+		err := d.Unmarshal([]byte(x.src), x.target)
+		tt.NotNil(t, err, x.src)
+		tt.Equal(t, x.expect, err.Error(), x.src)
+	}
+}
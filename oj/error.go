@@ -10,6 +10,10 @@ type ParseError struct {
 	Message string
 	Line    int
 	Column  int
+
+	// Key is the duplicated key when the error is the result of a duplicate
+	// key check.
+	Key string
 }
 
 // Error returns a string representation of the error.
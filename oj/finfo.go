@@ -39,6 +39,8 @@ type finfo struct {
 	jkey    []byte
 	index   []int
 	offset  uintptr
+	// asString is true if the json tag has the string option.
+	asString bool
 }
 
 func (f *finfo) keyLen() int {
@@ -132,11 +134,12 @@ func whichAppend(rt reflect.Type, omitEmpty bool) (f appendFunc, af appendFunc)
 
 func newFinfo(f *reflect.StructField, key string, omitEmpty, asString, pretty, embedded bool) *finfo {
 	fi := finfo{
-		rt:     f.Type,
-		key:    key,
-		kind:   f.Type.Kind(),
-		index:  f.Index,
-		offset: f.Offset,
+		rt:       f.Type,
+		key:      key,
+		kind:     f.Type.Kind(),
+		index:    f.Index,
+		offset:   f.Offset,
+		asString: asString,
 	}
 	var fx byte
 	// Check for interfaces first since almost any type can implement one of
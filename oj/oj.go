@@ -56,6 +56,10 @@ var (
 // only one JSON.
 //
 // A chan argument will be used to deliver parse results.
+//
+// An ojg.Discover argument of ojg.DiscoverAny or ojg.DiscoverSets has the
+// parser search for JSON documents embedded in other text. Each document
+// found is passed to the callback or chan.
 func Parse(b []byte, args ...any) (n any, err error) {
 	p := parserPool.Get().(*Parser)
 	defer parserPool.Put(p)
@@ -10,6 +10,7 @@ import (
 	"io"
 	"unicode/utf8"
 
+	"github.com/ohler55/ojg"
 	"github.com/ohler55/ojg/alt"
 	"github.com/ohler55/ojg/gen"
 )
@@ -32,19 +33,47 @@ type Parser struct {
 	stack      []any
 	starts     []int
 	maps       []map[string]any
+	index      []gen.OrderedIndex // key indexes of the open *gen.Ordered objects
 	cb         func(any)
 	resultChan chan any
 	ri         int // read index for null, false, and true
 	mi         int
 	num        gen.Number
 	rn         rune
+	discover   ojg.Discover
 	result     any
 	mode       string
 	nextMode   string
+	numStart   int
+	boff       int // offset of buf in the input
+	keyPos     ojg.Position
 
 	// Reuse maps. Previously returned maps will no longer be valid or rather
 	// could be modified during parsing.
 	Reuse bool
+
+	// NoDuplicates returns an error if an object has more than one member
+	// with the same key.
+	NoDuplicates bool
+
+	// Ordered if true produces *gen.Ordered objects that keep members in the
+	// order they appear instead of maps.
+	Ordered bool
+
+	// NumConv if not nil is called with the text of each number and the
+	// value returned is used in place of the int64, float64, or json.Number
+	// the parser would otherwise produce. Use gen.JSONNumber or
+	// gen.BigNumber to decode numbers without loss or a function that calls
+	// a decimal constructor.
+	NumConv func(text string) any
+
+	// Spans if not nil records the source span of each value and key of the
+	// most recently parsed document.
+	Spans *ojg.Spans
+
+	// Limits are resource limits for parsing untrusted input. Exceeding a
+	// limit returns an *ojg.LimitError.
+	Limits ojg.Limits
 }
 
 func recomposeToJSON(v any) (any, error) {
@@ -69,10 +98,16 @@ func (p *Parser) Unmarshal(data []byte, vp any, recomposer ...alt.Recomposer) (e
 }
 
 // Parse a JSON string in to simple types. An error is returned if not valid JSON.
+// Arguments can be a callback function, a chan any, or an ojg.Discover
+// value which is described in the package Parse function.
 func (p *Parser) Parse(buf []byte, args ...any) (any, error) {
+	if err := p.Limits.CheckBytes(buf); err != nil {
+		return nil, err
+	}
 	p.cb = nil
 	p.resultChan = nil
 	p.OnlyOne = true
+	p.discover = ojg.DiscoverOff
 	for _, a := range args {
 		switch ta := a.(type) {
 		case func(any) bool:
@@ -85,6 +120,8 @@ func (p *Parser) Parse(buf []byte, args ...any) (any, error) {
 			p.resultChan = ta
 			p.OnlyOne = false
 			p.Reuse = false
+		case ojg.Discover:
+			p.discover = ta
 		default:
 			return nil, fmt.Errorf("a %T is not a valid option type", a)
 		}
@@ -98,16 +135,24 @@ func (p *Parser) Parse(buf []byte, args ...any) (any, error) {
 		p.stack = p.stack[:0]
 		p.tmp = p.tmp[:0]
 		p.starts = p.starts[:0]
+		p.index = p.index[:0]
 	}
 	p.result = nil
 	p.noff = -1
 	p.line = 1
 	p.mode = valueMap
 	p.mi = 0
+	p.num.Conv = p.NumConv
+	p.boff = 0
+	if p.Spans != nil {
+		p.Spans.Reset()
+	}
 	var err error
-	// Skip BOM if present.
-	if 3 < len(buf) && buf[0] == 0xEF {
+	if p.discover == ojg.DiscoverAny || p.discover == ojg.DiscoverSets {
+		p.discover.Each(buf, false, p.discovered)
+	} else if 3 < len(buf) && buf[0] == 0xEF { // Skip BOM if present.
 		if buf[1] == 0xBB && buf[2] == 0xBF {
+			p.boff = 3
 			err = p.parseBuffer(buf[3:], true)
 		} else {
 			return nil, fmt.Errorf("expected BOM at 1:3")
@@ -130,6 +175,7 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data any, err error) {
 	p.cb = nil
 	p.resultChan = nil
 	p.OnlyOne = true
+	p.discover = ojg.DiscoverOff
 	for _, a := range args {
 		switch ta := a.(type) {
 		case func(any) bool:
@@ -142,6 +188,8 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data any, err error) {
 			p.resultChan = ta
 			p.OnlyOne = false
 			p.Reuse = false
+		case ojg.Discover:
+			p.discover = ta
 		default:
 			return nil, fmt.Errorf("a %T is not a valid option type", a)
 		}
@@ -155,16 +203,28 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data any, err error) {
 		p.stack = p.stack[:0]
 		p.tmp = p.tmp[:0]
 		p.starts = p.starts[:0]
+		p.index = p.index[:0]
 	}
 	p.result = nil
 	p.noff = -1
 	p.line = 1
 	p.mi = 0
+	p.num.Conv = p.NumConv
+	p.boff = 0
+	if p.Spans != nil {
+		p.Spans.Reset()
+	}
+	if p.discover == ojg.DiscoverAny || p.discover == ojg.DiscoverSets {
+		err = p.discover.Read(r, false, p.Limits.MaxBytes, p.discovered)
+		data = p.result
+		return
+	}
 	buf := make([]byte, readBufSize)
 	eof := false
 	var cnt int
 	cnt, err = r.Read(buf)
 	buf = buf[:cnt]
+	total := cnt
 	p.mode = valueMap
 	if err != nil {
 		if !errors.Is(err, io.EOF) {
@@ -176,13 +236,22 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data any, err error) {
 	// Skip BOM if present.
 	if 3 < len(buf) && buf[0] == 0xEF && buf[1] == 0xBB && buf[2] == 0xBF {
 		skip = 3
+		p.boff = 3
 	}
 	for {
+		over := 0 < p.Limits.MaxBytes && p.Limits.MaxBytes < total
+		if over {
+			buf = buf[:len(buf)-(total-p.Limits.MaxBytes)]
+			eof = false
+		}
 		if 0 < skip {
 			err = p.parseBuffer(buf[skip:], eof)
 		} else {
 			err = p.parseBuffer(buf, eof)
 		}
+		if err == nil && over {
+			err = p.limitError(len(buf)-skip, ojg.BytesLimit, p.Limits.MaxBytes)
+		}
 		if err != nil {
 			p.stack = p.stack[:cap(p.stack)]
 			for i := len(p.stack) - 1; 0 <= i; i-- {
@@ -192,6 +261,9 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data any, err error) {
 
 			return
 		}
+		// Offsets and columns in the next buffer continue from this one.
+		p.boff += len(buf) - skip
+		p.noff -= len(buf) - skip
 		skip = 0
 		if eof {
 			break
@@ -199,6 +271,7 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data any, err error) {
 		buf = buf[:cap(buf)]
 		cnt, err = r.Read(buf)
 		buf = buf[:cnt]
+		total += cnt
 		if err != nil {
 			if !errors.Is(err, io.EOF) {
 				return
@@ -237,6 +310,12 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 		case strOk:
 			p.tmp = append(p.tmp, b)
 		case keyQuote:
+			if 0 < p.Limits.MaxMembers && p.Limits.MaxMembers <= p.memberCount() {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
+			if p.Spans != nil {
+				p.keyPos = p.spanPos(off)
+			}
 			start := off + 1
 			if len(buf) <= start {
 				p.tmp = p.tmp[:0]
@@ -252,7 +331,14 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			off += i
 			if b == '"' {
 				off++
+				if 0 < p.Limits.MaxStringLen && p.Limits.MaxStringLen < off-start {
+					return p.limitError(off, ojg.StringLimit, p.Limits.MaxStringLen)
+				}
+				if p.NoDuplicates && p.hasKey(buf[start:off]) {
+					return p.keyError(off, string(buf[start:off]))
+				}
 				p.stack = append(p.stack, gen.Key(buf[start:off]))
+				p.spanKey(buf[start:off], off+1)
 				p.mode = colonMap
 			} else {
 				p.tmp = p.tmp[:0]
@@ -269,6 +355,10 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			}
 			continue
 		case valQuote:
+			if 0 < p.Limits.MaxMembers && p.Limits.MaxMembers <= p.memberCount() {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
+			p.spanBegin(off)
 			start := off + 1
 			if len(buf) <= start {
 				p.tmp = p.tmp[:0]
@@ -284,7 +374,11 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			off += i
 			if b == '"' {
 				off++
+				if 0 < p.Limits.MaxStringLen && p.Limits.MaxStringLen < off-start {
+					return p.limitError(off, ojg.StringLimit, p.Limits.MaxStringLen)
+				}
 				p.add(string(buf[start:off]))
+				p.spanEnd(off + 1)
 				p.mode = afterMap
 			} else {
 				p.tmp = p.tmp[:0]
@@ -294,7 +388,11 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 				continue
 			}
 		case numComma:
+			if p.numberTooLong(off) {
+				return p.limitError(p.numStart, ojg.NumberLimit, p.Limits.MaxNumberLen)
+			}
 			p.add(p.num.AsNum())
+			p.spanEnd(off)
 			if 0 < len(p.starts) && p.starts[len(p.starts)-1] == -1 {
 				p.mode = keyMap
 			} else {
@@ -308,8 +406,21 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			p.mode = stringMap
 			continue
 		case openObject:
+			if 0 < p.Limits.MaxMembers && p.Limits.MaxMembers <= p.memberCount() {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
+			p.spanBegin(off)
 			p.starts = append(p.starts, -1)
 			p.mode = key1Map
+			if 0 < p.Limits.MaxDepth && p.Limits.MaxDepth <= depth {
+				return p.limitError(off, ojg.DepthLimit, p.Limits.MaxDepth)
+			}
+			if p.Ordered {
+				p.stack = append(p.stack, &gen.Ordered{})
+				p.index = append(p.index, gen.OrderedIndex{})
+				depth++
+				continue
+			}
 			var m map[string]any
 			if p.Reuse {
 				if p.mi < len(p.maps) {
@@ -334,18 +445,39 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 				return p.newError(off, "unexpected object close")
 			}
 			if 256 < len(p.mode) && p.mode[256] == 'n' {
+				if p.numberTooLong(off) {
+					return p.limitError(p.numStart, ojg.NumberLimit, p.Limits.MaxNumberLen)
+				}
 				p.add(p.num.AsNum())
+				p.spanEnd(off)
+			}
+			if _, ok := p.stack[len(p.stack)-1].(gen.Key); ok {
+				return p.newError(off, "expected a value")
 			}
 			p.starts = p.starts[0:depth]
 			n := p.stack[len(p.stack)-1]
 			p.stack = p.stack[:len(p.stack)-1]
+			if _, ok := n.(*gen.Ordered); ok {
+				p.index = p.index[:len(p.index)-1]
+			}
 			p.add(n)
+			p.spanEnd(off + 1)
 			p.mode = afterMap
 		case val0:
+			if 0 < p.Limits.MaxMembers && p.Limits.MaxMembers <= p.memberCount() {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
+			p.spanBegin(off)
 			p.mode = zeroMap
 			p.num.Reset()
+			p.numStart = off
 		case valDigit:
+			if 0 < p.Limits.MaxMembers && p.Limits.MaxMembers <= p.memberCount() {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
+			p.spanBegin(off)
 			p.num.Reset()
+			p.numStart = off
 			p.mode = digitMap
 			p.num.I = uint64(b - '0')
 			for i, b = range buf[off+1:] {
@@ -364,9 +496,14 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			}
 			off += i
 		case valNeg:
+			if 0 < p.Limits.MaxMembers && p.Limits.MaxMembers <= p.memberCount() {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
+			p.spanBegin(off)
 			p.mode = negMap
 			p.num.Reset()
 			p.num.Neg = true
+			p.numStart = off
 			continue
 		case escU:
 			p.mode = uMap
@@ -374,6 +511,13 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			p.ri = 0
 			continue
 		case openArray:
+			if 0 < p.Limits.MaxMembers && p.Limits.MaxMembers <= p.memberCount() {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
+			p.spanBegin(off)
+			if 0 < p.Limits.MaxDepth && p.Limits.MaxDepth <= depth {
+				return p.limitError(off, ojg.DepthLimit, p.Limits.MaxDepth)
+			}
 			p.starts = append(p.starts, len(p.stack))
 			p.stack = append(p.stack, emptySlice)
 			p.mode = valueMap
@@ -387,7 +531,11 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			// Only modes with a close array are value, after, and numbers
 			// which are all over 256 long.
 			if p.mode[256] == 'n' {
+				if p.numberTooLong(off) {
+					return p.limitError(p.numStart, ojg.NumberLimit, p.Limits.MaxNumberLen)
+				}
 				p.add(p.num.AsNum())
+				p.spanEnd(off)
 			}
 			start := p.starts[len(p.starts)-1] + 1
 			p.starts = p.starts[:len(p.starts)-1]
@@ -396,30 +544,46 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			copy(n, p.stack[start:len(p.stack)])
 			p.stack = p.stack[0 : start-1]
 			p.add(n)
+			p.spanEnd(off + 1)
 			p.mode = afterMap
 		case valNull:
+			if 0 < p.Limits.MaxMembers && p.Limits.MaxMembers <= p.memberCount() {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
+			p.spanBegin(off)
 			if off+4 <= len(buf) && string(buf[off:off+4]) == "null" {
 				off += 3
 				p.mode = afterMap
 				p.add(nil)
+				p.spanEnd(off + 1)
 			} else {
 				p.mode = nullMap
 				p.ri = 0
 			}
 		case valTrue:
+			if 0 < p.Limits.MaxMembers && p.Limits.MaxMembers <= p.memberCount() {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
+			p.spanBegin(off)
 			if off+4 <= len(buf) && string(buf[off:off+4]) == "true" {
 				off += 3
 				p.mode = afterMap
 				p.add(true)
+				p.spanEnd(off + 1)
 			} else {
 				p.mode = trueMap
 				p.ri = 0
 			}
 		case valFalse:
+			if 0 < p.Limits.MaxMembers && p.Limits.MaxMembers <= p.memberCount() {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
+			p.spanBegin(off)
 			if off+5 <= len(buf) && string(buf[off:off+5]) == "false" {
 				off += 4
 				p.mode = afterMap
 				p.add(false)
+				p.spanEnd(off + 1)
 			} else {
 				p.mode = falseMap
 				p.ri = 0
@@ -456,11 +620,19 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			p.mode = expSignMap
 			continue
 		case strQuote:
+			if 0 < p.Limits.MaxStringLen && p.Limits.MaxStringLen < len(p.tmp) {
+				return p.limitError(off, ojg.StringLimit, p.Limits.MaxStringLen)
+			}
 			p.mode = p.nextMode
 			if p.mode[':'] == colonColon {
+				if p.NoDuplicates && p.hasKey(p.tmp) {
+					return p.keyError(off, string(p.tmp))
+				}
 				p.stack = append(p.stack, gen.Key(p.tmp))
+				p.spanKey(p.tmp, off+1)
 			} else {
 				p.add(string(p.tmp))
+				p.spanEnd(off + 1)
 			}
 		case numZero:
 			p.mode = zeroMap
@@ -470,10 +642,18 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			p.num.AddDigit(b)
 			p.mode = digitMap
 		case numSpc:
+			if p.numberTooLong(off) {
+				return p.limitError(p.numStart, ojg.NumberLimit, p.Limits.MaxNumberLen)
+			}
 			p.add(p.num.AsNum())
+			p.spanEnd(off)
 			p.mode = afterMap
 		case numNewline:
+			if p.numberTooLong(off) {
+				return p.limitError(p.numStart, ojg.NumberLimit, p.Limits.MaxNumberLen)
+			}
 			p.add(p.num.AsNum())
+			p.spanEnd(off)
 			p.line++
 			p.noff = off
 			p.mode = afterMap
@@ -488,6 +668,9 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			if b == '-' {
 				p.num.NegExp = true
 			}
+			if 0 < len(p.num.BigBuf) {
+				p.num.BigBuf = append(p.num.BigBuf, b)
+			}
 			continue
 		case expDigit:
 			p.num.AddExp(b)
@@ -520,6 +703,7 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 				}
 				if 3 <= p.ri {
 					p.add(true)
+					p.spanEnd(off + 1)
 					p.mode = afterMap
 				}
 			case p.mode['a'] == tokenOk:
@@ -529,6 +713,7 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 				}
 				if 4 <= p.ri {
 					p.add(false)
+					p.spanEnd(off + 1)
 					p.mode = afterMap
 				}
 			case p.mode['u'] == tokenOk && p.mode['l'] == tokenOk:
@@ -538,6 +723,7 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 				}
 				if 3 <= p.ri {
 					p.add(nil)
+					p.spanEnd(off + 1)
 					p.mode = afterMap
 				}
 			}
@@ -569,7 +755,11 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			return p.newError(off, "incomplete JSON")
 		}
 		if p.mode[256] == 'n' {
+			if p.numberTooLong(off) {
+				return p.limitError(p.numStart, ojg.NumberLimit, p.Limits.MaxNumberLen)
+			}
 			p.add(p.num.AsNum())
+			p.spanEnd(off)
 			if p.cb == nil && p.resultChan == nil {
 				p.result = p.stack[0]
 			} else {
@@ -582,18 +772,129 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			}
 		}
 	}
-	return nil
+	return p.checkPending(len(buf))
 }
 
 func (p *Parser) add(n any) {
 	if 2 <= len(p.stack) {
 		if k, ok := p.stack[len(p.stack)-1].(gen.Key); ok {
-			obj, _ := p.stack[len(p.stack)-2].(map[string]any)
-			obj[string(k)] = n
+			obj := p.stack[len(p.stack)-2]
+			p.setMember(obj, string(k), n)
 			p.stack = p.stack[0 : len(p.stack)-1]
 
 			return
 		}
 	}
 	p.stack = append(p.stack, n)
-}
\ No newline at end of file
+}
+
+// spanPos returns the position of off in the input.
+func (p *Parser) spanPos(off int) ojg.Position {
+	return ojg.Position{Offset: p.boff + off, Line: p.line, Column: off - p.noff}
+}
+
+// spanBegin records the start of a value at off if spans are being
+// recorded.
+func (p *Parser) spanBegin(off int) {
+	if p.Spans != nil {
+		p.Spans.Begin(p.spanPos(off))
+	}
+}
+
+// spanEnd records the end of the value that ends just before off if spans
+// are being recorded.
+func (p *Parser) spanEnd(off int) {
+	if p.Spans != nil {
+		p.Spans.End(p.spanPos(off))
+	}
+}
+
+// spanKey records the key that ends just before off if spans are being
+// recorded.
+func (p *Parser) spanKey(key []byte, off int) {
+	if p.Spans != nil {
+		p.Spans.Key(string(key), p.keyPos, p.spanPos(off))
+	}
+}
+
+// memberCount returns the number of members in the object or array being
+// parsed.
+func (p *Parser) memberCount() int {
+	if len(p.starts) == 0 {
+		return 0
+	}
+	if start := p.starts[len(p.starts)-1]; 0 <= start {
+		return len(p.stack) - start - 1
+	}
+	switch obj := p.stack[len(p.stack)-1].(type) {
+	case map[string]any:
+		return len(obj)
+	case *gen.Ordered:
+		return len(obj.Members)
+	}
+	return 0
+}
+
+func (p *Parser) numberTooLong(off int) bool {
+	return 0 < p.Limits.MaxNumberLen && p.Limits.MaxNumberLen < off-p.numStart
+}
+
+// checkPending checks the string or number that continues past the end of
+// the buffer so that neither can grow past the limits while reading. The
+// number start is adjusted to be relative to the next buffer.
+func (p *Parser) checkPending(off int) error {
+	if 0 < p.Limits.MaxStringLen && p.Limits.MaxStringLen < len(p.tmp) {
+		return p.limitError(off, ojg.StringLimit, p.Limits.MaxStringLen)
+	}
+	if 0 < p.Limits.MaxNumberLen && p.Limits.MaxNumberLen < len(p.num.BigBuf) {
+		return p.limitError(p.numStart, ojg.NumberLimit, p.Limits.MaxNumberLen)
+	}
+	p.numStart -= off
+	return nil
+}
+
+// hasKey returns true if the object on the top of the stack already has a
+// member with the key.
+func (p *Parser) hasKey(key []byte) (has bool) {
+	switch obj := p.stack[len(p.stack)-1].(type) {
+	case map[string]any:
+		_, has = obj[string(key)]
+	case *gen.Ordered:
+		has = p.index[len(p.index)-1].Has(obj, string(key))
+	}
+	return
+}
+
+// setMember sets a member of a map[string]any or an *gen.Ordered. An
+// *gen.Ordered must be the innermost open object so its key index is on the
+// top of the index stack.
+func (p *Parser) setMember(obj any, key string, value any) {
+	switch to := obj.(type) {
+	case map[string]any:
+		to[key] = value
+	case *gen.Ordered:
+		p.index[len(p.index)-1].Set(to, key, value)
+	}
+}
+
+// discovered parses a candidate document found while discovering and
+// reports whether it was valid and if discovery should stop. If there is no
+// callback or channel then only the first valid document is parsed.
+func (p *Parser) discovered(candidate []byte, pos ojg.Position) (valid, stop bool) {
+	p.stack = p.stack[:0]
+	p.starts = p.starts[:0]
+	p.index = p.index[:0]
+	p.tmp = p.tmp[:0]
+	p.mode = valueMap
+	p.mi = 0
+	p.line = pos.Line
+	p.noff = -pos.Column
+	p.boff = pos.Offset
+	if p.Spans != nil {
+		p.Spans.Abort()
+	}
+	if err := p.parseBuffer(candidate, true); err != nil {
+		return false, false
+	}
+	return true, p.cb == nil && p.resultChan == nil
+}
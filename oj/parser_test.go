@@ -4,11 +4,16 @@
 package oj_test
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
 	"strings"
 	"testing"
 	"testing/iotest"
 
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/gen"
 	"github.com/ohler55/ojg/oj"
 	"github.com/ohler55/ojg/tt"
 )
@@ -47,6 +52,7 @@ func TestParserParseString(t *testing.T) {
 		{src: `0.9223372036854775808`, value: "0.9223372036854775808"},
 		{src: `-0.9223372036854775808`, value: "-0.9223372036854775808"},
 		{src: `0.000001234567890123456789`, value: "0.000001234567890123456789"},
+		{src: `0.000000000000000000000000001`, value: "0.000000000000000000000000001"},
 		{src: `1.2e1025`, value: "1.2e1025"},
 		{src: `-1.2e-1025`, value: "-1.2e-1025"},
 		{src: `12345678901234567890.321e66`, value: "12345678901234567890.321e66"},
@@ -362,4 +368,168 @@ func TestMustPanic(t *testing.T) {
 	tt.Panic(t, func() { _ = oj.MustParse([]byte("[true}")) })
 	tt.Panic(t, func() { _ = oj.MustParseString("[true}") })
 	tt.Panic(t, func() { _ = oj.MustLoad(strings.NewReader("[true}")) })
-}
\ No newline at end of file
+}
+
+func TestParserNoDuplicates(t *testing.T) {
+	p := oj.Parser{NoDuplicates: true}
+	v, err := p.Parse([]byte(`{"a":1,"b":{"a":2},"c":[{"a":3},{"a":4}]}`))
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"a": 1, "b": map[string]any{"a": 2}, "c": []any{map[string]any{"a": 3}, map[string]any{"a": 4}}}, v)
+
+	for i, d := range []struct {
+		src    string
+		key    string
+		line   int
+		column int
+	}{
+		{src: `{"a":1,"a":2}`, key: "a", line: 1, column: 10},
+		{src: "{\n  \"abc\": 1,\n  \"abc\": 2\n}", key: "abc", line: 3, column: 7},
+		{src: `{"x":{"a\n":1,"a\u000a":2}}`, key: "a\n", line: 1, column: 23},
+		{src: `{"":1,"":2}`, key: "", line: 1, column: 8},
+	} {
+		_, err = p.Parse([]byte(d.src))
+		var pe *oj.ParseError
+		tt.Equal(t, true, errors.As(err, &pe), i, ": ", d.src)
+		tt.Equal(t, d.key, pe.Key, i, ": ", d.src)
+		tt.Equal(t, d.line, pe.Line, i, ": ", d.src)
+		tt.Equal(t, d.column, pe.Column, i, ": ", d.src)
+
+		_, err = p.ParseReader(strings.NewReader(d.src))
+		tt.NotNil(t, err, i, ": ", d.src)
+	}
+	// Duplicates are allowed by default.
+	p.NoDuplicates = false
+	v, err = p.Parse([]byte(`{"a":1,"a":2}`))
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"a": 2}, v)
+}
+
+func TestParserDiscover(t *testing.T) {
+	src := `2023-03-07 INFO {"a":1} [ERROR] {"b":[true,null]} {"bad":} "str" 7`
+	var results []any
+	cb := func(v any) { results = append(results, v) }
+
+	var p oj.Parser
+	_, err := p.Parse([]byte(src), cb, ojg.DiscoverSets)
+	tt.Nil(t, err)
+	tt.Equal(t, `[{"a":1},{"b":[true,null]}]`, oj.JSON(results))
+
+	results = results[:0]
+	_, err = p.ParseReader(strings.NewReader(src), cb, ojg.DiscoverAny)
+	tt.Nil(t, err)
+	tt.Equal(t, `[2023,{"a":1},{"b":[true,null]},"bad","str",7]`, oj.JSON(results))
+
+	results = results[:0]
+	_, err = p.ParseReader(iotest.OneByteReader(strings.NewReader(src)), cb, ojg.DiscoverAny)
+	tt.Nil(t, err)
+	tt.Equal(t, `[2023,{"a":1},{"b":[true,null]},"bad","str",7]`, oj.JSON(results))
+
+	// Without a callback the first document is returned.
+	v, err := p.Parse([]byte(src), ojg.DiscoverSets)
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"a": 1}, v)
+
+	v, err = p.Parse([]byte("nothing to see here"), ojg.DiscoverSets)
+	tt.Nil(t, err)
+	tt.Nil(t, v)
+
+	rc := make(chan any, 10)
+	_, err = oj.Parse([]byte(src), rc, ojg.DiscoverSets)
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"a": 1}, <-rc)
+	tt.Equal(t, map[string]any{"b": []any{true, nil}}, <-rc)
+
+	// Discover is not carried over to the next parse.
+	_, err = p.Parse([]byte(src))
+	tt.NotNil(t, err)
+}
+
+func TestParserOrdered(t *testing.T) {
+	p := oj.Parser{Ordered: true}
+	v, err := p.Parse([]byte(`{"z":1,"a":{"y":[1,{"c":2,"b":3}]},"m":null}`))
+	tt.Nil(t, err)
+	o, ok := v.(*gen.Ordered)
+	tt.Equal(t, true, ok)
+	tt.Equal(t, []string{"z", "a", "m"}, o.Keys())
+	tt.Equal(t, int64(1), o.Members[0].Value)
+	tt.Equal(t, `{"z":1,"a":{"y":[1,{"c":2,"b":3}]},"m":null}`, oj.JSON(v))
+
+	p.NoDuplicates = true
+	_, err = p.Parse([]byte(`{"a":1,"a":2}`))
+	tt.NotNil(t, err)
+}
+
+func TestParserNumConv(t *testing.T) {
+	p := oj.Parser{NumConv: gen.JSONNumber}
+	v, err := p.Parse([]byte(`{"price":19.99,"qty":3,"big":123456789012345678901234567890,"exp":1.5e-3}`))
+	tt.Nil(t, err)
+	tt.Equal(t, json.Number("19.99"), v.(map[string]any)["price"])
+	tt.Equal(t, json.Number("3"), v.(map[string]any)["qty"])
+	tt.Equal(t, `{"big":123456789012345678901234567890,"exp":1.5e-3,"price":19.99,"qty":3}`, oj.JSON(v, &oj.Options{Sort: true}))
+
+	p.NumConv = gen.BigNumber
+	v, err = p.ParseReader(strings.NewReader(`[0.1, -42]`))
+	tt.Nil(t, err)
+	tt.Equal(t, "*big.Float", fmt.Sprintf("%T", v.([]any)[0]))
+	tt.Equal(t, big.NewInt(-42), v.([]any)[1])
+	tt.Equal(t, `[0.1,-42]`, oj.JSON(v))
+}
+
+func TestParserLimits(t *testing.T) {
+	for i, d := range []struct {
+		src    string
+		limits ojg.Limits
+		expect string
+	}{
+		{src: `[[[1]]]`, limits: ojg.Limits{MaxDepth: 3}},
+		{src: `[[[[1]]]]`, limits: ojg.Limits{MaxDepth: 3}, expect: "maximum depth of 3 exceeded at 1:4"},
+		{src: `{"a":{"b":{"c":{}}}}`, limits: ojg.Limits{MaxDepth: 3}, expect: "maximum depth of 3 exceeded at 1:16"},
+		{src: `["abc","ab\nc"]`, limits: ojg.Limits{MaxStringLen: 4}},
+		{src: `["abc","abcd"]`, limits: ojg.Limits{MaxStringLen: 3}, expect: "maximum string length of 3 exceeded at 1:13"},
+		{src: `["ab\ncd"]`, limits: ojg.Limits{MaxStringLen: 4}, expect: "maximum string length of 4 exceeded at 1:9"},
+		{src: `{"abcd":1}`, limits: ojg.Limits{MaxStringLen: 3}, expect: "maximum string length of 3 exceeded at 1:7"},
+		{src: `[1,2,{"a":1,"b":2,"c":3}]`, limits: ojg.Limits{MaxMembers: 3}},
+		{src: `[true,2,"x",null]`, limits: ojg.Limits{MaxMembers: 3}, expect: "maximum members of 3 exceeded at 1:13"},
+		{src: `[1,2,3,4]`, limits: ojg.Limits{MaxMembers: 3}, expect: "maximum members of 3 exceeded at 1:8"},
+		{src: `{"a":1,"b":2,"c":3}`, limits: ojg.Limits{MaxMembers: 2}, expect: "maximum members of 2 exceeded at 1:14"},
+		{src: `[1,2]`, limits: ojg.Limits{MaxBytes: 5}},
+		{src: "[1,\n2] ", limits: ojg.Limits{MaxBytes: 6}, expect: "maximum bytes of 6 exceeded at 2:3"},
+		{src: `[12345,-1.5e3]`, limits: ojg.Limits{MaxNumberLen: 6}},
+		{src: `[12345,123456]`, limits: ojg.Limits{MaxNumberLen: 5}, expect: "maximum number length of 5 exceeded at 1:8"},
+		{src: `{"a":-1.25e10}`, limits: ojg.Limits{MaxNumberLen: 6}, expect: "maximum number length of 6 exceeded at 1:6"},
+		{src: `-1234.5 `, limits: ojg.Limits{MaxNumberLen: 6}, expect: "maximum number length of 6 exceeded at 1:1"},
+	} {
+		p := oj.Parser{Limits: d.limits}
+		for _, ps := range []func() (any, error){
+			func() (any, error) { return p.Parse([]byte(d.src)) },
+			func() (any, error) { return p.ParseReader(strings.NewReader(d.src)) },
+		} {
+			_, err := ps()
+			if len(d.expect) == 0 {
+				tt.Nil(t, err, i, ": ", d.src)
+				continue
+			}
+			var le *ojg.LimitError
+			tt.Equal(t, true, errors.As(err, &le), i, ": ", d.src)
+			tt.Equal(t, d.expect, err.Error(), i, ": ", d.src)
+		}
+	}
+}
+
+func TestParserLimitsReader(t *testing.T) {
+	for i, d := range []struct {
+		src    string
+		limits ojg.Limits
+		limit  ojg.Limit
+	}{
+		{src: "[" + strings.Repeat(`"`+strings.Repeat("x", 1000)+`",`, 100) + "1]", limits: ojg.Limits{MaxBytes: 10000}, limit: ojg.BytesLimit},
+		{src: `"` + strings.Repeat(`\n`, 10000) + `"`, limits: ojg.Limits{MaxStringLen: 5000}, limit: ojg.StringLimit},
+		{src: strings.Repeat("9", 10000), limits: ojg.Limits{MaxNumberLen: 5000}, limit: ojg.NumberLimit},
+	} {
+		p := oj.Parser{Limits: d.limits}
+		_, err := p.ParseReader(strings.NewReader(d.src))
+		var le *ojg.LimitError
+		tt.Equal(t, true, errors.As(err, &le), i)
+		tt.Equal(t, d.limit, le.Limit, i)
+	}
+}
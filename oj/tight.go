@@ -10,7 +10,6 @@ import (
 	"unsafe"
 
 	"github.com/ohler55/ojg"
-	"github.com/ohler55/ojg/alt"
 )
 
 func tightDefault(wr *Writer, data any, _ int) {
@@ -35,7 +34,7 @@ func tightDefault(wr *Writer, data any, _ int) {
 			}
 			wr.buf = append(wr.buf, "null"...)
 		default:
-			dec := alt.Decompose(data, &wr.Options)
+			dec := wr.cycles.Decompose(data, &wr.Options)
 			wr.appendJSON(dec, 0)
 		}
 	case wr.strict:
@@ -48,8 +47,10 @@ func tightDefault(wr *Writer, data any, _ int) {
 func tightArray(wr *Writer, n []any, _ int) {
 	if 0 < len(n) {
 		wr.buf = append(wr.buf, '[')
-		for _, m := range n {
+		for i, m := range n {
+			wr.cycles.Index(i)
 			wr.appendJSON(m, 0)
+			wr.cycles.Pop()
 			wr.buf = append(wr.buf, ',')
 		}
 		wr.buf[len(wr.buf)-1] = ']'
@@ -82,7 +83,9 @@ func tightObject(wr *Writer, n map[string]any, _ int) {
 		}
 		wr.buf = ojg.AppendJSONString(wr.buf, k, !wr.HTMLUnsafe)
 		wr.buf = append(wr.buf, ':')
+		wr.cycles.Key(k)
 		wr.appendJSON(m, 0)
+		wr.cycles.Pop()
 		wr.buf = append(wr.buf, ',')
 		comma = true
 	}
@@ -123,7 +126,9 @@ func tightSortObject(wr *Writer, n map[string]any, _ int) {
 		}
 		wr.buf = ojg.AppendJSONString(wr.buf, k, !wr.HTMLUnsafe)
 		wr.buf = append(wr.buf, ':')
+		wr.cycles.Key(k)
 		wr.appendJSON(m, 0)
+		wr.cycles.Pop()
 		wr.buf = append(wr.buf, ',')
 		comma = true
 	}
@@ -135,6 +140,12 @@ func tightSortObject(wr *Writer, n map[string]any, _ int) {
 }
 
 func (wr *Writer) tightStruct(rv reflect.Value, si *sinfo) {
+	if wr.cycles != nil && rv.CanAddr() {
+		if wr.cycled(rv.Addr(), 0) {
+			return
+		}
+		defer wr.cycles.Exit()
+	}
 	if si == nil {
 		si = getSinfo(rv.Interface(), wr.OmitEmpty)
 	}
@@ -178,13 +189,16 @@ func (wr *Writer) tightStruct(rv reflect.Value, si *sinfo) {
 				wr.buf = wr.buf[:len(wr.buf)-fi.keyLen()]
 				continue
 			}
+			wr.cycles.Key(fi.key)
 			wr.appendJSON(v, 0)
+			wr.cycles.Pop()
 			wr.buf = append(wr.buf, ',')
 			comma = true
 			continue
 		}
 		var fv reflect.Value
 		kind := fi.kind
+		wr.cycles.Key(fi.key)
 	Retry:
 		switch kind {
 		case reflect.Ptr:
@@ -196,12 +210,14 @@ func (wr *Writer) tightStruct(rv reflect.Value, si *sinfo) {
 			}
 			if wr.OmitNil {
 				wr.buf = wr.buf[:len(wr.buf)-fi.keyLen()]
+				wr.cycles.Pop()
 				continue
 			}
 			wr.buf = append(wr.buf, "null"...)
 		case reflect.Interface:
 			if wr.OmitNil && (*[2]uintptr)(unsafe.Pointer(&v))[1] == 0 {
 				wr.buf = wr.buf[:len(wr.buf)-fi.keyLen()]
+				wr.cycles.Pop()
 				continue
 			}
 			wr.appendJSON(v, 0)
@@ -223,6 +239,7 @@ func (wr *Writer) tightStruct(rv reflect.Value, si *sinfo) {
 		default:
 			wr.appendJSON(v, 0)
 		}
+		wr.cycles.Pop()
 		wr.buf = append(wr.buf, ',')
 		comma = true
 	}
@@ -234,10 +251,17 @@ func (wr *Writer) tightStruct(rv reflect.Value, si *sinfo) {
 }
 
 func (wr *Writer) tightSlice(rv reflect.Value, si *sinfo) {
+	if wr.cycles != nil && rv.Kind() == reflect.Slice {
+		if wr.cycled(rv, 0) {
+			return
+		}
+		defer wr.cycles.Exit()
+	}
 	end := rv.Len()
 	comma := false
 	wr.buf = append(wr.buf, '[')
 	for j := 0; j < end; j++ {
+		wr.cycles.Index(j)
 		rm := rv.Index(j)
 		if rm.Kind() == reflect.Ptr {
 			rm = rm.Elem()
@@ -252,6 +276,7 @@ func (wr *Writer) tightSlice(rv reflect.Value, si *sinfo) {
 		default:
 			wr.appendJSON(rm.Interface(), 0)
 		}
+		wr.cycles.Pop()
 		wr.buf = append(wr.buf, ',')
 		comma = true
 	}
@@ -263,6 +288,12 @@ func (wr *Writer) tightSlice(rv reflect.Value, si *sinfo) {
 }
 
 func (wr *Writer) tightMap(rv reflect.Value, si *sinfo) {
+	if wr.cycles != nil {
+		if wr.cycled(rv, 0) {
+			return
+		}
+		defer wr.cycles.Exit()
+	}
 	wr.buf = append(wr.buf, '{')
 	keys := rv.MapKeys()
 	if wr.Sort {
@@ -281,32 +312,42 @@ func (wr *Writer) tightMap(rv reflect.Value, si *sinfo) {
 		case reflect.Struct:
 			wr.buf = ojg.AppendJSONString(wr.buf, kv.String(), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ':')
+			wr.cycles.Key(kv.String())
 			wr.tightStruct(rm, si)
+			wr.cycles.Pop()
 		case reflect.Slice, reflect.Array:
 			if (wr.OmitNil || wr.OmitEmpty) && rm.Len() == 0 {
 				continue
 			}
 			wr.buf = ojg.AppendJSONString(wr.buf, kv.String(), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ':')
+			wr.cycles.Key(kv.String())
 			wr.tightSlice(rm, si)
+			wr.cycles.Pop()
 		case reflect.Map:
 			if (wr.OmitNil || wr.OmitEmpty) && rm.Len() == 0 {
 				continue
 			}
 			wr.buf = ojg.AppendJSONString(wr.buf, kv.String(), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ':')
+			wr.cycles.Key(kv.String())
 			wr.tightMap(rm, si)
+			wr.cycles.Pop()
 		case reflect.String:
 			if (wr.OmitNil || wr.OmitEmpty) && rm.Len() == 0 {
 				continue
 			}
 			wr.buf = ojg.AppendJSONString(wr.buf, kv.String(), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ':')
+			wr.cycles.Key(kv.String())
 			wr.appendJSON(rm.Interface(), 0)
+			wr.cycles.Pop()
 		default:
 			wr.buf = ojg.AppendJSONString(wr.buf, kv.String(), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ':')
+			wr.cycles.Key(kv.String())
 			wr.appendJSON(rm.Interface(), 0)
+			wr.cycles.Pop()
 		}
 		wr.buf = append(wr.buf, ',')
 		comma = true
@@ -12,6 +12,7 @@ import (
 	"math"
 	"unicode/utf8"
 
+	"github.com/ohler55/ojg"
 	"github.com/ohler55/ojg/gen"
 )
 
@@ -33,6 +34,13 @@ type Tokenizer struct {
 	rn        rune
 	mode      string
 	nextMode  string
+	counts    []int
+	numStart  int
+	fed       int // bytes fed since Start
+
+	// Limits are resource limits for tokenizing untrusted input. Exceeding
+	// a limit returns an *ojg.LimitError.
+	Limits ojg.Limits
 }
 
 // TokenizeString the provided JSON and call the handler functions for each
@@ -58,13 +66,18 @@ func TokenizeLoad(r io.Reader, handler TokenHandler) error {
 
 // Parse the JSON and call the handler functions for each token in the JSON.
 func (t *Tokenizer) Parse(buf []byte, handler TokenHandler) (err error) {
+	if err = t.Limits.CheckBytes(buf); err != nil {
+		return
+	}
 	t.handler = handler
 	if t.starts == nil {
 		t.tmp = make([]byte, 0, tmpInitSize)
 		t.starts = make([]byte, 0, 16)
+		t.counts = make([]int, 0, 16)
 	} else {
 		t.tmp = t.tmp[:0]
 		t.starts = t.starts[:0]
+		t.counts = t.counts[:0]
 	}
 	t.noff = -1
 	t.line = 1
@@ -86,57 +99,68 @@ func (t *Tokenizer) Parse(buf []byte, handler TokenHandler) (err error) {
 // Load aand parse the JSON and call the handler functions for each token in
 // the JSON.
 func (t *Tokenizer) Load(r io.Reader, handler TokenHandler) (err error) {
+	t.Start(handler)
+	buf := make([]byte, readBufSize)
+	for eof := false; !eof; {
+		var cnt int
+		cnt, err = r.Read(buf)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				return
+			}
+			eof = true
+		}
+		if err = t.Feed(buf[:cnt], eof); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Start prepares the Tokenizer to tokenize JSON that is provided in pieces
+// with Feed instead of all at once with Parse or Load.
+func (t *Tokenizer) Start(handler TokenHandler) {
 	t.handler = handler
 	if t.starts == nil {
 		t.tmp = make([]byte, 0, tmpInitSize)
 		t.starts = make([]byte, 0, 16)
+		t.counts = make([]int, 0, 16)
 	} else {
 		t.tmp = t.tmp[:0]
 		t.starts = t.starts[:0]
+		t.counts = t.counts[:0]
 	}
 	t.noff = -1
 	t.line = 1
 	t.mi = 0
-	buf := make([]byte, readBufSize)
-	eof := false
-	var cnt int
-	cnt, err = r.Read(buf)
-	buf = buf[:cnt]
 	t.mode = valueMap
-	if err != nil {
-		if !errors.Is(err, io.EOF) {
-			return
-		}
-		eof = true
+	t.fed = 0
+}
+
+// Feed the next piece of the JSON to a Tokenizer prepared with Start. The
+// handler functions are called for each token completed by the piece. A
+// token can be split across pieces. The last piece must be fed with last
+// set to true so that a final number can be completed and incomplete JSON
+// can be detected.
+func (t *Tokenizer) Feed(buf []byte, last bool) (err error) {
+	first := t.fed == 0
+	t.fed += len(buf)
+	over := 0 < t.Limits.MaxBytes && t.Limits.MaxBytes < t.fed
+	if over {
+		buf = buf[:len(buf)-(t.fed-t.Limits.MaxBytes)]
+		last = false
 	}
 	var skip int
 	// Skip BOM if present.
-	if 3 < len(buf) && buf[0] == 0xEF && buf[1] == 0xBB && buf[2] == 0xBF {
+	if first && 3 < len(buf) && buf[0] == 0xEF && buf[1] == 0xBB && buf[2] == 0xBF {
 		skip = 3
 	}
-	for {
-		if 0 < skip {
-			err = t.tokenizeBuffer(buf[skip:], eof)
-		} else {
-			err = t.tokenizeBuffer(buf, eof)
-		}
-		if err != nil {
-			return
-		}
-		skip = 0
-		if eof {
-			break
-		}
-		buf = buf[:cap(buf)]
-		cnt, err = r.Read(buf)
-		buf = buf[:cnt]
-		if err != nil {
-			if !errors.Is(err, io.EOF) {
-				return
-			}
-			eof = true
-		}
+	if err = t.tokenizeBuffer(buf[skip:], last); err == nil && over {
+		err = t.limitError(len(buf)-skip, ojg.BytesLimit, t.Limits.MaxBytes)
 	}
+	// The newline offset is relative to the start of the next piece.
+	t.noff -= len(buf) - skip
+
 	return
 }
 
@@ -166,6 +190,9 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 		case strOk:
 			t.tmp = append(t.tmp, b)
 		case keyQuote:
+			if 0 < t.Limits.MaxMembers && t.countMember(true) {
+				return t.limitError(off, ojg.MembersLimit, t.Limits.MaxMembers)
+			}
 			start := off + 1
 			if len(buf) <= start {
 				t.tmp = t.tmp[:0]
@@ -181,6 +208,9 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 			off += i
 			if b == '"' {
 				off++
+				if 0 < t.Limits.MaxStringLen && t.Limits.MaxStringLen < off-start {
+					return t.limitError(off, ojg.StringLimit, t.Limits.MaxStringLen)
+				}
 				t.handler.Key(string(buf[start:off]))
 				t.mode = colonMap
 			} else {
@@ -198,6 +228,9 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 			}
 			continue
 		case valQuote:
+			if 0 < t.Limits.MaxMembers && t.countMember(false) {
+				return t.limitError(off, ojg.MembersLimit, t.Limits.MaxMembers)
+			}
 			start := off + 1
 			if len(buf) <= start {
 				t.tmp = t.tmp[:0]
@@ -213,6 +246,9 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 			off += i
 			if b == '"' {
 				off++
+				if 0 < t.Limits.MaxStringLen && t.Limits.MaxStringLen < off-start {
+					return t.limitError(off, ojg.StringLimit, t.Limits.MaxStringLen)
+				}
 				t.handler.String(string(buf[start:off]))
 				t.mode = afterMap
 			} else {
@@ -223,6 +259,9 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 				continue
 			}
 		case numComma:
+			if t.numberTooLong(off) {
+				return t.limitError(t.numStart, ojg.NumberLimit, t.Limits.MaxNumberLen)
+			}
 			t.handleNum()
 			if 0 < len(t.starts) && t.starts[len(t.starts)-1] == '{' {
 				t.mode = keyMap
@@ -237,7 +276,14 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 			t.mode = stringMap
 			continue
 		case openObject:
+			if 0 < t.Limits.MaxMembers && t.countMember(false) {
+				return t.limitError(off, ojg.MembersLimit, t.Limits.MaxMembers)
+			}
+			if 0 < t.Limits.MaxDepth && t.Limits.MaxDepth <= depth {
+				return t.limitError(off, ojg.DepthLimit, t.Limits.MaxDepth)
+			}
 			t.starts = append(t.starts, objectStart)
+			t.counts = append(t.counts, 0)
 			t.handler.ObjectStart()
 			t.mode = key1Map
 			depth++
@@ -248,16 +294,28 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 				return t.newError(off, "unexpected object close")
 			}
 			if 256 < len(t.mode) && t.mode[256] == 'n' {
+				if t.numberTooLong(off) {
+					return t.limitError(t.numStart, ojg.NumberLimit, t.Limits.MaxNumberLen)
+				}
 				t.handleNum()
 			}
 			t.starts = t.starts[0:depth]
+			t.counts = t.counts[0:depth]
 			t.handler.ObjectEnd()
 			t.mode = afterMap
 		case val0:
+			if 0 < t.Limits.MaxMembers && t.countMember(false) {
+				return t.limitError(off, ojg.MembersLimit, t.Limits.MaxMembers)
+			}
 			t.mode = zeroMap
 			t.num.Reset()
+			t.numStart = off
 		case valDigit:
+			if 0 < t.Limits.MaxMembers && t.countMember(false) {
+				return t.limitError(off, ojg.MembersLimit, t.Limits.MaxMembers)
+			}
 			t.num.Reset()
+			t.numStart = off
 			t.mode = digitMap
 			t.num.I = uint64(b - '0')
 			for i, b = range buf[off+1:] {
@@ -275,9 +333,13 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 			}
 			off += i
 		case valNeg:
+			if 0 < t.Limits.MaxMembers && t.countMember(false) {
+				return t.limitError(off, ojg.MembersLimit, t.Limits.MaxMembers)
+			}
 			t.mode = negMap
 			t.num.Reset()
 			t.num.Neg = true
+			t.numStart = off
 			continue
 		case escU:
 			t.mode = uMap
@@ -285,7 +347,14 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 			t.ri = 0
 			continue
 		case openArray:
+			if 0 < t.Limits.MaxMembers && t.countMember(false) {
+				return t.limitError(off, ojg.MembersLimit, t.Limits.MaxMembers)
+			}
+			if 0 < t.Limits.MaxDepth && t.Limits.MaxDepth <= depth {
+				return t.limitError(off, ojg.DepthLimit, t.Limits.MaxDepth)
+			}
 			t.starts = append(t.starts, arrayStart)
+			t.counts = append(t.counts, 0)
 			t.handler.ArrayStart()
 			t.mode = valueMap
 			depth++
@@ -298,12 +367,19 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 			// Only modes with a close array are value, after, and numbers
 			// which are all over 256 long.
 			if t.mode[256] == 'n' {
+				if t.numberTooLong(off) {
+					return t.limitError(t.numStart, ojg.NumberLimit, t.Limits.MaxNumberLen)
+				}
 				t.handleNum()
 			}
 			t.starts = t.starts[:len(t.starts)-1]
+			t.counts = t.counts[:len(t.counts)-1]
 			t.handler.ArrayEnd()
 			t.mode = afterMap
 		case valNull:
+			if 0 < t.Limits.MaxMembers && t.countMember(false) {
+				return t.limitError(off, ojg.MembersLimit, t.Limits.MaxMembers)
+			}
 			if off+4 <= len(buf) && string(buf[off:off+4]) == "null" {
 				off += 3
 				t.mode = afterMap
@@ -313,6 +389,9 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 				t.ri = 0
 			}
 		case valTrue:
+			if 0 < t.Limits.MaxMembers && t.countMember(false) {
+				return t.limitError(off, ojg.MembersLimit, t.Limits.MaxMembers)
+			}
 			if off+4 <= len(buf) && string(buf[off:off+4]) == "true" {
 				off += 3
 				t.mode = afterMap
@@ -322,6 +401,9 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 				t.ri = 0
 			}
 		case valFalse:
+			if 0 < t.Limits.MaxMembers && t.countMember(false) {
+				return t.limitError(off, ojg.MembersLimit, t.Limits.MaxMembers)
+			}
 			if off+5 <= len(buf) && string(buf[off:off+5]) == "false" {
 				off += 4
 				t.mode = afterMap
@@ -342,7 +424,7 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 				}
 				t.num.Frac = t.num.Frac*10 + uint64(b-'0')
 				t.num.Div *= 10.0
-				if math.MaxInt64 < t.num.Frac {
+				if gen.BigLimit <= t.num.Div {
 					t.num.FillBig()
 					break
 				}
@@ -362,6 +444,9 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 			t.mode = expSignMap
 			continue
 		case strQuote:
+			if 0 < t.Limits.MaxStringLen && t.Limits.MaxStringLen < len(t.tmp) {
+				return t.limitError(off, ojg.StringLimit, t.Limits.MaxStringLen)
+			}
 			t.mode = t.nextMode
 			if t.nextMode == colonMap {
 				t.handler.Key(string(t.tmp))
@@ -376,9 +461,15 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 			t.num.AddDigit(b)
 			t.mode = digitMap
 		case numSpc:
+			if t.numberTooLong(off) {
+				return t.limitError(t.numStart, ojg.NumberLimit, t.Limits.MaxNumberLen)
+			}
 			t.handleNum()
 			t.mode = afterMap
 		case numNewline:
+			if t.numberTooLong(off) {
+				return t.limitError(t.numStart, ojg.NumberLimit, t.Limits.MaxNumberLen)
+			}
 			t.handleNum()
 			t.line++
 			t.noff = off
@@ -460,16 +551,42 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 		}
 	}
 	if last {
-		if len(t.mode) == 256 { // valid finishing maps are one byte longer
+		if 0 < len(t.starts) || len(t.mode) == 256 { // valid finishing maps are one byte longer
 			return t.newError(off, "incomplete JSON")
 		}
 		if t.mode[256] == 'n' {
+			if t.numberTooLong(off) {
+				return t.limitError(t.numStart, ojg.NumberLimit, t.Limits.MaxNumberLen)
+			}
 			t.handleNum()
 		}
 	}
+	if 0 < t.Limits.MaxStringLen && t.Limits.MaxStringLen < len(t.tmp) {
+		return t.limitError(off, ojg.StringLimit, t.Limits.MaxStringLen)
+	}
+	if 0 < t.Limits.MaxNumberLen && t.Limits.MaxNumberLen < len(t.num.BigBuf) {
+		return t.limitError(t.numStart, ojg.NumberLimit, t.Limits.MaxNumberLen)
+	}
+	t.numStart -= len(buf)
+
 	return nil
 }
 
+// countMember counts a member of the object or array being tokenized and
+// returns true if there are more members than allowed. Keys start object
+// members and values start array members.
+func (t *Tokenizer) countMember(key bool) bool {
+	if 0 < len(t.counts) && key == (t.starts[len(t.starts)-1] == objectStart) {
+		t.counts[len(t.counts)-1]++
+		return t.Limits.MaxMembers < t.counts[len(t.counts)-1]
+	}
+	return false
+}
+
+func (t *Tokenizer) numberTooLong(off int) bool {
+	return 0 < t.Limits.MaxNumberLen && t.Limits.MaxNumberLen < off-t.numStart
+}
+
 func (t *Tokenizer) handleNum() {
 	switch tn := t.num.AsNum().(type) {
 	case int64:
@@ -4,11 +4,13 @@
 package oj_test
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"strings"
 	"testing"
 
+	"github.com/ohler55/ojg"
 	"github.com/ohler55/ojg/oj"
 	"github.com/ohler55/ojg/tt"
 )
@@ -84,6 +86,10 @@ func TestTokenizerParseBasic(t *testing.T) {
 	tt.Nil(t, err)
 	tt.Equal(t, "[ 1 2 3 ] ", string(h.buf))
 
+	h.buf = h.buf[:0]
+	err = toker.Parse([]byte("[0.000000000000000000000000001]"), &h)
+	tt.Nil(t, err)
+	tt.Equal(t, "[ 0.000000000000000000000000001 ] ", string(h.buf))
 }
 
 func TestTokenizerLoad(t *testing.T) {
@@ -94,6 +100,24 @@ func TestTokenizerLoad(t *testing.T) {
 	tt.Equal(t, "[ true null 123 12.3 ] { x: 3 } ", string(h.buf))
 }
 
+func TestTokenizerFeed(t *testing.T) {
+	toker := oj.Tokenizer{}
+	h := testHandler{}
+	src := []byte("\xef\xbb\xbf" + `[true,null,123,12.3]{"x":3}`)
+	toker.Start(&h)
+	tt.Nil(t, toker.Feed(src[:10], false))
+	tt.Nil(t, toker.Feed(src[10:18], false))
+	tt.Nil(t, toker.Feed(src[18:], true))
+	tt.Equal(t, "[ true null 123 12.3 ] { x: 3 } ", string(h.buf))
+
+	// Columns are the same as for Parse and not relative to the piece.
+	toker.Start(&h)
+	tt.Nil(t, toker.Feed([]byte("[\n  1,"), false))
+	err := toker.Feed([]byte(" 2"), true)
+	tt.NotNil(t, err)
+	tt.Equal(t, "incomplete JSON at 2:8", err.Error())
+}
+
 func TestZeroHandler(t *testing.T) {
 	h := oj.ZeroHandler{}
 	src := `[true,null,123,12.3]{"x":12345678901234567890}`
@@ -135,6 +159,19 @@ func TestTokenizerLoadEOF(t *testing.T) {
 	tt.NotNil(t, err)
 }
 
+func TestTokenizerIncomplete(t *testing.T) {
+	h := oj.ZeroHandler{}
+	for i, src := range []string{`{"a":1`, `[1,2`, `[1,[2]`, `{"a":{"b":[]}`} {
+		err := oj.TokenizeString(src, &h)
+		tt.NotNil(t, err, i, ": ", src)
+		tt.Equal(t, true, strings.HasPrefix(err.Error(), "incomplete JSON"), i, ": ", src)
+
+		err = oj.TokenizeLoad(strings.NewReader(src), &h)
+		tt.NotNil(t, err, i, ": ", src)
+		tt.Equal(t, true, strings.HasPrefix(err.Error(), "incomplete JSON"), i, ": ", src)
+	}
+}
+
 func TestTokenizerLoadMany(t *testing.T) {
 	h := oj.ZeroHandler{}
 	for i, s := range []string{
@@ -225,6 +262,7 @@ func TestTokenizerMany(t *testing.T) {
 		{src: "{}}", err: "unexpected object close at 1:3"},
 		{src: "{}\n }", err: "unexpected object close at 2:2"},
 		{src: "{ \n", err: "incomplete JSON at 2:1"},
+		{src: `{"a":1`, err: "incomplete JSON at 1:9"},
 		{src: "{]}", err: "expected a string start or object close, not ']' at 1:2"},
 		{src: "[}]", err: "unexpected object close at 1:2"},
 		{src: "{\"a\" \n : 1]}", err: "unexpected array close at 2:5"},
@@ -279,4 +317,33 @@ func TestTokenizerMany(t *testing.T) {
 			tt.Equal(t, d.err, err.Error(), i, ": ", d.src)
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestTokenizerLimits(t *testing.T) {
+	for i, d := range []struct {
+		src    string
+		limits ojg.Limits
+		expect string
+	}{
+		{src: `[[[1]]]`, limits: ojg.Limits{MaxDepth: 3}},
+		{src: `{"a":{"b":{"c":{}}}}`, limits: ojg.Limits{MaxDepth: 3}, expect: "maximum depth of 3 exceeded at 1:16"},
+		{src: `["abc","ab\ncd"]`, limits: ojg.Limits{MaxStringLen: 4}, expect: "maximum string length of 4 exceeded at 1:15"},
+		{src: `{"abcd":1}`, limits: ojg.Limits{MaxStringLen: 3}, expect: "maximum string length of 3 exceeded at 1:7"},
+		{src: `[1,2,3,4]`, limits: ojg.Limits{MaxMembers: 3}, expect: "maximum members of 3 exceeded at 1:8"},
+		{src: "[1,\n2] ", limits: ojg.Limits{MaxBytes: 6}, expect: "maximum bytes of 6 exceeded at 2:3"},
+		{src: `{"a":1234567}`, limits: ojg.Limits{MaxNumberLen: 6}, expect: "maximum number length of 6 exceeded at 1:6"},
+	} {
+		tz := oj.Tokenizer{Limits: d.limits}
+		err := tz.Parse([]byte(d.src), &oj.ZeroHandler{})
+		err2 := tz.Load(strings.NewReader(d.src), &oj.ZeroHandler{})
+		if len(d.expect) == 0 {
+			tt.Nil(t, err, i, ": ", d.src)
+			tt.Nil(t, err2, i, ": ", d.src)
+			continue
+		}
+		var le *ojg.LimitError
+		tt.Equal(t, true, errors.As(err, &le), i, ": ", d.src)
+		tt.Equal(t, d.expect, err.Error(), i, ": ", d.src)
+		tt.Equal(t, d.expect, err2.Error(), i, ": ", d.src)
+	}
+}
@@ -2,7 +2,11 @@
 
 package oj
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/ohler55/ojg"
+)
 
 type tracker struct {
 	line int
@@ -20,6 +24,24 @@ func (t *tracker) newError(off int, format string, args ...any) error {
 	}
 }
 
+func (t *tracker) keyError(off int, key string) error {
+	return &ParseError{
+		Message: fmt.Sprintf("duplicate key '%s'", key),
+		Line:    t.line,
+		Column:  off - t.noff,
+		Key:     key,
+	}
+}
+
+func (t *tracker) limitError(off int, limit ojg.Limit, max int) error {
+	return &ojg.LimitError{
+		Limit:  limit,
+		Max:    max,
+		Line:   t.line,
+		Column: off - t.noff,
+	}
+}
+
 func (t *tracker) byteError(off int, mode string, b byte, r rune) error {
 	err := &ParseError{
 		Line:   t.line,
@@ -33,6 +33,28 @@ func TestUnmarshal(t *testing.T) {
 	tt.Equal(t, src, oj.JSON(obj))
 }
 
+func TestUnmarshalOrdered(t *testing.T) {
+	type Item struct {
+		Name string
+		Tags map[string]any
+		Next *Item
+		Any  any
+	}
+	p := oj.Parser{Ordered: true}
+	var item Item
+	err := p.Unmarshal([]byte(`{"name":"a","tags":{"x":1},"next":{"name":"b"},"any":{"z":1,"y":2}}`), &item)
+	tt.Nil(t, err)
+	tt.Equal(t, "a", item.Name)
+	tt.Equal(t, map[string]any{"x": 1.0}, item.Tags)
+	tt.Equal(t, "b", item.Next.Name)
+	tt.Equal(t, `{"z":1,"y":2}`, oj.JSON(item.Any))
+
+	var m map[string]int
+	err = p.Unmarshal([]byte(`{"b":1,"a":2}`), &m)
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]int{"a": 2, "b": 1}, m)
+}
+
 func TestUnmarshalError(t *testing.T) {
 	type Query struct {
 		Level  string
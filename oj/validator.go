@@ -8,6 +8,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"unicode/utf8"
+
+	"github.com/ohler55/ojg"
 )
 
 const stackMinSize = 32 // for container stack { or [
@@ -28,18 +31,43 @@ type Validator struct {
 	mode     string
 	nextMode string
 
+	// Only used when checking for duplicate keys. The keys slice has an
+	// entry for each open object.
+	keys      []map[string]bool
+	tmp       []byte
+	runeBytes []byte
+	rn        rune
+
+	// Only used when checking limits.
+	counts   []int
+	strLen   int
+	numStart int
+
 	// OnlyOne returns an error if more than one JSON is in the string or
 	// stream.
 	OnlyOne bool
+
+	// NoDuplicates returns an error if an object has more than one member
+	// with the same key.
+	NoDuplicates bool
+
+	// Limits are resource limits for validating untrusted input. Exceeding
+	// a limit returns an *ojg.LimitError.
+	Limits ojg.Limits
 }
 
 // Validate a JSON encoded byte slice.
 func (p *Validator) Validate(buf []byte) (err error) {
+	if err = p.Limits.CheckBytes(buf); err != nil {
+		return
+	}
 	if cap(p.stack) < stackMinSize {
 		p.stack = make([]byte, 0, stackMinSize)
 	} else {
 		p.stack = p.stack[:0]
 	}
+	p.keys = p.keys[:0]
+	p.counts = p.counts[:0]
 	p.noff = -1
 	p.line = 1
 	p.mode = valueMap
@@ -63,6 +91,8 @@ func (p *Validator) ValidateReader(r io.Reader) error {
 	} else {
 		p.stack = p.stack[:0]
 	}
+	p.keys = p.keys[:0]
+	p.counts = p.counts[:0]
 	p.noff = -1
 	p.line = 1
 	p.mode = valueMap
@@ -70,6 +100,7 @@ func (p *Validator) ValidateReader(r io.Reader) error {
 	eof := false
 	cnt, err := r.Read(buf)
 	buf = buf[:cnt]
+	total := cnt
 	if err != nil {
 		if !errors.Is(err, io.EOF) {
 			return err
@@ -82,11 +113,19 @@ func (p *Validator) ValidateReader(r io.Reader) error {
 		skip = 3
 	}
 	for {
+		over := 0 < p.Limits.MaxBytes && p.Limits.MaxBytes < total
+		if over {
+			buf = buf[:len(buf)-(total-p.Limits.MaxBytes)]
+			eof = false
+		}
 		if 0 < skip {
 			err = p.validateBuffer(buf[skip:], eof)
 		} else {
 			err = p.validateBuffer(buf, eof)
 		}
+		if err == nil && over {
+			err = p.limitError(len(buf)-skip, ojg.BytesLimit, p.Limits.MaxBytes)
+		}
 		skip = 0
 		if err != nil {
 			return err
@@ -98,6 +137,7 @@ func (p *Validator) ValidateReader(r io.Reader) error {
 		buf = buf[:cap(buf)]
 		cnt, err := r.Read(buf)
 		buf = buf[:cnt]
+		total += cnt
 		if err != nil {
 			if err != io.EOF {
 				return err
@@ -132,8 +172,16 @@ func (p *Validator) validateBuffer(buf []byte, last bool) error {
 		case skipChar:
 			continue
 		case strOk:
+			if p.NoDuplicates {
+				p.tmp = append(p.tmp, b)
+			}
+			p.strLen++
 			continue
 		case keyQuote:
+			if 0 < p.Limits.MaxMembers && p.countMember(true) {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
+			start := off + 1
 			i = 0
 			for i, b = range buf[off+1:] {
 				if stringMap[b] != strOk {
@@ -143,8 +191,20 @@ func (p *Validator) validateBuffer(buf []byte, last bool) error {
 			off += i
 			if b == '"' && 0 < i {
 				off++
+				if 0 < p.Limits.MaxStringLen && p.Limits.MaxStringLen < off-start {
+					return p.limitError(off, ojg.StringLimit, p.Limits.MaxStringLen)
+				}
+				if p.NoDuplicates {
+					if err := p.checkKey(off, buf[start:off]); err != nil {
+						return err
+					}
+				}
 				p.mode = colonMap
 			} else {
+				if p.NoDuplicates {
+					p.tmp = append(p.tmp[:0], buf[start:off+1]...)
+				}
+				p.strLen = off + 1 - start
 				p.mode = stringMap
 				p.nextMode = colonMap
 			}
@@ -157,6 +217,10 @@ func (p *Validator) validateBuffer(buf []byte, last bool) error {
 			}
 			continue
 		case valQuote:
+			if 0 < p.Limits.MaxMembers && p.countMember(false) {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
+			start := off + 1
 			i = 0
 			for i, b = range buf[off+1:] {
 				if stringMap[b] != strOk {
@@ -166,13 +230,23 @@ func (p *Validator) validateBuffer(buf []byte, last bool) error {
 			off += i
 			if b == '"' && 0 < i {
 				off++
+				if 0 < p.Limits.MaxStringLen && p.Limits.MaxStringLen < off-start {
+					return p.limitError(off, ojg.StringLimit, p.Limits.MaxStringLen)
+				}
 				p.mode = afterMap
 			} else {
+				if p.NoDuplicates {
+					p.tmp = p.tmp[:0]
+				}
+				p.strLen = off + 1 - start
 				p.mode = stringMap
 				p.nextMode = afterMap
 				continue
 			}
 		case numComma:
+			if p.numberTooLong(off) {
+				return p.limitError(p.numStart, ojg.NumberLimit, p.Limits.MaxNumberLen)
+			}
 			if 0 < len(p.stack) && p.stack[len(p.stack)-1] == '{' {
 				p.mode = keyMap
 			} else {
@@ -182,35 +256,79 @@ func (p *Validator) validateBuffer(buf []byte, last bool) error {
 			p.mode = escMap
 			continue
 		case escOk:
+			if p.NoDuplicates {
+				p.tmp = append(p.tmp, escByteMap[b])
+			}
+			p.strLen++
 			p.mode = stringMap
 			continue
 		case openObject:
+			if 0 < p.Limits.MaxMembers && p.countMember(false) {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
+			if 0 < p.Limits.MaxDepth && p.Limits.MaxDepth <= depth {
+				return p.limitError(off, ojg.DepthLimit, p.Limits.MaxDepth)
+			}
 			p.stack = append(p.stack, '{')
+			p.counts = append(p.counts, 0)
 			p.mode = key1Map
 			depth++
+			if p.NoDuplicates {
+				p.pushKeys()
+			}
 			continue
 		case closeObject:
 			depth--
 			if depth < 0 || p.stack[depth] != '{' {
 				return p.newError(off, "unexpected object close")
 			}
+			if p.mode == valueMap {
+				return p.newError(off, "expected a value")
+			}
+			if p.numberTooLong(off) {
+				return p.limitError(p.numStart, ojg.NumberLimit, p.Limits.MaxNumberLen)
+			}
 			p.stack = p.stack[0:depth]
+			p.counts = p.counts[0:depth]
+			if p.NoDuplicates {
+				p.keys = p.keys[:len(p.keys)-1]
+			}
 			p.mode = afterMap
 		case val0:
+			if 0 < p.Limits.MaxMembers && p.countMember(false) {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
 			p.mode = zeroMap
+			p.numStart = off
 			continue
 		case valDigit:
+			if 0 < p.Limits.MaxMembers && p.countMember(false) {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
 			p.mode = digitMap
+			p.numStart = off
 			continue
 		case valNeg:
+			if 0 < p.Limits.MaxMembers && p.countMember(false) {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
 			p.mode = negMap
+			p.numStart = off
 			continue
 		case escU:
 			p.mode = uMap
 			p.ri = 0
+			p.rn = 0
 			continue
 		case openArray:
+			if 0 < p.Limits.MaxMembers && p.countMember(false) {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
+			if 0 < p.Limits.MaxDepth && p.Limits.MaxDepth <= depth {
+				return p.limitError(off, ojg.DepthLimit, p.Limits.MaxDepth)
+			}
 			p.stack = append(p.stack, '[')
+			p.counts = append(p.counts, 0)
 			p.mode = valueMap
 			depth++
 			continue
@@ -219,9 +337,16 @@ func (p *Validator) validateBuffer(buf []byte, last bool) error {
 			if depth < 0 || p.stack[depth] != '[' {
 				return p.newError(off, "unexpected array close")
 			}
+			if p.numberTooLong(off) {
+				return p.limitError(p.numStart, ojg.NumberLimit, p.Limits.MaxNumberLen)
+			}
 			p.stack = p.stack[0:depth]
+			p.counts = p.counts[0:depth]
 			p.mode = afterMap
 		case valNull:
+			if 0 < p.Limits.MaxMembers && p.countMember(false) {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
 			if off+4 <= len(buf) && string(buf[off:off+4]) == "null" {
 				off += 3
 				p.mode = afterMap
@@ -230,6 +355,9 @@ func (p *Validator) validateBuffer(buf []byte, last bool) error {
 				p.ri = 0
 			}
 		case valTrue:
+			if 0 < p.Limits.MaxMembers && p.countMember(false) {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
 			if off+4 <= len(buf) && string(buf[off:off+4]) == "true" {
 				off += 3
 				p.mode = afterMap
@@ -238,6 +366,9 @@ func (p *Validator) validateBuffer(buf []byte, last bool) error {
 				p.ri = 0
 			}
 		case valFalse:
+			if 0 < p.Limits.MaxMembers && p.countMember(false) {
+				return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+			}
 			if off+5 <= len(buf) && string(buf[off:off+5]) == "false" {
 				off += 4
 				p.mode = afterMap
@@ -254,6 +385,14 @@ func (p *Validator) validateBuffer(buf []byte, last bool) error {
 			p.mode = expSignMap
 			continue
 		case strQuote:
+			if 0 < p.Limits.MaxStringLen && p.Limits.MaxStringLen < p.strLen {
+				return p.limitError(off, ojg.StringLimit, p.Limits.MaxStringLen)
+			}
+			if p.NoDuplicates && p.nextMode[':'] == colonColon {
+				if err := p.checkKey(off, p.tmp); err != nil {
+					return err
+				}
+			}
 			p.mode = p.nextMode
 		case numZero:
 			p.mode = zeroMap
@@ -262,8 +401,14 @@ func (p *Validator) validateBuffer(buf []byte, last bool) error {
 		case negDigit:
 			p.mode = digitMap
 		case numSpc:
+			if p.numberTooLong(off) {
+				return p.limitError(p.numStart, ojg.NumberLimit, p.Limits.MaxNumberLen)
+			}
 			p.mode = afterMap
 		case numNewline:
+			if p.numberTooLong(off) {
+				return p.limitError(p.numStart, ojg.NumberLimit, p.Limits.MaxNumberLen)
+			}
 			p.line++
 			p.noff = off
 			p.mode = afterMap
@@ -281,6 +426,24 @@ func (p *Validator) validateBuffer(buf []byte, last bool) error {
 			p.mode = expMap
 		case uOk:
 			p.ri++
+			if p.NoDuplicates || 0 < p.Limits.MaxStringLen {
+				switch b {
+				case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+					p.rn = p.rn<<4 | rune(b-'0')
+				case 'a', 'b', 'c', 'd', 'e', 'f':
+					p.rn = p.rn<<4 | rune(b-'a'+10)
+				case 'A', 'B', 'C', 'D', 'E', 'F':
+					p.rn = p.rn<<4 | rune(b-'A'+10)
+				}
+				if p.ri == 4 {
+					if len(p.runeBytes) < 6 {
+						p.runeBytes = make([]byte, 6)
+					}
+					n := utf8.EncodeRune(p.runeBytes, p.rn)
+					p.tmp = append(p.tmp, p.runeBytes[:n]...)
+					p.strLen += n
+				}
+			}
 			if p.ri == 4 {
 				p.mode = stringMap
 			}
@@ -327,5 +490,58 @@ func (p *Validator) validateBuffer(buf []byte, last bool) error {
 	if last && len(p.mode) == 256 { // valid finishing maps are one byte longer
 		return p.newError(off, "incomplete JSON")
 	}
+	if p.numberTooLong(off) {
+		return p.limitError(p.numStart, ojg.NumberLimit, p.Limits.MaxNumberLen)
+	}
+	if 0 < p.Limits.MaxStringLen && p.Limits.MaxStringLen < p.strLen {
+		return p.limitError(off, ojg.StringLimit, p.Limits.MaxStringLen)
+	}
+	p.numStart -= len(buf)
+
+	return nil
+}
+
+// countMember counts a member of the object or array being validated and
+// returns true if there are more members than allowed. Keys start object
+// members and values start array members.
+func (p *Validator) countMember(key bool) bool {
+	if 0 < len(p.counts) && key == (p.stack[len(p.stack)-1] == '{') {
+		p.counts[len(p.counts)-1]++
+		return p.Limits.MaxMembers < p.counts[len(p.counts)-1]
+	}
+	return false
+}
+
+// numberTooLong returns true if the mode is for a number that has more
+// characters than allowed.
+func (p *Validator) numberTooLong(off int) bool {
+	return 0 < p.Limits.MaxNumberLen && 256 < len(p.mode) && p.mode[256] == 'n' &&
+		p.Limits.MaxNumberLen < off-p.numStart
+}
+
+// pushKeys adds a key set for a newly opened object, reusing previously
+// allocated sets when possible.
+func (p *Validator) pushKeys() {
+	if len(p.keys) < cap(p.keys) {
+		p.keys = p.keys[:len(p.keys)+1]
+		if m := p.keys[len(p.keys)-1]; m != nil {
+			for k := range m {
+				delete(m, k)
+			}
+			return
+		}
+	} else {
+		p.keys = append(p.keys, nil)
+	}
+	p.keys[len(p.keys)-1] = make(map[string]bool, mapInitSize)
+}
+
+func (p *Validator) checkKey(off int, key []byte) error {
+	m := p.keys[len(p.keys)-1]
+	if m[string(key)] {
+		return p.keyError(off, string(key))
+	}
+	m[string(key)] = true
+
 	return nil
-}
\ No newline at end of file
+}
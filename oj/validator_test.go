@@ -4,10 +4,12 @@ package oj_test
 
 import (
 	"bytes"
+	"errors"
 	"strings"
 	"testing"
 	"testing/iotest"
 
+	"github.com/ohler55/ojg"
 	"github.com/ohler55/ojg/oj"
 	"github.com/ohler55/ojg/tt"
 )
@@ -212,3 +214,75 @@ func TestValidatorValidateReaderErr(t *testing.T) {
 	err = v.ValidateReader(&r)
 	tt.NotNil(t, err)
 }
+
+func TestValidatorNoDuplicates(t *testing.T) {
+	v := oj.Validator{NoDuplicates: true}
+	tt.Nil(t, v.Validate([]byte(`{"a":1,"b":{"a":2},"c":[{"a":3},{"a":4}],"d":"A"}`)))
+	tt.Nil(t, v.Validate([]byte(`[{"a\n":1,"a":2},{"a\n":3}]`)))
+
+	for i, d := range []struct {
+		src    string
+		key    string
+		line   int
+		column int
+	}{
+		{src: `{"a":1,"a":2}`, key: "a", line: 1, column: 10},
+		{src: "{\n  \"abc\": 1,\n  \"abc\": 2\n}", key: "abc", line: 3, column: 7},
+		{src: `{"x":{"a\n":1,"a\u000a":2}}`, key: "a\n", line: 1, column: 23},
+		{src: `{"":1,"":2}`, key: "", line: 1, column: 8},
+	} {
+		err := v.Validate([]byte(d.src))
+		var pe *oj.ParseError
+		tt.Equal(t, true, errors.As(err, &pe), i, ": ", d.src)
+		tt.Equal(t, d.key, pe.Key, i, ": ", d.src)
+		tt.Equal(t, d.line, pe.Line, i, ": ", d.src)
+		tt.Equal(t, d.column, pe.Column, i, ": ", d.src)
+
+		err = v.ValidateReader(strings.NewReader(d.src))
+		tt.NotNil(t, err, i, ": ", d.src)
+	}
+	v.NoDuplicates = false
+	tt.Nil(t, v.Validate([]byte(`{"a":1,"a":2}`)))
+}
+
+func TestValidatorMissingValue(t *testing.T) {
+	for _, src := range []string{`{"a":}`, `{"a":1,"b":}`, `[{"a":}]`} {
+		tt.NotNil(t, oj.Validate([]byte(src)), src)
+		_, err := oj.ParseString(src)
+		tt.NotNil(t, err, src)
+	}
+}
+
+func TestValidatorLimits(t *testing.T) {
+	for i, d := range []struct {
+		src    string
+		limits ojg.Limits
+		expect string
+	}{
+		{src: `[[[1]]]`, limits: ojg.Limits{MaxDepth: 3}},
+		{src: `[[[[1]]]]`, limits: ojg.Limits{MaxDepth: 3}, expect: "maximum depth of 3 exceeded at 1:4"},
+		{src: `["abc","ab\ncd"]`, limits: ojg.Limits{MaxStringLen: 5}},
+		{src: `["abc","ab\ncd"]`, limits: ojg.Limits{MaxStringLen: 4}, expect: "maximum string length of 4 exceeded at 1:15"},
+		{src: `["ééé"]`, limits: ojg.Limits{MaxStringLen: 5}, expect: "maximum string length of 5 exceeded at 1:9"},
+		{src: `{"abcd":1}`, limits: ojg.Limits{MaxStringLen: 3}, expect: "maximum string length of 3 exceeded at 1:7"},
+		{src: `[true,2,"x",null]`, limits: ojg.Limits{MaxMembers: 3}, expect: "maximum members of 3 exceeded at 1:13"},
+		{src: `{"a":1,"b":2,"c":3}`, limits: ojg.Limits{MaxMembers: 2}, expect: "maximum members of 2 exceeded at 1:14"},
+		{src: "[1,\n2] ", limits: ojg.Limits{MaxBytes: 6}, expect: "maximum bytes of 6 exceeded at 2:3"},
+		{src: `[12345,1]`, limits: ojg.Limits{MaxNumberLen: 5}},
+		{src: `[12345,123456]`, limits: ojg.Limits{MaxNumberLen: 5}, expect: "maximum number length of 5 exceeded at 1:8"},
+		{src: `-1234.5`, limits: ojg.Limits{MaxNumberLen: 6}, expect: "maximum number length of 6 exceeded at 1:1"},
+	} {
+		v := oj.Validator{Limits: d.limits}
+		err := v.Validate([]byte(d.src))
+		err2 := v.ValidateReader(strings.NewReader(d.src))
+		if len(d.expect) == 0 {
+			tt.Nil(t, err, i, ": ", d.src)
+			tt.Nil(t, err2, i, ": ", d.src)
+			continue
+		}
+		var le *ojg.LimitError
+		tt.Equal(t, true, errors.As(err, &le), i, ": ", d.src)
+		tt.Equal(t, d.expect, err.Error(), i, ": ", d.src)
+		tt.Equal(t, d.expect, err2.Error(), i, ": ", d.src)
+	}
+}
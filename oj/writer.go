@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math/big"
 	"reflect"
 	"sort"
 	"strconv"
@@ -18,6 +19,7 @@ import (
 
 	"github.com/ohler55/ojg"
 	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/gen"
 )
 
 const (
@@ -34,6 +36,7 @@ type Writer struct {
 	w             io.Writer
 	findex        byte
 	strict        bool
+	cycles        *alt.Cycles
 	appendArray   func(wr *Writer, data []any, depth int)
 	appendObject  func(wr *Writer, data map[string]any, depth int)
 	appendDefault func(wr *Writer, data any, depth int)
@@ -66,6 +69,7 @@ func (wr *Writer) MustJSON(data any) []byte {
 		wr.buf = wr.buf[:0]
 	}
 	wr.calcFieldsIndex()
+	wr.cycles = alt.NewCycles(&wr.Options)
 	if wr.Color {
 		wr.colorJSON(data, 0)
 	} else {
@@ -120,6 +124,7 @@ func (wr *Writer) MustWrite(w io.Writer, data any) {
 		wr.buf = wr.buf[:0]
 	}
 	wr.calcFieldsIndex()
+	wr.cycles = alt.NewCycles(&wr.Options)
 	if wr.Color {
 		wr.colorJSON(data, 0)
 	} else {
@@ -150,6 +155,29 @@ func (wr *Writer) MustWrite(w io.Writer, data any) {
 	}
 }
 
+// cycled returns true if rv is already being written in which case the
+// replacement called for by the OnCycle option has been written in its
+// place. If false is returned wr.cycles.Exit() must be called once rv has
+// been written.
+func (wr *Writer) cycled(rv reflect.Value, depth int) bool {
+	cv, seen := wr.cycles.Enter(rv)
+	if seen {
+		if wr.Color {
+			// The caller adds the NoColor so the replacement is written
+			// without it.
+			if m, ok := cv.(map[string]any); ok {
+				wr.colorObject(m, depth)
+			} else {
+				wr.buf = append(wr.buf, wr.NullColor...)
+				wr.buf = append(wr.buf, "null"...)
+			}
+		} else {
+			wr.appendJSON(cv, depth)
+		}
+	}
+	return seen
+}
+
 func (wr *Writer) calcFieldsIndex() {
 	wr.findex = 0
 	if wr.NestEmbed {
@@ -202,6 +230,13 @@ func (wr *Writer) appendJSON(data any, depth int) {
 		wr.buf = strconv.AppendFloat(wr.buf, float64(td), 'g', -1, 32)
 	case float64:
 		wr.buf = strconv.AppendFloat(wr.buf, td, 'g', -1, 64)
+	case json.Number, *big.Int, *big.Float:
+		var err error
+		if wr.buf, err = ojg.AppendNumber(wr.buf, td); err != nil {
+			panic(err)
+		}
+	case gen.Big:
+		wr.buf = append(wr.buf, td...)
 
 	case string:
 		wr.buf = wr.appendString(wr.buf, td, !wr.HTMLUnsafe)
@@ -230,10 +265,22 @@ func (wr *Writer) appendJSON(data any, depth int) {
 			wr.buf = append(wr.buf, "null"...)
 			break
 		}
-		wr.appendArray(wr, td, depth)
+		if wr.cycles == nil || !wr.cycled(reflect.ValueOf(td), depth) {
+			wr.appendArray(wr, td, depth)
+			wr.cycles.Exit()
+		}
 
 	case map[string]any:
-		wr.appendObject(wr, td, depth)
+		if wr.cycles == nil || !wr.cycled(reflect.ValueOf(td), depth) {
+			wr.appendObject(wr, td, depth)
+			wr.cycles.Exit()
+		}
+
+	case *gen.Ordered:
+		if wr.cycles == nil || !wr.cycled(reflect.ValueOf(td), depth) {
+			wr.appendOrdered(td, depth)
+			wr.cycles.Exit()
+		}
 
 	case alt.Simplifier:
 		wr.appendJSON(td.Simplify(), depth)
@@ -285,7 +332,7 @@ func appendDefault(wr *Writer, data any, depth int) {
 			}
 			wr.buf = append(wr.buf, "null"...)
 		default:
-			dec := alt.Decompose(data, &wr.Options)
+			dec := wr.cycles.Decompose(data, &wr.Options)
 			wr.appendJSON(dec, depth)
 		}
 	case wr.strict:
@@ -324,9 +371,11 @@ func appendArray(wr *Writer, n []any, depth int) {
 	}
 	if 0 < len(n) {
 		wr.buf = append(wr.buf, '[')
-		for _, m := range n {
+		for i, m := range n {
 			wr.buf = append(wr.buf, cs...)
+			wr.cycles.Index(i)
 			wr.appendJSON(m, d2)
+			wr.cycles.Pop()
 			wr.buf = append(wr.buf, ',')
 		}
 		wr.buf[len(wr.buf)-1] = '\n'
@@ -390,7 +439,9 @@ func appendObject(wr *Writer, n map[string]any, depth int) {
 		wr.buf = wr.appendString(wr.buf, k, !wr.HTMLUnsafe)
 		wr.buf = append(wr.buf, ':')
 		wr.buf = append(wr.buf, ' ')
+		wr.cycles.Key(k)
 		wr.appendJSON(m, d2)
+		wr.cycles.Pop()
 		wr.buf = append(wr.buf, ',')
 	}
 	if !empty {
@@ -459,7 +510,9 @@ func appendSortObject(wr *Writer, n map[string]any, depth int) {
 		wr.buf = wr.appendString(wr.buf, k, !wr.HTMLUnsafe)
 		wr.buf = append(wr.buf, ':')
 		wr.buf = append(wr.buf, ' ')
+		wr.cycles.Key(k)
 		wr.appendJSON(m, d2)
+		wr.cycles.Pop()
 		wr.buf = append(wr.buf, ',')
 	}
 	if !empty {
@@ -470,6 +523,12 @@ func appendSortObject(wr *Writer, n map[string]any, depth int) {
 }
 
 func (wr *Writer) appendStruct(rv reflect.Value, depth int, si *sinfo) {
+	if wr.cycles != nil && rv.CanAddr() {
+		if wr.cycled(rv.Addr(), depth) {
+			return
+		}
+		defer wr.cycles.Exit()
+	}
 	if si == nil {
 		si = getSinfo(rv.Interface(), wr.OmitEmpty)
 	}
@@ -547,7 +606,9 @@ func (wr *Writer) appendStruct(rv reflect.Value, depth int, si *sinfo) {
 				wr.buf = wr.buf[:len(wr.buf)-fi.keyLen()]
 				continue
 			}
+			wr.cycles.Key(fi.key)
 			wr.appendJSON(v, d2)
+			wr.cycles.Pop()
 			wr.buf = append(wr.buf, ',')
 			indented = false
 			empty = false
@@ -556,6 +617,7 @@ func (wr *Writer) appendStruct(rv reflect.Value, depth int, si *sinfo) {
 		indented = false
 		var fv reflect.Value
 		kind := fi.kind
+		wr.cycles.Key(fi.key)
 	Retry:
 		switch kind {
 		case reflect.Ptr:
@@ -568,6 +630,7 @@ func (wr *Writer) appendStruct(rv reflect.Value, depth int, si *sinfo) {
 			if wr.OmitNil {
 				wr.buf = wr.buf[:len(wr.buf)-fi.keyLen()]
 				indented = true
+				wr.cycles.Pop()
 				continue
 			}
 			wr.buf = append(wr.buf, "null"...)
@@ -575,6 +638,7 @@ func (wr *Writer) appendStruct(rv reflect.Value, depth int, si *sinfo) {
 			if wr.OmitNil && (*[2]uintptr)(unsafe.Pointer(&v))[1] == 0 {
 				wr.buf = wr.buf[:len(wr.buf)-fi.keyLen()]
 				indented = true
+				wr.cycles.Pop()
 				continue
 			}
 			wr.appendJSON(v, 0)
@@ -596,6 +660,7 @@ func (wr *Writer) appendStruct(rv reflect.Value, depth int, si *sinfo) {
 		default:
 			wr.appendJSON(v, d2)
 		}
+		wr.cycles.Pop()
 		wr.buf = append(wr.buf, ',')
 		empty = false
 	}
@@ -615,6 +680,12 @@ func (wr *Writer) appendSlice(rv reflect.Value, depth int, si *sinfo) {
 		wr.buf = append(wr.buf, "[]"...)
 		return
 	}
+	if wr.cycles != nil && rv.Kind() == reflect.Slice {
+		if wr.cycled(rv, depth) {
+			return
+		}
+		defer wr.cycles.Exit()
+	}
 	d2 := depth + 1
 	var is string
 	var cs string
@@ -644,6 +715,7 @@ func (wr *Writer) appendSlice(rv reflect.Value, depth int, si *sinfo) {
 	wr.buf = append(wr.buf, '[')
 	for j := 0; j < end; j++ {
 		wr.buf = append(wr.buf, cs...)
+		wr.cycles.Index(j)
 		rm := rv.Index(j)
 		switch rm.Kind() {
 		case reflect.Struct:
@@ -655,6 +727,7 @@ func (wr *Writer) appendSlice(rv reflect.Value, depth int, si *sinfo) {
 		default:
 			wr.appendJSON(rm.Interface(), d2)
 		}
+		wr.cycles.Pop()
 		wr.buf = append(wr.buf, ',')
 	}
 	wr.buf[len(wr.buf)-1] = '\n'
@@ -663,6 +736,12 @@ func (wr *Writer) appendSlice(rv reflect.Value, depth int, si *sinfo) {
 }
 
 func (wr *Writer) appendMap(rv reflect.Value, depth int, si *sinfo) {
+	if wr.cycles != nil {
+		if wr.cycled(rv, depth) {
+			return
+		}
+		defer wr.cycles.Exit()
+	}
 	keys := rv.MapKeys()
 	if wr.Sort {
 		sort.Slice(keys, func(i, j int) bool { return 0 > strings.Compare(keys[i].String(), keys[j].String()) })
@@ -711,7 +790,9 @@ func (wr *Writer) appendMap(rv reflect.Value, depth int, si *sinfo) {
 			wr.buf = append(wr.buf, cs...)
 			wr.buf = wr.appendString(wr.buf, kv.String(), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ": "...)
+			wr.cycles.Key(kv.String())
 			wr.appendStruct(rm, d2, si)
+			wr.cycles.Pop()
 		case reflect.Slice, reflect.Array:
 			if (wr.OmitNil || wr.OmitEmpty) && rm.Len() == 0 {
 				continue
@@ -719,7 +800,9 @@ func (wr *Writer) appendMap(rv reflect.Value, depth int, si *sinfo) {
 			wr.buf = append(wr.buf, cs...)
 			wr.buf = wr.appendString(wr.buf, kv.String(), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ": "...)
+			wr.cycles.Key(kv.String())
 			wr.appendSlice(rm, d2, si)
+			wr.cycles.Pop()
 		case reflect.Map:
 			if (wr.OmitNil || wr.OmitEmpty) && rm.Len() == 0 {
 				continue
@@ -727,7 +810,9 @@ func (wr *Writer) appendMap(rv reflect.Value, depth int, si *sinfo) {
 			wr.buf = append(wr.buf, cs...)
 			wr.buf = wr.appendString(wr.buf, kv.String(), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ": "...)
+			wr.cycles.Key(kv.String())
 			wr.appendMap(rm, d2, si)
+			wr.cycles.Pop()
 		case reflect.String:
 			if (wr.OmitEmpty) && rm.Len() == 0 {
 				continue
@@ -735,12 +820,16 @@ func (wr *Writer) appendMap(rv reflect.Value, depth int, si *sinfo) {
 			wr.buf = append(wr.buf, cs...)
 			wr.buf = wr.appendString(wr.buf, kv.String(), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ": "...)
+			wr.cycles.Key(kv.String())
 			wr.appendJSON(rm.Interface(), d2)
+			wr.cycles.Pop()
 		default:
 			wr.buf = append(wr.buf, cs...)
 			wr.buf = wr.appendString(wr.buf, kv.String(), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ": "...)
+			wr.cycles.Key(kv.String())
 			wr.appendJSON(rm.Interface(), d2)
+			wr.cycles.Pop()
 		}
 		wr.buf = append(wr.buf, ',')
 		empty = false
@@ -750,4 +839,82 @@ func (wr *Writer) appendMap(rv reflect.Value, depth int, si *sinfo) {
 		wr.buf = append(wr.buf, is...)
 	}
 	wr.buf = append(wr.buf, '}')
-}
\ No newline at end of file
+}
+
+// appendOrdered writes an Ordered object with the members in order unless
+// the Sort option is set.
+func (wr *Writer) appendOrdered(n *gen.Ordered, depth int) {
+	d2 := depth + 1
+	var is string
+	var cs string
+	if wr.Tab {
+		x := depth + 1
+		if len(tabs) < x {
+			x = len(tabs)
+		}
+		is = tabs[0:x]
+		x = d2 + 1
+		if len(tabs) < x {
+			x = len(tabs)
+		}
+		cs = tabs[0:x]
+	} else if 0 < wr.Indent {
+		x := depth*wr.Indent + 1
+		if len(spaces) < x {
+			x = len(spaces)
+		}
+		is = spaces[0:x]
+		x = d2*wr.Indent + 1
+		if len(spaces) < x {
+			x = len(spaces)
+		}
+		cs = spaces[0:x]
+	}
+	members := n.Members
+	if wr.Sort {
+		members = n.Sorted()
+	}
+	empty := true
+	wr.buf = append(wr.buf, '{')
+	for _, m := range members {
+		switch tm := m.Value.(type) {
+		case nil:
+			if wr.OmitNil {
+				continue
+			}
+		case string:
+			if wr.OmitEmpty && len(tm) == 0 {
+				continue
+			}
+		case map[string]any:
+			if wr.OmitEmpty && len(tm) == 0 {
+				continue
+			}
+		case []any:
+			if wr.OmitEmpty && len(tm) == 0 {
+				continue
+			}
+		case *gen.Ordered:
+			if wr.OmitEmpty && tm.Len() == 0 {
+				continue
+			}
+		}
+		if !empty {
+			wr.buf = append(wr.buf, ',')
+		}
+		empty = false
+		wr.buf = append(wr.buf, cs...)
+		wr.buf = wr.appendString(wr.buf, m.Key, !wr.HTMLUnsafe)
+		wr.buf = append(wr.buf, ':')
+		if 0 < len(cs) {
+			wr.buf = append(wr.buf, ' ')
+		}
+		wr.cycles.Key(m.Key)
+		wr.appendJSON(m.Value, d2)
+		wr.cycles.Pop()
+	}
+	if !empty {
+		wr.buf = append(wr.buf, is...)
+	}
+	wr.buf = append(wr.buf, '}')
+}
@@ -4,7 +4,9 @@
 package oj_test
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"strings"
 	"testing"
 	"time"
@@ -1057,4 +1059,124 @@ func TestWriteDev(t *testing.T) {
 	opt := oj.Options{OmitEmpty: true}
 	s := oj.JSON(data, &opt)
 	tt.Equal(t, `{}`, s)
-}
\ No newline at end of file
+}
+
+func TestWriteOrdered(t *testing.T) {
+	o := &gen.Ordered{Members: []gen.Member{
+		{Key: "z", Value: int64(1)},
+		{Key: "a", Value: &gen.Ordered{Members: []gen.Member{{Key: "y", Value: []any{true, ""}}}}},
+		{Key: "n", Value: nil},
+	}}
+	tt.Equal(t, `{"z":1,"a":{"y":[true,""]},"n":null}`, oj.JSON(o))
+	tt.Equal(t, `{"a":{"y":[true,""]},"n":null,"z":1}`, oj.JSON(o, &oj.Options{Sort: true}))
+	tt.Equal(t, `{"z":1,"a":{"y":[true,""]}}`, oj.JSON(o, &oj.Options{OmitNil: true}))
+	tt.Equal(t, `{
+  "z": 1,
+  "a": {
+    "y": [
+      true,
+      ""
+    ]
+  },
+  "n": null
+}`, oj.JSON(o, 2))
+	tt.Equal(t, "\x1b[1m{\x1b[0m\x1b[1;34m\"z\"\x1b[0m\x1b[1m:\x1b[0m\x1b[36m1\x1b[0m\x1b[1m}\x1b[0m",
+		oj.JSON(&gen.Ordered{Members: []gen.Member{{Key: "z", Value: int64(1)}}}, &oj.Options{Color: true, SyntaxColor: "\x1b[1m", KeyColor: "\x1b[1;34m", NumberColor: "\x1b[36m", NoColor: "\x1b[0m"}))
+}
+
+func TestWriteBigNumbers(t *testing.T) {
+	data := []any{
+		json.Number("19.990"),
+		json.Number(""),
+		big.NewInt(-7),
+		new(big.Float).SetFloat64(2.5),
+		gen.Big("12345678901234567890"),
+		(*big.Int)(nil),
+	}
+	tt.Equal(t, `[19.990,0,-7,2.5,12345678901234567890,null]`, oj.JSON(data))
+	tt.Equal(t, "\x1b[1m[\x1b[0m\x1b[36m1.5\x1b[0m\x1b[1m]\x1b[0m",
+		oj.JSON([]any{json.Number("1.5")}, &oj.Options{Color: true, SyntaxColor: "\x1b[1m", NumberColor: "\x1b[36m", NoColor: "\x1b[0m"}))
+
+	inf := new(big.Float).SetInf(true)
+	tt.Equal(t, "", oj.JSON([]any{inf}))
+	_, err := oj.Marshal([]any{inf})
+	tt.NotNil(t, err)
+	tt.Equal(t, "-Inf is not a valid JSON number", strings.Split(err.Error(), "\n")[0])
+}
+
+type cyNode struct {
+	Name   string
+	Parent *cyNode
+	Kids   []*cyNode
+}
+
+type cyTagged struct {
+	Name  string         `json:"title"`
+	Up    *cyTagged      `json:"up,omitempty"`
+	Extra map[string]any `json:"extra"`
+}
+
+func TestWriteCycle(t *testing.T) {
+	root := &cyNode{Name: "root"}
+	root.Kids = []*cyNode{{Name: "kid", Parent: root}}
+
+	tt.Equal(t, `{"kids":[{"kids":[],"name":"kid","parent":{"$ref":"$"}}],"name":"root","parent":null}`,
+		oj.JSON(root, &ojg.Options{Sort: true, OnCycle: ojg.CycleRef}))
+	tt.Equal(t, `{"kids":[{"kids":[],"name":"kid","parent":null}],"name":"root","parent":null}`,
+		oj.JSON(root, &ojg.Options{Sort: true, OnCycle: ojg.CycleNull}))
+	tt.Equal(t, "", oj.JSON(root, &ojg.Options{OnCycle: ojg.CycleFail}))
+
+	_, err := oj.Marshal(root, &ojg.Options{OnCycle: ojg.CycleFail})
+	tt.NotNil(t, err)
+	tt.Equal(t, "cycle at $.kids[0].parent refers back to $", strings.Split(err.Error(), "\n")[0])
+
+	var b strings.Builder
+	err = oj.Write(&b, root, &ojg.Options{OnCycle: ojg.CycleFail})
+	tt.NotNil(t, err)
+
+	// Cyclic values are written with the same struct handling as acyclic
+	// values so the output does not change from one write to the next.
+	expect := `{"kids":[{"kids":[],"name":"kid","parent":{"$ref":"$"}}],"name":"root","parent":null}`
+	for i := 0; i < 3; i++ {
+		tt.Equal(t, expect, oj.JSON(root, &ojg.Options{OnCycle: ojg.CycleRef}))
+	}
+	tt.Equal(t, `{
+  "kids": [
+    {
+      "kids": [],
+      "name": "kid",
+      "parent": {
+        "$ref": "$"
+      }
+    }
+  ],
+  "name": "root",
+  "parent": null
+}`, oj.JSON(root, &ojg.Options{OnCycle: ojg.CycleRef, Indent: 2}))
+
+	m := map[string]any{}
+	m["a"] = []any{1, m}
+	co := ojg.Options{Color: true, SyntaxColor: "\x1b[1m", KeyColor: "\x1b[2m", NoColor: "\x1b[0m"}
+	expect = oj.JSON(map[string]any{"a": []any{1, map[string]any{"$ref": "$"}}}, &co)
+	co.OnCycle = ojg.CycleRef
+	tt.Equal(t, expect, oj.JSON(m, &co))
+
+	tagged := &cyTagged{Name: "top", Extra: map[string]any{}}
+	tagged.Up = &cyTagged{Name: "down", Up: tagged}
+	tagged.Extra["list"] = []any{1, tagged.Extra}
+	tt.Equal(t, `{"extra":{"list":[1,{"$ref":"$.extra"}]},"title":"top","up":{"extra":{},"title":"down","up":{"$ref":"$"}}}`,
+		oj.JSON(tagged, &ojg.Options{OnCycle: ojg.CycleRef, UseTags: true}))
+	tt.Equal(t, `{"extra":{"list":[1,null]},"title":"top","up":{"extra":{},"title":"down","up":null}}`,
+		oj.JSON(tagged, &ojg.Options{OnCycle: ojg.CycleNull, UseTags: true}))
+	_, err = oj.Marshal(map[string]*cyTagged{"x": tagged}, &ojg.Options{OnCycle: ojg.CycleFail, UseTags: true})
+	tt.Equal(t, "cycle at $.x.extra.list[1] refers back to $.x.extra", strings.Split(err.Error(), "\n")[0])
+
+	// Without a cycle the output is unchanged.
+	plain, err := oj.Marshal(&cyNode{Name: "x"})
+	tt.Nil(t, err)
+	opt := ojg.GoOptions
+	opt.OnCycle = ojg.CycleFail
+	out, err := oj.Marshal(&cyNode{Name: "x"}, &opt)
+	tt.Nil(t, err)
+	tt.Equal(t, string(plain), string(out))
+}
@@ -246,6 +246,15 @@ type Options struct {
 	// Converter to use when decomposing or altering if non nil. The Converter
 	// type includes more details.
 	Converter *Converter
+
+	// OnCycle indicates how a pointer, map, or slice that contains itself is
+	// handled when writing or decomposing. Choices are CycleIgnore (the
+	// default), CycleFail, CycleNull, or CycleRef.
+	OnCycle int
+
+	// RefKey is the key for reference objects when OnCycle is CycleRef. If
+	// empty DefaultRefKey is used.
+	RefKey string
 }
 
 // AppendTime appends a time string to the buffer.
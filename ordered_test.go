@@ -0,0 +1,69 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package ojg_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/sen"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestOrderedParseLarge(t *testing.T) {
+	var b strings.Builder
+	b.WriteString(`{"nested":{`)
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(&b, `"k%d":%d,`, i, i)
+	}
+	b.WriteString(`"k5":-5},`)
+	for i := 0; i < 40; i++ {
+		fmt.Fprintf(&b, `"k%d":%d,`, i, i)
+	}
+	b.WriteString(`"k3":-3,"k30":-30}`)
+	src := b.String()
+
+	for _, d := range []struct {
+		name  string
+		parse func(src string, noDups bool) (any, error)
+	}{
+		{name: "gen", parse: func(src string, noDups bool) (any, error) {
+			p := gen.Parser{Ordered: true, NoDuplicates: noDups}
+			return p.Parse([]byte(src))
+		}},
+		{name: "oj", parse: func(src string, noDups bool) (any, error) {
+			p := oj.Parser{Ordered: true, NoDuplicates: noDups}
+			return p.Parse([]byte(src))
+		}},
+		{name: "sen", parse: func(src string, noDups bool) (any, error) {
+			p := sen.Parser{Ordered: true, NoDuplicates: noDups}
+			return p.Parse([]byte(src))
+		}},
+		{name: "json5", parse: func(src string, noDups bool) (any, error) {
+			p := sen.JSON5Parser{Ordered: true, NoDuplicates: noDups}
+			return p.Parse([]byte(src))
+		}},
+	} {
+		v, err := d.parse(src, false)
+		tt.Nil(t, err, d.name)
+		o, _ := v.(*gen.Ordered)
+		tt.NotNil(t, o, d.name)
+		tt.Equal(t, 41, o.Len(), d.name)
+		tt.Equal(t, "k39", o.Members[40].Key, d.name)
+		x, _ := o.Get("k30")
+		tt.Equal(t, "-30", fmt.Sprint(x), d.name)
+		x, _ = o.Get("k3")
+		tt.Equal(t, "-3", fmt.Sprint(x), d.name)
+		nested, _ := o.Get("nested")
+		tt.Equal(t, 20, nested.(*gen.Ordered).Len(), d.name)
+		x, _ = nested.(*gen.Ordered).Get("k5")
+		tt.Equal(t, "-5", fmt.Sprint(x), d.name)
+
+		_, err = d.parse(src, true)
+		tt.NotNil(t, err, d.name)
+		tt.Equal(t, true, strings.Contains(err.Error(), "k5"), d.name, ": ", err)
+	}
+}
@@ -264,6 +264,64 @@ s]x`, string(out))
 s]x`, string(out))
 }
 
+func TestWriteAlignMapOfMaps(t *testing.T) {
+	w := pretty.Writer{
+		Width:    50,
+		MaxDepth: 3,
+		Align:    true,
+	}
+	data := map[string]any{
+		"alpha": map[string]any{"x": 1, "y": 22},
+		"b":     map[string]any{"x": 333, "y": 4, "z": true},
+		"cc":    map[string]any{"y": 5},
+	}
+	out := w.Encode(data)
+	tt.Equal(t, `{
+  "alpha": {"x":   1, "y": 22,          },
+  "b":     {"x": 333, "y":  4, "z": true},
+  "cc":    {          "y":  5,          }
+}`, string(out))
+
+	w.SEN = true
+	w.Width = 40
+	out = w.Encode(data)
+	tt.Equal(t, `{
+  alpha: {x:   1 y: 22        }
+  b:     {x: 333 y:  4 z: true}
+  cc:    {       y:  5        }
+}`, string(out))
+}
+
+func TestWriteAlignMapOfArrays(t *testing.T) {
+	w := pretty.Writer{
+		Width:    20,
+		MaxDepth: 3,
+		Align:    true,
+	}
+	data := map[string]any{
+		"a":   []any{1, 2, 3},
+		"bbb": []any{10, 200, 3},
+	}
+	out := w.Encode(data)
+	tt.Equal(t, `{
+  "a":   [ 1,   2, 3],
+  "bbb": [10, 200, 3]
+}`, string(out))
+
+	// Too narrow for a table so only the values are aligned.
+	w.Width = 16
+	out = w.Encode(data)
+	tt.Equal(t, `{
+  "a":   [1, 2, 3],
+  "bbb": [10, 200, 3]
+}`, string(out))
+
+	// Flat maps are not padded.
+	w.Width = 80
+	out = w.Encode(data)
+	tt.Equal(t, `{"a": [1, 2, 3], "bbb": [10, 200, 3]}`, string(out))
+}
+
 type simplyPanic int
 
 func (sp simplyPanic) Simplify() any {
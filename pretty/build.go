@@ -5,6 +5,9 @@ package pretty
 
 import (
 	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"reflect"
 	"sort"
 	"strconv"
 	"time"
@@ -50,6 +53,14 @@ func (w *Writer) build(data any) (n *node) {
 		n = w.buildFloat64(td)
 	case gen.Float:
 		n = w.buildFloat64(float64(td))
+	case json.Number, *big.Int, *big.Float:
+		buf, err := ojg.AppendNumber(nil, td)
+		if err != nil {
+			panic(err)
+		}
+		n = w.buildNumber(buf)
+	case gen.Big:
+		n = w.buildNumber([]byte(td))
 	case string:
 		n = w.buildStringNode(td)
 	case gen.String:
@@ -72,15 +83,35 @@ func (w *Writer) build(data any) (n *node) {
 	case gen.Time:
 		n = w.buildTimeNode(time.Time(td))
 	case []any:
+		if w.cycles != nil {
+			if cv, seen := w.cycles.Enter(reflect.ValueOf(td)); seen {
+				return w.build(cv)
+			}
+			defer w.cycles.Exit()
+		}
 		n = w.buildArrayNode(td)
 	case gen.Array:
 		n = w.buildGenArrayNode(td)
 	case map[string]any:
+		if w.cycles != nil {
+			if cv, seen := w.cycles.Enter(reflect.ValueOf(td)); seen {
+				return w.build(cv)
+			}
+			defer w.cycles.Exit()
+		}
 		// TBD OmitNil and OmitEmpty
 		n = w.buildMapNode(td)
 	case gen.Object:
 		// TBD OmitNil and OmitEmpty
 		n = w.buildGenMapNode(td)
+	case *gen.Ordered:
+		if w.cycles != nil {
+			if cv, seen := w.cycles.Enter(reflect.ValueOf(td)); seen {
+				return w.build(cv)
+			}
+			defer w.cycles.Exit()
+		}
+		n = w.buildOrderedNode(td)
 	default:
 		if simp, _ := data.(alt.Simplifier); simp != nil {
 			return w.build(simp.Simplify())
@@ -88,7 +119,7 @@ func (w *Writer) build(data any) (n *node) {
 		if g, _ := data.(alt.Genericer); g != nil {
 			return w.build(g.Generic().Simplify())
 		}
-		n = w.build(alt.Decompose(data, &w.Options))
+		n = w.build(w.cycles.Decompose(data, &w.Options))
 	}
 	return
 }
@@ -162,6 +193,18 @@ func (w *Writer) buildFloat64(v float64) (n *node) {
 	return
 }
 
+func (w *Writer) buildNumber(buf []byte) (n *node) {
+	n = &node{
+		buf:  buf,
+		size: len(buf),
+		kind: numNode,
+	}
+	if w.Color {
+		n.buf = append(append([]byte(w.NumberColor), n.buf...), w.NoColor...)
+	}
+	return
+}
+
 func (w *Writer) buildStringNode(v string) (n *node) {
 	w.buf = w.buf[:0]
 	if w.SEN {
@@ -202,7 +245,9 @@ func (w *Writer) buildArrayNode(v []any) (n *node) {
 		skip:    (w.OmitNil || w.OmitEmpty) && len(v) == 0,
 	}
 	for i, m := range v {
+		w.cycles.Index(i)
 		mn := w.build(m)
+		w.cycles.Pop()
 		n.members = append(n.members, mn)
 		if 0 < i {
 			n.size++ // space
@@ -254,7 +299,9 @@ func (w *Writer) buildMapNode(v map[string]any) (n *node) {
 	}
 	sort.Strings(keys)
 	for _, k := range keys {
+		w.cycles.Key(k)
 		mn := w.build(v[k])
+		w.cycles.Pop()
 		if mn.skip {
 			continue
 		}
@@ -330,4 +377,52 @@ func (w *Writer) buildGenMapNode(v gen.Object) (n *node) {
 	n.skip = (w.OmitNil || w.OmitEmpty) && len(n.members) == 0
 
 	return
-}
\ No newline at end of file
+}
+
+// buildOrderedNode builds a map node with the members in order unless the
+// Sort option is set.
+func (w *Writer) buildOrderedNode(v *gen.Ordered) (n *node) {
+	n = &node{
+		members: make([]*node, 0, v.Len()),
+		size:    2, // {}
+		kind:    mapNode,
+	}
+	members := v.Members
+	if w.Sort {
+		members = v.Sorted()
+	}
+	for _, m := range members {
+		w.cycles.Key(m.Key)
+		mn := w.build(m.Value)
+		w.cycles.Pop()
+		if mn.skip {
+			continue
+		}
+		n.members = append(n.members, mn)
+		// build key
+		w.buf = w.buf[:0]
+		if w.SEN {
+			w.buf = ojg.AppendSENString(w.buf, m.Key, !w.HTMLUnsafe)
+		} else {
+			w.buf = ojg.AppendJSONString(w.buf, m.Key, !w.HTMLUnsafe)
+		}
+		mn.key = make([]byte, len(w.buf))
+		copy(mn.key, w.buf)
+		if 2 < n.size {
+			n.size++ // space
+			if !w.SEN {
+				n.size++ // comma
+			}
+		}
+		n.size += len(mn.key) + 2 + mn.size // key, colon, space, value
+		if n.depth < mn.depth+1 {
+			n.depth = mn.depth + 1
+		}
+		if w.Color {
+			mn.key = append(append([]byte(w.KeyColor), mn.key...), w.NoColor...)
+		}
+	}
+	n.skip = (w.OmitNil || w.OmitEmpty) && len(n.members) == 0
+
+	return
+}
@@ -8,6 +8,7 @@ import (
 	"math"
 
 	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/alt"
 )
 
 const (
@@ -36,8 +37,9 @@ type Writer struct {
 	// SEN format if true otherwise JSON encoding.
 	SEN bool
 
-	buf []byte
-	w   io.Writer
+	buf    []byte
+	w      io.Writer
+	cycles *alt.Cycles
 }
 
 // Encode data. Any panics during encoding will cause an empty return but will
@@ -124,6 +126,7 @@ func (w *Writer) encode(data any) (out []byte, err error) {
 			}
 		}
 	}()
+	w.cycles = alt.NewCycles(&w.Options)
 	tree := w.build(data)
 	w.buf = w.buf[:0]
 	w.Indent = 2
@@ -181,7 +184,7 @@ func (w *Writer) fill(n *node, depth int, flat bool) {
 				is = []byte(spaces[0:x])
 			}
 		}
-		if !w.Align || w.MaxDepth < n.depth || len(n.members) < 2 || w.checkAlign(n, start, comma, cs) {
+		if !w.Align || w.MaxDepth < n.depth || len(n.members) < 2 || w.checkAlign(n, start, 0, comma, cs) {
 			for i, m := range n.members {
 				if 0 < i {
 					w.buf = append(w.buf, comma...)
@@ -236,34 +239,28 @@ func (w *Writer) fill(n *node, depth int, flat bool) {
 				is = []byte(spaces[0:x])
 			}
 		}
+		// When aligning, the values of members on separate lines start in
+		// the same column.
 		keyWidth := 1
-		if w.Align {
+		if w.Align && !flat {
 			for _, m := range n.members {
 				if keyWidth < len(m.key) {
 					keyWidth = len(m.key)
 				}
 			}
 		}
-		for i, m := range n.members {
-			if 0 < i {
-				w.buf = append(w.buf, comma...)
-				w.buf = append(w.buf, cs...)
-			} else if !flat {
-				w.buf = append(w.buf, cs...)
-			}
-			w.buf = append(w.buf, m.key...)
-			if w.Color {
-				w.buf = append(w.buf, w.SyntaxColor...)
-				w.buf = append(w.buf, ':')
-				w.buf = append(w.buf, w.NoColor...)
-				w.buf = append(w.buf, ' ')
-			} else {
-				w.buf = append(w.buf, ": "...)
-			}
-			for i := keyWidth - len(m.key); 0 < i; i-- {
-				w.buf = append(w.buf, ' ')
+		if !w.Align || flat || w.MaxDepth < n.depth || len(n.members) < 2 ||
+			w.checkAlign(n, start, keyWidth+2, comma, cs) {
+			for i, m := range n.members {
+				if 0 < i {
+					w.buf = append(w.buf, comma...)
+					w.buf = append(w.buf, cs...)
+				} else if !flat {
+					w.buf = append(w.buf, cs...)
+				}
+				w.writeKey(m.key, keyWidth)
+				w.fill(m, d2, flat)
 			}
-			w.fill(m, d2, flat)
 		}
 		w.buf = append(w.buf, is...)
 		if w.Color {
@@ -282,10 +279,27 @@ func (w *Writer) fill(n *node, depth int, flat bool) {
 	}
 }
 
-// Return true if not filled.
-func (w *Writer) checkAlign(n *node, start int, comma, cs []byte) bool {
+func (w *Writer) writeKey(key []byte, keyWidth int) {
+	w.buf = append(w.buf, key...)
+	if w.Color {
+		w.buf = append(w.buf, w.SyntaxColor...)
+		w.buf = append(w.buf, ':')
+		w.buf = append(w.buf, w.NoColor...)
+		w.buf = append(w.buf, ' ')
+	} else {
+		w.buf = append(w.buf, ": "...)
+	}
+	for i := keyWidth - len(key); 0 < i; i-- {
+		w.buf = append(w.buf, ' ')
+	}
+}
+
+// Return true if not filled. The members of a map are written as rows of a
+// table with the keys as the row labels. The keyWidth is the width of the
+// key labels including the colon and space.
+func (w *Writer) checkAlign(n *node, start, keyWidth int, comma, cs []byte) bool {
 	c := n.genTables(w.SEN)
-	if c == nil || w.Width < start+c.size {
+	if c == nil || w.Width < start+keyWidth+c.size {
 		return true
 	}
 	for i, m := range n.members {
@@ -293,6 +307,9 @@ func (w *Writer) checkAlign(n *node, start int, comma, cs []byte) bool {
 			w.buf = append(w.buf, comma...)
 		}
 		w.buf = append(w.buf, cs...)
+		if n.kind == mapNode {
+			w.writeKey(m.key, keyWidth-2)
+		}
 		switch m.kind {
 		case arrayNode:
 			w.alignArray(m, c, comma, cs)
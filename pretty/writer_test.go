@@ -478,4 +478,47 @@ func TestSENEmpty(t *testing.T) {
 		"c": gen.Object{},
 	}, &ojg.Options{OmitEmpty: true})
 	tt.Equal(t, `{}`, genOut)
-}
\ No newline at end of file
+}
+
+func TestWriteOrdered(t *testing.T) {
+	p := sen.Parser{Ordered: true}
+	v, err := p.Parse([]byte(`{z: 1 a: {y: [1 2] b: true} m: null}`))
+	tt.Nil(t, err)
+	tt.Equal(t, `{
+  "z": 1,
+  "a": {"y": [1, 2], "b": true},
+  "m": null
+}`, pretty.JSON(v, 80.3))
+	tt.Equal(t, `{
+  a: {b: true y: [1 2]}
+  m: null
+  z: 1
+}`, pretty.SEN(v, &ojg.Options{Sort: true}, 80.3))
+}
+
+func TestWriteCycle(t *testing.T) {
+	m := map[string]any{}
+	m["a"] = []any{1, m}
+	tt.Equal(t, `{
+  "a": [1, {"$ref": "$"}]
+}`, pretty.JSON(m, &ojg.Options{OnCycle: ojg.CycleRef}, 80.3))
+	tt.Equal(t, `{
+  a: [1 {$ref: $}]
+}`, pretty.SEN(m, &ojg.Options{OnCycle: ojg.CycleRef}, 80.3))
+
+	type node struct {
+		Name string
+		Next *node
+	}
+	n := &node{Name: "x"}
+	n.Next = &node{Name: "y", Next: n}
+	tt.Equal(t, `{
+  "list": [
+    1,
+    {
+      "name": "x",
+      "next": {"name": "y", "next": {"$ref": "$.list[1]"}}
+    }
+  ]
+}`, pretty.JSON(map[string]any{"list": []any{1, n}}, &ojg.Options{OnCycle: ojg.CycleRef}, 80.3))
+}
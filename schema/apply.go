@@ -0,0 +1,310 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package schema
+
+import (
+	"regexp"
+
+	"github.com/ohler55/ojg/jp"
+)
+
+type ref struct {
+	loc    jp.Expr
+	uri    string
+	target *node
+}
+
+func (k *ref) validate(v *validator, value any, r *result) {
+	r2 := k.target.eval(v, value)
+	r.merge(&r2)
+}
+
+// dynamicRef is a $dynamicRef. If the statically resolved target has a
+// $dynamicAnchor with the same name as the reference fragment then the
+// outermost resource in the dynamic scope with that $dynamicAnchor is used
+// instead.
+type dynamicRef struct {
+	loc    jp.Expr
+	uri    string
+	anchor string
+	target *node
+}
+
+func (k *dynamicRef) validate(v *validator, value any, r *result) {
+	target := k.target
+	if 0 < len(k.anchor) && target.dynamic == k.anchor {
+		for _, res := range v.scope {
+			if n := res.dynamic[k.anchor]; n != nil {
+				target = n
+				break
+			}
+		}
+	}
+	r2 := target.eval(v, value)
+	r.merge(&r2)
+}
+
+type allOf struct {
+	loc   jp.Expr
+	nodes []*node
+}
+
+func (k *allOf) validate(v *validator, value any, r *result) {
+	for _, n := range k.nodes {
+		r2 := n.eval(v, value)
+		r.merge(&r2)
+	}
+}
+
+type anyOf struct {
+	loc   jp.Expr
+	nodes []*node
+}
+
+func (k *anyOf) validate(v *validator, value any, r *result) {
+	valid := false
+	// All the schemas are evaluated to collect annotations.
+	for _, n := range k.nodes {
+		if r2 := n.eval(v, value); len(r2.errs) == 0 {
+			valid = true
+			r.mergeAnnotations(&r2)
+		}
+	}
+	if !valid {
+		v.fail(r, k.loc, "must match at least one schema")
+	}
+}
+
+type oneOf struct {
+	loc   jp.Expr
+	nodes []*node
+}
+
+func (k *oneOf) validate(v *validator, value any, r *result) {
+	var matched []int
+	var first result
+	for i, n := range k.nodes {
+		if r2 := n.eval(v, value); len(r2.errs) == 0 {
+			if len(matched) == 0 {
+				first = r2
+			}
+			matched = append(matched, i)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		v.fail(r, k.loc, "must match exactly one schema but matched none")
+	case 1:
+		r.mergeAnnotations(&first)
+	default:
+		v.fail(r, k.loc, "must match exactly one schema but matched %v", matched)
+	}
+}
+
+type not struct {
+	loc  jp.Expr
+	node *node
+}
+
+func (k *not) validate(v *validator, value any, r *result) {
+	if r2 := k.node.eval(v, value); len(r2.errs) == 0 {
+		v.fail(r, k.loc, "must not match the schema")
+	}
+}
+
+type ifThenElse struct {
+	cond *node
+	then *node
+	els  *node
+}
+
+func (k *ifThenElse) validate(v *validator, value any, r *result) {
+	r2 := k.cond.eval(v, value)
+	if len(r2.errs) == 0 {
+		r.mergeAnnotations(&r2)
+		if k.then != nil {
+			r2 = k.then.eval(v, value)
+			r.merge(&r2)
+		}
+	} else if k.els != nil {
+		r2 = k.els.eval(v, value)
+		r.merge(&r2)
+	}
+}
+
+type dependentSchemas struct {
+	keys  []string
+	nodes map[string]*node
+}
+
+func (k *dependentSchemas) validate(v *validator, value any, r *result) {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return
+	}
+	for _, key := range k.keys {
+		if _, has := obj[key]; has {
+			r2 := k.nodes[key].eval(v, value)
+			r.merge(&r2)
+		}
+	}
+}
+
+type patternNode struct {
+	rx   *regexp.Regexp
+	node *node
+}
+
+// properties includes the properties, patternProperties, and
+// additionalProperties keywords since additionalProperties depends on the
+// other two.
+type properties struct {
+	props      map[string]*node
+	patterns   []*patternNode
+	additional *node
+}
+
+func (k *properties) validate(v *validator, value any, r *result) {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return
+	}
+	for _, key := range sortedKeys(obj) {
+		member := obj[key]
+		matched := false
+		if n := k.props[key]; n != nil {
+			matched = true
+			r2 := v.evalChild(n, key, member)
+			r.errs = append(r.errs, r2.errs...)
+		}
+		for _, pn := range k.patterns {
+			if pn.rx.MatchString(key) {
+				matched = true
+				r2 := v.evalChild(pn.node, key, member)
+				r.errs = append(r.errs, r2.errs...)
+			}
+		}
+		if !matched && k.additional != nil {
+			matched = true
+			r2 := v.evalChild(k.additional, key, member)
+			r.errs = append(r.errs, r2.errs...)
+		}
+		if matched {
+			r.evalProp(key)
+		}
+	}
+}
+
+type propertyNames struct {
+	node *node
+}
+
+func (k *propertyNames) validate(v *validator, value any, r *result) {
+	if obj, ok := value.(map[string]any); ok {
+		for _, key := range sortedKeys(obj) {
+			r2 := v.evalChild(k.node, key, key)
+			r.errs = append(r.errs, r2.errs...)
+		}
+	}
+}
+
+// items includes both the prefixItems and items keywords.
+type items struct {
+	prefix []*node
+	rest   *node
+}
+
+func (k *items) validate(v *validator, value any, r *result) {
+	list, ok := value.([]any)
+	if !ok {
+		return
+	}
+	for i, item := range list {
+		var n *node
+		if i < len(k.prefix) {
+			n = k.prefix[i]
+		} else if n = k.rest; n == nil {
+			break
+		}
+		r2 := v.evalChild(n, i, item)
+		r.errs = append(r.errs, r2.errs...)
+	}
+	if k.rest != nil {
+		r.allItems = true
+	} else if r.items < len(k.prefix) {
+		r.items = len(k.prefix)
+	}
+}
+
+// contains includes the contains, minContains, and maxContains keywords.
+type contains struct {
+	loc  jp.Expr
+	node *node
+	min  int
+	max  int // -1 if not set
+}
+
+func (k *contains) validate(v *validator, value any, r *result) {
+	list, ok := value.([]any)
+	if !ok {
+		return
+	}
+	var matched []int
+	for i, item := range list {
+		if r2 := v.evalChild(k.node, i, item); len(r2.errs) == 0 {
+			matched = append(matched, i)
+		}
+	}
+	switch {
+	case len(matched) < k.min:
+		if k.min == 1 {
+			v.fail(r, k.loc, "must contain at least one matching item")
+		} else {
+			v.fail(r, k.loc, "must contain at least %d matching items, found %d", k.min, len(matched))
+		}
+	case 0 <= k.max && k.max < len(matched):
+		v.fail(r, k.loc, "must contain at most %d matching items, found %d", k.max, len(matched))
+	default:
+		for _, i := range matched {
+			r.evalItem(i)
+		}
+	}
+}
+
+type unevaluatedProperties struct {
+	node *node
+}
+
+func (k *unevaluatedProperties) validate(v *validator, value any, r *result) {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return
+	}
+	for _, key := range sortedKeys(obj) {
+		if r.props[key] {
+			continue
+		}
+		r2 := v.evalChild(k.node, key, obj[key])
+		r.errs = append(r.errs, r2.errs...)
+		r.evalProp(key)
+	}
+}
+
+type unevaluatedItems struct {
+	node *node
+}
+
+func (k *unevaluatedItems) validate(v *validator, value any, r *result) {
+	list, ok := value.([]any)
+	if !ok || r.allItems {
+		return
+	}
+	for i := r.items; i < len(list); i++ {
+		if r.itemSet[i] {
+			continue
+		}
+		r2 := v.evalChild(k.node, i, list[i])
+		r.errs = append(r.errs, r2.errs...)
+	}
+	r.allItems = true
+}
@@ -0,0 +1,253 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package schema
+
+import (
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/oj"
+)
+
+type falseSchema struct {
+	loc jp.Expr
+}
+
+func (k *falseSchema) validate(v *validator, value any, r *result) {
+	v.fail(r, k.loc, "is not allowed")
+}
+
+type typeKw struct {
+	loc   jp.Expr
+	types []string
+}
+
+func (k *typeKw) validate(v *validator, value any, r *result) {
+	vt := jsonType(value)
+	for _, t := range k.types {
+		if t == vt || (t == "number" && vt == "integer") {
+			return
+		}
+	}
+	if len(k.types) == 1 {
+		v.fail(r, k.loc, "must be of type %s, not %s", k.types[0], vt)
+	} else {
+		v.fail(r, k.loc, "must be one of the types %s, not %s", strings.Join(k.types, ", "), vt)
+	}
+}
+
+type enumKw struct {
+	loc    jp.Expr
+	values []any
+}
+
+func (k *enumKw) validate(v *validator, value any, r *result) {
+	for _, ev := range k.values {
+		if equal(ev, value) {
+			return
+		}
+	}
+	v.fail(r, k.loc, "must be one of %s", oj.JSON(k.values))
+}
+
+type constKw struct {
+	loc   jp.Expr
+	value any
+}
+
+func (k *constKw) validate(v *validator, value any, r *result) {
+	if !equal(k.value, value) {
+		v.fail(r, k.loc, "must be %s", oj.JSON(k.value))
+	}
+}
+
+type multipleOf struct {
+	loc jp.Expr
+	by  any
+	rat *big.Rat
+}
+
+func (k *multipleOf) validate(v *validator, value any, r *result) {
+	if !isNumber(value) {
+		return
+	}
+	if rv := asRat(value); rv == nil || !rv.Quo(rv, k.rat).IsInt() {
+		v.fail(r, k.loc, "must be a multiple of %v", k.by)
+	}
+}
+
+const (
+	maxLimit = iota
+	exMaxLimit
+	minLimit
+	exMinLimit
+)
+
+type numLimit struct {
+	loc   jp.Expr
+	limit any
+	op    int
+}
+
+func (k *numLimit) validate(v *validator, value any, r *result) {
+	if !isNumber(value) {
+		return
+	}
+	c := compareNumbers(value, k.limit)
+	switch k.op {
+	case maxLimit:
+		if 0 < c {
+			v.fail(r, k.loc, "must be less than or equal to %v", k.limit)
+		}
+	case exMaxLimit:
+		if 0 <= c {
+			v.fail(r, k.loc, "must be less than %v", k.limit)
+		}
+	case minLimit:
+		if c < 0 {
+			v.fail(r, k.loc, "must be greater than or equal to %v", k.limit)
+		}
+	case exMinLimit:
+		if c <= 0 {
+			v.fail(r, k.loc, "must be greater than %v", k.limit)
+		}
+	}
+}
+
+// countLimit is used for the maxLength, minLength, maxItems, minItems,
+// maxProperties, and minProperties keywords.
+type countLimit struct {
+	loc   jp.Expr
+	limit int
+	max   bool
+	what  string
+}
+
+func (k *countLimit) validate(v *validator, value any, r *result) {
+	var n int
+	switch k.what {
+	case "characters":
+		s, ok := asString(value)
+		if !ok {
+			return
+		}
+		n = utf8.RuneCountInString(s)
+	case "items":
+		list, ok := value.([]any)
+		if !ok {
+			return
+		}
+		n = len(list)
+	default:
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+		n = len(obj)
+	}
+	if k.max && k.limit < n {
+		v.fail(r, k.loc, "must have at most %d %s", k.limit, k.what)
+	} else if !k.max && n < k.limit {
+		v.fail(r, k.loc, "must have at least %d %s", k.limit, k.what)
+	}
+}
+
+type patternKw struct {
+	loc jp.Expr
+	rx  *regexp.Regexp
+}
+
+func (k *patternKw) validate(v *validator, value any, r *result) {
+	if s, ok := asString(value); ok && !k.rx.MatchString(s) {
+		v.fail(r, k.loc, "must match the pattern %s", k.rx)
+	}
+}
+
+type formatKw struct {
+	loc   jp.Expr
+	name  string
+	check func(s string) bool
+}
+
+func (k *formatKw) validate(v *validator, value any, r *result) {
+	if !v.assertFormat {
+		return
+	}
+	if s, ok := value.(string); ok && !k.check(s) {
+		v.fail(r, k.loc, "must be a valid %s", k.name)
+	}
+}
+
+type uniqueItems struct {
+	loc jp.Expr
+}
+
+func (k *uniqueItems) validate(v *validator, value any, r *result) {
+	list, ok := value.([]any)
+	if !ok {
+		return
+	}
+	for i := 1; i < len(list); i++ {
+		for j := 0; j < i; j++ {
+			if equal(list[i], list[j]) {
+				v.fail(r, k.loc, "must have unique items, items %d and %d are equal", j, i)
+				return
+			}
+		}
+	}
+}
+
+type required struct {
+	loc   jp.Expr
+	names []string
+}
+
+func (k *required) validate(v *validator, value any, r *result) {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return
+	}
+	for _, name := range k.names {
+		if _, has := obj[name]; !has {
+			v.fail(r, k.loc, "is missing the required member %q", name)
+		}
+	}
+}
+
+type dependentRequired struct {
+	loc  jp.Expr
+	keys []string
+	deps map[string][]string
+}
+
+func (k *dependentRequired) validate(v *validator, value any, r *result) {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return
+	}
+	for _, key := range k.keys {
+		if _, has := obj[key]; !has {
+			continue
+		}
+		for _, name := range k.deps[key] {
+			if _, has := obj[name]; !has {
+				v.fail(r, append(append(jp.Expr{}, k.loc...), jp.Child(key)),
+					"is missing the member %q required by %q", name, key)
+			}
+		}
+	}
+}
+
+func sortedKeys(obj map[string]any) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,526 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package schema
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ohler55/ojg/jp"
+)
+
+// Compiler compiles JSON Schema documents into a Schema.
+type Compiler struct {
+	docs map[string]any
+}
+
+type compiler struct {
+	*Compiler
+	nodes     map[string]*node
+	resources map[string]*resource
+	anchors   map[string]*node
+	refs      []*pendingRef
+}
+
+type pendingRef struct {
+	loc     jp.Expr
+	uri     string
+	resolve func(n *node)
+}
+
+// AddResource adds a schema document that can be referenced by the uri
+// from schemas compiled by the compiler. Documents with an $id are also
+// available by that $id.
+func (c *Compiler) AddResource(uri string, doc any) {
+	if c.docs == nil {
+		c.docs = map[string]any{}
+	}
+	base, _, _ := splitURI("", uri)
+	c.docs[base] = plain(doc)
+}
+
+// Compile a JSON Schema document. The document can be simple data or a
+// gen.Node.
+func (c *Compiler) Compile(doc any) (s *Schema, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if err, _ = r.(error); err == nil {
+				err = fmt.Errorf("%v", r)
+			}
+			s = nil
+		}
+	}()
+	cc := compiler{
+		Compiler:  c,
+		nodes:     map[string]*node{},
+		resources: map[string]*resource{},
+		anchors:   map[string]*node{},
+	}
+	s = &Schema{root: cc.compileDoc("", plain(doc))}
+	for 0 < len(cc.refs) {
+		pr := cc.refs[len(cc.refs)-1]
+		cc.refs = cc.refs[:len(cc.refs)-1]
+		pr.resolve(cc.lookup(pr))
+	}
+	return
+}
+
+func (c *compiler) fail(loc jp.Expr, format string, args ...any) {
+	panic(fmt.Errorf("invalid schema at %s, %s", loc, fmt.Sprintf(format, args...)))
+}
+
+func (c *compiler) compileDoc(uri string, doc any) *node {
+	res := &resource{uri: uri, doc: doc, loc: jp.R(), dynamic: map[string]*node{}}
+	c.resources[uri] = res
+	return c.compile(doc, res, "", jp.R())
+}
+
+func (c *compiler) compile(doc any, res *resource, ptr string, loc jp.Expr) (n *node) {
+	key := res.uri + "#" + ptr
+	if n = c.nodes[key]; n != nil {
+		return
+	}
+	n = &node{loc: loc, res: res}
+	c.nodes[key] = n
+	if res.root == nil {
+		res.root = n
+	}
+	var obj map[string]any
+	switch td := doc.(type) {
+	case bool:
+		if !td {
+			n.keywords = append(n.keywords, &falseSchema{loc: loc})
+		}
+		return
+	case map[string]any:
+		obj = td
+	default:
+		c.fail(loc, "a schema must be an object or a boolean, not a %T", doc)
+	}
+	if v, has := obj["$id"]; has {
+		uri, frag := c.uri(n.at("$id"), res.uri, c.str(n.at("$id"), v))
+		if 0 < len(frag) {
+			c.fail(n.at("$id"), "an $id must not include a fragment")
+		}
+		if uri != res.uri {
+			res = &resource{uri: uri, doc: obj, loc: loc, root: n, dynamic: map[string]*node{}}
+			c.resources[uri] = res
+			n.res = res
+			ptr = ""
+			c.nodes[uri+"#"] = n
+		}
+	}
+	if v, has := obj["$anchor"]; has {
+		c.anchors[res.uri+"#"+c.str(n.at("$anchor"), v)] = n
+	}
+	if v, has := obj["$dynamicAnchor"]; has {
+		n.dynamic = c.str(n.at("$dynamicAnchor"), v)
+		c.anchors[res.uri+"#"+n.dynamic] = n
+		res.dynamic[n.dynamic] = n
+	}
+	for _, defs := range []string{"$defs", "definitions"} {
+		if v, has := obj[defs]; has {
+			for k, sub := range c.object(n.at(defs), v) {
+				c.compile(sub, res, childPtr(ptr, defs, k), n.at(defs).C(k))
+			}
+		}
+	}
+	c.compileRefs(n, obj, res)
+	c.compileAssertions(n, obj)
+	c.compileApplicators(n, obj, res, ptr)
+	c.compileUnevaluated(n, obj, res, ptr)
+
+	return
+}
+
+func (c *compiler) compileRefs(n *node, obj map[string]any, res *resource) {
+	if v, has := obj["$ref"]; has {
+		loc := n.at("$ref")
+		k := &ref{loc: loc, uri: c.str(loc, v)}
+		n.keywords = append(n.keywords, k)
+		uri, frag := c.uri(loc, res.uri, k.uri)
+		c.refs = append(c.refs, &pendingRef{loc: loc, uri: uri + "#" + frag, resolve: func(t *node) { k.target = t }})
+	}
+	if v, has := obj["$dynamicRef"]; has {
+		loc := n.at("$dynamicRef")
+		k := &dynamicRef{loc: loc, uri: c.str(loc, v)}
+		n.keywords = append(n.keywords, k)
+		uri, frag := c.uri(loc, res.uri, k.uri)
+		if !strings.HasPrefix(frag, "/") {
+			k.anchor = frag
+		}
+		c.refs = append(c.refs, &pendingRef{loc: loc, uri: uri + "#" + frag, resolve: func(t *node) { k.target = t }})
+	}
+}
+
+func (c *compiler) compileAssertions(n *node, obj map[string]any) {
+	if v, has := obj["type"]; has {
+		loc := n.at("type")
+		k := &typeKw{loc: loc}
+		switch tv := v.(type) {
+		case string:
+			k.types = []string{tv}
+		case []any:
+			for i, t := range tv {
+				k.types = append(k.types, c.str(loc.N(i), t))
+			}
+		default:
+			c.fail(loc, "type must be a string or an array of strings")
+		}
+		for _, t := range k.types {
+			switch t {
+			case "null", "boolean", "object", "array", "number", "integer", "string":
+			default:
+				c.fail(loc, "%s is not a valid type", t)
+			}
+		}
+		n.keywords = append(n.keywords, k)
+	}
+	if v, has := obj["enum"]; has {
+		list, ok := v.([]any)
+		if !ok {
+			c.fail(n.at("enum"), "enum must be an array")
+		}
+		n.keywords = append(n.keywords, &enumKw{loc: n.at("enum"), values: list})
+	}
+	if v, has := obj["const"]; has {
+		n.keywords = append(n.keywords, &constKw{loc: n.at("const"), value: v})
+	}
+	if v, has := obj["multipleOf"]; has {
+		loc := n.at("multipleOf")
+		rat := asRat(c.number(loc, v))
+		if rat == nil || rat.Sign() <= 0 {
+			c.fail(loc, "multipleOf must be greater than zero")
+		}
+		n.keywords = append(n.keywords, &multipleOf{loc: loc, by: v, rat: rat})
+	}
+	for _, lim := range []struct {
+		key string
+		op  int
+	}{
+		{key: "maximum", op: maxLimit},
+		{key: "exclusiveMaximum", op: exMaxLimit},
+		{key: "minimum", op: minLimit},
+		{key: "exclusiveMinimum", op: exMinLimit},
+	} {
+		if v, has := obj[lim.key]; has {
+			loc := n.at(lim.key)
+			n.keywords = append(n.keywords, &numLimit{loc: loc, limit: c.number(loc, v), op: lim.op})
+		}
+	}
+	for _, lim := range []struct {
+		key  string
+		max  bool
+		what string
+	}{
+		{key: "maxLength", max: true, what: "characters"},
+		{key: "minLength", what: "characters"},
+		{key: "maxItems", max: true, what: "items"},
+		{key: "minItems", what: "items"},
+		{key: "maxProperties", max: true, what: "members"},
+		{key: "minProperties", what: "members"},
+	} {
+		if v, has := obj[lim.key]; has {
+			loc := n.at(lim.key)
+			n.keywords = append(n.keywords, &countLimit{loc: loc, limit: c.count(loc, v), max: lim.max, what: lim.what})
+		}
+	}
+	if v, has := obj["pattern"]; has {
+		loc := n.at("pattern")
+		n.keywords = append(n.keywords, &patternKw{loc: loc, rx: c.regexp(loc, v)})
+	}
+	if v, has := obj["format"]; has {
+		loc := n.at("format")
+		name := c.str(loc, v)
+		if check := formats[name]; check != nil {
+			n.keywords = append(n.keywords, &formatKw{loc: loc, name: name, check: check})
+		}
+	}
+	if v, has := obj["uniqueItems"]; has {
+		if b, _ := v.(bool); b {
+			n.keywords = append(n.keywords, &uniqueItems{loc: n.at("uniqueItems")})
+		}
+	}
+	if v, has := obj["required"]; has {
+		loc := n.at("required")
+		n.keywords = append(n.keywords, &required{loc: loc, names: c.strings(loc, v)})
+	}
+	if v, has := obj["dependentRequired"]; has {
+		loc := n.at("dependentRequired")
+		k := &dependentRequired{loc: loc, deps: map[string][]string{}}
+		for key, names := range c.object(loc, v) {
+			k.keys = append(k.keys, key)
+			k.deps[key] = c.strings(loc.C(key), names)
+		}
+		sort.Strings(k.keys)
+		n.keywords = append(n.keywords, k)
+	}
+}
+
+func (c *compiler) compileApplicators(n *node, obj map[string]any, res *resource, ptr string) {
+	sub := func(key string, v any) *node {
+		return c.compile(v, res, childPtr(ptr, key), n.at(key))
+	}
+	subs := func(key string) (nodes []*node) {
+		loc := n.at(key)
+		list, ok := obj[key].([]any)
+		if !ok || len(list) == 0 {
+			c.fail(loc, "%s must be a non-empty array", key)
+		}
+		for i, v := range list {
+			nodes = append(nodes, c.compile(v, res, childPtr(ptr, key, strconv.Itoa(i)), loc.N(i)))
+		}
+		return
+	}
+	if _, has := obj["allOf"]; has {
+		n.keywords = append(n.keywords, &allOf{loc: n.at("allOf"), nodes: subs("allOf")})
+	}
+	if _, has := obj["anyOf"]; has {
+		n.keywords = append(n.keywords, &anyOf{loc: n.at("anyOf"), nodes: subs("anyOf")})
+	}
+	if _, has := obj["oneOf"]; has {
+		n.keywords = append(n.keywords, &oneOf{loc: n.at("oneOf"), nodes: subs("oneOf")})
+	}
+	if v, has := obj["not"]; has {
+		n.keywords = append(n.keywords, &not{loc: n.at("not"), node: sub("not", v)})
+	}
+	if v, has := obj["if"]; has {
+		k := &ifThenElse{cond: sub("if", v)}
+		if v, has = obj["then"]; has {
+			k.then = sub("then", v)
+		}
+		if v, has = obj["else"]; has {
+			k.els = sub("else", v)
+		}
+		n.keywords = append(n.keywords, k)
+	}
+	if v, has := obj["dependentSchemas"]; has {
+		loc := n.at("dependentSchemas")
+		k := &dependentSchemas{nodes: map[string]*node{}}
+		for key, sv := range c.object(loc, v) {
+			k.keys = append(k.keys, key)
+			k.nodes[key] = c.compile(sv, res, childPtr(ptr, "dependentSchemas", key), loc.C(key))
+		}
+		sort.Strings(k.keys)
+		n.keywords = append(n.keywords, k)
+	}
+	var props *properties
+	if v, has := obj["properties"]; has {
+		loc := n.at("properties")
+		props = &properties{props: map[string]*node{}}
+		for key, sv := range c.object(loc, v) {
+			props.props[key] = c.compile(sv, res, childPtr(ptr, "properties", key), loc.C(key))
+		}
+	}
+	if v, has := obj["patternProperties"]; has {
+		loc := n.at("patternProperties")
+		if props == nil {
+			props = &properties{}
+		}
+		pp := c.object(loc, v)
+		for _, key := range sortedKeys(pp) {
+			props.patterns = append(props.patterns, &patternNode{
+				rx:   c.regexp(loc.C(key), key),
+				node: c.compile(pp[key], res, childPtr(ptr, "patternProperties", key), loc.C(key)),
+			})
+		}
+	}
+	if v, has := obj["additionalProperties"]; has {
+		if props == nil {
+			props = &properties{}
+		}
+		props.additional = sub("additionalProperties", v)
+	}
+	if props != nil {
+		n.keywords = append(n.keywords, props)
+	}
+	if v, has := obj["propertyNames"]; has {
+		n.keywords = append(n.keywords, &propertyNames{node: sub("propertyNames", v)})
+	}
+	var it *items
+	if _, has := obj["prefixItems"]; has {
+		it = &items{prefix: subs("prefixItems")}
+	}
+	if v, has := obj["items"]; has {
+		if it == nil {
+			it = &items{}
+		}
+		it.rest = sub("items", v)
+	}
+	if it != nil {
+		n.keywords = append(n.keywords, it)
+	}
+	if v, has := obj["contains"]; has {
+		k := &contains{loc: n.at("contains"), node: sub("contains", v), min: 1, max: -1}
+		if v, has = obj["minContains"]; has {
+			k.min = c.count(n.at("minContains"), v)
+		}
+		if v, has = obj["maxContains"]; has {
+			k.max = c.count(n.at("maxContains"), v)
+		}
+		n.keywords = append(n.keywords, k)
+	}
+}
+
+// compileUnevaluated adds the unevaluated keywords which must be evaluated
+// after all the other keywords in the schema.
+func (c *compiler) compileUnevaluated(n *node, obj map[string]any, res *resource, ptr string) {
+	if v, has := obj["unevaluatedItems"]; has {
+		n.keywords = append(n.keywords, &unevaluatedItems{
+			node: c.compile(v, res, childPtr(ptr, "unevaluatedItems"), n.at("unevaluatedItems")),
+		})
+	}
+	if v, has := obj["unevaluatedProperties"]; has {
+		n.keywords = append(n.keywords, &unevaluatedProperties{
+			node: c.compile(v, res, childPtr(ptr, "unevaluatedProperties"), n.at("unevaluatedProperties")),
+		})
+	}
+}
+
+// lookup finds the node a reference refers to, compiling the node if
+// needed.
+func (c *compiler) lookup(pr *pendingRef) *node {
+	if n := c.nodes[pr.uri]; n != nil {
+		return n
+	}
+	if n := c.anchors[pr.uri]; n != nil {
+		return n
+	}
+	base, frag, _ := strings.Cut(pr.uri, "#")
+	res := c.resources[base]
+	if res == nil {
+		doc, has := c.docs[base]
+		if !has {
+			c.fail(pr.loc, "can not resolve %s", pr.uri)
+		}
+		c.compileDoc(base, doc)
+		return c.lookup(pr)
+	}
+	if len(frag) == 0 {
+		return res.root
+	}
+	if frag[0] != '/' {
+		c.fail(pr.loc, "can not resolve %s", pr.uri)
+	}
+	// The reference is to a location that is not a known subschema so
+	// follow the pointer through the resource document.
+	v := res.doc
+	loc := append(jp.Expr{}, res.loc...)
+	for _, tok := range strings.Split(frag[1:], "/") {
+		tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+		switch tv := v.(type) {
+		case map[string]any:
+			v = tv[tok]
+			loc = loc.C(tok)
+		case []any:
+			i, err := strconv.Atoi(tok)
+			if err != nil || i < 0 || len(tv) <= i {
+				c.fail(pr.loc, "can not resolve %s", pr.uri)
+			}
+			v = tv[i]
+			loc = loc.N(i)
+		default:
+			c.fail(pr.loc, "can not resolve %s", pr.uri)
+		}
+		if v == nil {
+			c.fail(pr.loc, "can not resolve %s", pr.uri)
+		}
+	}
+	return c.compile(v, res, frag, loc)
+}
+
+// uri resolves a reference against a base URI and returns the absolute URI
+// without the fragment and the unescaped fragment.
+func (c *compiler) uri(loc jp.Expr, base, ref string) (string, string) {
+	uri, frag, err := splitURI(base, ref)
+	if err != nil {
+		c.fail(loc, "%s", err)
+	}
+	return uri, frag
+}
+
+func splitURI(base, ref string) (string, string, error) {
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", "", err
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return "", "", err
+	}
+	u := r
+	if 0 < len(base) {
+		u = b.ResolveReference(r)
+	}
+	frag := u.Fragment
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	return u.String(), frag, nil
+}
+
+// childPtr returns a JSON Pointer to a child of ptr.
+func childPtr(ptr string, keys ...string) string {
+	var b strings.Builder
+	b.WriteString(ptr)
+	for _, k := range keys {
+		b.WriteByte('/')
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(k, "~", "~0"), "/", "~1"))
+	}
+	return b.String()
+}
+
+func (c *compiler) str(loc jp.Expr, v any) string {
+	s, ok := v.(string)
+	if !ok {
+		c.fail(loc, "expected a string, not a %T", v)
+	}
+	return s
+}
+
+func (c *compiler) strings(loc jp.Expr, v any) (list []string) {
+	vs, ok := v.([]any)
+	if !ok {
+		c.fail(loc, "expected an array of strings, not a %T", v)
+	}
+	for i, s := range vs {
+		list = append(list, c.str(loc.N(i), s))
+	}
+	return
+}
+
+func (c *compiler) object(loc jp.Expr, v any) map[string]any {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		c.fail(loc, "expected an object, not a %T", v)
+	}
+	return obj
+}
+
+func (c *compiler) number(loc jp.Expr, v any) any {
+	if !isNumber(v) {
+		c.fail(loc, "expected a number, not a %T", v)
+	}
+	return v
+}
+
+func (c *compiler) count(loc jp.Expr, v any) int {
+	if jsonType(v) != "integer" || compareNumbers(v, 0) < 0 {
+		c.fail(loc, "expected a non-negative integer, not %v", v)
+	}
+	f, _ := asFloat(v)
+
+	return int(f)
+}
+
+func (c *compiler) regexp(loc jp.Expr, v any) *regexp.Regexp {
+	rx, err := regexp.Compile(c.str(loc, v))
+	if err != nil {
+		c.fail(loc, "%s", err)
+	}
+	return rx
+}
@@ -0,0 +1,27 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+/*
+Package schema provides a JSON Schema draft 2020-12 validator for simple data
+and gen.Node data. Schema documents are usually loaded with oj.Parse() or
+sen.Parse() and then compiled.
+
+	s, err := schema.Compile(oj.MustParseString(`{
+	  "type": "object",
+	  "properties": {"age": {"type": "integer", "minimum": 0}},
+	  "required": ["age"]
+	}`))
+	errs := s.Validate(oj.MustParseString(`{"age": -1}`))
+	// errs[0]: $.age must be greater than or equal to 0 (schema $.properties.age.minimum)
+
+Each validation Error includes a JSONPath to the failing location in the
+instance and a JSONPath to the keyword in the schema that failed.
+
+All the applicator and assertion keywords of the core, applicator,
+unevaluated, validation, and format vocabularies are supported including
+$ref, $dynamicRef, $defs, $anchor, and $dynamicAnchor. References to other
+documents are resolved from resources added to a Compiler with
+AddResource(). The format keyword is an annotation unless the AssertFormat
+option is passed to Validate() or Valid(). Additional formats can be added
+with RegisterFormat().
+*/
+package schema
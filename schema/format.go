@@ -0,0 +1,156 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package schema
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	durationRx = regexp.MustCompile(`^P(?:(?:\d+Y)?(?:\d+M)?(?:\d+D)?(?:T(?:\d+H)?(?:\d+M)?(?:\d+S)?)?|\d+W)$`)
+	uuidRx     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	relPtrRx   = regexp.MustCompile(`^(?:0|[1-9][0-9]*)(?:#|(?:/.*)?)$`)
+
+	formats = map[string]func(s string) bool{
+		"date-time":             isDateTime,
+		"date":                  isDate,
+		"time":                  isTime,
+		"duration":              isDuration,
+		"email":                 isEmail,
+		"idn-email":             isEmail,
+		"hostname":              isHostname,
+		"idn-hostname":          isHostname,
+		"ipv4":                  isIPv4,
+		"ipv6":                  isIPv6,
+		"uri":                   isURI,
+		"uri-reference":         isURIReference,
+		"iri":                   isURI,
+		"iri-reference":         isURIReference,
+		"uri-template":          isURITemplate,
+		"uuid":                  uuidRx.MatchString,
+		"regex":                 isRegex,
+		"json-pointer":          isJSONPointer,
+		"relative-json-pointer": isRelativeJSONPointer,
+	}
+)
+
+// RegisterFormat registers a function that checks strings for the named
+// format. A registered format replaces a built in format with the same
+// name. Formats should be registered before any schemas are compiled as the
+// registry is not protected against concurrent access.
+func RegisterFormat(name string, check func(s string) bool) {
+	formats[name] = check
+}
+
+func isDateTime(s string) bool {
+	// RFC 3339 allows a lowercase t and z.
+	_, err := time.Parse(time.RFC3339, strings.ToUpper(s))
+	return err == nil
+}
+
+func isDate(s string) bool {
+	_, err := time.Parse("2006-01-02", s)
+	return err == nil
+}
+
+func isTime(s string) bool {
+	_, err := time.Parse("15:04:05Z07:00", strings.ToUpper(s))
+	return err == nil
+}
+
+func isDuration(s string) bool {
+	return durationRx.MatchString(s) && s != "P" && !strings.HasSuffix(s, "T")
+}
+
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Name == "" && addr.Address == s
+}
+
+func isHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if len(s) == 0 || 253 < len(s) {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if len(label) == 0 || 63 < len(label) || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, b := range []byte(label) {
+			if !('a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9' || b == '-' || 0x80 <= b) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isIPv4(s string) bool {
+	ip := net.ParseIP(s)
+	return ip != nil && ip.To4() != nil && !strings.Contains(s, ":")
+}
+
+func isIPv6(s string) bool {
+	return net.ParseIP(s) != nil && strings.Contains(s, ":")
+}
+
+func isURI(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.IsAbs() && !strings.ContainsAny(s, " \\")
+}
+
+func isURIReference(s string) bool {
+	_, err := url.Parse(s)
+	return err == nil && !strings.ContainsAny(s, " \\")
+}
+
+func isURITemplate(s string) bool {
+	depth := 0
+	for _, b := range []byte(s) {
+		switch b {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		}
+		if depth < 0 || 1 < depth {
+			return false
+		}
+	}
+	return depth == 0
+}
+
+func isRegex(s string) bool {
+	_, err := regexp.Compile(s)
+	return err == nil
+}
+
+func isJSONPointer(s string) bool {
+	if len(s) == 0 {
+		return true
+	}
+	if s[0] != '/' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] == '~' && (len(s) <= i+1 || (s[i+1] != '0' && s[i+1] != '1')) {
+			return false
+		}
+	}
+	return true
+}
+
+func isRelativeJSONPointer(s string) bool {
+	if !relPtrRx.MatchString(s) {
+		return false
+	}
+	if i := strings.IndexByte(s, '/'); 0 < i {
+		return isJSONPointer(s[i:])
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/ohler55/ojg/schema"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestSchemaFormats(t *testing.T) {
+	for i, d := range []struct {
+		format string
+		value  string
+		valid  bool
+	}{
+		{format: "date-time", value: "2023-03-07T12:34:56Z", valid: true},
+		{format: "date-time", value: "2023-03-07T12:34:56+01:00", valid: true},
+		{format: "date-time", value: "2023-03-07T12:34:56", valid: false},
+		{format: "date", value: "2023-02-28", valid: true},
+		{format: "date", value: "2023-02-30", valid: false},
+		{format: "time", value: "12:34:56.5-05:00", valid: true},
+		{format: "time", value: "12:34", valid: false},
+		{format: "duration", value: "P1Y2M3DT4H5M6S", valid: true},
+		{format: "duration", value: "P2W", valid: true},
+		{format: "duration", value: "PT", valid: false},
+		{format: "duration", value: "P1H", valid: false},
+		{format: "email", value: "pete@example.com", valid: true},
+		{format: "email", value: "Pete <pete@example.com>", valid: false},
+		{format: "hostname", value: "www.example.com", valid: true},
+		{format: "hostname", value: "-bad.example.com", valid: false},
+		{format: "ipv4", value: "192.168.0.1", valid: true},
+		{format: "ipv4", value: "192.168.0.01", valid: false},
+		{format: "ipv6", value: "::ffff:192.168.0.1", valid: true},
+		{format: "ipv6", value: "1.2.3.4", valid: false},
+		{format: "uri", value: "http://example.com/a?b=c#d", valid: true},
+		{format: "uri", value: "/a/b", valid: false},
+		{format: "uri-reference", value: "/a/b", valid: true},
+		{format: "uri-template", value: "http://example.com/{id}", valid: true},
+		{format: "uri-template", value: "http://example.com/{id", valid: false},
+		{format: "uuid", value: "2eb8aa08-aa98-11ea-b4aa-73b441d16380", valid: true},
+		{format: "uuid", value: "2eb8aa08-aa98-11ea-b4aa-73b441d1638", valid: false},
+		{format: "regex", value: "^[a-z]+$", valid: true},
+		{format: "regex", value: "^[a-z+$", valid: false},
+		{format: "json-pointer", value: "/a/~0b/~1c", valid: true},
+		{format: "json-pointer", value: "/a~2", valid: false},
+		{format: "relative-json-pointer", value: "1/a", valid: true},
+		{format: "relative-json-pointer", value: "0#", valid: true},
+		{format: "relative-json-pointer", value: "01", valid: false},
+		{format: "unknown", value: "anything", valid: true},
+	} {
+		s := schema.MustCompile(map[string]any{"format": d.format})
+		tt.Equal(t, d.valid, s.Valid(d.value, schema.AssertFormat), i, ": ", d.format, " ", d.value)
+	}
+}
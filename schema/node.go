@@ -0,0 +1,129 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package schema
+
+import (
+	"fmt"
+
+	"github.com/ohler55/ojg/jp"
+)
+
+// keyword is implemented by each compiled keyword or group of keywords
+// that are evaluated together such as properties and
+// additionalProperties.
+type keyword interface {
+	validate(v *validator, value any, r *result)
+}
+
+// node is a compiled schema object or boolean schema.
+type node struct {
+	loc      jp.Expr
+	res      *resource
+	dynamic  string // $dynamicAnchor name if present
+	keywords []keyword
+}
+
+// resource is a schema resource, the root of a document or a schema with an
+// $id.
+type resource struct {
+	uri     string
+	doc     any
+	loc     jp.Expr
+	root    *node
+	dynamic map[string]*node
+}
+
+// result of evaluating a schema against a value. Along with the errors are
+// the annotations needed by unevaluatedProperties and unevaluatedItems.
+type result struct {
+	errs     []*Error
+	props    map[string]bool
+	items    int
+	allItems bool
+	itemSet  map[int]bool
+}
+
+type validator struct {
+	path         jp.Expr
+	scope        []*resource
+	assertFormat bool
+}
+
+func (n *node) eval(v *validator, value any) (r result) {
+	if n.res.root == n {
+		v.scope = append(v.scope, n.res)
+		defer func() { v.scope = v.scope[:len(v.scope)-1] }()
+	}
+	for _, k := range n.keywords {
+		k.validate(v, value, &r)
+	}
+	if 0 < len(r.errs) {
+		// Annotations from a failed schema are dropped.
+		r.props = nil
+		r.items = 0
+		r.allItems = false
+		r.itemSet = nil
+	}
+	return
+}
+
+// at returns the location of a keyword in the node. The capacity is limited
+// so that appending to the returned location always makes a copy.
+func (n *node) at(key string) jp.Expr {
+	loc := append(append(jp.Expr{}, n.loc...), jp.Child(key))
+	return loc[:len(loc):len(loc)]
+}
+
+func (v *validator) evalChild(n *node, key any, value any) result {
+	switch tk := key.(type) {
+	case string:
+		v.path = append(v.path, jp.Child(tk))
+	case int:
+		v.path = append(v.path, jp.Nth(tk))
+	}
+	r := n.eval(v, value)
+	v.path = v.path[:len(v.path)-1]
+
+	return r
+}
+
+func (v *validator) fail(r *result, loc jp.Expr, format string, args ...any) {
+	r.errs = append(r.errs, &Error{
+		Instance: append(jp.Expr{}, v.path...),
+		Schema:   append(jp.Expr{}, loc...),
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// merge the errors and annotations of a sub-schema evaluation.
+func (r *result) merge(r2 *result) {
+	r.errs = append(r.errs, r2.errs...)
+	r.mergeAnnotations(r2)
+}
+
+func (r *result) mergeAnnotations(r2 *result) {
+	for k := range r2.props {
+		r.evalProp(k)
+	}
+	if r.items < r2.items {
+		r.items = r2.items
+	}
+	r.allItems = r.allItems || r2.allItems
+	for i := range r2.itemSet {
+		r.evalItem(i)
+	}
+}
+
+func (r *result) evalProp(key string) {
+	if r.props == nil {
+		r.props = map[string]bool{}
+	}
+	r.props[key] = true
+}
+
+func (r *result) evalItem(i int) {
+	if r.itemSet == nil {
+		r.itemSet = map[int]bool{}
+	}
+	r.itemSet[i] = true
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/jp"
+)
+
+// Schema is a compiled JSON Schema that can be used to validate data.
+type Schema struct {
+	root *node
+}
+
+// Error describes a validation failure.
+type Error struct {
+	// Instance is the location of the failing value in the validated data.
+	Instance jp.Expr
+
+	// Schema is the location of the failing keyword in the schema
+	// document. If the keyword was reached by a $ref to another document
+	// the location is relative to the root of that document.
+	Schema jp.Expr
+
+	// Message describes the failure.
+	Message string
+}
+
+// Error returns a string representation of the error.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s (schema %s)", e.Instance, e.Message, e.Schema)
+}
+
+// Option is a validation option for Validate and Valid.
+type Option byte
+
+const (
+	// AssertFormat makes the format keyword an assertion. Without it the
+	// format keyword is an annotation only as described by the
+	// specification.
+	AssertFormat = Option('f')
+)
+
+// Compile a JSON Schema document with the default compiler options.
+func Compile(doc any) (*Schema, error) {
+	return (&Compiler{}).Compile(doc)
+}
+
+// MustCompile a JSON Schema document with the default compiler options
+// and panic on error.
+func MustCompile(doc any) *Schema {
+	s, err := (&Compiler{}).Compile(doc)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Validate data against the schema and return all the failures or nil if
+// the data is valid. The data can be simple data or a gen.Node.
+func (s *Schema) Validate(data any, opts ...Option) []*Error {
+	v := validator{path: jp.R()}
+	for _, o := range opts {
+		if o == AssertFormat {
+			v.assertFormat = true
+		}
+	}
+	return s.root.eval(&v, plain(data)).errs
+}
+
+// Valid returns true if the data is valid according to the schema.
+func (s *Schema) Valid(data any, opts ...Option) bool {
+	return len(s.Validate(data, opts...)) == 0
+}
+
+// plain converts gen.Node data to simple data while preserving big numbers
+// as a json.Number so they are still treated as numbers.
+func plain(v any) any {
+	switch tv := v.(type) {
+	case gen.Object:
+		obj := make(map[string]any, len(tv))
+		for k, m := range tv {
+			obj[k] = plain(m)
+		}
+		return obj
+	case gen.Array:
+		list := make([]any, len(tv))
+		for i, m := range tv {
+			list[i] = plain(m)
+		}
+		return list
+	case gen.Big:
+		return json.Number(tv)
+	case gen.Node:
+		return tv.Simplify()
+	}
+	return v
+}
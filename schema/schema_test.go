@@ -0,0 +1,227 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/schema"
+	"github.com/ohler55/ojg/sen"
+	"github.com/ohler55/ojg/tt"
+)
+
+type schemaData struct {
+	schema string
+	data   string
+	errs   []string // empty if valid
+}
+
+var schemaTestData = []*schemaData{
+	{schema: `true`, data: `[1]`},
+	{schema: `false`, data: `1`, errs: []string{"$ is not allowed (schema $)"}},
+	{schema: `{type: integer}`, data: `1.0`},
+	{schema: `{type: integer}`, data: `1.5`, errs: []string{"$ must be of type integer, not number (schema $.type)"}},
+	{schema: `{type: [string "null"]}`, data: `true`, errs: []string{"$ must be one of the types string, null, not boolean (schema $.type)"}},
+	{schema: `{type: number}`, data: `12345678901234567890123`},
+	{schema: `{enum: [1 "a" {b: [2]}]}`, data: `{"b":[2.0]}`},
+	{schema: `{enum: [1 "a"]}`, data: `2`, errs: []string{`$ must be one of [1,"a"] (schema $.enum)`}},
+	{schema: `{const: null}`, data: `0`, errs: []string{"$ must be null (schema $.const)"}},
+	{schema: `{multipleOf: 0.1}`, data: `0.3`},
+	{schema: `{multipleOf: 2}`, data: `7`, errs: []string{"$ must be a multiple of 2 (schema $.multipleOf)"}},
+	{schema: `{maximum: 3 exclusiveMinimum: 1}`, data: `[1 2 3 4 "x"]`},
+	{schema: `{items: {maximum: 3 exclusiveMinimum: 1}}`, data: `[1 2 3 4]`, errs: []string{
+		"$[0] must be greater than 1 (schema $.items.exclusiveMinimum)",
+		"$[3] must be less than or equal to 3 (schema $.items.maximum)",
+	}},
+	{schema: `{minLength: 2 maxLength: 3}`, data: `"日本"`},
+	{schema: `{minLength: 2}`, data: `"日"`, errs: []string{"$ must have at least 2 characters (schema $.minLength)"}},
+	{schema: `{pattern: "^a+$"}`, data: `"aab"`, errs: []string{"$ must match the pattern ^a+$ (schema $.pattern)"}},
+	{schema: `{minItems: 1 maxItems: 2 uniqueItems: true}`, data: `[1 1.0]`, errs: []string{
+		"$ must have unique items, items 0 and 1 are equal (schema $.uniqueItems)",
+	}},
+	{schema: `{maxProperties: 1}`, data: `{a:1 b:2}`, errs: []string{"$ must have at most 1 members (schema $.maxProperties)"}},
+	{schema: `{required: [a b]}`, data: `{a:1}`, errs: []string{`$ is missing the required member "b" (schema $.required)`}},
+	{schema: `{dependentRequired: {a: [b]}}`, data: `{a:1}`, errs: []string{
+		`$ is missing the member "b" required by "a" (schema $.dependentRequired.a)`,
+	}},
+	{schema: `{dependentRequired: {a: [b]}}`, data: `{c:1}`},
+	{
+		schema: `{properties: {a: {type: string}} patternProperties: {"^x": {type: integer}} additionalProperties: false}`,
+		data:   `{a: 1 x1: 2 x2: y z: 3}`,
+		errs: []string{
+			"$.a must be of type string, not integer (schema $.properties.a.type)",
+			"$.x2 must be of type integer, not string (schema $.patternProperties.^x.type)",
+			"$.z is not allowed (schema $.additionalProperties)",
+		},
+	},
+	{schema: `{propertyNames: {maxLength: 2}}`, data: `{abc: 1}`, errs: []string{"$.abc must have at most 2 characters (schema $.propertyNames.maxLength)"}},
+	{schema: `{prefixItems: [{type: string}] items: {type: integer}}`, data: `[a 1 b]`, errs: []string{
+		"$[2] must be of type integer, not string (schema $.items.type)",
+	}},
+	{schema: `{contains: {type: string}}`, data: `[1 2]`, errs: []string{"$ must contain at least one matching item (schema $.contains)"}},
+	{schema: `{contains: {type: string} minContains: 2 maxContains: 3}`, data: `[a b 1]`},
+	{schema: `{contains: {type: string} maxContains: 1}`, data: `[a b 1]`, errs: []string{
+		"$ must contain at most 1 matching items, found 2 (schema $.contains)",
+	}},
+	{schema: `{contains: {type: string} minContains: 0}`, data: `[]`},
+	{schema: `{allOf: [{minimum: 1} {maximum: 2}]}`, data: `3`, errs: []string{"$ must be less than or equal to 2 (schema $.allOf[1].maximum)"}},
+	{schema: `{anyOf: [{type: string} {type: integer}]}`, data: `1.5`, errs: []string{"$ must match at least one schema (schema $.anyOf)"}},
+	{schema: `{oneOf: [{type: number} {type: integer}]}`, data: `1.5`},
+	{schema: `{oneOf: [{type: number} {type: integer}]}`, data: `1`, errs: []string{"$ must match exactly one schema but matched [0 1] (schema $.oneOf)"}},
+	{schema: `{oneOf: [{type: string} {type: integer}]}`, data: `1.5`, errs: []string{"$ must match exactly one schema but matched none (schema $.oneOf)"}},
+	{schema: `{not: {type: string}}`, data: `a`, errs: []string{"$ must not match the schema (schema $.not)"}},
+	{schema: `{if: {type: string} then: {minLength: 2} else: {minimum: 5}}`, data: `[a 3 ab 7]`},
+	{schema: `{items: {if: {type: string} then: {minLength: 2} else: {minimum: 5}}}`, data: `[a 3 ab 7]`, errs: []string{
+		"$[0] must have at least 2 characters (schema $.items.then.minLength)",
+		"$[1] must be greater than or equal to 5 (schema $.items.else.minimum)",
+	}},
+	{schema: `{dependentSchemas: {a: {required: [b]}}}`, data: `{a: 1}`, errs: []string{
+		`$ is missing the required member "b" (schema $.dependentSchemas.a.required)`,
+	}},
+
+	// references
+	{schema: `{"$defs": {pos: {minimum: 0}} properties: {a: {"$ref": "#/$defs/pos"}}}`, data: `{a: -1}`, errs: []string{
+		"$.a must be greater than or equal to 0 (schema $['$defs'].pos.minimum)",
+	}},
+	{schema: `{"$defs": {pos: {"$anchor": p minimum: 0}} items: {"$ref": "#p"}}`, data: `[1 -1]`, errs: []string{
+		"$[1] must be greater than or equal to 0 (schema $['$defs'].pos.minimum)",
+	}},
+	{schema: `{properties: {a: {type: integer}} items: {"$ref": "#/properties/a"}}`, data: `[1 x]`, errs: []string{
+		"$[1] must be of type integer, not string (schema $.properties.a.type)",
+	}},
+	{schema: `{type: [object integer] additionalProperties: {"$ref": "#"}}`, data: `{a: {b: {c: x}}}`, errs: []string{
+		"$.a.b.c must be one of the types object, integer, not string (schema $.type)",
+	}},
+	{
+		schema: `{
+  "$id": "http://example.com/root.json"
+  items: {"$ref": "item.json"}
+  "$defs": {item: {"$id": "item.json" "$ref": "#/$defs/x" "$defs": {x: {type: string}}}}
+}`,
+		data: `[a 1]`,
+		errs: []string{"$[1] must be of type string, not integer (schema $['$defs'].item['$defs'].x.type)"},
+	},
+	{
+		// A tree with dynamic extension of the node schema.
+		schema: `{
+  "$id": "https://example.com/strict-tree"
+  "$dynamicAnchor": node
+  "$ref": "tree"
+  unevaluatedProperties: false
+  "$defs": {
+    tree: {
+      "$id": "tree"
+      "$dynamicAnchor": node
+      type: object
+      properties: {data: true children: {type: array items: {"$dynamicRef": "#node"}}}
+    }
+  }
+}`,
+		data: `{children: [{daat: 1}]}`,
+		errs: []string{
+			"$.children[0].daat is not allowed (schema $.unevaluatedProperties)",
+			// Annotations from the failed $ref are dropped so children is
+			// also unevaluated.
+			"$.children is not allowed (schema $.unevaluatedProperties)",
+		},
+	},
+
+	// unevaluated
+	{schema: `{allOf: [{properties: {a: true}}] unevaluatedProperties: false}`, data: `{a: 1 b: 2}`, errs: []string{
+		"$.b is not allowed (schema $.unevaluatedProperties)",
+	}},
+	{schema: `{anyOf: [{properties: {a: true} required: [a]} {properties: {b: true} required: [b]}] unevaluatedProperties: false}`, data: `{a: 1 b: 2}`},
+	{schema: `{anyOf: [{properties: {a: true} required: [a]} {properties: {b: true} required: [b]}] unevaluatedProperties: false}`, data: `{a: 1 c: 2}`, errs: []string{
+		"$.c is not allowed (schema $.unevaluatedProperties)",
+	}},
+	{schema: `{not: {not: {properties: {a: true}}} unevaluatedProperties: false}`, data: `{a: 1}`, errs: []string{
+		"$.a is not allowed (schema $.unevaluatedProperties)",
+	}},
+	{schema: `{if: {properties: {a: {const: 1}}} then: {properties: {b: true}} unevaluatedProperties: false}`, data: `{a: 1 b: 2}`},
+	{schema: `{prefixItems: [true] contains: {type: string} unevaluatedItems: {type: integer}}`, data: `[x y 1 2.5]`, errs: []string{
+		"$[3] must be of type integer, not number (schema $.unevaluatedItems.type)",
+	}},
+	{schema: `{allOf: [{items: true}] unevaluatedItems: false}`, data: `[1 2]`},
+	{schema: `{unevaluatedItems: false}`, data: `[1]`, errs: []string{"$[0] is not allowed (schema $.unevaluatedItems)"}},
+
+	// formats
+	{schema: `{format: date-time}`, data: `"2023-03-07T12:34:56.789z"`},
+	{schema: `{format: date-time}`, data: `"2023-03-07 12:34:56"`, errs: []string{"$ must be a valid date-time (schema $.format)"}},
+	{schema: `{format: email}`, data: `1`},
+	{schema: `{items: {format: ipv4}}`, data: `["1.2.3.4" "1.2.3" "::1"]`, errs: []string{
+		"$[1] must be a valid ipv4 (schema $.items.format)",
+		"$[2] must be a valid ipv4 (schema $.items.format)",
+	}},
+}
+
+func TestSchemaValidate(t *testing.T) {
+	for i, sd := range schemaTestData {
+		s, err := schema.Compile(sen.MustParse([]byte(sd.schema)))
+		tt.Nil(t, err, i, ": ", sd.schema)
+		var errs []string
+		for _, e := range s.Validate(sen.MustParse([]byte(sd.data)), schema.AssertFormat) {
+			errs = append(errs, e.Error())
+		}
+		tt.Equal(t, sd.errs, errs, i, ": ", sd.schema, " ", sd.data)
+		tt.Equal(t, len(sd.errs) == 0, s.Valid(sen.MustParse([]byte(sd.data)), schema.AssertFormat), i, ": ", sd.schema, " ", sd.data)
+	}
+}
+
+func TestSchemaGen(t *testing.T) {
+	s := schema.MustCompile(alt.Generify(oj.MustParseString(`{"type":"object","properties":{"a":{"type":"number"}}}`)))
+	tt.Equal(t, true, s.Valid(alt.Generify(oj.MustParseString(`{"a":12345678901234567890123}`))))
+	errs := s.Validate(alt.Generify(oj.MustParseString(`{"a":"x"}`)))
+	tt.Equal(t, 1, len(errs))
+	tt.Equal(t, "$.a", errs[0].Instance.String())
+	tt.Equal(t, "$.properties.a.type", errs[0].Schema.String())
+}
+
+func TestSchemaResource(t *testing.T) {
+	var c schema.Compiler
+	c.AddResource("http://example.com/pos.json", oj.MustParseString(`{"minimum":0}`))
+	s, err := c.Compile(oj.MustParseString(`{"$id":"http://example.com/root.json","items":{"$ref":"pos.json"}}`))
+	tt.Nil(t, err)
+	errs := s.Validate([]any{1, -1})
+	tt.Equal(t, 1, len(errs))
+	tt.Equal(t, "$[1] must be greater than or equal to 0 (schema $.minimum)", errs[0].Error())
+
+	_, err = schema.Compile(oj.MustParseString(`{"$ref":"other.json"}`))
+	tt.NotNil(t, err)
+	tt.Equal(t, "invalid schema at $['$ref'], can not resolve other.json#", err.Error())
+}
+
+func TestSchemaFormatAnnotation(t *testing.T) {
+	s := schema.MustCompile(oj.MustParseString(`{"format":"ipv4"}`))
+	tt.Equal(t, true, s.Valid("not an ip"))
+	tt.Equal(t, false, s.Valid("not an ip", schema.AssertFormat))
+	errs := s.Validate("not an ip", schema.AssertFormat)
+	tt.Equal(t, 1, len(errs))
+	tt.Equal(t, "$ must be a valid ipv4 (schema $.format)", errs[0].Error())
+
+	schema.RegisterFormat("upper", func(s string) bool { return s == strings.ToUpper(s) })
+	s = schema.MustCompile(oj.MustParseString(`{"format":"upper"}`))
+	tt.Equal(t, true, s.Valid("abc"))
+	tt.Equal(t, true, s.Valid("ABC", schema.AssertFormat))
+	tt.Equal(t, false, s.Valid("abc", schema.AssertFormat))
+}
+
+func TestSchemaCompileError(t *testing.T) {
+	for i, d := range [][2]string{
+		{`[]`, "invalid schema at $, a schema must be an object or a boolean, not a []interface {}"},
+		{`{type: thing}`, "invalid schema at $.type, thing is not a valid type"},
+		{`{minLength: -1}`, "invalid schema at $.minLength, expected a non-negative integer, not -1"},
+		{`{multipleOf: 0}`, "invalid schema at $.multipleOf, multipleOf must be greater than zero"},
+		{`{pattern: "("}`, "invalid schema at $.pattern, error parsing regexp: missing closing ): `(`"},
+		{`{allOf: []}`, "invalid schema at $.allOf, allOf must be a non-empty array"},
+		{`{properties: {a: 1}}`, "invalid schema at $.properties.a, a schema must be an object or a boolean, not a int64"},
+		{`{"$ref": "#/nope"}`, "invalid schema at $['$ref'], can not resolve #/nope"},
+	} {
+		_, err := schema.Compile(sen.MustParse([]byte(d[0])))
+		tt.NotNil(t, err, i, ": ", d[0])
+		tt.Equal(t, d[1], err.Error(), i, ": ", d[0])
+	}
+	tt.Panic(t, func() { schema.MustCompile("x") })
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package schema
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// jsonType returns the JSON type name of a value. Integers are reported as
+// integer even though they are also numbers.
+func jsonType(v any) string {
+	switch tv := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string, time.Time:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	case float32:
+		return floatType(float64(tv))
+	case float64:
+		return floatType(tv)
+	case json.Number:
+		if r, ok := new(big.Rat).SetString(string(tv)); ok && r.IsInt() {
+			return "integer"
+		}
+		return "number"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return "integer"
+	}
+	return "unknown"
+}
+
+func floatType(f float64) string {
+	if f == math.Trunc(f) && !math.IsInf(f, 0) {
+		return "integer"
+	}
+	return "number"
+}
+
+func isNumber(v any) bool {
+	switch jsonType(v) {
+	case "integer", "number":
+		return true
+	}
+	return false
+}
+
+func asString(v any) (string, bool) {
+	switch tv := v.(type) {
+	case string:
+		return tv, true
+	case time.Time:
+		return tv.Format(time.RFC3339Nano), true
+	}
+	return "", false
+}
+
+// asRat converts a number to a big.Rat. Floats are converted using their
+// shortest decimal representation so that 0.1 is 1/10 and not the binary
+// approximation.
+func asRat(v any) (r *big.Rat) {
+	r = new(big.Rat)
+	switch tv := v.(type) {
+	case int:
+		r.SetInt64(int64(tv))
+	case int8:
+		r.SetInt64(int64(tv))
+	case int16:
+		r.SetInt64(int64(tv))
+	case int32:
+		r.SetInt64(int64(tv))
+	case int64:
+		r.SetInt64(tv)
+	case uint:
+		r.SetUint64(uint64(tv))
+	case uint8:
+		r.SetUint64(uint64(tv))
+	case uint16:
+		r.SetUint64(uint64(tv))
+	case uint32:
+		r.SetUint64(uint64(tv))
+	case uint64:
+		r.SetUint64(tv)
+	case float32:
+		r.SetString(strconv.FormatFloat(float64(tv), 'g', -1, 32))
+	case float64:
+		if math.IsInf(tv, 0) || math.IsNaN(tv) {
+			return nil
+		}
+		r.SetString(strconv.FormatFloat(tv, 'g', -1, 64))
+	case json.Number:
+		if _, ok := r.SetString(string(tv)); !ok {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return
+}
+
+// compareNumbers returns -1, 0, or 1 depending on whether a is less than,
+// equal to, or greater than b.
+func compareNumbers(a, b any) int {
+	if ia, ok := asInt64(a); ok {
+		if ib, ok := asInt64(b); ok {
+			switch {
+			case ia < ib:
+				return -1
+			case ia > ib:
+				return 1
+			}
+			return 0
+		}
+	}
+	ra := asRat(a)
+	rb := asRat(b)
+	if ra == nil || rb == nil {
+		fa, _ := asFloat(a)
+		fb, _ := asFloat(b)
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+	return ra.Cmp(rb)
+}
+
+func asInt64(v any) (int64, bool) {
+	switch tv := v.(type) {
+	case int:
+		return int64(tv), true
+	case int8:
+		return int64(tv), true
+	case int16:
+		return int64(tv), true
+	case int32:
+		return int64(tv), true
+	case int64:
+		return tv, true
+	case uint8:
+		return int64(tv), true
+	case uint16:
+		return int64(tv), true
+	case uint32:
+		return int64(tv), true
+	}
+	return 0, false
+}
+
+func asFloat(v any) (float64, bool) {
+	if i, ok := asInt64(v); ok {
+		return float64(i), true
+	}
+	switch tv := v.(type) {
+	case uint:
+		return float64(tv), true
+	case uint64:
+		return float64(tv), true
+	case float32:
+		return float64(tv), true
+	case float64:
+		return tv, true
+	case json.Number:
+		f, err := tv.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// equal compares two values using JSON equality rules where numbers with
+// the same value are equal regardless of type.
+func equal(a, b any) bool {
+	switch ta := a.(type) {
+	case nil:
+		return b == nil
+	case bool:
+		tb, ok := b.(bool)
+		return ok && ta == tb
+	case []any:
+		tb, ok := b.([]any)
+		if !ok || len(ta) != len(tb) {
+			return false
+		}
+		for i, v := range ta {
+			if !equal(v, tb[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		tb, ok := b.(map[string]any)
+		if !ok || len(ta) != len(tb) {
+			return false
+		}
+		for k, v := range ta {
+			if bv, has := tb[k]; !has || !equal(v, bv) {
+				return false
+			}
+		}
+		return true
+	}
+	if sa, ok := asString(a); ok {
+		sb, ok := asString(b)
+		return ok && sa == sb
+	}
+	if isNumber(a) && isNumber(b) {
+		return compareNumbers(a, b) == 0
+	}
+	return false
+}
@@ -3,12 +3,16 @@
 package sen
 
 import (
+	"encoding/json"
+	"math/big"
+	"reflect"
 	"sort"
 	"strconv"
 	"time"
 
 	"github.com/ohler55/ojg"
 	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/gen"
 )
 
 func (wr *Writer) colorSEN(data any, depth int) {
@@ -62,6 +66,15 @@ func (wr *Writer) colorSEN(data any, depth int) {
 	case float64:
 		wr.buf = append(wr.buf, wr.NumberColor...)
 		wr.buf = strconv.AppendFloat(wr.buf, td, 'g', -1, 64)
+	case json.Number, *big.Int, *big.Float:
+		wr.buf = append(wr.buf, wr.NumberColor...)
+		var err error
+		if wr.buf, err = ojg.AppendNumber(wr.buf, td); err != nil {
+			panic(err)
+		}
+	case gen.Big:
+		wr.buf = append(wr.buf, wr.NumberColor...)
+		wr.buf = append(wr.buf, td...)
 
 	case string:
 		wr.buf = append(wr.buf, wr.StringColor...)
@@ -72,10 +85,22 @@ func (wr *Writer) colorSEN(data any, depth int) {
 		wr.buf = wr.AppendTime(wr.buf, td, true)
 
 	case []any:
-		wr.colorArray(td, depth)
+		if wr.cycles == nil || !wr.cycled(reflect.ValueOf(td), depth) {
+			wr.colorArray(td, depth)
+			wr.cycles.Exit()
+		}
 
 	case map[string]any:
-		wr.colorObject(td, depth)
+		if wr.cycles == nil || !wr.cycled(reflect.ValueOf(td), depth) {
+			wr.colorObject(td, depth)
+			wr.cycles.Exit()
+		}
+
+	case *gen.Ordered:
+		if wr.cycles == nil || !wr.cycled(reflect.ValueOf(td), depth) {
+			wr.colorOrdered(td, depth)
+			wr.cycles.Exit()
+		}
 
 	default:
 		if simp, _ := data.(alt.Simplifier); simp != nil {
@@ -89,10 +114,10 @@ func (wr *Writer) colorSEN(data any, depth int) {
 		}
 		if 0 < len(wr.CreateKey) {
 			ao := alt.Options{CreateKey: wr.CreateKey, OmitNil: wr.OmitNil, FullTypePath: wr.FullTypePath}
-			wr.colorSEN(alt.Decompose(data, &ao), depth)
+			wr.colorSEN(wr.cycles.Decompose(data, &ao), depth)
 			return
 		}
-		wr.colorSEN(alt.Decompose(data, &alt.Options{OmitNil: wr.OmitNil}), depth)
+		wr.colorSEN(wr.cycles.Decompose(data, &alt.Options{OmitNil: wr.OmitNil}), depth)
 	}
 	wr.buf = append(wr.buf, wr.NoColor...)
 
@@ -140,7 +165,9 @@ func (wr *Writer) colorArray(n []any, depth int) {
 			wr.buf = append(wr.buf, ' ')
 		}
 		wr.buf = append(wr.buf, []byte(cs)...)
+		wr.cycles.Index(j)
 		wr.colorSEN(m, d2)
+		wr.cycles.Pop()
 	}
 	wr.buf = append(wr.buf, []byte(is)...)
 	wr.buf = append(wr.buf, wr.SyntaxColor...)
@@ -220,7 +247,9 @@ func (wr *Writer) colorObject(n map[string]any, depth int) {
 			if 0 < wr.Indent {
 				wr.buf = append(wr.buf, ' ')
 			}
+			wr.cycles.Key(k)
 			wr.colorSEN(m, d2)
+			wr.cycles.Pop()
 		}
 	} else {
 		for k, m := range n {
@@ -257,8 +286,95 @@ func (wr *Writer) colorObject(n map[string]any, depth int) {
 			if 0 < wr.Indent {
 				wr.buf = append(wr.buf, ' ')
 			}
+			wr.cycles.Key(k)
 			wr.colorSEN(m, d2)
+			wr.cycles.Pop()
+		}
+	}
+	wr.buf = append(wr.buf, []byte(is)...)
+	wr.buf = append(wr.buf, wr.SyntaxColor...)
+	wr.buf = append(wr.buf, '}')
+}
+
+func (wr *Writer) colorOrdered(n *gen.Ordered, depth int) {
+	wr.buf = append(wr.buf, wr.SyntaxColor...)
+	wr.buf = append(wr.buf, '{')
+	wr.buf = append(wr.buf, wr.NoColor...)
+
+	d2 := depth + 1
+	var is string
+	var cs string
+	if wr.Tab {
+		x := depth + 1
+		if len(tabs) < x {
+			x = len(tabs)
+		}
+		is = tabs[0:x]
+		x = d2 + 1
+		if len(tabs) < x {
+			x = len(tabs)
+		}
+		cs = tabs[0:x]
+	} else if 0 < wr.Indent {
+		x := depth*wr.Indent + 1
+		if len(spaces) < x {
+			x = len(spaces)
+		}
+		is = spaces[0:x]
+		x = d2*wr.Indent + 1
+		if len(spaces) < x {
+			x = len(spaces)
+		}
+		cs = spaces[0:x]
+	}
+	members := n.Members
+	if wr.Sort {
+		members = n.Sorted()
+	}
+	first := true
+	for _, m := range members {
+		switch tm := m.Value.(type) {
+		case nil:
+			if wr.OmitNil {
+				continue
+			}
+		case string:
+			if wr.OmitEmpty && len(tm) == 0 {
+				continue
+			}
+		case map[string]any:
+			if wr.OmitEmpty && len(tm) == 0 {
+				continue
+			}
+		case []any:
+			if wr.OmitEmpty && len(tm) == 0 {
+				continue
+			}
+		case *gen.Ordered:
+			if wr.OmitEmpty && tm.Len() == 0 {
+				continue
+			}
+		}
+		if first {
+			first = false
+		} else {
+			if len(cs) == 0 {
+				wr.buf = append(wr.buf, ' ')
+			}
+		}
+		wr.buf = append(wr.buf, []byte(cs)...)
+		wr.buf = append(wr.buf, wr.KeyColor...)
+		wr.buf = ojg.AppendSENString(wr.buf, m.Key, !wr.HTMLUnsafe)
+		wr.buf = append(wr.buf, wr.NoColor...)
+		wr.buf = append(wr.buf, wr.SyntaxColor...)
+		wr.buf = append(wr.buf, ':')
+		wr.buf = append(wr.buf, wr.NoColor...)
+		if 0 < wr.Indent {
+			wr.buf = append(wr.buf, ' ')
 		}
+		wr.cycles.Key(m.Key)
+		wr.colorSEN(m.Value, d2)
+		wr.cycles.Pop()
 	}
 	wr.buf = append(wr.buf, []byte(is)...)
 	wr.buf = append(wr.buf, wr.SyntaxColor...)
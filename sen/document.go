@@ -0,0 +1,403 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package sen
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/oj"
+)
+
+// Document is a SEN or JSON document that can be edited without losing
+// comments, member order, white space, or the formatting of values that are
+// not changed. Edits replace only the text of the values that are set or
+// removed so writing the document reproduces everything else byte for
+// byte. The jp.Expr SetDocument() and RemoveDocument() functions edit a
+// Document using a JSONPath.
+type Document struct {
+	// JSON if true writes values that are set as JSON so that JSON and JSONC
+	// documents remain JSON. If false values are written as SEN.
+	JSON bool
+
+	// Options used for writing values that are set. Values that span more
+	// than one line are indented to line up with the line they are placed
+	// on.
+	Options ojg.Options
+
+	buf   []byte
+	data  any
+	spans ojg.Spans
+}
+
+type docEdit struct {
+	start int
+	end   int
+	text  string
+}
+
+// ParseDocument parses a SEN or JSON document, including JSONC documents
+// with // and /* */ comments, into a Document.
+func ParseDocument(buf []byte) (*Document, error) {
+	d := Document{Options: DefaultOptions}
+	if err := d.load(append([]byte{}, buf...)); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// Data returns the value of the document. It is replaced after each edit
+// and must not be modified directly.
+func (d *Document) Data() any {
+	return d.data
+}
+
+// Bytes returns the text of the document. The returned slice must not be
+// modified.
+func (d *Document) Bytes() []byte {
+	return d.buf
+}
+
+// String returns the text of the document.
+func (d *Document) String() string {
+	return string(d.buf)
+}
+
+// Write the text of the document to a writer.
+func (d *Document) Write(w io.Writer) (err error) {
+	_, err = w.Write(d.buf)
+	return
+}
+
+// Spans returns the spans of the values in the document.
+func (d *Document) Spans() *ojg.Spans {
+	return &d.spans
+}
+
+// Set the value at the path. If the value exists its text is replaced. If
+// not then a member is added to the object or an element appended to the
+// array that would hold it, creating objects and arrays as needed. Only an
+// index equal to the length of an array can be used to append to an array.
+func (d *Document) Set(path alt.Path, value any) error {
+	if span := d.spans.Get(path...); span != nil {
+		return d.edit(docEdit{
+			start: span.Start.Offset,
+			end:   span.End.Offset,
+			text:  d.encode(value, d.indent(span.Start.Offset)),
+		})
+	}
+	i := len(path) - 1
+	for ; 0 <= i && d.spans.Get(path[:i]...) == nil; i-- {
+	}
+	for j := len(path) - 1; i < j; j-- {
+		switch tk := path[j].(type) {
+		case string:
+			value = map[string]any{tk: value}
+		case int:
+			if tk != 0 {
+				return fmt.Errorf("can not set %v, index %d is out of range", path, tk)
+			}
+			value = []any{value}
+		default:
+			return fmt.Errorf("can not set %v, a %T is not a valid path element", path, tk)
+		}
+	}
+	if i < 0 {
+		return d.setRoot(value)
+	}
+	return d.insert(path[:i], path[i], value)
+}
+
+// Remove the value at the path along with its key if it is an object member
+// and any comment on the same line if the value is on a line by itself.
+// Nothing is changed if there is no value at the path.
+func (d *Document) Remove(path alt.Path) error {
+	span := d.spans.Get(path...)
+	if span == nil {
+		return nil
+	}
+	if len(path) == 0 {
+		return d.edit(docEdit{start: span.Start.Offset, end: span.End.Offset})
+	}
+	start := span.Start.Offset
+	if 0 < span.KeyStart.Line {
+		start = span.KeyStart.Offset
+	}
+	end := span.End.Offset
+	var prev, next *ojg.Span
+	siblings := d.children(path[:len(path)-1])
+	for k, s := range siblings {
+		if s == span {
+			if 0 < k {
+				prev = siblings[k-1]
+			}
+			if k < len(siblings)-1 {
+				next = siblings[k+1]
+			}
+			break
+		}
+	}
+	after, comma := d.skipSep(end)
+	var edits []docEdit
+	if ls := d.lineStart(start); len(strings.Trim(string(d.buf[ls:start]), " \t")) == 0 && (len(d.buf) <= after || d.buf[after] == '\n') {
+		if after < len(d.buf) {
+			after++
+		}
+		edits = append(edits, docEdit{start: ls, end: after})
+		if next == nil && prev != nil && !comma {
+			if c := d.findComma(prev.End.Offset, ls); 0 <= c {
+				edits = append(edits, docEdit{start: c, end: c + 1})
+			}
+		}
+		return d.edit(edits...)
+	}
+	switch {
+	case next != nil:
+		end = next.Start.Offset
+		if 0 < next.KeyStart.Line {
+			end = next.KeyStart.Offset
+		}
+	case prev != nil:
+		start = prev.End.Offset
+	case comma:
+		end = d.findComma(end, len(d.buf)) + 1
+	}
+	return d.edit(docEdit{start: start, end: end})
+}
+
+func (d *Document) load(buf []byte) error {
+	p := Parser{Spans: &d.spans}
+	data, err := p.Parse(buf)
+	if err != nil {
+		return err
+	}
+	d.buf = buf
+	d.data = data
+	return nil
+}
+
+// edit applies the edits and reparses the document. If the result is not
+// valid the document is left unchanged.
+func (d *Document) edit(edits ...docEdit) error {
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	buf := append([]byte{}, d.buf...)
+	for _, e := range edits {
+		buf = append(buf[:e.start], append([]byte(e.text), buf[e.end:]...)...)
+	}
+	spans := d.spans
+	if err := d.load(buf); err != nil {
+		d.spans = spans
+		return err
+	}
+	return nil
+}
+
+func (d *Document) setRoot(value any) error {
+	text := d.encode(value, "")
+	if 0 < len(d.buf) && d.buf[len(d.buf)-1] != '\n' {
+		text = "\n" + text
+	}
+	return d.edit(docEdit{start: len(d.buf), end: len(d.buf), text: text + "\n"})
+}
+
+// insert a new member or element into the container at the path.
+func (d *Document) insert(path alt.Path, key any, value any) error {
+	parent := d.spans.Get(path...)
+	var member string
+	siblings := d.children(path)
+	switch d.buf[parent.Start.Offset] {
+	case '{':
+		k, ok := key.(string)
+		if !ok {
+			return fmt.Errorf("can not set %v in an object", key)
+		}
+		sep := ": "
+		if 0 < len(siblings) {
+			last := siblings[len(siblings)-1]
+			sep = string(d.buf[last.KeyEnd.Offset:last.Start.Offset])
+		}
+		if d.JSON {
+			member = oj.JSON(k)
+		} else {
+			member = String(k)
+		}
+		member += sep
+	case '[':
+		if k, ok := key.(int); !ok || k != len(siblings) {
+			return fmt.Errorf("can not set %v in an array of length %d", key, len(siblings))
+		}
+	default:
+		return fmt.Errorf("can not set %v in a %T", key, d.data)
+	}
+	if len(siblings) == 0 {
+		start := parent.Start.Offset + 1
+		if parent.Start.Line == parent.End.Line {
+			return d.edit(docEdit{start: start, end: start, text: member + d.encode(value, "")})
+		}
+		indent := d.indent(parent.Start.Offset) + d.indentUnit()
+		return d.edit(docEdit{start: start, end: start, text: "\n" + indent + member + d.encode(value, indent)})
+	}
+	last := siblings[len(siblings)-1]
+	lastStart := last.Start
+	if 0 < last.KeyStart.Line {
+		lastStart = last.KeyStart
+	}
+	comma := d.JSON
+	if 1 < len(siblings) {
+		comma = comma || 0 <= d.findComma(siblings[len(siblings)-2].End.Offset, lastStart.Offset)
+	}
+	end := last.End.Offset
+	after, trailing := d.skipSep(end)
+	if lastStart.Line == parent.Start.Line || len(d.buf) <= after || d.buf[after] != '\n' {
+		text := " " + member + d.encode(value, d.indent(lastStart.Offset))
+		if comma {
+			text = "," + text
+		}
+		return d.edit(docEdit{start: end, end: end, text: text})
+	}
+	indent := d.indent(lastStart.Offset)
+	edits := []docEdit{{start: after, end: after, text: "\n" + indent + member + d.encode(value, indent)}}
+	if comma && !trailing {
+		edits = append(edits, docEdit{start: end, end: end, text: ","})
+	}
+	return d.edit(edits...)
+}
+
+// children returns the spans of the members or elements of the container
+// at the path ordered by position.
+func (d *Document) children(path alt.Path) (spans []*ojg.Span) {
+	v := d.data
+	for _, key := range path {
+		switch tv := v.(type) {
+		case map[string]any:
+			v = tv[fmt.Sprint(key)]
+		case []any:
+			i, _ := key.(int)
+			v = tv[i]
+		}
+	}
+	child := append(append(alt.Path{}, path...), nil)
+	switch tv := v.(type) {
+	case map[string]any:
+		for k := range tv {
+			child[len(path)] = k
+			if s := d.spans.Get(child...); s != nil {
+				spans = append(spans, s)
+			}
+		}
+		sort.Slice(spans, func(i, j int) bool { return spans[i].Start.Offset < spans[j].Start.Offset })
+	case []any:
+		for i := range tv {
+			child[len(path)] = i
+			if s := d.spans.Get(child...); s != nil {
+				spans = append(spans, s)
+			}
+		}
+	}
+	return
+}
+
+func (d *Document) encode(value any, indent string) (text string) {
+	if d.JSON {
+		text = oj.JSON(value, &d.Options)
+	} else {
+		text = String(value, &d.Options)
+	}
+	if 0 < len(indent) {
+		text = strings.ReplaceAll(text, "\n", "\n"+indent)
+	}
+	return
+}
+
+// indent returns the leading white space of the line that includes off.
+func (d *Document) indent(off int) string {
+	start := d.lineStart(off)
+	end := start
+	for end < len(d.buf) && (d.buf[end] == ' ' || d.buf[end] == '\t') {
+		end++
+	}
+	return string(d.buf[start:end])
+}
+
+func (d *Document) indentUnit() string {
+	switch {
+	case d.Options.Tab:
+		return "\t"
+	case 0 < d.Options.Indent:
+		return strings.Repeat(" ", d.Options.Indent)
+	}
+	return "  "
+}
+
+func (d *Document) lineStart(off int) int {
+	for 0 < off && d.buf[off-1] != '\n' {
+		off--
+	}
+	return off
+}
+
+// skipSep skips spaces, a comma, and a comment on the same line after
+// off. The offset reached is returned along with an indication of whether
+// a comma was skipped.
+func (d *Document) skipSep(off int) (int, bool) {
+	comma := false
+	for off < len(d.buf) {
+		switch d.buf[off] {
+		case ' ', '\t', '\r':
+			off++
+		case ',':
+			if comma {
+				return off, comma
+			}
+			comma = true
+			off++
+		case '/':
+			if off+1 < len(d.buf) && d.buf[off+1] == '/' {
+				for off < len(d.buf) && d.buf[off] != '\n' {
+					off++
+				}
+				return off, comma
+			}
+			if off+1 < len(d.buf) && d.buf[off+1] == '*' {
+				end := strings.Index(string(d.buf[off+2:]), "*/")
+				if end < 0 || 0 <= strings.IndexByte(string(d.buf[off:off+2+end]), '\n') {
+					return off, comma
+				}
+				off += end + 4
+				continue
+			}
+			return off, comma
+		default:
+			return off, comma
+		}
+	}
+	return off, comma
+}
+
+// findComma returns the offset of the first comma outside of a comment
+// between start and end or -1 if there is none.
+func (d *Document) findComma(start, end int) int {
+	for off := start; off < end; off++ {
+		switch d.buf[off] {
+		case ',':
+			return off
+		case '/':
+			if off+1 < end && d.buf[off+1] == '/' {
+				for off < end && d.buf[off] != '\n' {
+					off++
+				}
+			} else if off+1 < end && d.buf[off+1] == '*' {
+				if i := strings.Index(string(d.buf[off+2:end]), "*/"); 0 <= i {
+					off += i + 3
+				} else {
+					return -1
+				}
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package sen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/sen"
+	"github.com/ohler55/ojg/tt"
+)
+
+const docSrc = `// Config file.
+{
+  // The name.
+  name: "sample" // trailing
+  size: 1.50e2
+  list: [1, 2, 3]
+  nested: {
+    a: true
+  }
+}
+`
+
+func TestDocumentRoundTrip(t *testing.T) {
+	doc, err := sen.ParseDocument([]byte(docSrc))
+	tt.Nil(t, err)
+	tt.Equal(t, docSrc, doc.String())
+	tt.Equal(t, 150.0, doc.Data().(map[string]any)["size"])
+
+	var b strings.Builder
+	tt.Nil(t, doc.Write(&b))
+	tt.Equal(t, docSrc, b.String())
+
+	_, err = sen.ParseDocument([]byte("{a: ]"))
+	tt.NotNil(t, err)
+}
+
+func TestDocumentSet(t *testing.T) {
+	doc, err := sen.ParseDocument([]byte(docSrc))
+	tt.Nil(t, err)
+
+	tt.Nil(t, doc.Set(alt.Path{"name"}, "other"))
+	tt.Nil(t, doc.Set(alt.Path{"list", 1}, 7))
+	tt.Nil(t, doc.Set(alt.Path{"list", 3}, 4))
+	tt.Nil(t, doc.Set(alt.Path{"nested", "b"}, false))
+	tt.Nil(t, doc.Set(alt.Path{"added", "x", 0}, "y"))
+	tt.Equal(t, `// Config file.
+{
+  // The name.
+  name: other // trailing
+  size: 1.50e2
+  list: [1, 7, 3, 4]
+  nested: {
+    a: true
+    b: false
+  }
+  added: {x:[y]}
+}
+`, doc.String())
+
+	tt.NotNil(t, doc.Set(alt.Path{"list", 7}, 4))
+	tt.NotNil(t, doc.Set(alt.Path{"list", "x"}, 4))
+	tt.NotNil(t, doc.Set(alt.Path{"nested", 0}, 4))
+	tt.NotNil(t, doc.Set(alt.Path{"new", 2}, 4))
+}
+
+func TestDocumentSetJSON(t *testing.T) {
+	src := `{
+  "a": 1, // one
+  "b": [
+  ],
+  "c": {}
+}`
+	doc, err := sen.ParseDocument([]byte(src))
+	tt.Nil(t, err)
+	doc.JSON = true
+	tt.Nil(t, doc.Set(alt.Path{"d"}, "x y"))
+	tt.Nil(t, doc.Set(alt.Path{"b", 0}, map[string]any{"e": nil}))
+	tt.Nil(t, doc.Set(alt.Path{"c", "f"}, 2))
+	tt.Equal(t, `{
+  "a": 1, // one
+  "b": [
+    {"e":null}
+  ],
+  "c": {"f": 2},
+  "d": "x y"
+}`, doc.String())
+
+	doc, err = sen.ParseDocument([]byte("// nothing yet"))
+	tt.Nil(t, err)
+	tt.Nil(t, doc.Set(alt.Path{}, []any{1}))
+	tt.Equal(t, "// nothing yet\n[1]\n", doc.String())
+
+	doc.Options.Indent = 2
+	doc, err = sen.ParseDocument([]byte("{\n  a: {\n    b: 1\n  }\n}"))
+	tt.Nil(t, err)
+	doc.Options.Indent = 2
+	tt.Nil(t, doc.Set(alt.Path{"a", "c"}, map[string]any{"d": 1}))
+	tt.Equal(t, "{\n  a: {\n    b: 1\n    c: {\n      d: 1\n    }\n  }\n}", doc.String())
+}
+
+func TestDocumentRemove(t *testing.T) {
+	doc, err := sen.ParseDocument([]byte(docSrc))
+	tt.Nil(t, err)
+	tt.Nil(t, doc.Remove(alt.Path{"name"}))
+	tt.Nil(t, doc.Remove(alt.Path{"list", 1}))
+	tt.Nil(t, doc.Remove(alt.Path{"list", 1}))
+	tt.Nil(t, doc.Remove(alt.Path{"nested", "a"}))
+	tt.Nil(t, doc.Remove(alt.Path{"missing"}))
+	tt.Equal(t, `// Config file.
+{
+  // The name.
+  size: 1.50e2
+  list: [1]
+  nested: {
+  }
+}
+`, doc.String())
+
+	src := `{
+  "a": 1,
+  "b": 2 // two
+}`
+	doc, err = sen.ParseDocument([]byte(src))
+	tt.Nil(t, err)
+	tt.Nil(t, doc.Remove(alt.Path{"b"}))
+	tt.Equal(t, "{\n  \"a\": 1\n}", doc.String())
+
+	doc, err = sen.ParseDocument([]byte(`{"a":1,"b":2,"c":3}`))
+	tt.Nil(t, err)
+	tt.Nil(t, doc.Remove(alt.Path{"b"}))
+	tt.Equal(t, `{"a":1,"c":3}`, doc.String())
+	tt.Nil(t, doc.Remove(alt.Path{"c"}))
+	tt.Equal(t, `{"a":1}`, doc.String())
+	tt.Nil(t, doc.Remove(alt.Path{"a"}))
+	tt.Equal(t, `{}`, doc.String())
+	tt.Nil(t, doc.Remove(alt.Path{}))
+	tt.Equal(t, ``, doc.String())
+}
@@ -0,0 +1,752 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package sen
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/oj"
+)
+
+// JSON5Parser is a parser for JSON5 as defined by https://spec.json5.org.
+// Unlike the SEN Parser it accepts only JSON5. That includes unquoted
+// identifier keys, single quoted strings with line continuations and \x
+// escapes, hexadecimal numbers, leading and trailing decimal points, an
+// explicit plus sign, Infinity, NaN, trailing commas, and comments. Anything
+// JSON5 forbids such as unquoted string values, missing commas, or leading
+// zeros is an error.
+type JSON5Parser struct {
+	// Ordered if true produces *gen.Ordered objects that keep members in the
+	// order they appear instead of maps.
+	Ordered bool
+
+	// NoDuplicates returns an error if an object has more than one member
+	// with the same key. Otherwise the last member wins.
+	NoDuplicates bool
+
+	// NumConv if not nil is called with the text of each decimal number and
+	// the value returned is used in place of the int64, float64, or
+	// json.Number the parser would otherwise produce. The text is normalized
+	// to JSON number form and hexadecimal numbers are passed as their
+	// decimal text. Infinity and NaN are always float64.
+	NumConv func(text string) any
+
+	// Limits are resource limits for parsing untrusted input. Exceeding a
+	// limit returns an *ojg.LimitError.
+	Limits ojg.Limits
+
+	buf   []byte
+	off   int
+	line  int
+	noff  int
+	depth int
+	tmp   []byte
+	num   gen.Number
+}
+
+// ParseJSON5 parses a JSON5 document into simple types. The optional
+// arguments are the same as for JSON5Parser.Parse.
+func ParseJSON5(buf []byte, args ...any) (any, error) {
+	var p JSON5Parser
+	return p.Parse(buf, args...)
+}
+
+// Parse a JSON5 document into simple types. An error is returned if not
+// valid JSON5. A JSON5 text is a single value but if a func(any) bool or
+// func(any) callback argument is provided then each of a sequence of values
+// separated by white space is passed to the callback.
+func (p *JSON5Parser) Parse(buf []byte, args ...any) (result any, err error) {
+	if err = p.Limits.CheckBytes(buf); err != nil {
+		return nil, err
+	}
+	var cb func(any) bool
+	for _, a := range args {
+		switch ta := a.(type) {
+		case func(any) bool:
+			cb = ta
+		case func(any):
+			cb = func(v any) bool { ta(v); return false }
+		default:
+			return nil, fmt.Errorf("a %T is not a valid option type", a)
+		}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			result = nil
+			if err, _ = r.(error); err == nil {
+				err = ojg.NewError(r)
+			}
+		}
+		p.buf = nil
+	}()
+	p.buf = buf
+	p.off = 0
+	p.line = 1
+	p.noff = -1
+	p.depth = 0
+	p.num.Conv = nil
+	// Skip BOM if present.
+	if 3 <= len(buf) && buf[0] == 0xEF && buf[1] == 0xBB && buf[2] == 0xBF {
+		p.off = 3
+	}
+	p.skipSpace()
+	if len(p.buf) <= p.off {
+		panic(p.newError("expected a value"))
+	}
+	for p.off < len(p.buf) {
+		result = p.value()
+		p.skipSpace()
+		if cb == nil {
+			if p.off < len(p.buf) {
+				panic(p.newError("unexpected character '%c' after the value", p.buf[p.off]))
+			}
+			break
+		}
+		if cb(result) {
+			break
+		}
+		result = nil
+	}
+	return
+}
+
+// ParseReader reads and parses a JSON5 document. The arguments are the same
+// as for Parse.
+func (p *JSON5Parser) ParseReader(r io.Reader, args ...any) (data any, err error) {
+	var buf []byte
+	if buf, err = readAll(r, p.Limits.MaxBytes); err == nil {
+		data, err = p.Parse(buf, args...)
+	}
+	return
+}
+
+// MustParse a JSON5 document into simple types. Panics on error.
+func (p *JSON5Parser) MustParse(buf []byte, args ...any) any {
+	val, err := p.Parse(buf, args...)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+func (p *JSON5Parser) value() (v any) {
+	b := p.buf[p.off]
+	switch b {
+	case '{':
+		v = p.object()
+	case '[':
+		v = p.array()
+	case '"', '\'':
+		v = p.str()
+	case 'n':
+		p.word("null")
+	case 't':
+		p.word("true")
+		v = true
+	case 'f':
+		p.word("false")
+		v = false
+	case '-', '+', '.', 'I', 'N', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		v = p.number()
+	default:
+		panic(p.newError("unexpected character '%c'", b))
+	}
+	return
+}
+
+func (p *JSON5Parser) word(w string) {
+	if len(p.buf) < p.off+len(w) || string(p.buf[p.off:p.off+len(w)]) != w {
+		panic(p.newError("invalid token"))
+	}
+	p.off += len(w)
+	if p.off < len(p.buf) {
+		if r, _ := utf8.DecodeRune(p.buf[p.off:]); isIdentPart(r) {
+			panic(p.newError("invalid token"))
+		}
+	}
+}
+
+func (p *JSON5Parser) push() {
+	if 0 < p.Limits.MaxDepth && p.Limits.MaxDepth <= p.depth {
+		panic(p.limitError(ojg.DepthLimit, p.Limits.MaxDepth))
+	}
+	p.depth++
+	p.off++
+}
+
+func (p *JSON5Parser) object() any {
+	p.push()
+	var obj map[string]any
+	var ordered *gen.Ordered
+	var index gen.OrderedIndex
+	if p.Ordered {
+		ordered = &gen.Ordered{}
+	} else {
+		obj = map[string]any{}
+	}
+	cnt := 0
+	for {
+		p.skipSpace()
+		if len(p.buf) <= p.off {
+			panic(p.newError("object not terminated"))
+		}
+		if p.buf[p.off] == '}' {
+			break
+		}
+		if cnt++; 0 < p.Limits.MaxMembers && p.Limits.MaxMembers < cnt {
+			panic(p.limitError(ojg.MembersLimit, p.Limits.MaxMembers))
+		}
+		keyOff := p.off
+		var key string
+		switch p.buf[p.off] {
+		case '"', '\'':
+			key = p.str()
+		default:
+			key = p.identifier()
+		}
+		p.skipSpace()
+		if len(p.buf) <= p.off || p.buf[p.off] != ':' {
+			panic(p.newError("expected a colon"))
+		}
+		p.off++
+		p.skipSpace()
+		if len(p.buf) <= p.off {
+			panic(p.newError("expected a value"))
+		}
+		v := p.value()
+		if ordered != nil {
+			if p.NoDuplicates && index.Has(ordered, key) {
+				panic(p.keyError(keyOff, key))
+			}
+			index.Set(ordered, key, v)
+		} else {
+			if _, has := obj[key]; has && p.NoDuplicates {
+				panic(p.keyError(keyOff, key))
+			}
+			obj[key] = v
+		}
+		p.skipSpace()
+		if len(p.buf) <= p.off {
+			panic(p.newError("object not terminated"))
+		}
+		if p.buf[p.off] == ',' {
+			p.off++
+			continue
+		}
+		if p.buf[p.off] != '}' {
+			panic(p.newError("expected a comma or close, not '%c'", p.buf[p.off]))
+		}
+		break
+	}
+	p.off++
+	p.depth--
+	if ordered != nil {
+		return ordered
+	}
+	return obj
+}
+
+func (p *JSON5Parser) array() any {
+	p.push()
+	list := []any{}
+	for {
+		p.skipSpace()
+		if len(p.buf) <= p.off {
+			panic(p.newError("array not terminated"))
+		}
+		if p.buf[p.off] == ']' {
+			break
+		}
+		if 0 < p.Limits.MaxMembers && p.Limits.MaxMembers <= len(list) {
+			panic(p.limitError(ojg.MembersLimit, p.Limits.MaxMembers))
+		}
+		list = append(list, p.value())
+		p.skipSpace()
+		if len(p.buf) <= p.off {
+			panic(p.newError("array not terminated"))
+		}
+		if p.buf[p.off] == ',' {
+			p.off++
+			continue
+		}
+		if p.buf[p.off] != ']' {
+			panic(p.newError("expected a comma or close, not '%c'", p.buf[p.off]))
+		}
+		break
+	}
+	p.off++
+	p.depth--
+
+	return list
+}
+
+// str reads a single or double quoted string.
+func (p *JSON5Parser) str() string {
+	q := p.buf[p.off]
+	p.off++
+	start := p.off
+	p.tmp = p.tmp[:0]
+	for p.off < len(p.buf) {
+		b := p.buf[p.off]
+		switch b {
+		case q:
+			p.tmp = append(p.tmp, p.buf[start:p.off]...)
+			p.off++
+			p.checkStringLen(len(p.tmp))
+			return string(p.tmp)
+		case '\n', '\r':
+			panic(p.newError("unescaped line terminator in a string"))
+		case '\\':
+			p.tmp = append(p.tmp, p.buf[start:p.off]...)
+			p.escape()
+			start = p.off
+		default:
+			p.off++
+		}
+	}
+	panic(p.newError("string not terminated"))
+}
+
+// escape reads an escape sequence in a string and appends the result to
+// tmp.
+func (p *JSON5Parser) escape() {
+	p.off++
+	if len(p.buf) <= p.off {
+		panic(p.newError("string not terminated"))
+	}
+	b := p.buf[p.off]
+	p.off++
+	switch b {
+	case 'b':
+		p.tmp = append(p.tmp, '\b')
+	case 'f':
+		p.tmp = append(p.tmp, '\f')
+	case 'n':
+		p.tmp = append(p.tmp, '\n')
+	case 'r':
+		p.tmp = append(p.tmp, '\r')
+	case 't':
+		p.tmp = append(p.tmp, '\t')
+	case 'v':
+		p.tmp = append(p.tmp, '\v')
+	case '0':
+		if p.off < len(p.buf) && '0' <= p.buf[p.off] && p.buf[p.off] <= '9' {
+			p.off--
+			panic(p.newError("invalid escape, octal escapes are not allowed"))
+		}
+		p.tmp = append(p.tmp, 0)
+	case '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		p.off--
+		panic(p.newError("invalid escape, octal escapes are not allowed"))
+	case 'x':
+		p.tmp = utf8.AppendRune(p.tmp, p.hexRune(2))
+	case 'u':
+		p.tmp = utf8.AppendRune(p.tmp, p.uRune())
+	case '\n':
+		p.newLine(p.off - 1)
+	case '\r':
+		if p.off < len(p.buf) && p.buf[p.off] == '\n' {
+			p.off++
+		}
+		p.newLine(p.off - 1)
+	default:
+		if b < utf8.RuneSelf {
+			p.tmp = append(p.tmp, b)
+			break
+		}
+		r, n := utf8.DecodeRune(p.buf[p.off-1:])
+		p.off += n - 1
+		// A line or paragraph separator is a line continuation.
+		if r != '\u2028' && r != '\u2029' {
+			p.tmp = utf8.AppendRune(p.tmp, r)
+		}
+	}
+}
+
+// uRune reads the hex digits of a \u escape and a following low surrogate
+// if needed.
+func (p *JSON5Parser) uRune() rune {
+	r := p.hexRune(4)
+	if utf16.IsSurrogate(r) && p.off+6 <= len(p.buf) && p.buf[p.off] == '\\' && p.buf[p.off+1] == 'u' {
+		save := p.off
+		p.off += 2
+		if r2 := p.hexRune(4); 0xdc00 <= r2 && r2 < 0xe000 {
+			return utf16.DecodeRune(r, r2)
+		}
+		p.off = save
+	}
+	return r
+}
+
+func (p *JSON5Parser) hexRune(n int) (r rune) {
+	if len(p.buf) < p.off+n {
+		panic(p.newError("invalid hex escape"))
+	}
+	for _, b := range p.buf[p.off : p.off+n] {
+		v := hexValue(b)
+		if v < 0 {
+			panic(p.newError("invalid hex character '%c'", b))
+		}
+		r = r<<4 | rune(v)
+		p.off++
+	}
+	return
+}
+
+func hexValue(b byte) int {
+	switch {
+	case '0' <= b && b <= '9':
+		return int(b - '0')
+	case 'a' <= b && b <= 'f':
+		return int(b-'a') + 10
+	case 'A' <= b && b <= 'F':
+		return int(b-'A') + 10
+	}
+	return -1
+}
+
+// identifier reads an ECMAScript 5.1 IdentifierName used as a key.
+func (p *JSON5Parser) identifier() string {
+	p.tmp = p.tmp[:0]
+	for first := true; p.off < len(p.buf); first = false {
+		var r rune
+		start := p.off
+		if p.buf[p.off] == '\\' {
+			if len(p.buf) <= p.off+1 || p.buf[p.off+1] != 'u' {
+				panic(p.newError("invalid identifier escape"))
+			}
+			p.off += 2
+			r = p.hexRune(4)
+		} else {
+			var n int
+			r, n = utf8.DecodeRune(p.buf[p.off:])
+			p.off += n
+		}
+		switch {
+		case isIdentStart(r):
+		case !first && isIdentPart(r):
+		case first:
+			p.off = start
+			panic(p.newError("invalid key"))
+		default:
+			if p.buf[start] == '\\' {
+				p.off = start
+				panic(p.newError("invalid identifier escape"))
+			}
+			p.off = start
+			p.checkStringLen(len(p.tmp))
+			return string(p.tmp)
+		}
+		p.tmp = utf8.AppendRune(p.tmp, r)
+	}
+	p.checkStringLen(len(p.tmp))
+	return string(p.tmp)
+}
+
+func isIdentStart(r rune) bool {
+	return r == '$' || r == '_' || unicode.IsLetter(r) || unicode.Is(unicode.Nl, r)
+}
+
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc) ||
+		r == '\u200c' || r == '\u200d'
+}
+
+// IsJSON5Identifier returns true if the string can be used as an unquoted
+// JSON5 key.
+func IsJSON5Identifier(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for i, r := range s {
+		if r == utf8.RuneError || (i == 0 && !isIdentStart(r)) || !isIdentPart(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *JSON5Parser) number() any {
+	start := p.off
+	neg := false
+	switch p.buf[p.off] {
+	case '-':
+		neg = true
+		p.off++
+	case '+':
+		p.off++
+	}
+	if len(p.buf) <= p.off {
+		panic(p.newError("invalid number"))
+	}
+	switch p.buf[p.off] {
+	case 'I':
+		p.word("Infinity")
+		if neg {
+			return math.Inf(-1)
+		}
+		return math.Inf(1)
+	case 'N':
+		p.word("NaN")
+		return math.NaN()
+	case '0':
+		if p.off+1 < len(p.buf) && (p.buf[p.off+1] == 'x' || p.buf[p.off+1] == 'X') {
+			return p.hexNumber(start, neg)
+		}
+	}
+	// The text is normalized into JSON form as it is read.
+	p.tmp = p.tmp[:0]
+	if neg {
+		p.tmp = append(p.tmp, '-')
+	}
+	digits := p.digits()
+	if digits == 0 {
+		if len(p.buf) <= p.off || p.buf[p.off] != '.' {
+			panic(p.newError("invalid number"))
+		}
+		p.tmp = append(p.tmp, '0')
+	} else if 1 < digits && p.tmp[len(p.tmp)-digits] == '0' {
+		panic(p.newError("invalid number, leading zeros are not allowed"))
+	}
+	if p.off < len(p.buf) && p.buf[p.off] == '.' {
+		p.off++
+		p.tmp = append(p.tmp, '.')
+		frac := p.digits()
+		if frac == 0 {
+			if digits == 0 {
+				panic(p.newError("invalid number"))
+			}
+			p.tmp = p.tmp[:len(p.tmp)-1]
+		}
+	}
+	if p.off < len(p.buf) && (p.buf[p.off] == 'e' || p.buf[p.off] == 'E') {
+		p.off++
+		p.tmp = append(p.tmp, 'e')
+		if p.off < len(p.buf) && (p.buf[p.off] == '+' || p.buf[p.off] == '-') {
+			p.tmp = append(p.tmp, p.buf[p.off])
+			p.off++
+		}
+		if p.digits() == 0 {
+			panic(p.newError("invalid number"))
+		}
+	}
+	if p.off < len(p.buf) {
+		if r, _ := utf8.DecodeRune(p.buf[p.off:]); isIdentPart(r) {
+			panic(p.newError("invalid number"))
+		}
+	}
+	if 0 < p.Limits.MaxNumberLen && p.Limits.MaxNumberLen < p.off-start {
+		panic(p.limitError(ojg.NumberLimit, p.Limits.MaxNumberLen))
+	}
+	if p.NumConv != nil {
+		return p.NumConv(string(p.tmp))
+	}
+	p.num.Reset()
+	mode := 'i'
+	for _, b := range p.tmp {
+		switch {
+		case b == '-':
+			if mode != 'e' {
+				p.num.Neg = true
+				break
+			}
+			p.num.NegExp = true
+			if 0 < len(p.num.BigBuf) {
+				p.num.BigBuf = append(p.num.BigBuf, b)
+			}
+		case b == '.':
+			mode = 'f'
+			if 0 < len(p.num.BigBuf) {
+				p.num.BigBuf = append(p.num.BigBuf, b)
+			}
+		case b == 'e':
+			mode = 'e'
+			if 0 < len(p.num.BigBuf) {
+				p.num.BigBuf = append(p.num.BigBuf, b)
+			}
+		case b == '+':
+		case mode == 'i':
+			p.num.AddDigit(b)
+		case mode == 'f':
+			p.num.AddFrac(b)
+		default:
+			p.num.AddExp(b)
+		}
+	}
+	return p.num.AsNum()
+}
+
+// digits appends decimal digits to tmp and returns the number appended.
+func (p *JSON5Parser) digits() (cnt int) {
+	for ; p.off < len(p.buf) && '0' <= p.buf[p.off] && p.buf[p.off] <= '9'; p.off++ {
+		p.tmp = append(p.tmp, p.buf[p.off])
+		cnt++
+	}
+	return
+}
+
+func (p *JSON5Parser) hexNumber(start int, neg bool) any {
+	p.off += 2
+	hs := p.off
+	for p.off < len(p.buf) && 0 <= hexValue(p.buf[p.off]) {
+		p.off++
+	}
+	if hs == p.off {
+		panic(p.newError("invalid hex number"))
+	}
+	if p.off < len(p.buf) {
+		if r, _ := utf8.DecodeRune(p.buf[p.off:]); isIdentPart(r) {
+			panic(p.newError("invalid hex number"))
+		}
+	}
+	if 0 < p.Limits.MaxNumberLen && p.Limits.MaxNumberLen < p.off-start {
+		panic(p.limitError(ojg.NumberLimit, p.Limits.MaxNumberLen))
+	}
+	bi, _ := new(big.Int).SetString(string(p.buf[hs:p.off]), 16)
+	if neg {
+		bi.Neg(bi)
+	}
+	if p.NumConv != nil {
+		return p.NumConv(bi.String())
+	}
+	if bi.IsInt64() {
+		return bi.Int64()
+	}
+	// Like JavaScript, numbers too large for an integer are doubles.
+	f, _ := new(big.Float).SetInt(bi).Float64()
+
+	return f
+}
+
+// skipSpace skips white space and comments.
+func (p *JSON5Parser) skipSpace() {
+	for p.off < len(p.buf) {
+		b := p.buf[p.off]
+		switch b {
+		case ' ', '\t', '\v', '\f':
+			p.off++
+		case '\n':
+			p.newLine(p.off)
+			p.off++
+		case '\r':
+			if p.off+1 < len(p.buf) && p.buf[p.off+1] == '\n' {
+				p.off++
+			}
+			p.newLine(p.off)
+			p.off++
+		case '/':
+			p.comment()
+		default:
+			if b < utf8.RuneSelf {
+				return
+			}
+			r, n := utf8.DecodeRune(p.buf[p.off:])
+			switch {
+			case r == '\u2028' || r == '\u2029':
+				p.newLine(p.off + n - 1)
+			case r == '\ufeff' || unicode.Is(unicode.Zs, r):
+			default:
+				return
+			}
+			p.off += n
+		}
+	}
+}
+
+func (p *JSON5Parser) comment() {
+	if len(p.buf) <= p.off+1 {
+		panic(p.newError("unexpected character '/'"))
+	}
+	switch p.buf[p.off+1] {
+	case '/':
+		p.off += 2
+		for p.off < len(p.buf) {
+			switch p.buf[p.off] {
+			case '\n', '\r':
+				return
+			case 0xe2: // possible line or paragraph separator
+				if r, _ := utf8.DecodeRune(p.buf[p.off:]); r == '\u2028' || r == '\u2029' {
+					return
+				}
+			}
+			p.off++
+		}
+	case '*':
+		start := p.off
+		p.off += 2
+		for p.off+1 < len(p.buf) {
+			switch p.buf[p.off] {
+			case '*':
+				if p.buf[p.off+1] == '/' {
+					p.off += 2
+					return
+				}
+			case '\n':
+				p.newLine(p.off)
+			case '\r':
+				if p.buf[p.off+1] != '\n' {
+					p.newLine(p.off)
+				}
+			}
+			p.off++
+		}
+		p.off = start
+		panic(p.newError("comment not terminated"))
+	default:
+		panic(p.newError("unexpected character '/'"))
+	}
+}
+
+func (p *JSON5Parser) newLine(off int) {
+	p.line++
+	p.noff = off
+}
+
+func (p *JSON5Parser) checkStringLen(n int) {
+	if 0 < p.Limits.MaxStringLen && p.Limits.MaxStringLen < n {
+		panic(p.limitError(ojg.StringLimit, p.Limits.MaxStringLen))
+	}
+}
+
+func (p *JSON5Parser) newError(format string, args ...any) error {
+	return &oj.ParseError{
+		Message: fmt.Sprintf(format, args...),
+		Line:    p.line,
+		Column:  p.off - p.noff,
+	}
+}
+
+func (p *JSON5Parser) limitError(limit ojg.Limit, max int) error {
+	return &ojg.LimitError{
+		Limit:  limit,
+		Max:    max,
+		Line:   p.line,
+		Column: p.off - p.noff,
+	}
+}
+
+func (p *JSON5Parser) keyError(off int, key string) error {
+	return &oj.ParseError{
+		Message: fmt.Sprintf("duplicate key '%s'", key),
+		Line:    p.line,
+		Column:  off - p.noff,
+		Key:     key,
+	}
+}
+
+// readAll reads all of r but no more than one byte over max if max is not
+// zero so that input over the limit is detected without reading all of it.
+func readAll(r io.Reader, max int) ([]byte, error) {
+	if 0 < max {
+		r = io.LimitReader(r, int64(max)+1)
+	}
+	return io.ReadAll(r)
+}
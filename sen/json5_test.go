@@ -0,0 +1,244 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package sen_test
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/sen"
+	"github.com/ohler55/ojg/tt"
+)
+
+const json5Sample = `// An example from json5.org.
+{
+  // comments
+  unquoted: 'and you can quote me on that',
+  singleQuotes: 'I can use "double quotes" here',
+  lineBreaks: "Look, Mom! \
+No \\n's!",
+  hexadecimal: 0xdecaf,
+  leadingDecimalPoint: .8675309, andTrailing: 8675309.,
+  positiveSign: +1,
+  trailingComma: 'in objects', andIn: ['arrays',],
+  "backwardsCompatible": "with JSON",
+  /* block
+     comment */
+}`
+
+func TestJSON5ParseSample(t *testing.T) {
+	v, err := sen.ParseJSON5([]byte(json5Sample))
+	tt.Nil(t, err)
+	tt.Equal(t,
+		`{"andIn":["arrays"],"andTrailing":8675309,"backwardsCompatible":"with JSON",`+
+			`"hexadecimal":912559,"leadingDecimalPoint":0.8675309,"lineBreaks":"Look, Mom! No \\n's!",`+
+			`"positiveSign":1,"singleQuotes":"I can use \"double quotes\" here",`+
+			`"trailingComma":"in objects","unquoted":"and you can quote me on that"}`,
+		oj.JSON(v, &ojg.Options{Sort: true}))
+}
+
+func TestJSON5ParseValues(t *testing.T) {
+	for i, d := range []struct {
+		src    string
+		expect string
+	}{
+		{src: "null", expect: "null"},
+		{src: " true ", expect: "true"},
+		{src: "\ufefffalse", expect: "false"},
+		{src: "-12", expect: "-12"},
+		{src: "+0.5e1", expect: "5"},
+		{src: "-.5", expect: "-0.5"},
+		{src: "0x7FFFFFFFFFFFFFFF", expect: "9223372036854775807"},
+		{src: "-0x10", expect: "-16"},
+		{src: "0xFFFFFFFFFFFFFFFFFF", expect: "4.722366482869645e+21"},
+		{src: "123456789012345678901234567890", expect: "123456789012345678901234567890"},
+		{src: `'a\'b"c'`, expect: `"a'b\"c"`},
+		{src: `"\x41\u00e9\0\v\q"`, expect: `"Aé\u0000\u000bq"`},
+		{src: `"\ud83d\ude00"`, expect: `"😀"`},
+		{src: "'a\\\r\nb\\\u2028c'", expect: `"abc"`},
+		{src: "{$a_1:1,\\u0062:2,é:3,}", expect: `{"$a_1":1,"b":2,"é":3}`},
+		{src: "[/*x*/1,//y\n2\u00a0,\u2029]", expect: "[1,2]"},
+		{src: "{a:{},b:[],}", expect: `{"a":{},"b":[]}`},
+	} {
+		v, err := sen.ParseJSON5([]byte(d.src))
+		tt.Nil(t, err, i, ": ", d.src)
+		tt.Equal(t, d.expect, oj.JSON(v, &ojg.Options{Sort: true}), i, ": ", d.src)
+	}
+	for _, src := range []string{"Infinity", "+Infinity", "-Infinity", "NaN", "-NaN"} {
+		v, err := sen.ParseJSON5([]byte(src))
+		tt.Nil(t, err, src)
+		f, _ := v.(float64)
+		switch {
+		case strings.HasSuffix(src, "NaN"):
+			tt.Equal(t, true, math.IsNaN(f), src)
+		case src[0] == '-':
+			tt.Equal(t, true, math.IsInf(f, -1), src)
+		default:
+			tt.Equal(t, true, math.IsInf(f, 1), src)
+		}
+	}
+}
+
+func TestJSON5ParseErrors(t *testing.T) {
+	for i, d := range []struct {
+		src    string
+		expect string
+	}{
+		{src: "", expect: "expected a value at 1:1"},
+		{src: "[1 2]", expect: "expected a comma or close, not '2' at 1:4"},
+		{src: "{a:1 b:2}", expect: "expected a comma or close, not 'b' at 1:6"},
+		{src: "[1,,]", expect: "unexpected character ',' at 1:4"},
+		{src: "{a b}", expect: "expected a colon at 1:4"},
+		{src: "{1:2}", expect: "invalid key at 1:2"},
+		{src: "[abc]", expect: "unexpected character 'a' at 1:2"},
+		{src: "[trueish]", expect: "invalid token at 1:6"},
+		{src: "[01]", expect: "invalid number, leading zeros are not allowed at 1:4"},
+		{src: "[1e]", expect: "invalid number at 1:4"},
+		{src: "[0x]", expect: "invalid hex number at 1:4"},
+		{src: "'abc", expect: "string not terminated at 1:5"},
+		{src: "'a\nb'", expect: "unescaped line terminator in a string at 1:3"},
+		{src: `'\1'`, expect: "invalid escape, octal escapes are not allowed at 1:3"},
+		{src: `'\xZZ'`, expect: "invalid hex character 'Z' at 1:4"},
+		{src: "[1] [2]", expect: "unexpected character '[' after the value at 1:5"},
+		{src: "/* x", expect: "comment not terminated at 1:1"},
+		{src: "[\n  / x]", expect: "unexpected character '/' at 2:3"},
+	} {
+		_, err := sen.ParseJSON5([]byte(d.src))
+		tt.NotNil(t, err, i, ": ", d.src)
+		tt.Equal(t, d.expect, err.Error(), i, ": ", d.src)
+	}
+}
+
+func TestJSON5ParseOptions(t *testing.T) {
+	p := sen.JSON5Parser{Ordered: true}
+	v, err := p.Parse([]byte("{b:1, a:2, b:3}"))
+	tt.Nil(t, err)
+	tt.Equal(t, `{"b":3,"a":2}`, oj.JSON(v))
+	_, ok := v.(*gen.Ordered)
+	tt.Equal(t, true, ok)
+
+	p = sen.JSON5Parser{NoDuplicates: true}
+	_, err = p.Parse([]byte("{b:1, a:2, b:3}"))
+	tt.NotNil(t, err)
+	var pe *oj.ParseError
+	tt.Equal(t, true, errors.As(err, &pe))
+	tt.Equal(t, "b", pe.Key)
+
+	p = sen.JSON5Parser{NumConv: func(s string) any { return "#" + s }}
+	v, err = p.Parse([]byte("[.5, 0x10, -2]"))
+	tt.Nil(t, err)
+	tt.Equal(t, `["#0.5","#16","#-2"]`, oj.JSON(v))
+
+	var list []any
+	v, err = p.Parse([]byte("1 'two' [3]"), func(x any) { list = append(list, x) })
+	tt.Nil(t, err)
+	tt.Nil(t, v)
+	tt.Equal(t, 3, len(list))
+
+	v, err = sen.ParseJSON5([]byte("{a:[1,2,3]}"))
+	tt.Nil(t, err)
+	tt.NotNil(t, v)
+
+	for i, d := range []struct {
+		limits ojg.Limits
+		src    string
+		limit  ojg.Limit
+	}{
+		{limits: ojg.Limits{MaxDepth: 2}, src: "[[[1]]]", limit: ojg.DepthLimit},
+		{limits: ojg.Limits{MaxMembers: 2}, src: "[1,2,3]", limit: ojg.MembersLimit},
+		{limits: ojg.Limits{MaxStringLen: 3}, src: "'abcd'", limit: ojg.StringLimit},
+		{limits: ojg.Limits{MaxNumberLen: 3}, src: "12345", limit: ojg.NumberLimit},
+		{limits: ojg.Limits{MaxBytes: 3}, src: "12345", limit: ojg.BytesLimit},
+	} {
+		p = sen.JSON5Parser{Limits: d.limits}
+		_, err = p.Parse([]byte(d.src))
+		var le *ojg.LimitError
+		tt.Equal(t, true, errors.As(err, &le), i, ": ", d.src)
+		tt.Equal(t, d.limit, le.Limit, i, ": ", d.src)
+	}
+	p = sen.JSON5Parser{}
+	v, err = p.ParseReader(strings.NewReader("{x:'y'}"))
+	tt.Nil(t, err)
+	tt.Equal(t, `{"x":"y"}`, oj.JSON(v))
+}
+
+func TestJSON5Write(t *testing.T) {
+	data := &gen.Ordered{Members: []gen.Member{
+		{Key: "name", Value: `say "hi"`},
+		{Key: "a-b", Value: []any{1, 2.5, true, nil}},
+		{Key: "$x", Value: map[string]any{}},
+		{Key: "ctl", Value: "\x00\x001\x01\v\u2028"},
+		{Key: "inf", Value: []any{math.Inf(1), math.Inf(-1), math.NaN()}},
+	}}
+	tt.Equal(t,
+		`{name:'say "hi"',"a-b":[1,2.5,true,null],$x:{},ctl:"\0\x001\x01\v\u2028",inf:[Infinity,-Infinity,NaN]}`,
+		sen.JSON5(data))
+	tt.Equal(t, `{
+  name: 'say "hi"',
+  "a-b": [
+    1,
+    2.5,
+    true,
+    null
+  ],
+  $x: {},
+  ctl: "\0\x001\x01\v\u2028",
+  inf: [
+    Infinity,
+    -Infinity,
+    NaN
+  ]
+}`, sen.JSON5(data, 2))
+	tt.Equal(t, "{\n\ta: [\n\t\t1\n\t]\n}", sen.JSON5(map[string]any{"a": []any{1}}, &ojg.Options{Tab: true}))
+	tt.Equal(t, "{a:1,b:2,c:3}", sen.JSON5(map[string]any{"c": 3, "a": 1, "b": 2}, &ojg.Options{Sort: true}))
+
+	type sample struct {
+		Name string
+		Size int
+	}
+	tt.Equal(t, `{name:"x",size:3}`, sen.JSON5(&sample{Name: "x", Size: 3}, &ojg.Options{Sort: true}))
+
+	tt.Equal(t, "[Infinity,-Infinity,2.5]",
+		sen.JSON5([]any{new(big.Float).SetInf(false), new(big.Float).SetInf(true), big.NewFloat(2.5)}))
+
+	var b strings.Builder
+	tt.Nil(t, sen.WriteJSON5(&b, []any{"a", gen.Int(1)}))
+	tt.Equal(t, `["a",1]`, b.String())
+
+	// A gen.Big in gen containers is written as a number.
+	tt.Equal(t, `{a:[12345678901234567890,"x",null],b:{c:1.50},d:{},e:""}`, sen.JSON5(gen.Object{
+		"a": gen.Array{gen.Big("12345678901234567890"), gen.String("x"), nil},
+		"b": gen.Object{"c": gen.Big("1.50")},
+		"d": gen.Object{},
+		"e": gen.String(""),
+	}, &ojg.Options{Sort: true}))
+	tt.Equal(t, `{a:[12345678901234567890]}`, sen.JSON5(gen.Object{
+		"a": gen.Array{gen.Big("12345678901234567890")},
+		"d": gen.Object{},
+		"e": gen.String(""),
+		"f": gen.Array{},
+	}, &ojg.Options{OmitEmpty: true}))
+}
+
+func TestJSON5RoundTrip(t *testing.T) {
+	v, err := sen.ParseJSON5([]byte(json5Sample))
+	tt.Nil(t, err)
+	out := sen.JSON5(v, &ojg.Options{Sort: true, Indent: 2})
+	v2, err := sen.ParseJSON5([]byte(out))
+	tt.Nil(t, err)
+	tt.Equal(t, v, v2)
+
+	src := "{s:\"\\0\\x001\\t'\\\"\\u2029\", f:[NaN,-Infinity,1e+300]}"
+	v, err = sen.ParseJSON5([]byte(src))
+	tt.Nil(t, err)
+	v2, err = sen.ParseJSON5([]byte(sen.JSON5(v)))
+	tt.Nil(t, err)
+	tt.Equal(t, v.(map[string]any)["s"], v2.(map[string]any)["s"])
+	tt.Equal(t, "[NaN,-Infinity,1e+300]", sen.JSON5(v2.(map[string]any)["f"]))
+}
@@ -0,0 +1,421 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package sen
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/gen"
+)
+
+// JSON5 writes data, JSON5 encoded. On error, an empty string is returned.
+func (wr *Writer) JSON5(data any) string {
+	defer func() {
+		if r := recover(); r != nil {
+			wr.buf = wr.buf[:0]
+		}
+	}()
+	return string(wr.MustJSON5(data))
+}
+
+// MustJSON5 writes data, JSON5 encoded as a []byte. Keys that are valid
+// identifiers are not quoted, strings use double quotes unless single quotes
+// need less escaping, and NaN and infinite floats are written as NaN and
+// Infinity. Colors are not supported. On error a panic is called with the
+// error. The returned buffer is the Writer buffer and is reused on the next
+// call to write.
+func (wr *Writer) MustJSON5(data any) []byte {
+	wr.w = nil
+	if wr.InitSize <= 0 {
+		wr.InitSize = 256
+	}
+	if cap(wr.buf) < wr.InitSize {
+		wr.buf = make([]byte, 0, wr.InitSize)
+	} else {
+		wr.buf = wr.buf[:0]
+	}
+	wr.cycles = alt.NewCycles(&wr.Options)
+	wr.appendJSON5(data, 0)
+
+	return wr.buf
+}
+
+// WriteJSON5 writes a JSON5 string for the data provided.
+func (wr *Writer) WriteJSON5(w io.Writer, data any) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			wr.buf = wr.buf[:0]
+			err = ojg.NewError(r)
+		}
+	}()
+	wr.MustJSON5(data)
+	_, err = w.Write(wr.buf)
+
+	return
+}
+
+// JSON5 returns a JSON5 string for the data provided. The args, if supplied
+// can be an int as an indent, *ojg.Options, or a *Writer.
+func JSON5(data any, args ...any) string {
+	var wr *Writer
+	if 0 < len(args) {
+		wr = pickWriter(args[0])
+	}
+	if wr == nil {
+		wr, _ = writerPool.Get().(*Writer)
+		defer writerPool.Put(wr)
+	}
+	return wr.JSON5(data)
+}
+
+// WriteJSON5 writes JSON5 for the data provided. The args, if supplied can
+// be an int as an indent, *ojg.Options, or a *Writer.
+func WriteJSON5(w io.Writer, data any, args ...any) error {
+	var wr *Writer
+	if 0 < len(args) {
+		wr = pickWriter(args[0])
+	}
+	if wr == nil {
+		wr, _ = writerPool.Get().(*Writer)
+		defer writerPool.Put(wr)
+	}
+	return wr.WriteJSON5(w, data)
+}
+
+func (wr *Writer) appendJSON5(data any, depth int) {
+	switch td := data.(type) {
+	case nil:
+		wr.buf = append(wr.buf, "null"...)
+	case bool:
+		if td {
+			wr.buf = append(wr.buf, "true"...)
+		} else {
+			wr.buf = append(wr.buf, "false"...)
+		}
+	case int:
+		wr.buf = strconv.AppendInt(wr.buf, int64(td), 10)
+	case int8:
+		wr.buf = strconv.AppendInt(wr.buf, int64(td), 10)
+	case int16:
+		wr.buf = strconv.AppendInt(wr.buf, int64(td), 10)
+	case int32:
+		wr.buf = strconv.AppendInt(wr.buf, int64(td), 10)
+	case int64:
+		wr.buf = strconv.AppendInt(wr.buf, td, 10)
+	case uint:
+		wr.buf = strconv.AppendUint(wr.buf, uint64(td), 10)
+	case uint8:
+		wr.buf = strconv.AppendUint(wr.buf, uint64(td), 10)
+	case uint16:
+		wr.buf = strconv.AppendUint(wr.buf, uint64(td), 10)
+	case uint32:
+		wr.buf = strconv.AppendUint(wr.buf, uint64(td), 10)
+	case uint64:
+		wr.buf = strconv.AppendUint(wr.buf, td, 10)
+	case float32:
+		wr.buf = appendJSON5Float(wr.buf, float64(td), 32)
+	case float64:
+		wr.buf = appendJSON5Float(wr.buf, td, 64)
+	case *big.Float:
+		if td != nil && td.IsInf() {
+			if td.Signbit() {
+				wr.buf = append(wr.buf, "-Infinity"...)
+			} else {
+				wr.buf = append(wr.buf, "Infinity"...)
+			}
+			break
+		}
+		wr.buf, _ = ojg.AppendNumber(wr.buf, td)
+	case json.Number, *big.Int:
+		wr.buf, _ = ojg.AppendNumber(wr.buf, td)
+	case gen.Big:
+		wr.buf = append(wr.buf, td...)
+	case string:
+		wr.buf = appendJSON5String(wr.buf, td)
+	case []byte:
+		switch wr.BytesAs {
+		case ojg.BytesAsBase64:
+			wr.buf = appendJSON5String(wr.buf, base64.StdEncoding.EncodeToString(td))
+		case ojg.BytesAsArray:
+			a := make([]any, len(td))
+			for i, m := range td {
+				a[i] = int64(m)
+			}
+			wr.appendJSON5Array(a, depth)
+		default:
+			wr.buf = appendJSON5String(wr.buf, string(td))
+		}
+	case time.Time:
+		wr.buf = wr.AppendTime(wr.buf, td, false)
+	case []any:
+		if wr.cycles == nil || !wr.json5Cycled(reflect.ValueOf(td), depth) {
+			wr.appendJSON5Array(td, depth)
+			wr.cycles.Exit()
+		}
+	case map[string]any:
+		if wr.cycles == nil || !wr.json5Cycled(reflect.ValueOf(td), depth) {
+			members := make([]gen.Member, 0, len(td))
+			for k, v := range td {
+				members = append(members, gen.Member{Key: k, Value: v})
+			}
+			if wr.Sort {
+				sort.Slice(members, func(i, j int) bool { return members[i].Key < members[j].Key })
+			}
+			wr.appendJSON5Object(members, depth)
+			wr.cycles.Exit()
+		}
+	case *gen.Ordered:
+		if td == nil {
+			wr.buf = append(wr.buf, "null"...)
+			break
+		}
+		if wr.cycles == nil || !wr.json5Cycled(reflect.ValueOf(td), depth) {
+			members := td.Members
+			if wr.Sort {
+				members = td.Sorted()
+			}
+			wr.appendJSON5Object(members, depth)
+			wr.cycles.Exit()
+		}
+	case gen.Array:
+		// The elements are left as gen.Node values so that a gen.Big is
+		// still written as a number.
+		if wr.cycles == nil || !wr.json5Cycled(reflect.ValueOf(td), depth) {
+			a := make([]any, len(td))
+			for i, v := range td {
+				a[i] = v
+			}
+			wr.appendJSON5Array(a, depth)
+			wr.cycles.Exit()
+		}
+	case gen.Object:
+		if wr.cycles == nil || !wr.json5Cycled(reflect.ValueOf(td), depth) {
+			members := make([]gen.Member, 0, len(td))
+			for k, v := range td {
+				members = append(members, gen.Member{Key: k, Value: v})
+			}
+			if wr.Sort {
+				sort.Slice(members, func(i, j int) bool { return members[i].Key < members[j].Key })
+			}
+			wr.appendJSON5Object(members, depth)
+			wr.cycles.Exit()
+		}
+	case gen.Node:
+		wr.appendJSON5(td.Simplify(), depth)
+	default:
+		if wr.NoReflect {
+			wr.buf = appendJSON5String(wr.buf, fmt.Sprintf("%v", data))
+			break
+		}
+		wr.appendJSON5(wr.cycles.Decompose(data, &wr.Options), depth)
+	}
+}
+
+// json5Cycled returns true if rv is already being written in which case the
+// replacement called for by the OnCycle option has been written in its
+// place. If false is returned wr.cycles.Exit() must be called once rv has
+// been written.
+func (wr *Writer) json5Cycled(rv reflect.Value, depth int) bool {
+	cv, seen := wr.cycles.Enter(rv)
+	if seen {
+		wr.appendJSON5(cv, depth)
+	}
+	return seen
+}
+
+func (wr *Writer) json5Indents(depth int) (is string, cs string) {
+	if wr.Tab {
+		x := depth + 1
+		if len(tabs) < x {
+			x = len(tabs)
+		}
+		is = tabs[0:x]
+		x = depth + 2
+		if len(tabs) < x {
+			x = len(tabs)
+		}
+		cs = tabs[0:x]
+	} else if 0 < wr.Indent {
+		x := depth*wr.Indent + 1
+		if len(spaces) < x {
+			x = len(spaces)
+		}
+		is = spaces[0:x]
+		x = (depth+1)*wr.Indent + 1
+		if len(spaces) < x {
+			x = len(spaces)
+		}
+		cs = spaces[0:x]
+	}
+	return
+}
+
+func (wr *Writer) appendJSON5Array(n []any, depth int) {
+	if len(n) == 0 {
+		wr.buf = append(wr.buf, "[]"...)
+		return
+	}
+	is, cs := wr.json5Indents(depth)
+	wr.buf = append(wr.buf, '[')
+	for i, m := range n {
+		if 0 < i {
+			wr.buf = append(wr.buf, ',')
+		}
+		wr.buf = append(wr.buf, cs...)
+		wr.cycles.Index(i)
+		wr.appendJSON5(m, depth+1)
+		wr.cycles.Pop()
+	}
+	wr.buf = append(wr.buf, is...)
+	wr.buf = append(wr.buf, ']')
+}
+
+func (wr *Writer) appendJSON5Object(members []gen.Member, depth int) {
+	is, cs := wr.json5Indents(depth)
+	empty := true
+	wr.buf = append(wr.buf, '{')
+	for _, m := range members {
+		switch tm := m.Value.(type) {
+		case nil:
+			if wr.OmitNil {
+				continue
+			}
+		case string:
+			if wr.OmitEmpty && len(tm) == 0 {
+				continue
+			}
+		case gen.String:
+			if wr.OmitEmpty && len(tm) == 0 {
+				continue
+			}
+		case gen.Object:
+			if wr.OmitEmpty && len(tm) == 0 {
+				continue
+			}
+		case gen.Array:
+			if wr.OmitEmpty && len(tm) == 0 {
+				continue
+			}
+		case map[string]any:
+			if wr.OmitEmpty && len(tm) == 0 {
+				continue
+			}
+		case []any:
+			if wr.OmitEmpty && len(tm) == 0 {
+				continue
+			}
+		case *gen.Ordered:
+			if wr.OmitEmpty && tm.Len() == 0 {
+				continue
+			}
+		}
+		if !empty {
+			wr.buf = append(wr.buf, ',')
+		}
+		empty = false
+		wr.buf = append(wr.buf, cs...)
+		if IsJSON5Identifier(m.Key) {
+			wr.buf = append(wr.buf, m.Key...)
+		} else {
+			wr.buf = appendJSON5String(wr.buf, m.Key)
+		}
+		wr.buf = append(wr.buf, ':')
+		if 0 < len(cs) {
+			wr.buf = append(wr.buf, ' ')
+		}
+		wr.cycles.Key(m.Key)
+		wr.appendJSON5(m.Value, depth+1)
+		wr.cycles.Pop()
+	}
+	if !empty {
+		wr.buf = append(wr.buf, is...)
+	}
+	wr.buf = append(wr.buf, '}')
+}
+
+const json5Hex = "0123456789abcdef"
+
+func appendJSON5Float(buf []byte, f float64, bits int) []byte {
+	switch {
+	case math.IsNaN(f):
+		return append(buf, "NaN"...)
+	case math.IsInf(f, 1):
+		return append(buf, "Infinity"...)
+	case math.IsInf(f, -1):
+		return append(buf, "-Infinity"...)
+	}
+	return strconv.AppendFloat(buf, f, 'g', -1, bits)
+}
+
+// appendJSON5String appends a quoted string. Double quotes are used unless
+// the string contains more double quotes than single quotes.
+func appendJSON5String(buf []byte, s string) []byte {
+	q := byte('"')
+	if strings.Count(s, `"`) > strings.Count(s, "'") {
+		q = '\''
+	}
+	buf = append(buf, q)
+	start := 0
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+		if 0x20 <= b && b != q && b != '\\' && b != 0xe2 {
+			continue
+		}
+		if b == 0xe2 { // check for U+2028 and U+2029
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r != '\u2028' && r != '\u2029' {
+				i += size - 1
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, `\u202`...)
+			buf = append(buf, json5Hex[r&0x0f])
+			i += size - 1
+			start = i + 1
+			continue
+		}
+		buf = append(buf, s[start:i]...)
+		switch b {
+		case q, '\\':
+			buf = append(buf, '\\', b)
+		case '\b':
+			buf = append(buf, `\b`...)
+		case '\f':
+			buf = append(buf, `\f`...)
+		case '\n':
+			buf = append(buf, `\n`...)
+		case '\r':
+			buf = append(buf, `\r`...)
+		case '\t':
+			buf = append(buf, `\t`...)
+		case '\v':
+			buf = append(buf, `\v`...)
+		case 0:
+			if i+1 < len(s) && '0' <= s[i+1] && s[i+1] <= '9' {
+				buf = append(buf, `\x00`...)
+			} else {
+				buf = append(buf, `\0`...)
+			}
+		default:
+			buf = append(buf, `\x`...)
+			buf = append(buf, json5Hex[b>>4], json5Hex[b&0x0f])
+		}
+		start = i + 1
+	}
+	buf = append(buf, s[start:]...)
+
+	return append(buf, q)
+}
@@ -41,6 +41,10 @@ const (
 	escU         = 'U'
 	commentStart = 'K'
 	commentEnd   = 'L'
+	blockStart   = 'M'
+	blockStar    = 'P'
+	blockEnd     = 'Q'
+	blockNewline = 'S'
 	charErr      = '.'
 
 	//   0123456789abcdef0123456789abcdef
@@ -206,7 +210,7 @@ const (
 	//   0123456789abcdef0123456789abcdef
 	spaceMap = "" +
 		".........ab..a.................." + // 0x00
-		"a...........a..................." + // 0x20
+		"a...........a..c................" + // 0x20
 		"................................" + // 0x40
 		"................................" + // 0x60
 		"................................" + // 0x80
@@ -216,7 +220,7 @@ const (
 	//   0123456789abcdef0123456789abcdef
 	commentStartMap = "" +
 		"................................" + // 0x00
-		"...............K................" + // 0x20
+		"..........M....K................" + // 0x20
 		"................................" + // 0x40
 		"................................" + // 0x60
 		"................................" + // 0x80
@@ -233,4 +237,24 @@ const (
 		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" + // 0xa0
 		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" + // 0xc0
 		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" //   0xe0)
+	//   0123456789abcdef0123456789abcdef
+	blockCommentMap = "" +
+		"aaaaaaaaaabaaaaaaaaaaaaaaaaaaaaa" + // 0x00
+		"aaaaaaaaaaPaaaaaaaaaaaaaaaaaaaaa" + // 0x20
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" + // 0x40
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" + // 0x60
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" + // 0x80
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" + // 0xa0
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" + // 0xc0
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" //   0xe0
+	//   0123456789abcdef0123456789abcdef
+	blockStarMap = "" +
+		"MMMMMMMMMMSMMMMMMMMMMMMMMMMMMMMM" + // 0x00
+		"MMMMMMMMMMPMMMMQMMMMMMMMMMMMMMMM" + // 0x20
+		"MMMMMMMMMMMMMMMMMMMMMMMMMMMMMMMM" + // 0x40
+		"MMMMMMMMMMMMMMMMMMMMMMMMMMMMMMMM" + // 0x60
+		"MMMMMMMMMMMMMMMMMMMMMMMMMMMMMMMM" + // 0x80
+		"MMMMMMMMMMMMMMMMMMMMMMMMMMMMMMMM" + // 0xa0
+		"MMMMMMMMMMMMMMMMMMMMMMMMMMMMMMMM" + // 0xc0
+		"MMMMMMMMMMMMMMMMMMMMMMMMMMMMMMMM" //   0xe0
 )
@@ -1,4 +1,3 @@
-
 // Copyright (c) 2020, Peter Ohler, All rights reserved.
 
 package sen
@@ -10,6 +9,7 @@ import (
 	"io"
 	"unicode/utf8"
 
+	"github.com/ohler55/ojg"
 	"github.com/ohler55/ojg/alt"
 	"github.com/ohler55/ojg/gen"
 	"github.com/ohler55/ojg/oj"
@@ -34,25 +34,32 @@ type TokenFunc func(args ...any) any
 // Parser is a reusable JSON parser. It can be reused for multiple parsings
 // which allows buffer reuse for a performance advantage.
 type Parser struct {
-	tmp        []byte // used for numbers and strings
-	runeBytes  []byte
-	stack      []any
-	starts     []int
-	maps       []map[string]any
-	cb         func(any)
-	resultChan chan any
-	line       int
-	noff       int // Offset of last newline from start of buf. Can be negative when using a reader.
-	ri         int // read index for null, false, and true
-	mi         int
-	num        gen.Number
-	rn         rune
-	result     any
-	mode       string
-	lastKey    gen.Key
-	lastStrKey gen.Key
-	tokenFuncs map[string]TokenFunc
-	quoteDelim byte
+	tmp          []byte // used for numbers and strings
+	runeBytes    []byte
+	stack        []any
+	starts       []int
+	maps         []map[string]any
+	index        []gen.OrderedIndex // key indexes of the open *gen.Ordered objects
+	cb           func(any)
+	resultChan   chan any
+	line         int
+	noff         int // Offset of last newline from start of buf. Can be negative when using a reader.
+	ri           int // read index for null, false, and true
+	mi           int
+	num          gen.Number
+	rn           rune
+	discover     ojg.Discover
+	result       any
+	mode         string
+	lastKey      gen.Key
+	lastStrKey   gen.Key
+	tokenFuncs   map[string]TokenFunc
+	quoteDelim   byte
+	afterComment string
+	numStart     int
+	boff         int // offset of buf in the input
+	markPos      ojg.Position
+	inFunc       int
 
 	// Reuse maps. Previously returned maps will no longer be valid or rather
 	// could be modified during parsing.
@@ -61,6 +68,29 @@ type Parser struct {
 	// OnlyOne returns an error if more than one JSON is in the string or stream.
 	OnlyOne bool
 
+	// NoDuplicates returns an error if an object has more than one member
+	// with the same key.
+	NoDuplicates bool
+
+	// Ordered if true produces *gen.Ordered objects that keep members in the
+	// order they appear instead of maps.
+	Ordered bool
+
+	// NumConv if not nil is called with the text of each number and the
+	// value returned is used in place of the int64, float64, or json.Number
+	// the parser would otherwise produce. Use gen.JSONNumber or
+	// gen.BigNumber to decode numbers without loss or a function that calls
+	// a decimal constructor.
+	NumConv func(text string) any
+
+	// Spans if not nil records the source span of each value and key of the
+	// most recently parsed document.
+	Spans *ojg.Spans
+
+	// Limits are resource limits for parsing untrusted input. Exceeding a
+	// limit returns an *ojg.LimitError.
+	Limits ojg.Limits
+
 	plus bool
 }
 
@@ -94,10 +124,16 @@ func (p *Parser) MustParse(buf []byte, args ...any) any {
 }
 
 // Parse a SEN string in to simple types. An error is returned if not valid SEN.
+// Arguments can be a callback function, a chan any, or an ojg.Discover
+// value which is described in the package Parse function.
 func (p *Parser) Parse(buf []byte, args ...any) (any, error) {
+	if err := p.Limits.CheckBytes(buf); err != nil {
+		return nil, err
+	}
 	p.cb = nil
 	p.resultChan = nil
 	p.OnlyOne = true
+	p.discover = ojg.DiscoverOff
 	for _, a := range args {
 		switch ta := a.(type) {
 		case func(any) bool:
@@ -110,6 +146,8 @@ func (p *Parser) Parse(buf []byte, args ...any) (any, error) {
 			p.resultChan = ta
 			p.OnlyOne = false
 			p.Reuse = false
+		case ojg.Discover:
+			p.discover = ta
 		default:
 			return nil, fmt.Errorf("a %T is not a valid option type", a)
 		}
@@ -123,16 +161,25 @@ func (p *Parser) Parse(buf []byte, args ...any) (any, error) {
 		p.stack = p.stack[:0]
 		p.tmp = p.tmp[:0]
 		p.starts = p.starts[:0]
+		p.index = p.index[:0]
 	}
 	p.result = nil
 	p.noff = -1
 	p.line = 1
 	p.mode = valueMap
 	p.mi = 0
+	p.num.Conv = p.NumConv
+	p.boff = 0
+	p.inFunc = 0
+	if p.Spans != nil {
+		p.Spans.Reset()
+	}
 	var err error
-	// Skip BOM if present.
-	if 3 < len(buf) && buf[0] == 0xEF {
+	if p.discover == ojg.DiscoverAny || p.discover == ojg.DiscoverSets {
+		p.discover.Each(buf, true, p.discovered)
+	} else if 3 < len(buf) && buf[0] == 0xEF { // Skip BOM if present.
 		if buf[1] == 0xBB && buf[2] == 0xBF {
+			p.boff = 3
 			err = p.parseBuffer(buf[3:], true)
 		} else {
 			return nil, fmt.Errorf("expected BOM at 1:3")
@@ -163,6 +210,7 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data any, err error) {
 	p.cb = nil
 	p.resultChan = nil
 	p.OnlyOne = true
+	p.discover = ojg.DiscoverOff
 	for _, a := range args {
 		switch ta := a.(type) {
 		case func(any) bool:
@@ -175,6 +223,8 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data any, err error) {
 			p.resultChan = ta
 			p.OnlyOne = false
 			p.Reuse = false
+		case ojg.Discover:
+			p.discover = ta
 		default:
 			return nil, fmt.Errorf("a %T is not a valid option type", a)
 		}
@@ -188,16 +238,29 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data any, err error) {
 		p.stack = p.stack[:0]
 		p.tmp = p.tmp[:0]
 		p.starts = p.starts[:0]
+		p.index = p.index[:0]
 	}
 	p.result = nil
 	p.noff = -1
 	p.line = 1
 	p.mi = 0
+	p.num.Conv = p.NumConv
+	p.boff = 0
+	p.inFunc = 0
+	if p.Spans != nil {
+		p.Spans.Reset()
+	}
+	if p.discover == ojg.DiscoverAny || p.discover == ojg.DiscoverSets {
+		err = p.discover.Read(r, true, p.Limits.MaxBytes, p.discovered)
+		data = p.result
+		return
+	}
 	buf := make([]byte, readBufSize)
 	eof := false
 	var cnt int
 	cnt, err = r.Read(buf)
 	buf = buf[:cnt]
+	total := cnt
 	p.mode = valueMap
 	if err != nil {
 		if !errors.Is(err, io.EOF) {
@@ -209,13 +272,22 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data any, err error) {
 	// Skip BOM if present.
 	if 3 < len(buf) && buf[0] == 0xEF && buf[1] == 0xBB && buf[2] == 0xBF {
 		skip = 3
+		p.boff = 3
 	}
 	for {
+		over := 0 < p.Limits.MaxBytes && p.Limits.MaxBytes < total
+		if over {
+			buf = buf[:len(buf)-(total-p.Limits.MaxBytes)]
+			eof = false
+		}
 		if 0 < skip {
 			err = p.parseBuffer(buf[skip:], eof)
 		} else {
 			err = p.parseBuffer(buf, eof)
 		}
+		if err == nil && over {
+			err = p.limitError(len(buf)-skip, ojg.BytesLimit, p.Limits.MaxBytes)
+		}
 		if err != nil {
 			p.stack = p.stack[:cap(p.stack)]
 			for i := len(p.stack) - 1; 0 <= i; i-- {
@@ -225,6 +297,9 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data any, err error) {
 
 			return
 		}
+		// Offsets and columns in the next buffer continue from this one.
+		p.boff += len(buf) - skip
+		p.noff -= len(buf) - skip
 		skip = 0
 		if eof {
 			break
@@ -232,6 +307,7 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data any, err error) {
 		buf = buf[:cap(buf)]
 		cnt, err = r.Read(buf)
 		buf = buf[:cnt]
+		total += cnt
 		if err != nil {
 			if !errors.Is(err, io.EOF) {
 				return
@@ -263,7 +339,11 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 			off += i
 			continue
 		case tokenStart:
+			if err = p.checkMembers(off); err != nil {
+				return
+			}
 			start := off
+			p.spanMark(off)
 			for i, b = range buf[off:] {
 				if tokenMap[b] != tokenOk {
 					break
@@ -277,6 +357,9 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 				continue
 			}
 			if b == '(' {
+				if 0 < p.Limits.MaxDepth && p.Limits.MaxDepth <= depth {
+					return p.limitError(off, ojg.DepthLimit, p.Limits.MaxDepth)
+				}
 				tf := TokenFunc(defaultTokenFunc)
 				if p.tokenFuncs != nil {
 					if f := p.tokenFuncs[string(buf[start:off])]; f != nil {
@@ -285,11 +368,14 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 				}
 				p.starts = append(p.starts, len(p.stack))
 				p.stack = append(p.stack, tf)
+				p.spanFunc()
 				depth++
 				p.mode = valueMap
 				continue
 			}
-			p.addTokenWith(string(buf[start:off]), off)
+			if err = p.addTokenWith(string(buf[start:off]), off); err != nil {
+				return
+			}
 			off--
 		case strOk:
 			p.tmp = append(p.tmp, b)
@@ -302,14 +388,29 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 			if 256 < len(p.mode) {
 				switch p.mode[256] {
 				case 'n':
-					if err = p.add(p.num.AsNum(), off); err != nil {
+					if err = p.addNumber(off); err != nil {
 						return
 					}
 				case 't':
-					p.addToken(off)
+					if err = p.addToken(off); err != nil {
+						return
+					}
 				}
 			}
+			if err = p.checkMembers(off); err != nil {
+				return
+			}
+			if 0 < p.Limits.MaxDepth && p.Limits.MaxDepth <= depth {
+				return p.limitError(off, ojg.DepthLimit, p.Limits.MaxDepth)
+			}
+			p.spanBegin(off)
 			p.starts = append(p.starts, -1)
+			if p.Ordered {
+				p.stack = append(p.stack, &gen.Ordered{})
+				p.index = append(p.index, gen.OrderedIndex{})
+				depth++
+				continue
+			}
 			var m map[string]any
 			if p.Reuse {
 				if p.mi < len(p.maps) {
@@ -336,22 +437,36 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 			if 256 < len(p.mode) {
 				switch p.mode[256] {
 				case 'n':
-					if err = p.add(p.num.AsNum(), off); err != nil {
+					if err = p.addNumber(off); err != nil {
 						return
 					}
 				case 't':
-					p.addToken(off)
+					if err = p.addToken(off); err != nil {
+						return
+					}
 				}
 			}
+			if _, ok := p.stack[len(p.stack)-1].(gen.Key); ok {
+				return p.newError(off, "expected a value")
+			}
 			p.starts = p.starts[0:depth]
 			n := p.stack[len(p.stack)-1]
 			p.stack = p.stack[:len(p.stack)-1]
+			if _, ok := n.(*gen.Ordered); ok {
+				p.index = p.index[:len(p.index)-1]
+			}
 			// TBD maybe separarte add function or check here for time options
 			if err = p.add(n, off); err != nil {
 				return
 			}
+			p.spanEnd(off + 1)
 		case valDigit:
+			if err = p.checkMembers(off); err != nil {
+				return
+			}
+			p.spanBegin(off)
 			p.num.Reset()
+			p.numStart = off
 			p.mode = digitMap
 			p.num.I = uint64(b - '0')
 			for i, b = range buf[off+1:] {
@@ -370,6 +485,10 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 			}
 			off += i
 		case valQuote:
+			if err = p.checkMembers(off); err != nil {
+				return
+			}
+			p.spanMark(off)
 			p.quoteDelim = b
 			start := off + 1
 			if len(buf) <= start {
@@ -385,7 +504,9 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 			off += i
 			if b == p.quoteDelim {
 				off++
-				p.addString(string(buf[start:off]), off)
+				if err = p.addString(string(buf[start:off]), off); err != nil {
+					return
+				}
 			} else {
 				p.tmp = p.tmp[:0]
 				p.tmp = append(p.tmp, buf[start:off+1]...)
@@ -393,7 +514,7 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 				continue
 			}
 		case numSpc:
-			if err = p.add(p.num.AsNum(), off); err != nil {
+			if err = p.addNumber(off); err != nil {
 				return
 			}
 		case strSlash:
@@ -404,12 +525,22 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 			p.mode = stringMap
 			continue
 		case val0:
+			if err = p.checkMembers(off); err != nil {
+				return
+			}
+			p.spanBegin(off)
 			p.mode = zeroMap
 			p.num.Reset()
+			p.numStart = off
 		case valNeg:
+			if err = p.checkMembers(off); err != nil {
+				return
+			}
+			p.spanBegin(off)
 			p.mode = negMap
 			p.num.Reset()
 			p.num.Neg = true
+			p.numStart = off
 			continue
 		case escU:
 			p.mode = uMap
@@ -420,13 +551,22 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 			if 256 < len(p.mode) {
 				switch p.mode[256] {
 				case 'n':
-					if err = p.add(p.num.AsNum(), off); err != nil {
+					if err = p.addNumber(off); err != nil {
 						return
 					}
 				case 't':
-					p.addToken(off)
+					if err = p.addToken(off); err != nil {
+						return
+					}
 				}
 			}
+			if err = p.checkMembers(off); err != nil {
+				return
+			}
+			if 0 < p.Limits.MaxDepth && p.Limits.MaxDepth <= depth {
+				return p.limitError(off, ojg.DepthLimit, p.Limits.MaxDepth)
+			}
+			p.spanBegin(off)
 			p.starts = append(p.starts, len(p.stack))
 			p.stack = append(p.stack, emptySlice)
 			p.mode = valueMap
@@ -441,10 +581,13 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 			// which are all over 256 long.
 			switch p.mode[256] {
 			case 'n':
-				// can not fail appending to an array
-				_ = p.add(p.num.AsNum(), off)
+				if err = p.addNumber(off); err != nil {
+					return
+				}
 			case 't':
-				p.addToken(off)
+				if err = p.addToken(off); err != nil {
+					return
+				}
 			}
 			start := p.starts[len(p.starts)-1] + 1
 			p.starts = p.starts[:len(p.starts)-1]
@@ -455,6 +598,7 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 			if err = p.add(n, off); err != nil {
 				return
 			}
+			p.spanEnd(off + 1)
 			p.mode = valueMap
 		case numDot:
 			if 0 < len(p.num.BigBuf) {
@@ -490,12 +634,18 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 		case tokenOk:
 			p.tmp = append(p.tmp, b)
 		case tokenSpc:
-			p.addToken(off)
+			if err = p.addToken(off); err != nil {
+				return
+			}
 		case tokenColon:
-			p.addToken(off)
+			if err = p.addToken(off); err != nil {
+				return
+			}
 			p.mode = valueMap
 		case tokenNlColon:
-			p.addToken(off)
+			if err = p.addToken(off); err != nil {
+				return
+			}
 			p.line++
 			p.noff = off
 			for i, b = range buf[off+1:] {
@@ -513,7 +663,9 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 			p.lastStrKey = p.lastKey
 		case strQuote:
 			if b == p.quoteDelim {
-				p.addString(string(p.tmp), off)
+				if err = p.addString(string(p.tmp), off); err != nil {
+					return
+				}
 			} else {
 				p.tmp = append(p.tmp, b)
 			}
@@ -525,7 +677,7 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 			p.num.AddDigit(b)
 			p.mode = digitMap
 		case numNewline:
-			if err = p.add(p.num.AsNum(), off); err != nil {
+			if err = p.addNumber(off); err != nil {
 				return
 			}
 			p.line++
@@ -542,6 +694,9 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 			if b == '-' {
 				p.num.NegExp = true
 			}
+			if 0 < len(p.num.BigBuf) {
+				p.num.BigBuf = append(p.num.BigBuf, b)
+			}
 			continue
 		case expDigit:
 			p.num.AddExp(b)
@@ -566,22 +721,42 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 			}
 			continue
 		case valSlash:
+			p.afterComment = valueMap
 			if 256 < len(p.mode) {
 				switch p.mode[256] {
 				case 'n':
-					if err = p.add(p.num.AsNum(), off); err != nil {
+					if err = p.addNumber(off); err != nil {
 						return
 					}
 				case 't':
-					p.addToken(off)
+					if err = p.addToken(off); err != nil {
+						return
+					}
+				case 's':
+					p.afterComment = spaceMap
 				}
 			}
 			p.mode = commentStartMap
 		case commentStart:
 			p.mode = commentMap
 		case commentEnd:
-			p.mode = valueMap
+			p.line++
+			p.noff = off
+			p.mode = p.afterComment
+		case blockStart:
+			p.mode = blockCommentMap
+		case blockStar:
+			p.mode = blockStarMap
+		case blockEnd:
+			p.mode = p.afterComment
+		case blockNewline:
+			p.line++
+			p.noff = off
+			p.mode = blockCommentMap
 		case openParen:
+			if 0 < p.Limits.MaxDepth && p.Limits.MaxDepth <= depth {
+				return p.limitError(off, ojg.DepthLimit, p.Limits.MaxDepth)
+			}
 			tf := TokenFunc(defaultTokenFunc)
 			if p.tokenFuncs != nil {
 				if f := p.tokenFuncs[string(p.tmp)]; f != nil {
@@ -590,6 +765,7 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 			}
 			p.starts = append(p.starts, len(p.stack))
 			p.stack = append(p.stack, tf)
+			p.spanFunc()
 			p.mode = valueMap
 			depth++
 			continue
@@ -602,10 +778,13 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 			// which are all over 256 long.
 			switch p.mode[256] {
 			case 'n':
-				// can not fail appending to a function argument set
-				_ = p.add(p.num.AsNum(), off)
+				if err = p.addNumber(off); err != nil {
+					return
+				}
 			case 't':
-				p.addToken(off)
+				if err = p.addToken(off); err != nil {
+					return
+				}
 			}
 			start := p.starts[len(p.starts)-1] + 1
 			p.starts = p.starts[:len(p.starts)-1]
@@ -615,12 +794,15 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 			}
 			v := tf(p.stack[start:]...)
 			p.stack = p.stack[0 : start-1]
-			_ = p.add(v, off)
+			if err = p.add(v, off); err != nil {
+				return
+			}
+			p.spanFuncEnd(off + 1)
 			p.mode = valueMap
 		case charErr:
 			return p.byteError(off, p.mode, b, bytes.Runes(buf[off:])[0])
 		}
-		if depth == 0 && 256 < len(p.mode) && p.mode[256] == 'v' {
+		if depth == 0 && 256 < len(p.mode) && p.mode[256] == 'v' && 0 < len(p.stack) {
 			if p.cb == nil && p.resultChan == nil {
 				p.result = p.stack[0]
 			} else {
@@ -644,12 +826,17 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 		if 0 < len(p.starts) {
 			return p.newError(off, "not closed")
 		}
+		if p.mode == commentMap { // a line comment can end the input
+			p.mode = p.afterComment
+		}
 		if len(p.mode) == 256 { // valid finishing maps are one byte longer
 			return p.newError(off, "incomplete JSON")
 		}
 		switch p.mode[256] {
 		case 'n': // number
-			_ = p.add(p.num.AsNum(), off)
+			if err = p.addNumber(off); err != nil {
+				return
+			}
 			if p.cb == nil && p.resultChan == nil {
 				p.result = p.stack[0]
 			} else {
@@ -661,7 +848,23 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 				}
 			}
 		case 't': // token
-			p.addToken(off)
+			if err = p.addToken(off); err != nil {
+				return
+			}
+			if p.cb == nil && p.resultChan == nil {
+				p.result = p.stack[0]
+			} else {
+				if p.cb != nil {
+					p.cb(p.stack[0])
+				}
+				if p.resultChan != nil {
+					p.resultChan <- p.stack[0]
+				}
+			}
+		case 'v': // value followed by a line comment
+			if len(p.stack) == 0 {
+				break
+			}
 			if p.cb == nil && p.resultChan == nil {
 				p.result = p.stack[0]
 			} else {
@@ -674,7 +877,7 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 			}
 		}
 	}
-	return nil
+	return p.checkPending(len(buf))
 }
 
 // only for non-string
@@ -683,8 +886,8 @@ func (p *Parser) add(n any, off int) error {
 	if 0 < len(p.starts) {
 		if p.starts[len(p.starts)-1] == -1 { // object
 			if k, ok := p.stack[len(p.stack)-1].(gen.Key); ok {
-				obj, _ := p.stack[len(p.stack)-2].(map[string]any)
-				obj[string(k)] = n
+				obj := p.stack[len(p.stack)-2]
+				p.setMember(obj, string(k), n)
 				p.lastKey = k
 				p.stack = p.stack[0 : len(p.stack)-1]
 			} else {
@@ -693,74 +896,60 @@ func (p *Parser) add(n any, off int) error {
 		} else { // array
 			p.stack = append(p.stack, n)
 		}
-	} else {
-		p.stack = append(p.stack, n)
+		return nil
 	}
+	p.stack = append(p.stack, n)
+
 	return nil
 }
 
-func (p *Parser) addToken(off int) {
-	s := string(p.tmp)
-	p.mode = valueMap
-	if 0 < len(p.starts) {
-		if p.starts[len(p.starts)-1] == -1 { // object
-			if k, ok := p.stack[len(p.stack)-1].(gen.Key); ok {
-				obj, _ := p.stack[len(p.stack)-2].(map[string]any)
-				switch s {
-				case "null":
-					obj[string(k)] = nil
-				case "true":
-					obj[string(k)] = true
-				case "false":
-					obj[string(k)] = false
-				default:
-					obj[string(k)] = s
-				}
-				p.lastKey = k
-				p.stack = p.stack[0 : len(p.stack)-1]
-			} else {
-				p.stack = append(p.stack, gen.Key(s))
-				p.mode = colonMap
-			}
-			return
-		}
+func (p *Parser) addNumber(off int) error {
+	if 0 < p.Limits.MaxNumberLen && p.Limits.MaxNumberLen < off-p.numStart {
+		return p.limitError(p.numStart, ojg.NumberLimit, p.Limits.MaxNumberLen)
 	}
-	// Array or just a value
-	switch s {
-	case "null":
-		p.stack = append(p.stack, nil)
-	case "true":
-		p.stack = append(p.stack, true)
-	case "false":
-		p.stack = append(p.stack, false)
-	default:
-		p.stack = append(p.stack, s)
+	if err := p.add(p.num.AsNum(), off); err != nil {
+		return err
 	}
+	p.spanEnd(off)
+	return nil
+}
+
+func (p *Parser) addToken(off int) error {
+	return p.addTokenWith(string(p.tmp), off)
 }
 
-func (p *Parser) addTokenWith(s string, off int) {
+func (p *Parser) addTokenWith(s string, off int) error {
+	if 0 < p.Limits.MaxStringLen && p.Limits.MaxStringLen < len(s) {
+		return p.limitError(off, ojg.StringLimit, p.Limits.MaxStringLen)
+	}
 	p.mode = valueMap
 	if 0 < len(p.starts) {
 		if p.starts[len(p.starts)-1] == -1 { // object
 			if k, ok := p.stack[len(p.stack)-1].(gen.Key); ok {
-				obj, _ := p.stack[len(p.stack)-2].(map[string]any)
+				obj := p.stack[len(p.stack)-2]
 				switch s {
 				case "null":
-					obj[string(k)] = nil
+					p.setMember(obj, string(k), nil)
 				case "true":
-					obj[string(k)] = true
+					p.setMember(obj, string(k), true)
 				case "false":
-					obj[string(k)] = false
+					p.setMember(obj, string(k), false)
 				default:
-					obj[string(k)] = s
+					p.setMember(obj, string(k), s)
 				}
 				p.lastKey = k
 				p.stack = p.stack[0 : len(p.stack)-1]
+				p.spanValue(off)
+				return nil
 			} else {
+				if p.NoDuplicates && p.hasKey(s) {
+					return p.keyError(off, s)
+				}
 				p.stack = append(p.stack, gen.Key(s))
+				p.spanKey(s, off)
 				p.mode = colonMap
 			}
-			return
+			return nil
 		}
 	}
 	// Array or just a value
@@ -774,30 +963,48 @@ func (p *Parser) addTokenWith(s string, off int) {
 	default:
 		p.stack = append(p.stack, s)
 	}
+	p.spanValue(off)
+	return nil
 }
 
-func (p *Parser) addString(s string, off int) {
+func (p *Parser) addString(s string, off int) error {
+	if 0 < p.Limits.MaxStringLen && p.Limits.MaxStringLen < len(s) {
+		return p.limitError(off, ojg.StringLimit, p.Limits.MaxStringLen)
+	}
 	p.mode = valueMap
 	if 0 < len(p.starts) && p.starts[len(p.starts)-1] == -1 { // object
 		if p.plus {
-			obj, _ := p.stack[len(p.stack)-1].(map[string]any)
-			prev := obj[string(p.lastStrKey)].(string)
-			obj[string(p.lastStrKey)] = prev + s
+			obj := p.stack[len(p.stack)-1]
+			var prev string
+			switch to := obj.(type) {
+			case map[string]any:
+				prev, _ = to[string(p.lastStrKey)].(string)
+			case *gen.Ordered:
+				v, _ := to.Get(string(p.lastStrKey))
+				prev, _ = v.(string)
+			}
+			p.setMember(obj, string(p.lastStrKey), prev+s)
 			p.lastStrKey = emptyKey
 			p.plus = false
-			return
+			p.spanExtend(off + 1)
+			return nil
 		}
 		if k, ok := p.stack[len(p.stack)-1].(gen.Key); ok {
-			obj, _ := p.stack[len(p.stack)-2].(map[string]any)
-			obj[string(k)] = s
+			obj := p.stack[len(p.stack)-2]
+			p.setMember(obj, string(k), s)
 			p.lastKey = k
 			p.stack = p.stack[0 : len(p.stack)-1]
-			return
+			p.spanValue(off + 1)
+			return nil
+		}
+		if p.NoDuplicates && p.hasKey(s) {
+			return p.keyError(off, s)
 		}
 		p.stack = append(p.stack, gen.Key(s))
+		p.spanKey(s, off+1)
 		p.mode = colonMap
 
-		return
+		return nil
 	}
 	if p.plus {
 		if 0 < len(p.stack) {
@@ -805,12 +1012,150 @@ func (p *Parser) addString(s string, off int) {
 			p.stack[len(p.stack)-1] = prev + s
 		}
 		p.plus = false
-		return
+		p.spanExtend(off + 1)
+		return nil
 	}
 	// TBD if time option for @ and length is over a certain size try as time
 
 	// Array or just a value
 	p.stack = append(p.stack, s)
+	p.spanValue(off + 1)
+
+	return nil
+}
+
+// spanPos returns the position of off in the input.
+func (p *Parser) spanPos(off int) ojg.Position {
+	return ojg.Position{Offset: p.boff + off, Line: p.line, Column: off - p.noff}
+}
+
+// spanBegin records the start of a value at off if spans are being
+// recorded. Spans are not recorded for token function arguments.
+func (p *Parser) spanBegin(off int) {
+	if p.Spans != nil && p.inFunc == 0 {
+		p.Spans.Begin(p.spanPos(off))
+	}
+}
+
+// spanEnd records the end of the value that ends just before off.
+func (p *Parser) spanEnd(off int) {
+	if p.Spans != nil && p.inFunc == 0 {
+		p.Spans.End(p.spanPos(off))
+	}
+}
+
+// spanMark records the start of a string or token. Whether it is a key or a
+// value is not known until it ends.
+func (p *Parser) spanMark(off int) {
+	if p.Spans != nil {
+		p.markPos = p.spanPos(off)
+	}
+}
+
+// spanValue records the span of a string or token value that started at the
+// mark and ends just before off.
+func (p *Parser) spanValue(off int) {
+	if p.Spans != nil && p.inFunc == 0 {
+		p.Spans.Begin(p.markPos)
+		p.Spans.End(p.spanPos(off))
+	}
+}
+
+// spanKey records a key that started at the mark and ends just before off.
+func (p *Parser) spanKey(key string, off int) {
+	if p.Spans != nil && p.inFunc == 0 {
+		p.Spans.Key(key, p.markPos, p.spanPos(off))
+	}
+}
+
+// spanExtend moves the end of the last string to just before off when
+// strings are joined with a +.
+func (p *Parser) spanExtend(off int) {
+	if p.Spans != nil && p.inFunc == 0 {
+		p.Spans.Extend(p.spanPos(off))
+	}
+}
+
+// spanFunc records the start of a token function value at the mark.
+func (p *Parser) spanFunc() {
+	if p.Spans != nil {
+		if p.inFunc == 0 {
+			p.Spans.Begin(p.markPos)
+		}
+		p.inFunc++
+	}
+}
+
+// spanFuncEnd records the end of a token function value.
+func (p *Parser) spanFuncEnd(off int) {
+	if p.Spans != nil {
+		p.inFunc--
+		if p.inFunc == 0 {
+			p.Spans.End(p.spanPos(off))
+		}
+	}
+}
+
+// checkMembers returns an error if a member that starts at off would give
+// the object or array being parsed more members than the limit. A value
+// that follows a key or a + is not a new member.
+func (p *Parser) checkMembers(off int) error {
+	if p.Limits.MaxMembers <= 0 || len(p.starts) == 0 || p.plus {
+		return nil
+	}
+	var cnt int
+	if start := p.starts[len(p.starts)-1]; 0 <= start {
+		cnt = len(p.stack) - start - 1
+	} else {
+		switch obj := p.stack[len(p.stack)-1].(type) {
+		case map[string]any:
+			cnt = len(obj)
+		case *gen.Ordered:
+			cnt = len(obj.Members)
+		}
+	}
+	if p.Limits.MaxMembers <= cnt {
+		return p.limitError(off, ojg.MembersLimit, p.Limits.MaxMembers)
+	}
+	return nil
+}
+
+// checkPending checks the string, token, or number that continues past the
+// end of the buffer so that none can grow past the limits while reading.
+// The number start is adjusted to be relative to the next buffer.
+func (p *Parser) checkPending(off int) error {
+	if 0 < p.Limits.MaxStringLen && p.Limits.MaxStringLen < len(p.tmp) {
+		return p.limitError(off, ojg.StringLimit, p.Limits.MaxStringLen)
+	}
+	if 0 < p.Limits.MaxNumberLen && p.Limits.MaxNumberLen < len(p.num.BigBuf) {
+		return p.limitError(p.numStart, ojg.NumberLimit, p.Limits.MaxNumberLen)
+	}
+	p.numStart -= off
+	return nil
+}
+
+// hasKey returns true if the object on the top of the stack already has a
+// member with the key.
+func (p *Parser) hasKey(key string) (has bool) {
+	switch obj := p.stack[len(p.stack)-1].(type) {
+	case map[string]any:
+		_, has = obj[key]
+	case *gen.Ordered:
+		has = p.index[len(p.index)-1].Has(obj, key)
+	}
+	return
+}
+
+// setMember sets a member of a map[string]any or an *gen.Ordered. An
+// *gen.Ordered must be the innermost open object so its key index is on the
+// top of the index stack.
+func (p *Parser) setMember(obj any, key string, value any) {
+	switch to := obj.(type) {
+	case map[string]any:
+		to[key] = value
+	case *gen.Ordered:
+		p.index[len(p.index)-1].Set(to, key, value)
+	}
 }
 
 func (p *Parser) newError(off int, format string, args ...any) error {
@@ -821,6 +1166,24 @@ func (p *Parser) newError(off int, format string, args ...any) error {
 	}
 }
 
+func (p *Parser) limitError(off int, limit ojg.Limit, max int) error {
+	return &ojg.LimitError{
+		Limit:  limit,
+		Max:    max,
+		Line:   p.line,
+		Column: off - p.noff,
+	}
+}
+
+func (p *Parser) keyError(off int, key string) error {
+	return &oj.ParseError{
+		Message: fmt.Sprintf("duplicate key '%s'", key),
+		Line:    p.line,
+		Column:  off - p.noff,
+		Key:     key,
+	}
+}
+
 func (p *Parser) byteError(off int, mode string, b byte, r rune) error {
 	err := &oj.ParseError{
 		Line:   p.line,
@@ -850,4 +1213,28 @@ func defaultTokenFunc(args ...any) (result any) {
 		result = args[0]
 	}
 	return
-}
\ No newline at end of file
+}
+
+// discovered parses a candidate document found while discovering and
+// reports whether it was valid and if discovery should stop. If there is no
+// callback or channel then only the first valid document is parsed.
+func (p *Parser) discovered(candidate []byte, pos ojg.Position) (valid, stop bool) {
+	p.stack = p.stack[:0]
+	p.starts = p.starts[:0]
+	p.index = p.index[:0]
+	p.tmp = p.tmp[:0]
+	p.mode = valueMap
+	p.mi = 0
+	p.plus = false
+	p.line = pos.Line
+	p.noff = -pos.Column
+	p.boff = pos.Offset
+	p.inFunc = 0
+	if p.Spans != nil {
+		p.Spans.Abort()
+	}
+	if err := p.parseBuffer(candidate, true); err != nil {
+		return false, false
+	}
+	return true, p.cb == nil && p.resultChan == nil
+}
@@ -3,11 +3,16 @@
 package sen_test
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
 	"testing/iotest"
 
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/oj"
 	"github.com/ohler55/ojg/sen"
 	"github.com/ohler55/ojg/tt"
 )
@@ -178,6 +183,13 @@ func TestParserParseString(t *testing.T) {
 		{src: "[ // a comment\n  true\n]", value: []any{true}},
 		{src: "[\n  null // a comment\n  true\n]", value: []any{nil, true}},
 		{src: "[\n  null / a comment\n  true\n]", expect: "unexpected character ' ' at 2:9"},
+		{src: "// a comment\n[true]", value: []any{true}},
+		{src: "[true] // a comment", value: []any{true}},
+		{src: "123// a comment", value: 123},
+		{src: "/* a\n * comment */ [1 /**/ 2 /***/]", value: []any{1, 2}},
+		{src: "{a: 1 /* comment */ b: 2}", value: map[string]any{"a": 1, "b": 2}},
+		{src: "[ // a comment\n  true /* another\n */ x:\n]", expect: "unexpected character ':' at 3:6"},
+		{src: "[1 /* comment", expect: "not closed at 1:14"},
 	} {
 		if testing.Verbose() {
 			fmt.Printf("... %d: %q\n", i, d.src)
@@ -212,10 +224,10 @@ func TestParserParseReader(t *testing.T) {
 		{src: strings.Repeat(" ", 4093) + "[abc// comment\n]", value: []any{"abc"}},
 		{src: strings.Repeat(" ", 4093) + "[abc{x:1}]", value: []any{"abc", map[string]any{"x": 1}}},
 
-		{src: strings.Repeat(" ", 4094) + "abc#", expect: "unexpected character '#' at 1:2"},
+		{src: strings.Repeat(" ", 4094) + "abc#", expect: "unexpected character '#' at 1:4098"},
 		{src: strings.Repeat(" ", 4094) + "hello\n #", expect: "extra characters after close, '#' at 2:2"},
-		{src: strings.Repeat(" ", 4094) + "hello]", expect: "unexpected array close at 1:4"},
-		{src: strings.Repeat(" ", 4094) + "hello}", expect: "unexpected object close at 1:4"},
+		{src: strings.Repeat(" ", 4094) + "hello]", expect: "unexpected array close at 1:4100"},
+		{src: strings.Repeat(" ", 4094) + "hello}", expect: "unexpected object close at 1:4100"},
 		{src: strings.Repeat(" ", 4095) + `"x"`, value: "x"},
 	} {
 		if testing.Verbose() {
@@ -498,3 +510,119 @@ func TestParserTokenFunc(t *testing.T) {
 	v = sen.MustParseReader(strings.NewReader(src))
 	tt.Equal(t, "abc", v)
 }
+
+func TestParserNoDuplicates(t *testing.T) {
+	p := sen.Parser{NoDuplicates: true}
+	v, err := p.Parse([]byte(`{a:1 b:{a:2} c:[{a:3} {a:4}]}`))
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"a": 1, "b": map[string]any{"a": 2}, "c": []any{map[string]any{"a": 3}, map[string]any{"a": 4}}}, v)
+
+	for i, d := range []struct {
+		src    string
+		key    string
+		line   int
+		column int
+	}{
+		{src: `{a:1 a:2}`, key: "a", line: 1, column: 7},
+		{src: `{a:1 "a":2}`, key: "a", line: 1, column: 8},
+		{src: "{\n  'abc': 1\n  abc: 2\n}", key: "abc", line: 3, column: 6},
+		{src: `{x:{a: true a: false}}`, key: "a", line: 1, column: 14},
+	} {
+		_, err = p.Parse([]byte(d.src))
+		var pe *oj.ParseError
+		tt.Equal(t, true, errors.As(err, &pe), i, ": ", d.src)
+		tt.Equal(t, d.key, pe.Key, i, ": ", d.src)
+		tt.Equal(t, d.line, pe.Line, i, ": ", d.src)
+		tt.Equal(t, d.column, pe.Column, i, ": ", d.src)
+
+		_, err = p.ParseReader(strings.NewReader(d.src))
+		tt.NotNil(t, err, i, ": ", d.src)
+	}
+	p.NoDuplicates = false
+	v, err = p.Parse([]byte(`{a:1 a:2}`))
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"a": 2}, v)
+}
+
+func TestParserDiscover(t *testing.T) {
+	src := `log: {a:1 b:'x'} then [1 2] {bad:} it's done`
+	var results []any
+	cb := func(v any) { results = append(results, v) }
+
+	var p sen.Parser
+	_, err := p.Parse([]byte(src), cb, ojg.DiscoverSets)
+	tt.Nil(t, err)
+	tt.Equal(t, []any{map[string]any{"a": 1, "b": "x"}, []any{1, 2}}, results)
+
+	results = results[:0]
+	_, err = p.ParseReader(strings.NewReader(src), cb, ojg.DiscoverAny)
+	tt.Nil(t, err)
+	tt.Equal(t, []any{map[string]any{"a": 1, "b": "x"}, []any{1, 2}}, results)
+
+	results = results[:0]
+	_, err = p.ParseReader(iotest.OneByteReader(strings.NewReader(src)), cb, ojg.DiscoverAny)
+	tt.Nil(t, err)
+	tt.Equal(t, []any{map[string]any{"a": 1, "b": "x"}, []any{1, 2}}, results)
+
+	v, err := sen.Parse([]byte(src), ojg.DiscoverSets)
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"a": 1, "b": "x"}, v)
+}
+
+func TestParserMissingValue(t *testing.T) {
+	for _, src := range []string{`{a:}`, `{a:1 b:}`, `{a:1 b}`, `[{a:}]`} {
+		_, err := sen.Parse([]byte(src))
+		tt.NotNil(t, err, src)
+	}
+}
+
+func TestParserNumConv(t *testing.T) {
+	p := sen.Parser{NumConv: gen.JSONNumber}
+	v, err := p.Parse([]byte(`{price: 19.990 qty: 12345678901234567890123}`))
+	tt.Nil(t, err)
+	tt.Equal(t, json.Number("19.990"), v.(map[string]any)["price"])
+	tt.Equal(t, "{price:19.990 qty:12345678901234567890123}", sen.String(v, &sen.Options{Sort: true}))
+
+	v, err = p.ParseReader(strings.NewReader(`[1.5e-2]`))
+	tt.Nil(t, err)
+	tt.Equal(t, []any{json.Number("1.5e-2")}, v)
+}
+
+func TestParserLimits(t *testing.T) {
+	for i, d := range []struct {
+		src    string
+		limits ojg.Limits
+		expect string
+	}{
+		{src: `[[[1]]]`, limits: ojg.Limits{MaxDepth: 3}},
+		{src: `{a:{b:{c:{}}}}`, limits: ojg.Limits{MaxDepth: 3}, expect: "maximum depth of 3 exceeded at 1:10"},
+		{src: `[[[f(1)]]]`, limits: ojg.Limits{MaxDepth: 3}, expect: "maximum depth of 3 exceeded at 1:5"},
+		{src: `[abc abcd]`, limits: ojg.Limits{MaxStringLen: 3}, expect: "maximum string length of 3 exceeded at 1:10"},
+		{src: `["abc" 'abcd']`, limits: ojg.Limits{MaxStringLen: 3}, expect: "maximum string length of 3 exceeded at 1:13"},
+		{src: `{abcd:1}`, limits: ojg.Limits{MaxStringLen: 3}, expect: "maximum string length of 3 exceeded at 1:6"},
+		{src: `[1 2 3]`, limits: ojg.Limits{MaxMembers: 3}},
+		{src: `[true 2 x null]`, limits: ojg.Limits{MaxMembers: 3}, expect: "maximum members of 3 exceeded at 1:11"},
+		{src: `[[] {} [] {}]`, limits: ojg.Limits{MaxMembers: 3}, expect: "maximum members of 3 exceeded at 1:11"},
+		{src: `{a:1 b:2 c:"3"}`, limits: ojg.Limits{MaxMembers: 2}, expect: "maximum members of 2 exceeded at 1:10"},
+		{src: "[1\n2] ", limits: ojg.Limits{MaxBytes: 5}, expect: "maximum bytes of 5 exceeded at 2:3"},
+		{src: `[12345 123456]`, limits: ojg.Limits{MaxNumberLen: 5}, expect: "maximum number length of 5 exceeded at 1:8"},
+		{src: `{a:1234567}`, limits: ojg.Limits{MaxNumberLen: 6}, expect: "maximum number length of 6 exceeded at 1:4"},
+	} {
+		p := sen.Parser{Limits: d.limits}
+		_, err := p.Parse([]byte(d.src))
+		_, err2 := p.ParseReader(strings.NewReader(d.src))
+		if len(d.expect) == 0 {
+			tt.Nil(t, err, i, ": ", d.src)
+			tt.Nil(t, err2, i, ": ", d.src)
+			continue
+		}
+		var le *ojg.LimitError
+		tt.Equal(t, true, errors.As(err, &le), i, ": ", d.src)
+		tt.Equal(t, d.expect, err.Error(), i, ": ", d.src)
+		tt.Equal(t, d.expect, err2.Error(), i, ": ", d.src)
+	}
+	_, err := (&sen.Parser{Limits: ojg.Limits{MaxStringLen: 5000}}).ParseReader(strings.NewReader(strings.Repeat("x", 10000)))
+	var le *ojg.LimitError
+	tt.Equal(t, true, errors.As(err, &le))
+	tt.Equal(t, ojg.StringLimit, le.Limit)
+}
@@ -44,6 +44,10 @@ var (
 // only one SEN.
 //
 // A chan argument will be used to deliver parse results.
+//
+// An ojg.Discover argument of ojg.DiscoverAny or ojg.DiscoverSets has the
+// parser search for SEN documents embedded in other text. Each document
+// found is passed to the callback or chan.
 func Parse(buf []byte, args ...any) (any, error) {
 	p, _ := parserPool.Get().(*Parser)
 	p.cb = nil
@@ -11,7 +11,6 @@ import (
 	"unsafe"
 
 	"github.com/ohler55/ojg"
-	"github.com/ohler55/ojg/alt"
 )
 
 func tightDefault(wr *Writer, data any, _ int) {
@@ -32,7 +31,7 @@ func tightDefault(wr *Writer, data any, _ int) {
 		default:
 			// Not much should get here except Map, Complex and un-decomposable
 			// values.
-			dec := alt.Decompose(data, &wr.Options)
+			dec := wr.cycles.Decompose(data, &wr.Options)
 			wr.appendSEN(dec, 0)
 			return
 		}
@@ -45,8 +44,10 @@ func tightArray(wr *Writer, n []any, _ int) {
 	if 0 < len(n) {
 		space := false
 		wr.buf = append(wr.buf, '[')
-		for _, m := range n {
+		for i, m := range n {
+			wr.cycles.Index(i)
 			wr.appendSEN(m, 0)
+			wr.cycles.Pop()
 			if wr.needSep {
 				wr.buf = append(wr.buf, ' ')
 				space = true
@@ -88,7 +89,9 @@ func tightObject(wr *Writer, n map[string]any, _ int) {
 		}
 		wr.buf = ojg.AppendSENString(wr.buf, k, !wr.HTMLUnsafe)
 		wr.buf = append(wr.buf, ':')
+		wr.cycles.Key(k)
 		wr.appendSEN(m, 0)
+		wr.cycles.Pop()
 		wr.buf = append(wr.buf, ' ')
 		comma = true
 	}
@@ -129,7 +132,9 @@ func tightSortObject(wr *Writer, n map[string]any, _ int) {
 		}
 		wr.buf = ojg.AppendSENString(wr.buf, k, !wr.HTMLUnsafe)
 		wr.buf = append(wr.buf, ':')
+		wr.cycles.Key(k)
 		wr.appendSEN(m, 0)
+		wr.cycles.Pop()
 		wr.buf = append(wr.buf, ' ')
 		comma = true
 	}
@@ -141,6 +146,12 @@ func tightSortObject(wr *Writer, n map[string]any, _ int) {
 }
 
 func (wr *Writer) tightStruct(rv reflect.Value, si *sinfo) {
+	if wr.cycles != nil && rv.CanAddr() {
+		if wr.cycled(rv.Addr(), 0) {
+			return
+		}
+		defer wr.cycles.Exit()
+	}
 	if si == nil {
 		si = getSinfo(rv.Interface(), wr.OmitEmpty)
 	}
@@ -186,13 +197,16 @@ func (wr *Writer) tightStruct(rv reflect.Value, si *sinfo) {
 				wr.buf = wr.buf[:len(wr.buf)-fi.keyLen()]
 				continue
 			}
+			wr.cycles.Key(fi.key)
 			wr.appendSEN(v, 0)
+			wr.cycles.Pop()
 			wr.buf = append(wr.buf, ' ')
 			comma = true
 			continue
 		}
 		var fv reflect.Value
 		kind := fi.kind
+		wr.cycles.Key(fi.key)
 	Retry:
 		switch kind {
 		case reflect.Ptr:
@@ -204,12 +218,14 @@ func (wr *Writer) tightStruct(rv reflect.Value, si *sinfo) {
 			}
 			if wr.OmitNil {
 				wr.buf = wr.buf[:len(wr.buf)-fi.keyLen()]
+				wr.cycles.Pop()
 				continue
 			}
 			wr.buf = append(wr.buf, "null"...)
 		case reflect.Interface:
 			if wr.OmitNil && (*[2]uintptr)(unsafe.Pointer(&v))[1] == 0 {
 				wr.buf = wr.buf[:len(wr.buf)-fi.keyLen()]
+				wr.cycles.Pop()
 				continue
 			}
 			wr.appendSEN(v, 0)
@@ -231,6 +247,7 @@ func (wr *Writer) tightStruct(rv reflect.Value, si *sinfo) {
 		default:
 			wr.appendSEN(v, 0)
 		}
+		wr.cycles.Pop()
 		wr.buf = append(wr.buf, ' ')
 		comma = true
 	}
@@ -242,10 +259,17 @@ func (wr *Writer) tightStruct(rv reflect.Value, si *sinfo) {
 }
 
 func (wr *Writer) tightSlice(rv reflect.Value, si *sinfo) {
+	if wr.cycles != nil && rv.Kind() == reflect.Slice {
+		if wr.cycled(rv, 0) {
+			return
+		}
+		defer wr.cycles.Exit()
+	}
 	end := rv.Len()
 	comma := false
 	wr.buf = append(wr.buf, '[')
 	for j := 0; j < end; j++ {
+		wr.cycles.Index(j)
 		rm := rv.Index(j)
 		switch rm.Kind() {
 		case reflect.Struct:
@@ -257,6 +281,7 @@ func (wr *Writer) tightSlice(rv reflect.Value, si *sinfo) {
 		default:
 			wr.appendSEN(rm.Interface(), 0)
 		}
+		wr.cycles.Pop()
 		wr.buf = append(wr.buf, ' ')
 		comma = true
 	}
@@ -268,6 +293,12 @@ func (wr *Writer) tightSlice(rv reflect.Value, si *sinfo) {
 }
 
 func (wr *Writer) tightMap(rv reflect.Value, si *sinfo) {
+	if wr.cycles != nil {
+		if wr.cycled(rv, 0) {
+			return
+		}
+		defer wr.cycles.Exit()
+	}
 	wr.buf = append(wr.buf, '{')
 	keys := rv.MapKeys()
 	if wr.Sort {
@@ -289,32 +320,42 @@ func (wr *Writer) tightMap(rv reflect.Value, si *sinfo) {
 		case reflect.Struct:
 			wr.buf = ojg.AppendSENString(wr.buf, kv.String(), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ':')
+			wr.cycles.Key(kv.String())
 			wr.tightStruct(rm, si)
+			wr.cycles.Pop()
 		case reflect.Slice, reflect.Array:
 			if (wr.OmitNil || wr.OmitEmpty) && rm.Len() == 0 {
 				continue
 			}
 			wr.buf = ojg.AppendSENString(wr.buf, kv.String(), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ':')
+			wr.cycles.Key(kv.String())
 			wr.tightSlice(rm, si)
+			wr.cycles.Pop()
 		case reflect.Map:
 			if (wr.OmitNil || wr.OmitEmpty) && rm.Len() == 0 {
 				continue
 			}
 			wr.buf = ojg.AppendSENString(wr.buf, kv.String(), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ':')
+			wr.cycles.Key(kv.String())
 			wr.tightMap(rm, si)
+			wr.cycles.Pop()
 		case reflect.String:
 			if (wr.OmitNil || wr.OmitEmpty) && rm.Len() == 0 {
 				continue
 			}
 			wr.buf = ojg.AppendSENString(wr.buf, kv.String(), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ':')
+			wr.cycles.Key(kv.String())
 			wr.appendSEN(rm.Interface(), 0)
+			wr.cycles.Pop()
 		default:
 			wr.buf = ojg.AppendSENString(wr.buf, kv.String(), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ':')
+			wr.cycles.Key(kv.String())
 			wr.appendSEN(rm.Interface(), 0)
+			wr.cycles.Pop()
 		}
 		wr.buf = append(wr.buf, ' ')
 		comma = true
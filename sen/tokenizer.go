@@ -36,6 +36,7 @@ type Tokenizer struct {
 	rn        rune
 	mode      string
 	exkey     bool
+	fed       int // bytes fed since Start
 
 	// OnlyOne returns an error if more than one JSON is in the string or stream.
 	OnlyOne bool
@@ -94,6 +95,27 @@ func (t *Tokenizer) Parse(buf []byte, handler oj.TokenHandler) (err error) {
 
 // Load a JSON io.Reader. An error is returned if not valid JSON.
 func (t *Tokenizer) Load(r io.Reader, handler oj.TokenHandler) (err error) {
+	t.Start(handler)
+	buf := make([]byte, readBufSize)
+	for eof := false; !eof; {
+		var cnt int
+		cnt, err = r.Read(buf)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				return
+			}
+			eof = true
+		}
+		if err = t.Feed(buf[:cnt], eof); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Start prepares the Tokenizer to tokenize SEN that is provided in pieces
+// with Feed instead of all at once with Parse or Load.
+func (t *Tokenizer) Start(handler oj.TokenHandler) {
 	t.handler = handler
 	if t.starts == nil {
 		t.tmp = make([]byte, 0, tmpInitSize)
@@ -105,50 +127,36 @@ func (t *Tokenizer) Load(r io.Reader, handler oj.TokenHandler) (err error) {
 	t.noff = -1
 	t.line = 1
 	t.mi = 0
-	buf := make([]byte, readBufSize)
-	eof := false
+	t.mode = valueMap
+	t.exkey = false
+	t.fed = 0
+}
+
+// Feed the next piece of the SEN to a Tokenizer prepared with Start. The
+// handler functions are called for each token completed by the piece. A
+// token can be split across pieces. The last piece must be fed with last
+// set to true so that a final token can be completed and incomplete SEN can
+// be detected. Parse errors are returned as an *oj.ParseError.
+func (t *Tokenizer) Feed(buf []byte, last bool) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			err = ojg.NewError(r)
+			if pe, ok := r.(*oj.ParseError); ok {
+				err = pe
+			} else {
+				err = ojg.NewError(r)
+			}
 		}
 	}()
-	var cnt int
-	cnt, err = r.Read(buf)
-	buf = buf[:cnt]
-	t.mode = valueMap
-	if err != nil {
-		if !errors.Is(err, io.EOF) {
-			return
-		}
-		eof = true
-		err = nil
-	}
-	var skip int
+	first := t.fed == 0
+	t.fed += len(buf)
 	// Skip BOM if present.
-	if 3 < len(buf) && buf[0] == 0xEF && buf[1] == 0xBB && buf[2] == 0xBF {
-		skip = 3
-	}
-	for {
-		if 0 < skip {
-			t.tokenizeBuffer(buf[skip:], eof)
-		} else {
-			t.tokenizeBuffer(buf, eof)
-		}
-		skip = 0
-		if eof {
-			break
-		}
-		buf = buf[:cap(buf)]
-		cnt, err = r.Read(buf)
-		buf = buf[:cnt]
-		if err != nil {
-			if !errors.Is(err, io.EOF) {
-				return
-			}
-			eof = true
-			err = nil
-		}
+	if first && 3 < len(buf) && buf[0] == 0xEF && buf[1] == 0xBB && buf[2] == 0xBF {
+		buf = buf[3:]
 	}
+	t.tokenizeBuffer(buf, last)
+	// The newline offset is relative to the start of the next piece.
+	t.noff -= len(buf)
+
 	return
 }
 
@@ -334,7 +342,7 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) {
 				}
 				t.num.Frac = t.num.Frac*10 + uint64(b-'0')
 				t.num.Div *= 10.0
-				if math.MaxInt64 < t.num.Frac {
+				if gen.BigLimit <= t.num.Div {
 					t.num.FillBig()
 					break
 				}
@@ -431,7 +439,19 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) {
 		case commentStart:
 			t.mode = commentMap
 		case commentEnd:
+			t.line++
+			t.noff = off
+			t.mode = valueMap
+		case blockStart:
+			t.mode = blockCommentMap
+		case blockStar:
+			t.mode = blockStarMap
+		case blockEnd:
 			t.mode = valueMap
+		case blockNewline:
+			t.line++
+			t.noff = off
+			t.mode = blockCommentMap
 		case charErr:
 			t.byteError(off, t.mode, b)
 		}
@@ -102,6 +102,24 @@ func TestTokenizerLoad(t *testing.T) {
 	tt.Equal(t, "[ true null 123 12.3 ] { x: 3 } ", string(h.buf))
 }
 
+func TestTokenizerFeed(t *testing.T) {
+	toker := sen.Tokenizer{}
+	h := testHandler{}
+	src := []byte("\xef\xbb\xbf" + `[true,null,123,12.3]{x:3}`)
+	toker.Start(&h)
+	tt.Nil(t, toker.Feed(src[:10], false))
+	tt.Nil(t, toker.Feed(src[10:18], false))
+	tt.Nil(t, toker.Feed(src[18:], true))
+	tt.Equal(t, "[ true null 123 12.3 ] { x: 3 } ", string(h.buf))
+
+	// Columns are the same as for Parse and not relative to the piece.
+	toker.Start(&h)
+	tt.Nil(t, toker.Feed([]byte("[\n  1,"), false))
+	err := toker.Feed([]byte(" 2"), true)
+	tt.NotNil(t, err)
+	tt.Equal(t, "not closed at 2:8", err.Error())
+}
+
 func TestTokenizerLoadErrRead(t *testing.T) {
 	h := oj.ZeroHandler{}
 	r := tt.ShortReader{Max: 5, Content: []byte("[1, 2, 3, true, false]")}
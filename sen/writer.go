@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math/big"
 	"reflect"
 	"sort"
 	"strconv"
@@ -17,6 +18,7 @@ import (
 
 	"github.com/ohler55/ojg"
 	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/gen"
 )
 
 const (
@@ -37,6 +39,7 @@ type Writer struct {
 	appendString  func(buf []byte, s string, htmlSafe bool) []byte
 	findex        byte
 	needSep       bool
+	cycles        *alt.Cycles
 }
 
 // SEN writes data, SEN encoded. On error, an empty string is returned.
@@ -65,6 +68,7 @@ func (wr *Writer) MustSEN(data any) []byte {
 		wr.buf = wr.buf[:0]
 	}
 	wr.calcFieldsIndex()
+	wr.cycles = alt.NewCycles(&wr.Options)
 	if wr.Color {
 		wr.colorSEN(data, 0)
 	} else {
@@ -119,6 +123,7 @@ func (wr *Writer) MustWrite(w io.Writer, data any) {
 		wr.buf = wr.buf[:0]
 	}
 	wr.calcFieldsIndex()
+	wr.cycles = alt.NewCycles(&wr.Options)
 	if wr.Color {
 		wr.colorSEN(data, 0)
 	} else {
@@ -149,6 +154,29 @@ func (wr *Writer) MustWrite(w io.Writer, data any) {
 	}
 }
 
+// cycled returns true if rv is already being written in which case the
+// replacement called for by the OnCycle option has been written in its
+// place. If false is returned wr.cycles.Exit() must be called once rv has
+// been written.
+func (wr *Writer) cycled(rv reflect.Value, depth int) bool {
+	cv, seen := wr.cycles.Enter(rv)
+	if seen {
+		if wr.Color {
+			// The caller adds the NoColor so the replacement is written
+			// without it.
+			if m, ok := cv.(map[string]any); ok {
+				wr.colorObject(m, depth)
+			} else {
+				wr.buf = append(wr.buf, wr.NullColor...)
+				wr.buf = append(wr.buf, "null"...)
+			}
+		} else {
+			wr.appendSEN(cv, depth)
+		}
+	}
+	return seen
+}
+
 func (wr *Writer) calcFieldsIndex() {
 	wr.findex = 0
 	if wr.NestEmbed {
@@ -202,6 +230,13 @@ func (wr *Writer) appendSEN(data any, depth int) {
 		wr.buf = strconv.AppendFloat(wr.buf, float64(td), 'g', -1, 32)
 	case float64:
 		wr.buf = strconv.AppendFloat(wr.buf, td, 'g', -1, 64)
+	case json.Number, *big.Int, *big.Float:
+		var err error
+		if wr.buf, err = ojg.AppendNumber(wr.buf, td); err != nil {
+			panic(err)
+		}
+	case gen.Big:
+		wr.buf = append(wr.buf, td...)
 
 	case string:
 		wr.buf = wr.appendString(wr.buf, td, !wr.HTMLUnsafe)
@@ -224,12 +259,25 @@ func (wr *Writer) appendSEN(data any, depth int) {
 		wr.buf = wr.AppendTime(wr.buf, td, true)
 
 	case []any:
-		wr.appendArray(wr, td, depth)
-		wr.needSep = false
+		if wr.cycles == nil || !wr.cycled(reflect.ValueOf(td), depth) {
+			wr.appendArray(wr, td, depth)
+			wr.cycles.Exit()
+			wr.needSep = false
+		}
 
 	case map[string]any:
-		wr.appendObject(wr, td, depth)
-		wr.needSep = false
+		if wr.cycles == nil || !wr.cycled(reflect.ValueOf(td), depth) {
+			wr.appendObject(wr, td, depth)
+			wr.cycles.Exit()
+			wr.needSep = false
+		}
+
+	case *gen.Ordered:
+		if wr.cycles == nil || !wr.cycled(reflect.ValueOf(td), depth) {
+			wr.appendOrdered(td, depth)
+			wr.cycles.Exit()
+			wr.needSep = false
+		}
 
 	case alt.Simplifier:
 		wr.appendSEN(td.Simplify(), depth)
@@ -284,7 +332,7 @@ func appendDefault(wr *Writer, data any, depth int) {
 		default:
 			// Not much should get here except Complex and non-decomposable
 			// values.
-			dec := alt.Decompose(data, &wr.Options)
+			dec := wr.cycles.Decompose(data, &wr.Options)
 			wr.appendSEN(dec, depth)
 			return
 		}
@@ -322,9 +370,11 @@ func appendArray(wr *Writer, n []any, depth int) {
 	}
 	if 0 < len(n) {
 		wr.buf = append(wr.buf, '[')
-		for _, m := range n {
+		for i, m := range n {
 			wr.buf = append(wr.buf, cs...)
+			wr.cycles.Index(i)
 			wr.appendSEN(m, d2)
+			wr.cycles.Pop()
 		}
 		wr.buf = append(wr.buf, is...)
 		wr.buf = append(wr.buf, ']')
@@ -383,7 +433,9 @@ func appendObject(wr *Writer, n map[string]any, depth int) {
 		wr.buf = append(wr.buf, cs...)
 		wr.buf = wr.appendString(wr.buf, k, !wr.HTMLUnsafe)
 		wr.buf = append(wr.buf, ": "...)
+		wr.cycles.Key(k)
 		wr.appendSEN(m, d2)
+		wr.cycles.Pop()
 	}
 	wr.buf = append(wr.buf, is...)
 	wr.buf = append(wr.buf, '}')
@@ -445,13 +497,21 @@ func appendSortObject(wr *Writer, n map[string]any, depth int) {
 		wr.buf = append(wr.buf, cs...)
 		wr.buf = wr.appendString(wr.buf, k, !wr.HTMLUnsafe)
 		wr.buf = append(wr.buf, ": "...)
+		wr.cycles.Key(k)
 		wr.appendSEN(m, d2)
+		wr.cycles.Pop()
 	}
 	wr.buf = append(wr.buf, is...)
 	wr.buf = append(wr.buf, '}')
 }
 
 func (wr *Writer) appendStruct(rv reflect.Value, depth int, si *sinfo) {
+	if wr.cycles != nil && rv.CanAddr() {
+		if wr.cycled(rv.Addr(), depth) {
+			return
+		}
+		defer wr.cycles.Exit()
+	}
 	if si == nil {
 		si = getSinfo(rv.Interface(), wr.OmitEmpty)
 	}
@@ -527,7 +587,9 @@ func (wr *Writer) appendStruct(rv reflect.Value, depth int, si *sinfo) {
 				wr.buf = wr.buf[:len(wr.buf)-fi.keyLen()]
 				continue
 			}
+			wr.cycles.Key(fi.key)
 			wr.appendSEN(v, d2)
+			wr.cycles.Pop()
 			indented = false
 			empty = false
 			continue
@@ -535,6 +597,7 @@ func (wr *Writer) appendStruct(rv reflect.Value, depth int, si *sinfo) {
 		indented = false
 		var fv reflect.Value
 		kind := fi.kind
+		wr.cycles.Key(fi.key)
 	Retry:
 		switch kind {
 		case reflect.Ptr:
@@ -547,6 +610,7 @@ func (wr *Writer) appendStruct(rv reflect.Value, depth int, si *sinfo) {
 			if wr.OmitNil {
 				wr.buf = wr.buf[:len(wr.buf)-fi.keyLen()]
 				indented = true
+				wr.cycles.Pop()
 				continue
 			}
 			wr.buf = append(wr.buf, "null"...)
@@ -554,6 +618,7 @@ func (wr *Writer) appendStruct(rv reflect.Value, depth int, si *sinfo) {
 			if wr.OmitNil && (*[2]uintptr)(unsafe.Pointer(&v))[1] == 0 {
 				wr.buf = wr.buf[:len(wr.buf)-fi.keyLen()]
 				indented = true
+				wr.cycles.Pop()
 				continue
 			}
 			wr.appendSEN(v, 0)
@@ -575,6 +640,7 @@ func (wr *Writer) appendStruct(rv reflect.Value, depth int, si *sinfo) {
 		default:
 			wr.appendSEN(v, d2)
 		}
+		wr.cycles.Pop()
 		empty = false
 	}
 	if indented {
@@ -592,6 +658,12 @@ func (wr *Writer) appendSlice(rv reflect.Value, depth int, si *sinfo) {
 		wr.buf = append(wr.buf, "[]"...)
 		return
 	}
+	if wr.cycles != nil && rv.Kind() == reflect.Slice {
+		if wr.cycled(rv, depth) {
+			return
+		}
+		defer wr.cycles.Exit()
+	}
 	d2 := depth + 1
 	var is string
 	var cs string
@@ -621,6 +693,7 @@ func (wr *Writer) appendSlice(rv reflect.Value, depth int, si *sinfo) {
 	wr.buf = append(wr.buf, '[')
 	for j := 0; j < end; j++ {
 		wr.buf = append(wr.buf, cs...)
+		wr.cycles.Index(j)
 		rm := rv.Index(j)
 		switch rm.Kind() {
 		case reflect.Struct:
@@ -632,12 +705,19 @@ func (wr *Writer) appendSlice(rv reflect.Value, depth int, si *sinfo) {
 		default:
 			wr.appendSEN(rm.Interface(), d2)
 		}
+		wr.cycles.Pop()
 	}
 	wr.buf = append(wr.buf, is...)
 	wr.buf = append(wr.buf, ']')
 }
 
 func (wr *Writer) appendMap(rv reflect.Value, depth int, si *sinfo) {
+	if wr.cycles != nil {
+		if wr.cycled(rv, depth) {
+			return
+		}
+		defer wr.cycles.Exit()
+	}
 	d2 := depth + 1
 	var is string
 	var cs string
@@ -686,7 +766,9 @@ func (wr *Writer) appendMap(rv reflect.Value, depth int, si *sinfo) {
 			wr.buf = append(wr.buf, cs...)
 			wr.buf = wr.appendString(wr.buf, kv.String(), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ": "...)
+			wr.cycles.Key(kv.String())
 			wr.appendStruct(rm, d2, si)
+			wr.cycles.Pop()
 		case reflect.Slice, reflect.Array:
 			if (wr.OmitNil || wr.OmitEmpty) && rm.Len() == 0 {
 				continue
@@ -694,7 +776,9 @@ func (wr *Writer) appendMap(rv reflect.Value, depth int, si *sinfo) {
 			wr.buf = append(wr.buf, cs...)
 			wr.buf = wr.appendString(wr.buf, kv.String(), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ": "...)
+			wr.cycles.Key(kv.String())
 			wr.appendSlice(rm, d2, si)
+			wr.cycles.Pop()
 		case reflect.Map:
 			if (wr.OmitNil || wr.OmitEmpty) && rm.Len() == 0 {
 				continue
@@ -702,7 +786,9 @@ func (wr *Writer) appendMap(rv reflect.Value, depth int, si *sinfo) {
 			wr.buf = append(wr.buf, cs...)
 			wr.buf = wr.appendString(wr.buf, kv.String(), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ": "...)
+			wr.cycles.Key(kv.String())
 			wr.appendMap(rm, d2, si)
+			wr.cycles.Pop()
 		case reflect.String:
 			if (wr.OmitEmpty) && rm.Len() == 0 {
 				continue
@@ -710,14 +796,96 @@ func (wr *Writer) appendMap(rv reflect.Value, depth int, si *sinfo) {
 			wr.buf = append(wr.buf, cs...)
 			wr.buf = wr.appendString(wr.buf, kv.String(), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ": "...)
+			wr.cycles.Key(kv.String())
 			wr.appendSEN(rm.Interface(), d2)
+			wr.cycles.Pop()
 		default:
 			wr.buf = append(wr.buf, cs...)
 			wr.buf = wr.appendString(wr.buf, kv.String(), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ": "...)
+			wr.cycles.Key(kv.String())
 			wr.appendSEN(rm.Interface(), d2)
+			wr.cycles.Pop()
+		}
+		empty = false
+	}
+	if !empty {
+		wr.buf = append(wr.buf, is...)
+	}
+	wr.buf = append(wr.buf, '}')
+}
+
+// appendOrdered writes an Ordered object with the members in order unless
+// the Sort option is set.
+func (wr *Writer) appendOrdered(n *gen.Ordered, depth int) {
+	d2 := depth + 1
+	var is string
+	var cs string
+	if wr.Tab {
+		x := depth + 1
+		if len(tabs) < x {
+			x = len(tabs)
+		}
+		is = tabs[0:x]
+		x = d2 + 1
+		if len(tabs) < x {
+			x = len(tabs)
+		}
+		cs = tabs[0:x]
+	} else if 0 < wr.Indent {
+		x := depth*wr.Indent + 1
+		if len(spaces) < x {
+			x = len(spaces)
+		}
+		is = spaces[0:x]
+		x = d2*wr.Indent + 1
+		if len(spaces) < x {
+			x = len(spaces)
+		}
+		cs = spaces[0:x]
+	}
+	members := n.Members
+	if wr.Sort {
+		members = n.Sorted()
+	}
+	empty := true
+	wr.buf = append(wr.buf, '{')
+	for _, m := range members {
+		switch tm := m.Value.(type) {
+		case nil:
+			if wr.OmitNil {
+				continue
+			}
+		case string:
+			if wr.OmitEmpty && len(tm) == 0 {
+				continue
+			}
+		case map[string]any:
+			if wr.OmitEmpty && len(tm) == 0 {
+				continue
+			}
+		case []any:
+			if wr.OmitEmpty && len(tm) == 0 {
+				continue
+			}
+		case *gen.Ordered:
+			if wr.OmitEmpty && tm.Len() == 0 {
+				continue
+			}
+		}
+		if !empty && len(cs) == 0 {
+			wr.buf = append(wr.buf, ' ')
 		}
 		empty = false
+		wr.buf = append(wr.buf, cs...)
+		wr.buf = ojg.AppendSENString(wr.buf, m.Key, !wr.HTMLUnsafe)
+		wr.buf = append(wr.buf, ':')
+		if 0 < len(cs) {
+			wr.buf = append(wr.buf, ' ')
+		}
+		wr.cycles.Key(m.Key)
+		wr.appendSEN(m.Value, d2)
+		wr.cycles.Pop()
 	}
 	if !empty {
 		wr.buf = append(wr.buf, is...)
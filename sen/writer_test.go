@@ -753,4 +753,53 @@ func TestMarshalTextMarshaler(t *testing.T) {
 	tt.Equal(t, `"-- 3 --"`, string(j))
 
 	tt.Panic(t, func() { _ = sen.Bytes(&TM{val: 5}) })
-}
\ No newline at end of file
+}
+
+func TestWriteOrdered(t *testing.T) {
+	p := sen.Parser{Ordered: true}
+	v, err := p.Parse([]byte(`{z: 1 a: {y: [1 "a b"]} m: null}`))
+	tt.Nil(t, err)
+	_, ok := v.(*gen.Ordered)
+	tt.Equal(t, true, ok)
+	tt.Equal(t, `{z:1 a:{y:[1 "a b"]} m:null}`, sen.String(v))
+	tt.Equal(t, `{a:{y:[1 "a b"]} m:null z:1}`, sen.String(v, &sen.Options{Sort: true}))
+	tt.Equal(t, `{
+  z: 1
+  a: {
+    y: [
+      1
+      "a b"
+    ]
+  }
+  m: null
+}`, sen.String(v, &sen.Options{Indent: 2}))
+}
+
+func TestWriteCycle(t *testing.T) {
+	m := map[string]any{}
+	m["a"] = []any{1, m}
+	tt.Equal(t, `{a:[1 {$ref:$}]}`, sen.String(m, &sen.Options{OnCycle: ojg.CycleRef}))
+	tt.Equal(t, `{a:[1 null]}`, sen.String(m, &sen.Options{OnCycle: ojg.CycleNull}))
+	tt.Equal(t, `{a:[1 {a:{@:$.a}}]}`, sen.String(map[string]any{"a": m["a"]}, &sen.Options{OnCycle: ojg.CycleRef, RefKey: "@"}))
+	tt.Equal(t, `{a:[1,{$ref:"$"}]}`, sen.JSON5(m, &sen.Options{OnCycle: ojg.CycleRef}))
+
+	type node struct {
+		Name string
+		Next *node
+	}
+	n := &node{Name: "x"}
+	n.Next = &node{Name: "y", Next: n}
+	for i := 0; i < 3; i++ {
+		tt.Equal(t, `{name:x next:{name:y next:{$ref:$}}}`, sen.String(n, &sen.Options{OnCycle: ojg.CycleRef}))
+	}
+	tt.Equal(t, `{
+  name: y
+  next: {
+    name: x
+    next: {
+      $ref: $
+    }
+  }
+}`, sen.String(n.Next, &sen.Options{OnCycle: ojg.CycleRef, Indent: 2}))
+	tt.Equal(t, `{name:"x",next:{name:"y",next:null}}`, sen.JSON5(n, &sen.Options{OnCycle: ojg.CycleNull, Sort: true}))
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package ojg
+
+import (
+	"strconv"
+)
+
+// Position in a source document. Lines and columns start at 1 and columns
+// are byte columns, the same as a ParseError.
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+// Span of a value in a source document. The End is the position just after
+// the last byte of the value. If the value is an object member then the key
+// span is also set otherwise KeyStart and KeyEnd are zero Positions.
+type Span struct {
+	Start    Position
+	End      Position
+	KeyStart Position
+	KeyEnd   Position
+}
+
+// Spans is a table of the spans of the values in a parsed document indexed
+// by path. When set on the oj, sen, or gen parsers the spans of each
+// document are recorded as the document is parsed and remain available
+// until the next document is parsed. Paths are made up of string keys and
+// int indexes just like an alt.Path. The jp.Expr.Span() function provides a
+// lookup with a JSONPath.
+type Spans struct {
+	spans    map[string]*Span
+	building map[string]*Span
+	frames   []spanFrame
+	last     *Span
+	key      []byte
+	keyed    bool
+	keyStart Position
+	keyEnd   Position
+}
+
+type spanFrame struct {
+	span  *Span
+	klen  int
+	index int
+}
+
+// Get the span of the value at the path or nil if there is no value at the
+// path. The path elements must be string object keys or int array indexes.
+func (s *Spans) Get(path ...any) *Span {
+	var key []byte
+	for _, p := range path {
+		switch tp := p.(type) {
+		case string:
+			key = spanKey(key, tp)
+		case int:
+			key = spanIndex(key, tp)
+		default:
+			return nil
+		}
+	}
+	return s.spans[string(key)]
+}
+
+// Len returns the number of spans in the table.
+func (s *Spans) Len() int {
+	return len(s.spans)
+}
+
+// Reset the table. Parsers call Reset at the start of each parse.
+func (s *Spans) Reset() {
+	s.spans = nil
+	s.Abort()
+}
+
+// Abort drops the spans of a document that has not been completed. The
+// spans of the last completed document are kept. Parsers call Abort when
+// discovering before each candidate document is parsed.
+func (s *Spans) Abort() {
+	s.building = nil
+	s.last = nil
+	s.frames = s.frames[:0]
+	s.key = s.key[:0]
+	s.keyed = false
+}
+
+// Key records the key of the object member that is about to begin along
+// with the start and end of the key in the source.
+func (s *Spans) Key(key string, start, end Position) {
+	if 0 < len(s.frames) {
+		s.key = spanKey(s.key[:s.frames[len(s.frames)-1].klen], key)
+		s.keyed = true
+		s.keyStart = start
+		s.keyEnd = end
+	}
+}
+
+// Begin records the start of a value. A value begun before the previous
+// value has ended is a member of that value. It is an object member if Key
+// was called first otherwise it is the next element of an array.
+func (s *Spans) Begin(pos Position) {
+	span := &Span{Start: pos}
+	switch {
+	case len(s.frames) == 0:
+		s.key = s.key[:0]
+		s.building = map[string]*Span{}
+	case s.keyed:
+		span.KeyStart = s.keyStart
+		span.KeyEnd = s.keyEnd
+		s.keyed = false
+	default:
+		f := &s.frames[len(s.frames)-1]
+		s.key = spanIndex(s.key[:f.klen], f.index)
+		f.index++
+	}
+	s.building[string(s.key)] = span
+	s.frames = append(s.frames, spanFrame{span: span, klen: len(s.key)})
+}
+
+// End records the end of the most recently begun value that has not
+// ended. When a document ends its spans replace those in the table.
+func (s *Spans) End(pos Position) {
+	if len(s.frames) == 0 {
+		return
+	}
+	s.last = s.frames[len(s.frames)-1].span
+	s.last.End = pos
+	s.frames = s.frames[:len(s.frames)-1]
+	if len(s.frames) == 0 {
+		s.spans = s.building
+		s.building = nil
+	}
+}
+
+// Extend moves the end of the value that ended most recently to pos. The
+// SEN parser calls Extend for strings joined with a +.
+func (s *Spans) Extend(pos Position) {
+	if s.last != nil {
+		s.last.End = pos
+	}
+}
+
+func spanKey(key []byte, k string) []byte {
+	key = append(key, 'k')
+	key = strconv.AppendInt(key, int64(len(k)), 10)
+	key = append(key, ':')
+	return append(key, k...)
+}
+
+func spanIndex(key []byte, i int) []byte {
+	key = append(key, 'i')
+	key = strconv.AppendInt(key, int64(i), 10)
+	return append(key, ';')
+}
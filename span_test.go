@@ -0,0 +1,158 @@
+// Copyright (c) 2023, Peter Ohler, All rights reserved.
+
+package ojg_test
+
+import (
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/sen"
+	"github.com/ohler55/ojg/tt"
+)
+
+const spanJSON = `{
+  "a": [1, "two", {"b": null}],
+  "c\"d": -1.5e3
+}`
+
+func checkSpans(t *testing.T, src string, spans *ojg.Spans) {
+	for i, d := range []struct {
+		path  []any
+		value string
+		key   string
+		line  int
+		col   int
+	}{
+		{path: []any{}, value: src, line: 1, col: 1},
+		{path: []any{"a"}, value: `[1, "two", {"b": null}]`, key: `"a"`, line: 2, col: 8},
+		{path: []any{"a", 0}, value: "1", line: 2, col: 9},
+		{path: []any{"a", 1}, value: `"two"`, line: 2, col: 12},
+		{path: []any{"a", 2, "b"}, value: "null", key: `"b"`, line: 2, col: 25},
+		{path: []any{`c"d`}, value: "-1.5e3", key: `"c\"d"`, line: 3, col: 11},
+	} {
+		span := spans.Get(d.path...)
+		tt.NotNil(t, span, i)
+		tt.Equal(t, d.value, src[span.Start.Offset:span.End.Offset], i)
+		tt.Equal(t, d.line, span.Start.Line, i)
+		tt.Equal(t, d.col, span.Start.Column, i)
+		if 0 < len(d.key) {
+			tt.Equal(t, d.key, src[span.KeyStart.Offset:span.KeyEnd.Offset], i)
+		} else {
+			tt.Equal(t, 0, span.KeyStart.Line, i)
+		}
+	}
+	tt.Equal(t, 7, spans.Len())
+	tt.Nil(t, spans.Get("x"))
+	tt.Nil(t, spans.Get("a", 3))
+	tt.Nil(t, spans.Get(true))
+}
+
+func TestSpansOj(t *testing.T) {
+	var spans ojg.Spans
+	p := oj.Parser{Spans: &spans}
+	_, err := p.Parse([]byte(spanJSON))
+	tt.Nil(t, err)
+	checkSpans(t, spanJSON, &spans)
+
+	_, err = p.ParseReader(strings.NewReader(spanJSON))
+	tt.Nil(t, err)
+	checkSpans(t, spanJSON, &spans)
+
+	// Each read returns one byte so every value crosses a buffer boundary.
+	_, err = p.ParseReader(iotest.OneByteReader(strings.NewReader(spanJSON)))
+	tt.Nil(t, err)
+	checkSpans(t, spanJSON, &spans)
+}
+
+func TestSpansGen(t *testing.T) {
+	var spans ojg.Spans
+	p := gen.Parser{Spans: &spans}
+	_, err := p.Parse([]byte(spanJSON))
+	tt.Nil(t, err)
+	checkSpans(t, spanJSON, &spans)
+
+	_, err = p.ParseReader(iotest.OneByteReader(strings.NewReader(spanJSON)))
+	tt.Nil(t, err)
+	checkSpans(t, spanJSON, &spans)
+}
+
+func TestSpansSen(t *testing.T) {
+	var spans ojg.Spans
+	p := sen.Parser{Spans: &spans}
+	_, err := p.Parse([]byte(spanJSON))
+	tt.Nil(t, err)
+	checkSpans(t, spanJSON, &spans)
+
+	src := `{a: [x // comment
+ 'y z'] b: "p" + "q", f: f(1 2)}`
+	_, err = p.ParseReader(iotest.OneByteReader(strings.NewReader(spanJSON)))
+	tt.Nil(t, err)
+	checkSpans(t, spanJSON, &spans)
+
+	for j, parse := range []func() error{
+		func() (err error) { _, err = p.Parse([]byte(src)); return },
+		func() (err error) {
+			_, err = p.ParseReader(iotest.OneByteReader(strings.NewReader(src)))
+			return
+		},
+	} {
+		tt.Nil(t, parse(), j)
+		for i, d := range []struct {
+			path  []any
+			value string
+			line  int
+		}{
+			{path: []any{"a", 0}, value: "x", line: 1},
+			{path: []any{"a", 1}, value: "'y z'", line: 2},
+			{path: []any{"b"}, value: `"p" + "q"`, line: 2},
+			{path: []any{"f"}, value: `f(1 2)`, line: 2},
+		} {
+			span := spans.Get(d.path...)
+			tt.NotNil(t, span, j, ".", i)
+			tt.Equal(t, d.value, src[span.Start.Offset:span.End.Offset], j, ".", i)
+			tt.Equal(t, d.line, span.Start.Line, j, ".", i)
+		}
+	}
+}
+
+func TestSpansMultiple(t *testing.T) {
+	var spans ojg.Spans
+	src := "{\"a\":1}\n[true,\n false]"
+	var found []string
+	p := oj.Parser{Spans: &spans}
+	_, err := p.Parse([]byte(src), func(any) bool {
+		span := spans.Get(0)
+		if span == nil {
+			span = spans.Get("a")
+		}
+		found = append(found, src[span.Start.Offset:span.End.Offset])
+		return false
+	})
+	tt.Nil(t, err)
+	tt.Equal(t, []string{"1", "true"}, found)
+
+	src = "xx {\"a\":1} yy [\n 2]"
+	found = found[:0]
+	_, err = p.Parse([]byte(src), ojg.DiscoverAny, func(any) bool {
+		span := spans.Get()
+		found = append(found, src[span.Start.Offset:span.End.Offset])
+		return false
+	})
+	tt.Nil(t, err)
+	tt.Equal(t, []string{`{"a":1}`, "[\n 2]"}, found)
+	tt.Equal(t, 2, spans.Get(0).Start.Line)
+
+	found = found[:0]
+	_, err = p.ParseReader(iotest.OneByteReader(strings.NewReader(src)), ojg.DiscoverAny, func(any) bool {
+		span := spans.Get()
+		found = append(found, src[span.Start.Offset:span.End.Offset])
+		return false
+	})
+	tt.Nil(t, err)
+	tt.Equal(t, []string{`{"a":1}`, "[\n 2]"}, found)
+	tt.Equal(t, 2, spans.Get(0).Start.Line)
+}